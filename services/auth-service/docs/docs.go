@@ -174,50 +174,6 @@ const docTemplate = `{
                 }
             }
         },
-        "/logout/{historyID}": {
-            "post": {
-                "description": "This endpoint records the logout time for a specific login history record.",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "login-history"
-                ],
-                "summary": "Record user logout",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "History ID",
-                        "name": "historyID",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Logout recorded successfully",
-                        "schema": {
-                            "$ref": "#/definitions/pkg.APIResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid history ID",
-                        "schema": {
-                            "$ref": "#/definitions/pkg.APIResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal server error",
-                        "schema": {
-                            "$ref": "#/definitions/pkg.APIResponse"
-                        }
-                    }
-                }
-            }
-        },
         "/register": {
             "post": {
                 "description": "This endpoint registers a new user with the provided credentials.",
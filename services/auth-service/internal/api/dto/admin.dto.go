@@ -0,0 +1,79 @@
+package dto
+
+import (
+	"auth-service/internal/models"
+	"time"
+)
+
+// ListUsersQuery narrows and pages the user listing.
+type ListUsersQuery struct {
+	Search   string `form:"search"` // Part of the username or email
+	Role     string `form:"role"`
+	Status   string `form:"status" binding:"omitempty,oneof=active disabled locked deleted"`
+	Verified *bool  `form:"verified"`
+	Offset   int    `form:"offset" binding:"min=0"`
+	Limit    int    `form:"limit" binding:"min=0,max=100"` // Defaults to 20
+}
+
+// AdminUserSummary describes an account in the user listing.
+type AdminUserSummary struct {
+	UserID                uint       `json:"userID"`
+	Username              string     `json:"username"`
+	Email                 string     `json:"email"`
+	Role                  string     `json:"role"`
+	Verified              bool       `json:"verified"`
+	PhoneNumber           string     `json:"phoneNumber,omitempty"`
+	PhoneVerified         bool       `json:"phoneVerified"`
+	AccountCreationDate   time.Time  `json:"accountCreationDate"`
+	LockedUntil           *time.Time `json:"lockedUntil,omitempty"`
+	DisabledAt            *time.Time `json:"disabledAt,omitempty"`
+	DisabledReason        string     `json:"disabledReason,omitempty"`
+	PasswordResetRequired bool       `json:"passwordResetRequired"`
+	DeletedAt             *time.Time `json:"deletedAt,omitempty"`
+}
+
+func FromUserModelForAdmin(u models.User) AdminUserSummary {
+	summary := AdminUserSummary{
+		UserID:                u.ID,
+		Username:              u.Username,
+		Email:                 u.Email,
+		Role:                  u.Role,
+		Verified:              u.Verified,
+		PhoneNumber:           u.PhoneNumber,
+		PhoneVerified:         u.PhoneVerified,
+		AccountCreationDate:   u.AccountCreationDate,
+		LockedUntil:           u.LockedUntil,
+		DisabledAt:            u.DisabledAt,
+		DisabledReason:        u.DisabledReason,
+		PasswordResetRequired: u.PasswordResetRequired,
+	}
+	if u.DeletedAt.Valid {
+		summary.DeletedAt = &u.DeletedAt.Time
+	}
+	return summary
+}
+
+// UserListResponse is a page of accounts with the total number of matches.
+type UserListResponse struct {
+	Users []AdminUserSummary `json:"users"`
+	Total int64              `json:"total"`
+}
+
+// AdminUserResponse describes an account with its verifications and latest login attempts.
+type AdminUserResponse struct {
+	AdminUserSummary
+	FailedLoginAttempts int                        `json:"failedLoginAttempts"`
+	Roles               []string                   `json:"roles"` // Roles given in addition to the account role
+	Verifications       []UserVerificationResponse `json:"verifications"`
+	LoginHistory        []LoginHistoryResponse     `json:"loginHistory"` // Newest first
+}
+
+// DisableUserRequest disables an account for the given reason.
+type DisableUserRequest struct {
+	Reason string `json:"reason" binding:"required,max=255"`
+}
+
+// ChangeUserRoleRequest changes the role of an account.
+type ChangeUserRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=admin customer"`
+}
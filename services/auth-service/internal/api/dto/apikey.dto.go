@@ -0,0 +1,65 @@
+package dto
+
+import (
+	"auth-service/internal/models"
+	"strings"
+	"time"
+)
+
+// CreateAPIKeyRequest issues an API key for a partner's server-side integration.
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" binding:"required,max=255"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1,dive,oneof=search book manifest:read"`
+	ExpiresAt *time.Time `json:"expiresAt"` // Keys without an expiry are valid until revoked
+}
+
+// APIKeyResponse describes an API key. The key itself is only returned when it is issued or rotated.
+type APIKeyResponse struct {
+	ID         uint       `json:"id"`
+	Key        string     `json:"key,omitempty"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedBy  uint       `json:"createdBy"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+	RevokedAt  *time.Time `json:"revokedAt"`
+}
+
+func FromAPIKeyModel(k models.APIKey) APIKeyResponse {
+	return APIKeyResponse{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     strings.Fields(k.Scopes),
+		CreatedBy:  k.CreatedBy,
+		CreatedAt:  k.CreatedAt,
+		LastUsedAt: k.LastUsedAt,
+		ExpiresAt:  k.ExpiresAt,
+		RevokedAt:  k.RevokedAt,
+	}
+}
+
+// RotateAPIKeyResponse carries the key replacing a rotated one. The rotated key keeps working until
+// PreviousKeyExpiresAt so the integration can be switched over.
+type RotateAPIKeyResponse struct {
+	APIKeyResponse
+	PreviousKeyExpiresAt time.Time `json:"previousKeyExpiresAt"`
+}
+
+// APIKeyIntrospectionRequest asks which account an API key acts as.
+type APIKeyIntrospectionRequest struct {
+	Key string `json:"key" binding:"required"`
+}
+
+// APIKeyIntrospectionResponse tells services whether an API key is active and, if so, the account it acts as and
+// its scopes. Inactive keys only carry Active.
+type APIKeyIntrospectionResponse struct {
+	Active   bool   `json:"active"`
+	KeyID    uint   `json:"keyId,omitempty"`
+	UserID   uint   `json:"userId,omitempty"`
+	Username string `json:"username,omitempty"`
+	Role     string `json:"role,omitempty"`
+	Scope    string `json:"scope,omitempty"` // Space separated
+}
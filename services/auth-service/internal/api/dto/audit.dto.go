@@ -0,0 +1,80 @@
+package dto
+
+import (
+	"auth-service/internal/models"
+	"encoding/json"
+	"time"
+)
+
+// Actor is who performs an audited action and from where. It is taken from the request, never from its body.
+type Actor struct {
+	UserID    uint   `json:"-"` // 0 when the actor is not signed in
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+// AuditEventQuery narrows and pages the audit log.
+type AuditEventQuery struct {
+	ActorID    *uint     `form:"actorID"`
+	Action     string    `form:"action"`
+	TargetType string    `form:"targetType"`
+	TargetID   string    `form:"targetID"`
+	From       time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Offset     int       `form:"offset" binding:"min=0"`
+	Limit      int       `form:"limit" binding:"min=0,max=500"` // Defaults to 50
+}
+
+// AuditExportQuery selects the audit events to export and the file format.
+type AuditExportQuery struct {
+	AuditEventQuery
+	Format string `form:"format" binding:"omitempty,oneof=csv json"` // Defaults to csv
+}
+
+// AuditEventResponse describes an entry of the audit log.
+type AuditEventResponse struct {
+	ID         uint                          `json:"id"`
+	CreatedAt  time.Time                     `json:"createdAt"`
+	ActorID    uint                          `json:"actorID"`
+	Action     string                        `json:"action"`
+	TargetType string                        `json:"targetType"`
+	TargetID   string                        `json:"targetID"`
+	IPAddress  string                        `json:"ipAddress"`
+	UserAgent  string                        `json:"userAgent"`
+	Changes    map[string]models.AuditChange `json:"changes,omitempty"`
+	Hash       string                        `json:"hash"`
+	PrevHash   string                        `json:"prevHash"`
+}
+
+func FromAuditEventModel(e models.AuditEvent) AuditEventResponse {
+	response := AuditEventResponse{
+		ID:         e.ID,
+		CreatedAt:  e.CreatedAt,
+		ActorID:    e.ActorID,
+		Action:     e.Action,
+		TargetType: e.TargetType,
+		TargetID:   e.TargetID,
+		IPAddress:  e.IPAddress,
+		UserAgent:  e.UserAgent,
+		Hash:       e.Hash,
+		PrevHash:   e.PrevHash,
+	}
+	if e.Changes != "" {
+		_ = json.Unmarshal([]byte(e.Changes), &response.Changes)
+	}
+	return response
+}
+
+// AuditEventListResponse is a page of audit events, newest first, with the total number of matches.
+type AuditEventListResponse struct {
+	Events []AuditEventResponse `json:"events"`
+	Total  int64                `json:"total"`
+}
+
+// AuditVerificationResponse reports whether the hash chain of the audit log is intact.
+type AuditVerificationResponse struct {
+	Valid      bool      `json:"valid"`
+	Checked    int64     `json:"checked"`              // Events checked, up to the first broken one
+	BrokenAtID uint      `json:"brokenAtID,omitempty"` // First event whose hash or link to the one before does not match
+	VerifiedAt time.Time `json:"verifiedAt"`
+}
@@ -0,0 +1,48 @@
+package dto
+
+import (
+	"auth-service/internal/models"
+	"time"
+)
+
+// AccountErasureStepResponse describes the erasure of the data one service holds about the user.
+type AccountErasureStepResponse struct {
+	Service     string     `json:"service"`
+	Status      string     `json:"status"`
+	Attempts    int        `json:"attempts"`
+	LastError   string     `json:"lastError,omitempty"`
+	CompletedAt *time.Time `json:"completedAt"`
+}
+
+// AccountErasureResponse describes the progress of the erasure of a deleted account across services.
+type AccountErasureResponse struct {
+	UserID      uint                         `json:"userID"`
+	RequestedBy uint                         `json:"requestedBy"`
+	Status      string                       `json:"status"`
+	ScheduledAt time.Time                    `json:"scheduledAt"` // When the erasure starts; the account can be restored until then
+	StartedAt   *time.Time                   `json:"startedAt"`
+	CompletedAt *time.Time                   `json:"completedAt"`
+	Steps       []AccountErasureStepResponse `json:"steps"`
+}
+
+func FromAccountErasureModel(e models.AccountErasure) AccountErasureResponse {
+	steps := make([]AccountErasureStepResponse, 0, len(e.Steps))
+	for _, step := range e.Steps {
+		steps = append(steps, AccountErasureStepResponse{
+			Service:     step.Service,
+			Status:      step.Status,
+			Attempts:    step.Attempts,
+			LastError:   step.LastError,
+			CompletedAt: step.CompletedAt,
+		})
+	}
+	return AccountErasureResponse{
+		UserID:      e.UserID,
+		RequestedBy: e.RequestedBy,
+		Status:      e.Status,
+		ScheduledAt: e.ScheduledAt,
+		StartedAt:   e.StartedAt,
+		CompletedAt: e.CompletedAt,
+		Steps:       steps,
+	}
+}
@@ -0,0 +1,30 @@
+package dto
+
+import (
+	"auth-service/internal/models"
+	"time"
+)
+
+// DataExportResponse describes a request for a copy of the user's personal data. The archive itself is only
+// downloaded through the link emailed once it is ready.
+type DataExportResponse struct {
+	ID           uint       `json:"id"`
+	Status       string     `json:"status"`
+	RequestedAt  time.Time  `json:"requestedAt"`
+	CompletedAt  *time.Time `json:"completedAt"`
+	ExpiresAt    *time.Time `json:"expiresAt"` // The download link works until then
+	DownloadedAt *time.Time `json:"downloadedAt"`
+	Size         int64      `json:"size"` // Size of the archive in bytes
+}
+
+func FromDataExportModel(e models.DataExport) DataExportResponse {
+	return DataExportResponse{
+		ID:           e.ID,
+		Status:       e.Status,
+		RequestedAt:  e.CreatedAt,
+		CompletedAt:  e.CompletedAt,
+		ExpiresAt:    e.ExpiresAt,
+		DownloadedAt: e.DownloadedAt,
+		Size:         e.Size,
+	}
+}
@@ -2,6 +2,7 @@ package dto
 
 import (
 	"auth-service/internal/models"
+	"strings"
 	"time"
 )
 
@@ -34,6 +35,10 @@ type LoginHistoryResponse struct {
 	DeviceInformation string    `json:"deviceInformation"`
 	Successful        bool      `json:"successful"`
 	FailureReason     string    `json:"failureReason"`
+	DeviceFingerprint string    `json:"deviceFingerprint,omitempty"`
+	Country           string    `json:"country,omitempty"`
+	Anomalies         []string  `json:"anomalies"`          // Set on successful logins that look unusual
+	Archived          bool      `json:"archived,omitempty"` // Moved to the archive by the retention job
 }
 
 func FromHistoryModel(l models.LoginHistory) LoginHistoryResponse {
@@ -46,9 +51,52 @@ func FromHistoryModel(l models.LoginHistory) LoginHistoryResponse {
 		DeviceInformation: l.DeviceInformation,
 		Successful:        l.Successful,
 		FailureReason:     l.FailureReason,
+		DeviceFingerprint: l.DeviceFingerprint,
+		Country:           l.Country,
+		Anomalies:         append([]string{}, strings.Fields(l.Anomalies)...),
 	}
 }
 
+// FromHistoryArchiveModel describes an archived login attempt like the attempts still in the login history.
+func FromHistoryArchiveModel(a models.LoginHistoryArchive) LoginHistoryResponse {
+	return LoginHistoryResponse{
+		LoginHistoryID:    a.ID,
+		UserID:            a.UserID,
+		LoginTime:         a.LoginTime,
+		LogoutTime:        a.LogoutTime,
+		IPAddress:         a.IPAddress,
+		DeviceInformation: a.DeviceInformation,
+		Successful:        a.Successful,
+		FailureReason:     a.FailureReason,
+		DeviceFingerprint: a.DeviceFingerprint,
+		Country:           a.Country,
+		Anomalies:         append([]string{}, strings.Fields(a.Anomalies)...),
+		Archived:          true,
+	}
+}
+
+// LoginHistoryFilterQuery narrows the login attempts of a user listed or exported.
+type LoginHistoryFilterQuery struct {
+	From       time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Successful *bool     `form:"successful"`
+	IPAddress  string    `form:"ipAddress" binding:"omitempty,ip"`
+	Device     string    `form:"device" binding:"max=255"` // Part of the device information, case-insensitive
+}
+
+// LoginHistoryQuery filters the login attempts of a user and pages through them, newest first.
+type LoginHistoryQuery struct {
+	LoginHistoryFilterQuery
+	Cursor string `form:"cursor"`                        // nextCursor of the previous page
+	Limit  int    `form:"limit" binding:"min=0,max=200"` // Defaults to 50
+}
+
+// LoginHistoryPageResponse is a page of login attempts, newest first. nextCursor is empty on the last page.
+type LoginHistoryPageResponse struct {
+	Attempts   []LoginHistoryResponse `json:"attempts"`
+	NextCursor string                 `json:"nextCursor"`
+}
+
 type UpdateLoginHistoryRequest struct {
 	LogoutTime    time.Time `json:"logoutTime" binding:"omitempty"`
 	Successful    bool      `json:"successful" binding:"omitempty"`
@@ -0,0 +1,43 @@
+package dto
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+)
+
+// JWK is a public key in JSON Web Key format (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`           // RSA or OKP
+	Kid string `json:"kid,omitempty"` // Key ID, matching the kid header of the tokens it verifies
+	Use string `json:"use,omitempty"` // Always sig
+	Alg string `json:"alg,omitempty"` // RS256 or EdDSA
+	N   string `json:"n,omitempty"`   // RSA modulus
+	E   string `json:"e,omitempty"`   // RSA public exponent
+	Crv string `json:"crv,omitempty"` // OKP curve, Ed25519
+	X   string `json:"x,omitempty"`   // OKP public key
+}
+
+// JWKSResponse is the JSON Web Key Set published at /.well-known/jwks.json.
+type JWKSResponse struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewJWK converts an RSA or Ed25519 public key to a JWK with only its required members set.
+func NewJWK(publicKey crypto.PublicKey) (JWK, error) {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}, nil
+	case ed25519.PublicKey:
+		return JWK{Kty: "OKP", Crv: "Ed25519", X: base64.RawURLEncoding.EncodeToString(key)}, nil
+	default:
+		return JWK{}, fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+}
@@ -0,0 +1,64 @@
+package dto
+
+import (
+	"auth-service/internal/models"
+	"time"
+)
+
+// MFAEnrollmentResponse carries a new TOTP secret, to be added to an authenticator app by scanning the QR code
+// or entering the secret.
+type MFAEnrollmentResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauthURI"`
+	QRCode     string `json:"qrCode"` // PNG data URI of the otpauth URI
+}
+
+// MFACodeRequest carries a TOTP code, or a recovery code where accepted.
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// RecoveryCodesResponse lists newly generated recovery codes. They are shown only once.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// MFAStatusResponse describes the MFA state of a user.
+type MFAStatusResponse struct {
+	Enabled                bool       `json:"enabled"`
+	Required               bool       `json:"required"` // MFA is required for the user's role
+	ConfirmedAt            *time.Time `json:"confirmedAt,omitempty"`
+	RecoveryCodesRemaining int64      `json:"recoveryCodesRemaining"`
+}
+
+// MFALoginRequest completes a login with the MFA token returned by the login endpoint and a TOTP or recovery code.
+type MFALoginRequest struct {
+	MFAToken string `json:"mfaToken" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// MFAEnrollLoginRequest starts the enrollment required to complete a login.
+type MFAEnrollLoginRequest struct {
+	MFAToken string `json:"mfaToken" binding:"required"`
+}
+
+// MFAPolicyRequest sets whether a role requires MFA.
+type MFAPolicyRequest struct {
+	Required bool `json:"required"`
+}
+
+type MFAPolicyResponse struct {
+	Role      string    `json:"role"`
+	Required  bool      `json:"required"`
+	UpdatedBy uint      `json:"updatedBy"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func FromMFAPolicyModel(p models.MFAPolicy) MFAPolicyResponse {
+	return MFAPolicyResponse{
+		Role:      p.Role,
+		Required:  p.Required,
+		UpdatedBy: p.UpdatedBy,
+		UpdatedAt: p.UpdatedAt,
+	}
+}
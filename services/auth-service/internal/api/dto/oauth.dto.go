@@ -0,0 +1,135 @@
+package dto
+
+import (
+	"auth-service/internal/models"
+	"strings"
+	"time"
+)
+
+// RegisterOAuthClientRequest registers a partner application with the OpenID Connect provider, or another
+// service of the platform.
+type RegisterOAuthClientRequest struct {
+	Name         string   `json:"name" binding:"required,max=255"`
+	RedirectURIs []string `json:"redirectUris" binding:"required_unless=Service true,omitempty,dive,url"`                                                         // Not used by service clients
+	Scopes       []string `json:"scopes" binding:"omitempty,dive,oneof=openid profile email routes:read buses:read seats:write ratings:read api-keys:introspect"` // Defaults to all supported scopes for partner applications
+	Public       bool     `json:"public"`                                                                                                                         // Clients that cannot keep a secret, such as mobile apps
+	Service      bool     `json:"service"`                                                                                                                        // Services using the client credentials grant, which must name their scopes
+}
+
+// OAuthClientResponse describes an OAuth client. The secret is only returned when the client is registered.
+type OAuthClientResponse struct {
+	ClientID     string    `json:"clientId"`
+	ClientSecret string    `json:"clientSecret,omitempty"`
+	Name         string    `json:"name"`
+	RedirectURIs []string  `json:"redirectUris"`
+	Scopes       []string  `json:"scopes"`
+	Public       bool      `json:"public"`
+	Service      bool      `json:"service"`
+	CreatedBy    uint      `json:"createdBy"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+func FromOAuthClientModel(c models.OAuthClient) OAuthClientResponse {
+	return OAuthClientResponse{
+		ClientID:     c.ClientID,
+		Name:         c.Name,
+		RedirectURIs: strings.Fields(c.RedirectURIs),
+		Scopes:       strings.Fields(c.Scopes),
+		Public:       c.Public,
+		Service:      c.Service,
+		CreatedBy:    c.CreatedBy,
+		CreatedAt:    c.CreatedAt,
+	}
+}
+
+// AuthorizationRequest is an OAuth 2.0 authorization request with PKCE, forwarded by the consent page on
+// behalf of the signed in user.
+type AuthorizationRequest struct {
+	ResponseType        string `form:"response_type" json:"response_type" binding:"required"`
+	ClientID            string `form:"client_id" json:"client_id" binding:"required"`
+	RedirectURI         string `form:"redirect_uri" json:"redirect_uri" binding:"required"`
+	Scope               string `form:"scope" json:"scope" binding:"required"`
+	State               string `form:"state" json:"state"`
+	Nonce               string `form:"nonce" json:"nonce"`
+	CodeChallenge       string `form:"code_challenge" json:"code_challenge" binding:"required"`
+	CodeChallengeMethod string `form:"code_challenge_method" json:"code_challenge_method" binding:"required"`
+	Approve             bool   `form:"-" json:"approve"` // The user's consent decision
+	UserID              uint   `form:"-" json:"-"`       // Taken from the token
+	SessionID           uint   `form:"-" json:"-"`       // Taken from the token
+}
+
+// OAuthClientSummary is what the consent page shows of a client.
+type OAuthClientSummary struct {
+	ClientID string `json:"clientId"`
+	Name     string `json:"name"`
+}
+
+// AuthorizationResponse either asks for the user's consent or carries the URI to send the user back to the
+// client with, holding the authorization code or the error.
+type AuthorizationResponse struct {
+	ConsentRequired bool               `json:"consentRequired"`
+	Client          OAuthClientSummary `json:"client"`
+	Scopes          []string           `json:"scopes"`
+	RedirectTo      string             `json:"redirectTo,omitempty"`
+}
+
+// TokenRequest is an OAuth 2.0 token request, sent form encoded. The client credentials may also be sent with
+// HTTP Basic authentication.
+type TokenRequest struct {
+	GrantType    string `form:"grant_type" binding:"required"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier"`
+	RefreshToken string `form:"refresh_token"`
+	Scope        string `form:"scope"` // Space separated scopes asked for with the client credentials grant
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+}
+
+// OAuthTokenResponse is the OAuth 2.0 token response.
+type OAuthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// OAuthErrorResponse is the OAuth 2.0 error response of the token endpoint.
+type OAuthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// UserInfoResponse holds the OpenID Connect claims of the user, limited to the granted scopes.
+type UserInfoResponse struct {
+	Subject           string `json:"sub"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	Email             string `json:"email,omitempty"`
+	EmailVerified     *bool  `json:"email_verified,omitempty"`
+}
+
+// OAuthConsentResponse describes the scopes a user allowed a client to access.
+type OAuthConsentResponse struct {
+	Client    OAuthClientSummary `json:"client"`
+	Scopes    []string           `json:"scopes"`
+	UpdatedAt time.Time          `json:"updatedAt"`
+}
+
+// DiscoveryDocument is the OpenID Connect provider metadata.
+type DiscoveryDocument struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserInfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
@@ -0,0 +1,32 @@
+package dto
+
+import "time"
+
+// PasswordlessLoginRequest asks for a sign-in link and code to be sent to the user with the given username or
+// email address.
+type PasswordlessLoginRequest struct {
+	Identifier string `json:"identifier" binding:"required,max=255"`       // Username or email address
+	Channel    string `json:"channel" binding:"omitempty,oneof=email sms"` // Defaults to email
+}
+
+// PasswordlessLoginResponse carries the login token to enter the code with. It is returned whether or not the
+// account exists.
+type PasswordlessLoginResponse struct {
+	LoginToken string    `json:"loginToken"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+// PasswordlessCodeRequest completes a passwordless login with the login token and the code sent to the user.
+type PasswordlessCodeRequest struct {
+	LoginToken        string `json:"loginToken" binding:"required"`
+	Code              string `json:"code" binding:"required,len=6,numeric"`
+	IPAddress         string `json:"-"` // Taken from the request
+	DeviceInformation string `json:"-"` // User agent of the request
+}
+
+// PasswordlessLinkRequest completes a passwordless login with the token of the link sent to the user.
+type PasswordlessLinkRequest struct {
+	Token             string `json:"token" binding:"required"`
+	IPAddress         string `json:"-"` // Taken from the request
+	DeviceInformation string `json:"-"` // User agent of the request
+}
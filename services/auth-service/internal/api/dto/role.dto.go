@@ -0,0 +1,63 @@
+package dto
+
+import (
+	"auth-service/internal/models"
+	"time"
+)
+
+// CreateRoleRequest creates a role with the permissions granted to its users.
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required,min=2,max=50"` // Lowercase letters, digits, - and _
+	Description string   `json:"description" binding:"max=255"`
+	Permissions []string `json:"permissions" binding:"required"`
+}
+
+// UpdateRoleRequest replaces the description and permissions of a role.
+type UpdateRoleRequest struct {
+	Description string   `json:"description" binding:"max=255"`
+	Permissions []string `json:"permissions" binding:"required"`
+}
+
+// RoleResponse describes a role and its permissions.
+type RoleResponse struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	System      bool      `json:"system"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+func FromRoleModel(r models.Role) RoleResponse {
+	permissions := make([]string, 0, len(r.Permissions))
+	for _, p := range r.Permissions {
+		permissions = append(permissions, p.Permission)
+	}
+	return RoleResponse{
+		Name:        r.Name,
+		Description: r.Description,
+		System:      r.System,
+		Permissions: permissions,
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+	}
+}
+
+// PermissionResponse describes a permission that can be granted to roles.
+type PermissionResponse struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// AssignRoleRequest gives a user a role in addition to the role of their account.
+type AssignRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// UserRolesResponse lists the roles of a user and the permissions they have through them.
+type UserRolesResponse struct {
+	UserID      uint     `json:"userId"`
+	AccountRole string   `json:"accountRole"` // Role of the account
+	Roles       []string `json:"roles"`       // Roles given in addition
+	Permissions []string `json:"permissions"`
+}
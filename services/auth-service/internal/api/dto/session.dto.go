@@ -0,0 +1,37 @@
+package dto
+
+import (
+	"auth-service/internal/models"
+	"time"
+)
+
+// SessionResponse describes a device or application a user is signed in on.
+type SessionResponse struct {
+	ID                uint      `json:"id"`
+	ClientID          string    `json:"clientId,omitempty"` // OAuth client the session was granted to
+	IPAddress         string    `json:"ipAddress"`
+	DeviceInformation string    `json:"deviceInformation"`
+	CreatedAt         time.Time `json:"createdAt"`
+	LastSeenAt        time.Time `json:"lastSeenAt"`
+	Current           bool      `json:"current"` // The session of the caller
+}
+
+func FromSessionModel(s models.Session, currentSessionID uint) SessionResponse {
+	return SessionResponse{
+		ID:                s.ID,
+		ClientID:          s.ClientID,
+		IPAddress:         s.IPAddress,
+		DeviceInformation: s.DeviceInformation,
+		CreatedAt:         s.CreatedAt,
+		LastSeenAt:        s.LastSeenAt,
+		Current:           s.ID == currentSessionID,
+	}
+}
+
+// SessionIntrospectionResponse tells other services that the session of an access token is active. Tokens of
+// revoked sessions are rejected with 401 instead.
+type SessionIntrospectionResponse struct {
+	Active    bool `json:"active"`
+	SessionID uint `json:"sessionId"`
+	UserID    uint `json:"userId"`
+}
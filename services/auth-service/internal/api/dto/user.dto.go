@@ -2,24 +2,22 @@ package dto
 
 import (
 	"auth-service/internal/models"
-	"golang.org/x/crypto/bcrypt"
 	"time"
 )
 
 type CreateUserRequest struct {
 	Username string `json:"username" binding:"required,alphanum,min=3,max=255"`
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
-	Role     string `json:"role" binding:"required,oneof=admin customer"`
+	Password string `json:"password" binding:"required"` // Checked against the password policy
 }
 
+// ToUserModel returns the user to create, without a password; the service sets its hash. Users registering
+// themselves are always customers, other roles are given by admins.
 func (c *CreateUserRequest) ToUserModel() models.User {
-	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(c.Password), bcrypt.DefaultCost)
 	return models.User{
 		Username:            c.Username,
 		Email:               c.Email,
-		Password:            string(hashedPassword),
-		Role:                c.Role,
+		Role:                models.RoleCustomer,
 		AccountCreationDate: time.Now(),
 		Verified:            false,
 	}
@@ -32,6 +30,8 @@ type UserResponse struct {
 	Role                string    `json:"role"`
 	AccountCreationDate time.Time `json:"accountCreationDate"`
 	Verified            bool      `json:"verified"`
+	PhoneNumber         string    `json:"phoneNumber,omitempty"`
+	PhoneVerified       bool      `json:"phoneVerified"`
 }
 
 func FromUserModel(u models.User) UserResponse {
@@ -42,12 +42,13 @@ func FromUserModel(u models.User) UserResponse {
 		Role:                u.Role,
 		AccountCreationDate: u.AccountCreationDate,
 		Verified:            u.Verified,
+		PhoneNumber:         u.PhoneNumber,
+		PhoneVerified:       u.PhoneVerified,
 	}
 }
 
 type UpdateUserRequest struct {
 	Email    string `json:"email" binding:"omitempty,email"`
-	Password string `json:"password" binding:"omitempty,min=6"`
 	Role     string `json:"role" binding:"omitempty,oneof=admin customer"`
 	Verified bool   `json:"verified" binding:"omitempty"`
 }
@@ -57,10 +58,6 @@ func (u *UpdateUserRequest) ToUpdateUserModel() models.User {
 	if u.Email != "" {
 		user.Email = u.Email
 	}
-	if u.Password != "" {
-		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
-		user.Password = string(hashedPassword)
-	}
 	if u.Role != "" {
 		user.Role = u.Role
 	}
@@ -68,34 +65,57 @@ func (u *UpdateUserRequest) ToUpdateUserModel() models.User {
 }
 
 type UserLoginDTO struct {
-	Username string `json:"username" binding:"required,alphanum,min=3,max=255"`
-	Password string `json:"password" binding:"required,min=6"`
+	Username          string `json:"username" binding:"required,alphanum,min=3,max=255"`
+	Password          string `json:"password" binding:"required,min=6"`
+	IPAddress         string `json:"-"` // Taken from the request
+	DeviceInformation string `json:"-"` // User agent of the request
 }
 
 // UserPasswordUpdateDTO represents the data required to update a user's password.
 type UserPasswordUpdateDTO struct {
-	UserID   uint   `json:"userID" binding:"required"` // Ensure UserID is passed securely, not from client directly in a real-world scenario
-	Password string `json:"password" binding:"required,min=6"`
+	UserID          uint   `json:"-"` // Taken from the path
+	SessionID       uint   `json:"-"` // Session of the caller, kept signed in
+	CurrentPassword string `json:"currentPassword" binding:"required"`
+	Password        string `json:"password" binding:"required"` // Checked against the password policy
 }
 
-// ToUpdatePasswordModel Function to update a user's password in the user model
-func (dto *UserPasswordUpdateDTO) ToUpdatePasswordModel() models.User {
-	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(dto.Password), bcrypt.DefaultCost)
-	return models.User{
-		Password: string(hashedPassword),
-	}
+// UserLoginResponseDto includes the user's ID and the tokens of their session. When MFA is required, it only
+// carries the MFA token to complete the login with.
+type UserLoginResponseDto struct {
+	UserID                uint     `json:"userID"`
+	Token                 string   `json:"token,omitempty"` // Short-lived access token
+	RefreshToken          string   `json:"refreshToken,omitempty"`
+	TokenType             string   `json:"tokenType,omitempty"`
+	ExpiresIn             int64    `json:"expiresIn,omitempty"` // Access token lifetime in seconds
+	MFARequired           bool     `json:"mfaRequired,omitempty"`
+	MFAEnrollmentRequired bool     `json:"mfaEnrollmentRequired,omitempty"` // MFA must be set up before the login completes
+	MFAToken              string   `json:"mfaToken,omitempty"`
+	RecoveryCodes         []string `json:"recoveryCodes,omitempty"` // Set when MFA was set up during the login
 }
 
-// UserLoginResponseDto  includes only the user's ID and JWT token for authenticated sessions.
-type UserLoginResponseDto struct {
-	UserID uint   `json:"userID"`
-	Token  string `json:"token"`
+// MFAChallengeResponse creates the response of a login that needs an MFA code to complete.
+func MFAChallengeResponse(userID uint, mfaToken string, enrollmentRequired bool) *UserLoginResponseDto {
+	return &UserLoginResponseDto{
+		UserID:                userID,
+		MFARequired:           true,
+		MFAEnrollmentRequired: enrollmentRequired,
+		MFAToken:              mfaToken,
+	}
 }
 
 // UserLoginResponse  creates a new instance of UserLoginResponse.
-func UserLoginResponse(userID uint, token string) *UserLoginResponseDto {
+func UserLoginResponse(userID uint, token, refreshToken string, expiresIn int64) *UserLoginResponseDto {
 	return &UserLoginResponseDto{
-		UserID: userID,
-		Token:  token,
+		UserID:       userID,
+		Token:        token,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    expiresIn,
 	}
 }
+
+// RefreshTokenRequest carries the refresh token of a session, to rotate it or to log out.
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+	IPAddress    string `json:"-"` // Taken from the request
+}
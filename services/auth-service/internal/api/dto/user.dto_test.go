@@ -0,0 +1,28 @@
+package dto
+
+import (
+	"auth-service/internal/models"
+	"encoding/json"
+	"testing"
+)
+
+func TestCreateUserRequestToUserModelRole(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "no role", body: `{"username":"ann","email":"ann@example.com","password":"correct horse"}`},
+		{name: "admin role requested", body: `{"username":"ann","email":"ann@example.com","password":"correct horse","role":"admin"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req CreateUserRequest
+			if err := json.Unmarshal([]byte(tt.body), &req); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if user := req.ToUserModel(); user.Role != models.RoleCustomer {
+				t.Errorf("ToUserModel() role = %q, want %q", user.Role, models.RoleCustomer)
+			}
+		})
+	}
+}
@@ -2,27 +2,12 @@ package dto
 
 import (
 	"auth-service/internal/models"
-	"auth-service/pkg"
 	"time"
 )
 
 type CreateUserVerificationRequest struct {
 	UserID           uint   `json:"userID" binding:"required"`
-	VerificationType string `json:"verificationType" binding:"required"`
-}
-
-func (v *CreateUserVerificationRequest) ToVerificationModel() models.UserVerification {
-	token, err := pkg.GenerateToken()
-	if err != nil {
-		panic(err)
-	}
-	return models.UserVerification{
-		UserID:             v.UserID,
-		VerificationType:   v.VerificationType,
-		VerificationStatus: "pending",
-		VerificationToken:  token,
-		ExpirationDate:     time.Now().Add(24 * time.Hour), // Expiration typically set for 24 hours later
-	}
+	VerificationType string `json:"verificationType" binding:"required,oneof=email password_reset phone"`
 }
 
 type UserVerificationResponse struct {
@@ -30,7 +15,6 @@ type UserVerificationResponse struct {
 	UserID             uint      `json:"userID"`
 	VerificationType   string    `json:"verificationType"`
 	VerificationStatus string    `json:"verificationStatus"`
-	VerificationToken  string    `json:"verificationToken"`
 	ExpirationDate     time.Time `json:"expirationDate"`
 	VerifiedAt         time.Time `json:"verifiedAt"`
 }
@@ -41,12 +25,22 @@ func FromVerificationModel(v models.UserVerification) UserVerificationResponse {
 		UserID:             v.UserID,
 		VerificationType:   v.VerificationType,
 		VerificationStatus: v.VerificationStatus,
-		VerificationToken:  v.VerificationToken,
 		ExpirationDate:     v.ExpirationDate,
 		VerifiedAt:         v.VerifiedAt,
 	}
 }
 
+// ForgotPasswordRequest asks for a password reset link to be emailed.
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest sets a new password with the token of a password reset email.
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"` // Checked against the password policy
+}
+
 type UpdateUserVerificationRequest struct {
 	VerificationStatus string    `json:"verificationStatus" binding:"required,oneof=pending verified failed"`
 	VerifiedAt         time.Time `json:"verifiedAt" binding:"omitempty"`
@@ -60,3 +54,19 @@ func (u *UpdateUserVerificationRequest) ToUpdateVerificationModel() models.UserV
 	}
 	return verification
 }
+
+// PhoneNumberRequest asks for a verification code to be sent by SMS to a phone number in E.164 format.
+type PhoneNumberRequest struct {
+	PhoneNumber string `json:"phoneNumber" binding:"required,e164"`
+}
+
+// PhoneVerificationResponse tells where a phone verification code was sent and until when it can be entered.
+type PhoneVerificationResponse struct {
+	PhoneNumber string    `json:"phoneNumber"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+}
+
+// PhoneCodeRequest verifies a phone number with the code sent to it.
+type PhoneCodeRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
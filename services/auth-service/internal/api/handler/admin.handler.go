@@ -0,0 +1,251 @@
+package handler
+
+import (
+	"auth-service/internal/api/dto"
+	"auth-service/internal/services"
+	"auth-service/pkg"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type UserAdminHandler struct {
+	userAdminService services.IUserAdminService
+}
+
+func NewUserAdminHandler(userAdminService services.IUserAdminService) *UserAdminHandler {
+	return &UserAdminHandler{
+		userAdminService: userAdminService,
+	}
+}
+
+// ListUsers handles GET /users endpoint
+// @Summary List users
+// @Description This endpoint lists accounts in the order they were created, optionally searching usernames and emails and filtering by role, status and verification. Deleted accounts are only listed with the deleted status. Requires the users:read permission.
+// @Tags users
+// @Produce json
+// @Param search query string false "Part of the username or email"
+// @Param role query string false "Account role"
+// @Param status query string false "active, disabled, locked or deleted"
+// @Param verified query bool false "Whether the email address is verified"
+// @Param offset query int false "Pagination offset"
+// @Param limit query int false "Pagination limit, at most 100"
+// @Success 200 {object} pkg.APIResponse "Users fetched successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid query parameters"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 403 {object} pkg.APIResponse "Missing permission"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /users [get]
+func (h *UserAdminHandler) ListUsers(c *gin.Context) {
+	var query dto.ListUsersQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid query parameters: %v", err))
+		return
+	}
+
+	response, err := h.userAdminService.ListUsers(query)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "Users fetched successfully")
+}
+
+// GetUser handles GET /users/{userID} endpoint
+// @Summary Get user
+// @Description This endpoint shows an account, including deleted ones, with its additional roles, verifications and latest login attempts. Requires the users:read permission.
+// @Tags users
+// @Produce json
+// @Param userID path int true "User ID"
+// @Success 200 {object} pkg.APIResponse "User fetched successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid user ID format"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 403 {object} pkg.APIResponse "Missing permission"
+// @Failure 404 {object} pkg.APIResponse "User not found"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /users/{userID} [get]
+func (h *UserAdminHandler) GetUser(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userID"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid user ID format: %v", err))
+		return
+	}
+
+	response, err := h.userAdminService.GetUser(uint(userID))
+	if err != nil {
+		pkg.RespondWithError(c, userAdminErrorStatus(err), err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "User fetched successfully")
+}
+
+// DisableUser handles POST /users/{id}/disable endpoint
+// @Summary Disable user
+// @Description This endpoint disables an account for the given reason. Its sessions end and logins are refused until it is enabled again. Admins cannot disable their own account. Admin only.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body dto.DisableUserRequest true "Disable User Request"
+// @Success 200 {object} pkg.APIResponse "User disabled successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid user ID format or request data"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 404 {object} pkg.APIResponse "User not found"
+// @Failure 409 {object} pkg.APIResponse "Own account or deleted user"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /users/{id}/disable [post]
+func (h *UserAdminHandler) DisableUser(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid user ID format: %v", err))
+		return
+	}
+
+	var req dto.DisableUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid request data: %v", err))
+		return
+	}
+
+	response, err := h.userAdminService.DisableUser(uint(userID), req, currentActor(c))
+	if err != nil {
+		pkg.RespondWithError(c, userAdminErrorStatus(err), err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "User disabled successfully")
+}
+
+// EnableUser handles POST /users/{id}/enable endpoint
+// @Summary Enable user
+// @Description This endpoint allows logins to a disabled account again. Admin only.
+// @Tags users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} pkg.APIResponse "User enabled successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid user ID format"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 404 {object} pkg.APIResponse "User not found"
+// @Failure 409 {object} pkg.APIResponse "User is not disabled or deleted"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /users/{id}/enable [post]
+func (h *UserAdminHandler) EnableUser(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid user ID format: %v", err))
+		return
+	}
+
+	response, err := h.userAdminService.EnableUser(uint(userID), currentActor(c))
+	if err != nil {
+		pkg.RespondWithError(c, userAdminErrorStatus(err), err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "User enabled successfully")
+}
+
+// ChangeRole handles PUT /users/{id}/role endpoint
+// @Summary Change user role
+// @Description This endpoint changes the role of an account. Its sessions end so the user signs in again with the permissions of the new role. Admins cannot change the role of their own account. Admin only.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body dto.ChangeUserRoleRequest true "Change User Role Request"
+// @Success 200 {object} pkg.APIResponse "User role changed successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid user ID format or request data"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 404 {object} pkg.APIResponse "User not found"
+// @Failure 409 {object} pkg.APIResponse "Own account or deleted user"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /users/{id}/role [put]
+func (h *UserAdminHandler) ChangeRole(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid user ID format: %v", err))
+		return
+	}
+
+	var req dto.ChangeUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid request data: %v", err))
+		return
+	}
+
+	response, err := h.userAdminService.ChangeRole(uint(userID), req, currentActor(c))
+	if err != nil {
+		pkg.RespondWithError(c, userAdminErrorStatus(err), err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "User role changed successfully")
+}
+
+// ForcePasswordReset handles POST /users/{id}/password-reset endpoint
+// @Summary Force password reset
+// @Description This endpoint ends the sessions of an account and emails the user a password reset link. Logins are refused until the password is reset. Admin only.
+// @Tags users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} pkg.APIResponse "Password reset required successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid user ID format"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 404 {object} pkg.APIResponse "User not found"
+// @Failure 409 {object} pkg.APIResponse "User is deleted"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /users/{id}/password-reset [post]
+func (h *UserAdminHandler) ForcePasswordReset(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid user ID format: %v", err))
+		return
+	}
+
+	if err := h.userAdminService.ForcePasswordReset(uint(userID), currentActor(c)); err != nil {
+		pkg.RespondWithError(c, userAdminErrorStatus(err), err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, nil, "Password reset required successfully")
+}
+
+// RestoreUser handles POST /users/{id}/restore endpoint
+// @Summary Restore user
+// @Description This endpoint restores a deleted account and cancels the erasure of its data, which is only possible until the grace period ends. The user signs in again as their sessions ended when it was deleted. Admin only.
+// @Tags users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} pkg.APIResponse "User restored successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid user ID format"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 404 {object} pkg.APIResponse "User not found"
+// @Failure 409 {object} pkg.APIResponse "User is not deleted, or their data was erased"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /users/{id}/restore [post]
+func (h *UserAdminHandler) RestoreUser(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid user ID format: %v", err))
+		return
+	}
+
+	response, err := h.userAdminService.RestoreUser(uint(userID), currentActor(c))
+	if err != nil {
+		pkg.RespondWithError(c, userAdminErrorStatus(err), err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "User restored successfully")
+}
+
+// userAdminErrorStatus maps user administration errors to HTTP status codes.
+func userAdminErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrUserNotDeleted), errors.Is(err, services.ErrUserDeleted),
+		errors.Is(err, services.ErrOwnAccount), errors.Is(err, services.ErrUserNotDisabled),
+		errors.Is(err, services.ErrAccountErasureStarted):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
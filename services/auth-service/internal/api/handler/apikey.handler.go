@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"auth-service/internal/api/dto"
+	"auth-service/internal/services"
+	"auth-service/pkg"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type APIKeyHandler struct {
+	apiKeyService services.IAPIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService services.IAPIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+// CreateKey handles POST /users/{id}/api-keys endpoint
+// @Summary Issue API key
+// @Description This endpoint issues an API key for the server-side integration of a partner account, limited to the given scopes. The key is only returned now. Admin accounts cannot have API keys.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param key body dto.CreateAPIKeyRequest true "Create API Key Request"
+// @Success 201 {object} pkg.APIResponse "API key issued successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid request data"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 403 {object} pkg.APIResponse "Not allowed to issue keys for the account"
+// @Failure 404 {object} pkg.APIResponse "User not found"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /users/{id}/api-keys [post]
+func (h *APIKeyHandler) CreateKey(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid user ID format: %v", err))
+		return
+	}
+
+	var req dto.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid request data: %v", err))
+		return
+	}
+	response, err := h.apiKeyService.CreateKey(uint(userID), req, currentActor(c))
+	if err != nil {
+		pkg.RespondWithError(c, apiKeyErrorStatus(err), err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusCreated, response, "API key issued successfully")
+}
+
+// ListKeys handles GET /users/{userID}/api-keys endpoint
+// @Summary List API keys
+// @Description This endpoint lists the API keys of an account with their scopes and last use, without the keys themselves.
+// @Tags api-keys
+// @Produce json
+// @Param userID path int true "User ID"
+// @Success 200 {object} pkg.APIResponse "API keys fetched successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid user ID format"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /users/{userID}/api-keys [get]
+func (h *APIKeyHandler) ListKeys(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userID"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid user ID format: %v", err))
+		return
+	}
+
+	responses, err := h.apiKeyService.ListKeys(uint(userID))
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, responses, "API keys fetched successfully")
+}
+
+// RotateKey handles POST /users/{id}/api-keys/{keyID}/rotate endpoint
+// @Summary Rotate API key
+// @Description This endpoint issues a key with the same name, scopes and expiry to replace an API key. The replaced key keeps working for a grace period so the integration can be switched over.
+// @Tags api-keys
+// @Produce json
+// @Param id path int true "User ID"
+// @Param keyID path int true "API Key ID"
+// @Success 201 {object} pkg.APIResponse "API key rotated successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid ID format"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 404 {object} pkg.APIResponse "API key not found"
+// @Failure 409 {object} pkg.APIResponse "API key revoked or expired"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /users/{id}/api-keys/{keyID}/rotate [post]
+func (h *APIKeyHandler) RotateKey(c *gin.Context) {
+	userID, keyID, err := apiKeyParams(c)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	response, err := h.apiKeyService.RotateKey(userID, keyID, currentActor(c))
+	if err != nil {
+		pkg.RespondWithError(c, apiKeyErrorStatus(err), err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusCreated, response, "API key rotated successfully")
+}
+
+// RevokeKey handles DELETE /users/{id}/api-keys/{keyID} endpoint
+// @Summary Revoke API key
+// @Description This endpoint revokes an API key. Services may keep accepting it for up to a minute while they cache it.
+// @Tags api-keys
+// @Produce json
+// @Param id path int true "User ID"
+// @Param keyID path int true "API Key ID"
+// @Success 200 {object} pkg.APIResponse "API key revoked successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid ID format"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 404 {object} pkg.APIResponse "API key not found"
+// @Failure 409 {object} pkg.APIResponse "API key already revoked"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /users/{id}/api-keys/{keyID} [delete]
+func (h *APIKeyHandler) RevokeKey(c *gin.Context) {
+	userID, keyID, err := apiKeyParams(c)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.apiKeyService.RevokeKey(userID, keyID, currentActor(c)); err != nil {
+		pkg.RespondWithError(c, apiKeyErrorStatus(err), err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, nil, "API key revoked successfully")
+}
+
+// Introspect handles POST /api-keys/introspect endpoint
+// @Summary Introspect API key
+// @Description This endpoint tells services, calling with a service token, whether an API key is active and, if so, the account it acts as and its scopes. Inactive keys are reported with active set to false.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param request body dto.APIKeyIntrospectionRequest true "API Key Introspection Request"
+// @Success 200 {object} dto.APIKeyIntrospectionResponse "API key state"
+// @Failure 400 {object} pkg.APIResponse "Invalid request data"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid service token"
+// @Failure 403 {object} pkg.APIResponse "The service client was not granted the api-keys:introspect scope"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /api-keys/introspect [post]
+func (h *APIKeyHandler) Introspect(c *gin.Context) {
+	var req dto.APIKeyIntrospectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid request data: %v", err))
+		return
+	}
+
+	response, err := h.apiKeyService.Introspect(req.Key)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	// Served bare, like the OAuth endpoints, since only services read it.
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, response)
+}
+
+// apiKeyParams parses the user and API key IDs of the path.
+func apiKeyParams(c *gin.Context) (uint, uint, error) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid user ID format: %v", err)
+	}
+	keyID, err := strconv.ParseUint(c.Param("keyID"), 10, 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid API key ID format: %v", err)
+	}
+	return uint(userID), uint(keyID), nil
+}
+
+// apiKeyErrorStatus maps API key service errors to HTTP status codes.
+func apiKeyErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrAPIKeyExpiryInPast):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrAPIKeyAdminAccount):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrAPIKeyNotFound), errors.Is(err, services.ErrAPIKeyOwnerNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrAPIKeyRevoked):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
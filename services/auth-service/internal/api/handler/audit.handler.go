@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"auth-service/internal/api/dto"
+	"auth-service/internal/services"
+	"auth-service/pkg"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"shared/authz"
+	"time"
+)
+
+type AuditHandler struct {
+	auditService services.IAuditService
+}
+
+func NewAuditHandler(auditService services.IAuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// ListEvents handles GET /audit-events endpoint
+// @Summary List audit events
+// @Description This endpoint searches the security audit log, newest first: who changed passwords, roles, MFA, API keys and accounts, from where, and the values before and after. Requires the audit:read permission.
+// @Tags audit
+// @Produce json
+// @Param actorID query int false "User who performed the action, 0 for actions taken without signing in"
+// @Param action query string false "Action, such as user.role_changed"
+// @Param targetType query string false "Type of the target, such as user or role"
+// @Param targetID query string false "ID of the target"
+// @Param from query string false "Earliest time, RFC 3339"
+// @Param to query string false "Time before which events are listed, RFC 3339"
+// @Param offset query int false "Pagination offset"
+// @Param limit query int false "Pagination limit, at most 500"
+// @Success 200 {object} pkg.APIResponse "Audit events fetched successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid query parameters"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 403 {object} pkg.APIResponse "Missing permission"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /audit-events [get]
+func (h *AuditHandler) ListEvents(c *gin.Context) {
+	var query dto.AuditEventQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid query parameters: %v", err))
+		return
+	}
+
+	response, err := h.auditService.ListEvents(query)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "Audit events fetched successfully")
+}
+
+// ExportEvents handles GET /audit-events/export endpoint
+// @Summary Export audit events
+// @Description This endpoint downloads every audit event matching the filters, in the order they were written, as CSV or JSON. Events include their hashes, so the chain can be checked outside the service. Exports are recorded in the audit log. Requires the audit:read permission.
+// @Tags audit
+// @Produce text/csv
+// @Produce json
+// @Param format query string false "csv or json, defaults to csv"
+// @Param actorID query int false "User who performed the action"
+// @Param action query string false "Action, such as user.role_changed"
+// @Param targetType query string false "Type of the target, such as user or role"
+// @Param targetID query string false "ID of the target"
+// @Param from query string false "Earliest time, RFC 3339"
+// @Param to query string false "Time before which events are exported, RFC 3339"
+// @Success 200 {file} file "Audit events"
+// @Failure 400 {object} pkg.APIResponse "Invalid query parameters"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 403 {object} pkg.APIResponse "Missing permission"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /audit-events/export [get]
+func (h *AuditHandler) ExportEvents(c *gin.Context) {
+	var query dto.AuditExportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid query parameters: %v", err))
+		return
+	}
+
+	extension, contentType := "csv", "text/csv"
+	if query.Format == "json" {
+		extension, contentType = "json", "application/json"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="audit-events-%s.%s"`, time.Now().UTC().Format("20060102T150405Z"), extension))
+	c.Header("Cache-Control", "no-store")
+
+	if err := h.auditService.ExportEvents(currentActor(c), query, c.Writer); err != nil {
+		// Once streaming started the status is sent, the export is cut short instead
+		if !c.Writer.Written() {
+			c.Header("Content-Type", "")
+			c.Header("Content-Disposition", "")
+			pkg.RespondWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+		log.Printf("Audit log export failed: %v", err)
+		c.Abort()
+	}
+}
+
+// VerifyChain handles GET /audit-events/verify endpoint
+// @Summary Verify the audit log
+// @Description This endpoint recomputes the hash chain of the whole audit log to detect events changed or removed outside the service, and reports the first event that does not match. Requires the audit:read permission.
+// @Tags audit
+// @Produce json
+// @Success 200 {object} pkg.APIResponse "Audit log verified"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 403 {object} pkg.APIResponse "Missing permission"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /audit-events/verify [get]
+func (h *AuditHandler) VerifyChain(c *gin.Context) {
+	response, err := h.auditService.VerifyChain()
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "Audit log verified")
+}
+
+// currentActor returns who makes the request and from where, for the audit log.
+func currentActor(c *gin.Context) dto.Actor {
+	actor := dto.Actor{IPAddress: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+	if principal, ok := authz.CurrentPrincipal(c); ok {
+		actor.UserID = principal.UserID
+	}
+	return actor
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"auth-service/internal/services"
+	"auth-service/pkg"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type AccountErasureHandler struct {
+	erasureService services.IAccountErasureService
+}
+
+func NewAccountErasureHandler(erasureService services.IAccountErasureService) *AccountErasureHandler {
+	return &AccountErasureHandler{
+		erasureService: erasureService,
+	}
+}
+
+// GetErasure handles GET /users/{userID}/erasure endpoint
+// @Summary Get account erasure
+// @Description This endpoint reports the erasure of the data of a deleted account across services: when it is scheduled, and whether each service has erased its data. Requires the users:read permission.
+// @Tags users
+// @Produce json
+// @Param userID path int true "User ID"
+// @Success 200 {object} pkg.APIResponse "Account erasure retrieved successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid user ID format"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 404 {object} pkg.APIResponse "No erasure was scheduled for the account"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /users/{userID}/erasure [get]
+func (h *AccountErasureHandler) GetErasure(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userID"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid user ID format: %v", err))
+		return
+	}
+
+	response, err := h.erasureService.GetErasure(uint(userID))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrAccountErasureNotFound) {
+			status = http.StatusNotFound
+		}
+		pkg.RespondWithError(c, status, err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "Account erasure retrieved successfully")
+}
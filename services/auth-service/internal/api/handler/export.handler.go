@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"auth-service/internal/services"
+	"auth-service/pkg"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type DataExportHandler struct {
+	exportService services.IDataExportService
+}
+
+func NewDataExportHandler(exportService services.IDataExportService) *DataExportHandler {
+	return &DataExportHandler{
+		exportService: exportService,
+	}
+}
+
+// RequestExport handles POST /users/{id}/data-exports endpoint
+// @Summary Request a copy of personal data
+// @Description This endpoint asks for a copy of the data every service holds about the user: account, login history, profile, notification preferences and history, and bookings. It is prepared in the background as a ZIP archive of JSON files, and the user is emailed a link to download it for a limited time.
+// @Tags users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 202 {object} pkg.APIResponse "Data export requested successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid user ID format"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 409 {object} pkg.APIResponse "A copy of the data is already being prepared"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /users/{id}/data-exports [post]
+func (h *DataExportHandler) RequestExport(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid user ID format: %v", err))
+		return
+	}
+
+	response, err := h.exportService.Request(uint(userID))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrDataExportPending) {
+			status = http.StatusConflict
+		}
+		pkg.RespondWithError(c, status, err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusAccepted, response, "Data export requested successfully")
+}
+
+// ListExports handles GET /users/{userID}/data-exports endpoint
+// @Summary List data exports
+// @Description This endpoint lists the copies of personal data the user asked for, newest first, and whether they are ready.
+// @Tags users
+// @Produce json
+// @Param userID path int true "User ID"
+// @Success 200 {object} pkg.APIResponse "Data exports fetched successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid user ID format"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /users/{userID}/data-exports [get]
+func (h *DataExportHandler) ListExports(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userID"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid user ID format: %v", err))
+		return
+	}
+
+	responses, err := h.exportService.ListExports(uint(userID))
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, responses, "Data exports fetched successfully")
+}
+
+// DownloadExport handles GET /data-exports/download endpoint
+// @Summary Download a copy of personal data
+// @Description This endpoint downloads the ZIP archive of a data export with the token of the link emailed to the user. The link works until the archive expires.
+// @Tags users
+// @Produce application/zip
+// @Param token query string true "Token of the download link"
+// @Success 200 {file} file "ZIP archive of JSON files"
+// @Failure 400 {object} pkg.APIResponse "Missing token"
+// @Failure 404 {object} pkg.APIResponse "Invalid or expired download link"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /data-exports/download [get]
+func (h *DataExportHandler) DownloadExport(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		pkg.RespondWithError(c, http.StatusBadRequest, errors.New("token is required"))
+		return
+	}
+
+	export, err := h.exportService.Download(token)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrInvalidDownloadLink) {
+			status = http.StatusNotFound
+		}
+		pkg.RespondWithError(c, status, err)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="personal-data-%d.zip"`, export.ID))
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/zip", export.Archive)
+}
@@ -4,11 +4,12 @@ import (
 	"auth-service/internal/api/dto"
 	"auth-service/internal/services"
 	"auth-service/pkg"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"log"
 	"net/http"
 	"strconv"
-	"time"
 )
 
 type LoginHistoryHandler struct {
@@ -23,7 +24,7 @@ func NewLoginHistoryHandler(loginHistoryService services.ILoginHistoryService) *
 
 // RecordLoginAttempt handles POST /login-attempts
 // @Summary Record login attempt
-// @Description This endpoint records a login attempt for a user.
+// @Description This endpoint records a login attempt made outside the login endpoint, which records its own attempts. Admin only.
 // @Tags login-history
 // @Accept json
 // @Produce json
@@ -50,13 +51,18 @@ func (h *LoginHistoryHandler) RecordLoginAttempt(c *gin.Context) {
 
 // GetLoginAttemptsByUser handles GET /login-attempts/{userID}
 // @Summary Get login attempts by user
-// @Description This endpoint fetches all login attempts for a specified user within a given time frame.
+// @Description This endpoint pages through the login attempts of a user, newest first, optionally within a time frame and filtered by outcome, IP address and device. Pass the nextCursor of a page as cursor to get the next one. Successful logins from a new device, after impossible travel or at an unusual hour list these anomalies, and the user was alerted about them.
 // @Tags login-history
 // @Accept json
 // @Produce json
 // @Param userID path int true "User ID"
-// @Param from query string true "Start time (RFC3339 format)"
-// @Param to query string true "End time (RFC3339 format)"
+// @Param from query string false "Start time (RFC3339 format)"
+// @Param to query string false "End time (RFC3339 format)"
+// @Param successful query bool false "Only successful or only failed attempts"
+// @Param ipAddress query string false "IP address of the attempts"
+// @Param device query string false "Part of the device information"
+// @Param cursor query string false "nextCursor of the previous page"
+// @Param limit query int false "Page size, at most 200"
 // @Success 200 {object} pkg.APIResponse "Login attempts fetched successfully"
 // @Failure 400 {object} pkg.APIResponse "Invalid parameters"
 // @Failure 500 {object} pkg.APIResponse "Internal server error"
@@ -68,52 +74,68 @@ func (h *LoginHistoryHandler) GetLoginAttemptsByUser(c *gin.Context) {
 		return
 	}
 
-	from, err := time.Parse(time.RFC3339, c.Query("from"))
-	if err != nil {
-		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid from time: %v", err))
+	var query dto.LoginHistoryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid query parameters: %v", err))
 		return
 	}
 
-	to, err := time.Parse(time.RFC3339, c.Query("to"))
+	response, err := h.loginHistoryService.GetLoginAttemptsByUser(uint(userID), query)
 	if err != nil {
-		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid to time: %v", err))
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrInvalidCursor) {
+			status = http.StatusBadRequest
+		}
+		pkg.RespondWithError(c, status, err)
 		return
 	}
 
-	responses, err := h.loginHistoryService.GetLoginAttemptsByUser(uint(userID), from, to)
-	if err != nil {
-		pkg.RespondWithError(c, http.StatusInternalServerError, err)
-		return
-	}
-
-	pkg.RespondWithSuccess(c, http.StatusOK, responses, "Login attempts fetched successfully")
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "Login attempts fetched successfully")
 }
 
-// RecordUserLogout handles PUT /logout/{historyID}
-// @Summary Record user logout
-// @Description This endpoint records the logout time for a specific login history record.
+// ExportLoginAttempts handles GET /login-attempts/{userID}/export
+// @Summary Export login attempts
+// @Description This endpoint downloads the login attempts of a user matching the filters as CSV, in the order they were made, including those moved to the archive by the retention job.
 // @Tags login-history
-// @Accept json
-// @Produce json
-// @Param historyID path int true "History ID"
-// @Success 200 {object} pkg.APIResponse "Logout recorded successfully"
-// @Failure 400 {object} pkg.APIResponse "Invalid history ID"
+// @Produce text/csv
+// @Param userID path int true "User ID"
+// @Param from query string false "Start time (RFC3339 format)"
+// @Param to query string false "End time (RFC3339 format)"
+// @Param successful query bool false "Only successful or only failed attempts"
+// @Param ipAddress query string false "IP address of the attempts"
+// @Param device query string false "Part of the device information"
+// @Success 200 {file} file "Login attempts"
+// @Failure 400 {object} pkg.APIResponse "Invalid parameters"
 // @Failure 500 {object} pkg.APIResponse "Internal server error"
-// @Router /logout/{historyID} [post]
-func (h *LoginHistoryHandler) RecordUserLogout(c *gin.Context) {
-	historyID, err := strconv.ParseUint(c.Param("historyID"), 10, 32)
+// @Router /login-attempts/{userID}/export [get]
+func (h *LoginHistoryHandler) ExportLoginAttempts(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userID"), 10, 32)
 	if err != nil {
-		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid history ID: %v", err))
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid user ID: %v", err))
 		return
 	}
 
-	logoutTime := time.Now() // Assuming logout at current time
-	if err := h.loginHistoryService.RecordUserLogout(uint(historyID), logoutTime); err != nil {
-		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+	var query dto.LoginHistoryFilterQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid query parameters: %v", err))
 		return
 	}
 
-	pkg.RespondWithSuccess(c, http.StatusOK, nil, "Logout recorded successfully")
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="login-attempts-%d.csv"`, userID))
+	c.Header("Cache-Control", "no-store")
+
+	if err := h.loginHistoryService.ExportLoginAttempts(uint(userID), query, c.Writer); err != nil {
+		// Once streaming started the status is sent, the export is cut short instead
+		if !c.Writer.Written() {
+			c.Header("Content-Type", "")
+			c.Header("Content-Disposition", "")
+			pkg.RespondWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+		log.Printf("Login history export of user %d failed: %v", userID, err)
+		c.Abort()
+	}
 }
 
 // CheckSuspiciousActivity handles GET /suspicious-activity/{userID}
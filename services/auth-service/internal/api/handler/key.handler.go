@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"auth-service/internal/services"
+	"auth-service/pkg"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// jwksMaxAge is how long verifiers and proxies may cache the JWKS, in seconds.
+const jwksMaxAge = 300
+
+type KeyHandler struct {
+	keyService services.IKeyService
+}
+
+func NewKeyHandler(keyService services.IKeyService) *KeyHandler {
+	return &KeyHandler{
+		keyService: keyService,
+	}
+}
+
+// GetJWKS handles GET /.well-known/jwks.json endpoint
+// @Summary Get token signing keys
+// @Description This endpoint publishes the public keys access tokens are verified with as a JSON Web Key Set. Tokens name their key in the kid header; retired keys stay listed until the tokens they signed have expired.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} dto.JWKSResponse "JSON Web Key Set"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /.well-known/jwks.json [get]
+func (h *KeyHandler) GetJWKS(c *gin.Context) {
+	jwks, err := h.keyService.JWKS()
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	// The key set is served bare, as JWKS clients expect, rather than wrapped in an APIResponse.
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", jwksMaxAge))
+	c.JSON(http.StatusOK, jwks)
+}
@@ -0,0 +1,268 @@
+package handler
+
+import (
+	"auth-service/internal/api/dto"
+	"auth-service/internal/services"
+	"auth-service/pkg"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"shared/authz"
+)
+
+type MFAHandler struct {
+	mfaService  services.IMFAService
+	userService services.IUserService
+}
+
+func NewMFAHandler(mfaService services.IMFAService, userService services.IUserService) *MFAHandler {
+	return &MFAHandler{
+		mfaService:  mfaService,
+		userService: userService,
+	}
+}
+
+// CompleteLogin handles POST /login/mfa endpoint
+// @Summary Complete MFA login
+// @Description This endpoint completes a login that requires MFA with the MFA token returned by the login endpoint and a TOTP or recovery code. If MFA was set up during the login, the response includes the new recovery codes.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param login body dto.MFALoginRequest true "MFA Login Request"
+// @Success 200 {object} pkg.APIResponse "User authenticated successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid request data"
+// @Failure 401 {object} pkg.APIResponse "Invalid code or MFA token"
+// @Failure 403 {object} pkg.APIResponse "Account disabled"
+// @Failure 423 {object} pkg.APIResponse "Account temporarily locked"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /login/mfa [post]
+func (h *MFAHandler) CompleteLogin(c *gin.Context) {
+	var req dto.MFALoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid request data: %v", err))
+		return
+	}
+
+	response, err := h.userService.CompleteMFALogin(req)
+	if err != nil {
+		pkg.RespondWithError(c, loginErrorStatus(err), err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "User authenticated successfully")
+}
+
+// BeginLoginEnrollment handles POST /login/mfa/enroll endpoint
+// @Summary Set up MFA during login
+// @Description This endpoint generates a TOTP secret for a user whose role requires MFA but who has not set it up yet, using the MFA token returned by the login endpoint. The login is completed with the first code of the authenticator.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.MFAEnrollLoginRequest true "MFA Enroll Login Request"
+// @Success 200 {object} pkg.APIResponse "MFA enrollment started successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid request data"
+// @Failure 401 {object} pkg.APIResponse "Invalid MFA token"
+// @Failure 409 {object} pkg.APIResponse "MFA already enabled"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /login/mfa/enroll [post]
+func (h *MFAHandler) BeginLoginEnrollment(c *gin.Context) {
+	var req dto.MFAEnrollLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid request data: %v", err))
+		return
+	}
+
+	response, err := h.mfaService.BeginChallengeEnrollment(req)
+	if err != nil {
+		pkg.RespondWithError(c, mfaErrorStatus(err), err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "MFA enrollment started successfully")
+}
+
+// GetStatus handles GET /mfa endpoint
+// @Summary Get MFA status
+// @Description This endpoint tells whether the authenticated user has MFA enabled, whether their role requires it and how many recovery codes are left.
+// @Tags mfa
+// @Produce json
+// @Success 200 {object} pkg.APIResponse "MFA status fetched successfully"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /mfa [get]
+func (h *MFAHandler) GetStatus(c *gin.Context) {
+	principal, _ := authz.CurrentPrincipal(c)
+	response, err := h.mfaService.GetStatus(principal.UserID)
+	if err != nil {
+		pkg.RespondWithError(c, mfaErrorStatus(err), err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "MFA status fetched successfully")
+}
+
+// BeginEnrollment handles POST /mfa/enroll endpoint
+// @Summary Start MFA enrollment
+// @Description This endpoint generates a TOTP secret for the authenticated user, returned with its otpauth URI and a QR code. MFA is enabled once confirmed with a code.
+// @Tags mfa
+// @Produce json
+// @Success 200 {object} pkg.APIResponse "MFA enrollment started successfully"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 409 {object} pkg.APIResponse "MFA already enabled"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /mfa/enroll [post]
+func (h *MFAHandler) BeginEnrollment(c *gin.Context) {
+	principal, _ := authz.CurrentPrincipal(c)
+	response, err := h.mfaService.BeginEnrollment(principal.UserID)
+	if err != nil {
+		pkg.RespondWithError(c, mfaErrorStatus(err), err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "MFA enrollment started successfully")
+}
+
+// ConfirmEnrollment handles POST /mfa/enroll/confirm endpoint
+// @Summary Confirm MFA enrollment
+// @Description This endpoint enables MFA with the first code of the authenticator and returns recovery codes, which are shown only once.
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param request body dto.MFACodeRequest true "MFA Code Request"
+// @Success 200 {object} pkg.APIResponse "MFA enabled successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid request data or code"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 409 {object} pkg.APIResponse "MFA already enabled or not set up"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /mfa/enroll/confirm [post]
+func (h *MFAHandler) ConfirmEnrollment(c *gin.Context) {
+	var req dto.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid request data: %v", err))
+		return
+	}
+
+	principal, _ := authz.CurrentPrincipal(c)
+	response, err := h.mfaService.ConfirmEnrollment(principal.UserID, req, currentActor(c))
+	if err != nil {
+		pkg.RespondWithError(c, mfaErrorStatus(err), err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "MFA enabled successfully")
+}
+
+// RegenerateRecoveryCodes handles POST /mfa/recovery-codes endpoint
+// @Summary Regenerate recovery codes
+// @Description This endpoint replaces the recovery codes of the authenticated user after checking a TOTP or recovery code.
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param request body dto.MFACodeRequest true "MFA Code Request"
+// @Success 200 {object} pkg.APIResponse "Recovery codes regenerated successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid request data or code"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 409 {object} pkg.APIResponse "MFA not enabled"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /mfa/recovery-codes [post]
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req dto.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid request data: %v", err))
+		return
+	}
+
+	principal, _ := authz.CurrentPrincipal(c)
+	response, err := h.mfaService.RegenerateRecoveryCodes(principal.UserID, req, currentActor(c))
+	if err != nil {
+		pkg.RespondWithError(c, mfaErrorStatus(err), err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "Recovery codes regenerated successfully")
+}
+
+// Disable handles POST /mfa/disable endpoint
+// @Summary Disable MFA
+// @Description This endpoint turns MFA off for the authenticated user after checking a TOTP or recovery code. It is refused when the user's role requires MFA.
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param request body dto.MFACodeRequest true "MFA Code Request"
+// @Success 200 {object} pkg.APIResponse "MFA disabled successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid request data or code"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 403 {object} pkg.APIResponse "MFA required for the user's role"
+// @Failure 409 {object} pkg.APIResponse "MFA not enabled"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /mfa/disable [post]
+func (h *MFAHandler) Disable(c *gin.Context) {
+	var req dto.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid request data: %v", err))
+		return
+	}
+
+	principal, _ := authz.CurrentPrincipal(c)
+	if err := h.mfaService.Disable(principal.UserID, req, currentActor(c)); err != nil {
+		pkg.RespondWithError(c, mfaErrorStatus(err), err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, nil, "MFA disabled successfully")
+}
+
+// ListPolicies handles GET /mfa/policies endpoint
+// @Summary List MFA policies
+// @Description This endpoint lists which roles require MFA. Roles without a policy do not. Admin only.
+// @Tags mfa
+// @Produce json
+// @Success 200 {object} pkg.APIResponse "MFA policies fetched successfully"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /mfa/policies [get]
+func (h *MFAHandler) ListPolicies(c *gin.Context) {
+	responses, err := h.mfaService.ListPolicies()
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, responses, "MFA policies fetched successfully")
+}
+
+// SetPolicy handles PUT /mfa/policies/{role} endpoint
+// @Summary Set MFA policy
+// @Description This endpoint sets whether users of a role must use MFA. Users of the role without MFA set it up at their next login. Admin only.
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param role path string true "Role name"
+// @Param policy body dto.MFAPolicyRequest true "MFA Policy Request"
+// @Success 200 {object} pkg.APIResponse "MFA policy updated successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid request data or role"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /mfa/policies/{role} [put]
+func (h *MFAHandler) SetPolicy(c *gin.Context) {
+	var req dto.MFAPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid request data: %v", err))
+		return
+	}
+	response, err := h.mfaService.SetPolicy(c.Param("role"), req, currentActor(c))
+	if err != nil {
+		pkg.RespondWithError(c, mfaErrorStatus(err), err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "MFA policy updated successfully")
+}
+
+// mfaErrorStatus maps MFA service errors to HTTP status codes.
+func mfaErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidMFACode), errors.Is(err, services.ErrUnknownRole):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrInvalidMFAToken):
+		return http.StatusUnauthorized
+	case errors.Is(err, services.ErrMFARequired):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrMFAAlreadyEnabled), errors.Is(err, services.ErrMFANotEnrolled):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
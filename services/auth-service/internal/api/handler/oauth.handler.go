@@ -0,0 +1,280 @@
+package handler
+
+import (
+	"auth-service/internal/api/dto"
+	"auth-service/internal/services"
+	"auth-service/pkg"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"shared/authz"
+)
+
+type OAuthHandler struct {
+	oauthService services.IOAuthService
+}
+
+func NewOAuthHandler(oauthService services.IOAuthService) *OAuthHandler {
+	return &OAuthHandler{
+		oauthService: oauthService,
+	}
+}
+
+// GetDiscoveryDocument handles GET /.well-known/openid-configuration endpoint
+// @Summary Get OpenID Connect configuration
+// @Description This endpoint publishes the OpenID Connect provider metadata partners configure their clients with.
+// @Tags oauth
+// @Produce json
+// @Success 200 {object} dto.DiscoveryDocument "OpenID Connect provider metadata"
+// @Router /.well-known/openid-configuration [get]
+func (h *OAuthHandler) GetDiscoveryDocument(c *gin.Context) {
+	// Served bare, as OpenID Connect clients expect, rather than wrapped in an APIResponse.
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", jwksMaxAge))
+	c.JSON(http.StatusOK, h.oauthService.Discovery())
+}
+
+// RegisterClient handles POST /oauth/clients endpoint
+// @Summary Register OAuth client
+// @Description This endpoint registers a partner application, or a service using the client credentials grant. Confidential clients get a client secret, which is only returned now. Admin only.
+// @Tags oauth
+// @Accept json
+// @Produce json
+// @Param client body dto.RegisterOAuthClientRequest true "Register OAuth Client Request"
+// @Success 201 {object} pkg.APIResponse "OAuth client registered successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid request data"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /oauth/clients [post]
+func (h *OAuthHandler) RegisterClient(c *gin.Context) {
+	var req dto.RegisterOAuthClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid request data: %v", err))
+		return
+	}
+	response, err := h.oauthService.RegisterClient(req, currentActor(c))
+	if err != nil {
+		pkg.RespondWithError(c, oauthErrorStatus(err), err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusCreated, response, "OAuth client registered successfully")
+}
+
+// ListClients handles GET /oauth/clients endpoint
+// @Summary List OAuth clients
+// @Description This endpoint lists the registered partner applications. Admin only.
+// @Tags oauth
+// @Produce json
+// @Success 200 {object} pkg.APIResponse "OAuth clients fetched successfully"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /oauth/clients [get]
+func (h *OAuthHandler) ListClients(c *gin.Context) {
+	responses, err := h.oauthService.ListClients()
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, responses, "OAuth clients fetched successfully")
+}
+
+// DeleteClient handles DELETE /oauth/clients/{clientID} endpoint
+// @Summary Delete OAuth client
+// @Description This endpoint removes a partner application and signs it out of every account. Admin only.
+// @Tags oauth
+// @Produce json
+// @Param clientID path string true "Client ID"
+// @Success 200 {object} pkg.APIResponse "OAuth client deleted successfully"
+// @Failure 404 {object} pkg.APIResponse "OAuth client not found"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /oauth/clients/{clientID} [delete]
+func (h *OAuthHandler) DeleteClient(c *gin.Context) {
+	if err := h.oauthService.DeleteClient(c.Param("clientID"), currentActor(c)); err != nil {
+		pkg.RespondWithError(c, oauthErrorStatus(err), err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, nil, "OAuth client deleted successfully")
+}
+
+// GetAuthorization handles GET /oauth/authorize endpoint
+// @Summary Check authorization request
+// @Description This endpoint validates an authorization request for the signed in user, as forwarded by the consent page. If the user already allowed the requested scopes, the response holds the redirect URI with the authorization code; otherwise it asks for consent.
+// @Tags oauth
+// @Produce json
+// @Param response_type query string true "Must be code"
+// @Param client_id query string true "Client ID"
+// @Param redirect_uri query string true "Registered redirect URI"
+// @Param scope query string true "Space separated scopes, including openid"
+// @Param state query string false "Opaque value returned to the client"
+// @Param nonce query string false "Value copied into the ID token"
+// @Param code_challenge query string true "PKCE code challenge"
+// @Param code_challenge_method query string true "Must be S256"
+// @Success 200 {object} pkg.APIResponse "Authorization request checked successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid authorization request"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /oauth/authorize [get]
+func (h *OAuthHandler) GetAuthorization(c *gin.Context) {
+	var req dto.AuthorizationRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid authorization request: %v", err))
+		return
+	}
+	h.authorize(c, req, false)
+}
+
+// Authorize handles POST /oauth/authorize endpoint
+// @Summary Decide authorization request
+// @Description This endpoint records the signed in user's consent decision on an authorization request. The response holds the redirect URI to send the user back to the client with, carrying the authorization code or the access_denied error.
+// @Tags oauth
+// @Accept json
+// @Produce json
+// @Param request body dto.AuthorizationRequest true "Authorization request with the consent decision"
+// @Success 200 {object} pkg.APIResponse "Authorization decided successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid authorization request"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /oauth/authorize [post]
+func (h *OAuthHandler) Authorize(c *gin.Context) {
+	var req dto.AuthorizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid authorization request: %v", err))
+		return
+	}
+	h.authorize(c, req, true)
+}
+
+func (h *OAuthHandler) authorize(c *gin.Context, req dto.AuthorizationRequest, decided bool) {
+	principal, _ := authz.CurrentPrincipal(c)
+	req.UserID, req.SessionID = principal.UserID, principal.SessionID
+
+	response, err := h.oauthService.Authorize(req, decided)
+	if err != nil {
+		pkg.RespondWithError(c, oauthErrorStatus(err), err)
+		return
+	}
+	message := "Authorization request checked successfully"
+	if decided {
+		message = "Authorization decided successfully"
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, response, message)
+}
+
+// Token handles POST /oauth/token endpoint
+// @Summary Exchange tokens
+// @Description This endpoint exchanges an authorization code and its PKCE verifier, or a refresh token, for tokens. Service clients get an access token for themselves with the client_credentials grant. Confidential clients authenticate with HTTP Basic or client_secret in the form.
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param grant_type formData string true "authorization_code, refresh_token or client_credentials"
+// @Param code formData string false "Authorization code"
+// @Param redirect_uri formData string false "Redirect URI of the authorization request"
+// @Param code_verifier formData string false "PKCE code verifier"
+// @Param refresh_token formData string false "Refresh token"
+// @Param scope formData string false "Space separated scopes of the client credentials grant, all of the client's by default"
+// @Param client_id formData string false "Client ID, unless sent with HTTP Basic"
+// @Param client_secret formData string false "Client secret of confidential clients, unless sent with HTTP Basic"
+// @Success 200 {object} dto.OAuthTokenResponse "Tokens"
+// @Failure 400 {object} dto.OAuthErrorResponse "Invalid request or grant"
+// @Failure 401 {object} dto.OAuthErrorResponse "Client authentication failed"
+// @Router /oauth/token [post]
+func (h *OAuthHandler) Token(c *gin.Context) {
+	// The token endpoint speaks OAuth 2.0, so responses are not wrapped in an APIResponse.
+	c.Header("Cache-Control", "no-store")
+
+	var req dto.TokenRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.OAuthErrorResponse{Error: services.ErrInvalidOAuthRequest.Code, ErrorDescription: err.Error()})
+		return
+	}
+	if clientID, secret, ok := c.Request.BasicAuth(); ok {
+		req.ClientID, req.ClientSecret = clientID, secret
+	}
+
+	response, err := h.oauthService.Exchange(req)
+	if err != nil {
+		var oauthErr *services.OAuthError
+		if !errors.As(err, &oauthErr) {
+			c.JSON(http.StatusInternalServerError, dto.OAuthErrorResponse{Error: "server_error"})
+			return
+		}
+		if oauthErr == services.ErrInvalidClient {
+			c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+		}
+		c.JSON(oauthErrorStatus(err), dto.OAuthErrorResponse{Error: oauthErr.Code, ErrorDescription: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// UserInfo handles GET /oauth/userinfo endpoint
+// @Summary Get user info
+// @Description This endpoint returns the OpenID Connect claims of the user of the access token, limited to the scopes granted to the client.
+// @Tags oauth
+// @Produce json
+// @Success 200 {object} dto.UserInfoResponse "User claims"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 403 {object} pkg.APIResponse "The client was not granted the openid scope"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /oauth/userinfo [get]
+func (h *OAuthHandler) UserInfo(c *gin.Context) {
+	principal, _ := authz.CurrentPrincipal(c)
+	response, err := h.oauthService.UserInfo(principal.UserID, principal.SessionID)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// ListConsents handles GET /oauth/consents endpoint
+// @Summary List OAuth consents
+// @Description This endpoint lists the partner applications the signed in user allowed to access their account.
+// @Tags oauth
+// @Produce json
+// @Success 200 {object} pkg.APIResponse "OAuth consents fetched successfully"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /oauth/consents [get]
+func (h *OAuthHandler) ListConsents(c *gin.Context) {
+	principal, _ := authz.CurrentPrincipal(c)
+	responses, err := h.oauthService.ListConsents(principal.UserID)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, responses, "OAuth consents fetched successfully")
+}
+
+// RevokeConsent handles DELETE /oauth/consents/{clientID} endpoint
+// @Summary Revoke OAuth consent
+// @Description This endpoint withdraws the signed in user's consent to a partner application and signs it out of their account.
+// @Tags oauth
+// @Produce json
+// @Param clientID path string true "Client ID"
+// @Success 200 {object} pkg.APIResponse "OAuth consent revoked successfully"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 404 {object} pkg.APIResponse "No consent given to the client"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /oauth/consents/{clientID} [delete]
+func (h *OAuthHandler) RevokeConsent(c *gin.Context) {
+	principal, _ := authz.CurrentPrincipal(c)
+	if err := h.oauthService.RevokeConsent(principal.UserID, c.Param("clientID")); err != nil {
+		pkg.RespondWithError(c, oauthErrorStatus(err), err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, nil, "OAuth consent revoked successfully")
+}
+
+// oauthErrorStatus maps OAuth service errors to HTTP status codes.
+func oauthErrorStatus(err error) int {
+	var oauthErr *services.OAuthError
+	switch {
+	case errors.Is(err, services.ErrInvalidClient):
+		return http.StatusUnauthorized
+	case errors.As(err, &oauthErr):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrOAuthClientNotFound), errors.Is(err, services.ErrOAuthConsentNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"auth-service/internal/api/dto"
+	"auth-service/internal/services"
+	"auth-service/pkg"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type PasswordlessHandler struct {
+	passwordlessService services.IPasswordlessService
+	userService         services.IUserService
+}
+
+func NewPasswordlessHandler(passwordlessService services.IPasswordlessService, userService services.IUserService) *PasswordlessHandler {
+	return &PasswordlessHandler{
+		passwordlessService: passwordlessService,
+		userService:         userService,
+	}
+}
+
+// StartLogin handles POST /login/passwordless endpoint
+// @Summary Start passwordless login
+// @Description This endpoint sends a single-use sign-in link and 6-digit code by email, or by SMS to a verified phone number, to the user with the given username or email address, replacing those sent before. It sends at most once per cooldown period. The returned login token is used to enter the code with. The response is the same whether or not the account exists.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.PasswordlessLoginRequest true "Passwordless Login Request"
+// @Success 200 {object} pkg.APIResponse "Login code sent if the account exists"
+// @Failure 400 {object} pkg.APIResponse "Invalid request data"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /login/passwordless [post]
+func (h *PasswordlessHandler) StartLogin(c *gin.Context) {
+	var req dto.PasswordlessLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid request data: %v", err))
+		return
+	}
+
+	response, err := h.passwordlessService.Start(req)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "If the account exists, a sign-in link and code have been sent")
+}
+
+// LoginWithCode handles POST /login/passwordless/code endpoint
+// @Summary Complete passwordless login with a code
+// @Description This endpoint logs a user in with the login token of a passwordless login and the code sent to them, returning the same tokens as the login endpoint. Each code works once, before it expires, and is discarded after 5 attempts. Invalid codes count as failed logins.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.PasswordlessCodeRequest true "Passwordless Code Request"
+// @Success 200 {object} pkg.APIResponse "User authenticated successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid request data"
+// @Failure 401 {object} pkg.APIResponse "Invalid or expired code"
+// @Failure 403 {object} pkg.APIResponse "Account disabled or password reset required"
+// @Failure 423 {object} pkg.APIResponse "Account temporarily locked"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /login/passwordless/code [post]
+func (h *PasswordlessHandler) LoginWithCode(c *gin.Context) {
+	var req dto.PasswordlessCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid request data: %v", err))
+		return
+	}
+	req.IPAddress = c.ClientIP()
+	req.DeviceInformation = c.Request.UserAgent()
+
+	response, err := h.userService.LoginWithCode(req)
+	if err != nil {
+		pkg.RespondWithError(c, loginErrorStatus(err), err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "User authenticated successfully")
+}
+
+// LoginWithLink handles POST /login/passwordless/link endpoint
+// @Summary Complete passwordless login with a link
+// @Description This endpoint logs a user in with the token of the sign-in link sent to them, returning the same tokens as the login endpoint. Each link works once, before it expires.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.PasswordlessLinkRequest true "Passwordless Link Request"
+// @Success 200 {object} pkg.APIResponse "User authenticated successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid request data"
+// @Failure 401 {object} pkg.APIResponse "Invalid, used or expired link"
+// @Failure 403 {object} pkg.APIResponse "Account disabled or password reset required"
+// @Failure 423 {object} pkg.APIResponse "Account temporarily locked"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /login/passwordless/link [post]
+func (h *PasswordlessHandler) LoginWithLink(c *gin.Context) {
+	var req dto.PasswordlessLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid request data: %v", err))
+		return
+	}
+	req.IPAddress = c.ClientIP()
+	req.DeviceInformation = c.Request.UserAgent()
+
+	response, err := h.userService.LoginWithLink(req)
+	if err != nil {
+		pkg.RespondWithError(c, loginErrorStatus(err), err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "User authenticated successfully")
+}
@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"auth-service/internal/api/dto"
+	"auth-service/internal/services"
+	"auth-service/pkg"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type RoleHandler struct {
+	accessControlService services.IAccessControlService
+}
+
+func NewRoleHandler(accessControlService services.IAccessControlService) *RoleHandler {
+	return &RoleHandler{
+		accessControlService: accessControlService,
+	}
+}
+
+// ListPermissions handles GET /permissions endpoint
+// @Summary List permissions
+// @Description This endpoint lists every permission that can be granted to roles. Admin only.
+// @Tags roles
+// @Produce json
+// @Success 200 {object} pkg.APIResponse "Permissions fetched successfully"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Router /permissions [get]
+func (h *RoleHandler) ListPermissions(c *gin.Context) {
+	pkg.RespondWithSuccess(c, http.StatusOK, h.accessControlService.ListPermissions(), "Permissions fetched successfully")
+}
+
+// ListRoles handles GET /roles endpoint
+// @Summary List roles
+// @Description This endpoint lists every role with its permissions. Admin only.
+// @Tags roles
+// @Produce json
+// @Success 200 {object} pkg.APIResponse "Roles fetched successfully"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /roles [get]
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	responses, err := h.accessControlService.ListRoles()
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, responses, "Roles fetched successfully")
+}
+
+// CreateRole handles POST /roles endpoint
+// @Summary Create role
+// @Description This endpoint creates a role granting the given permissions. Admin only.
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param role body dto.CreateRoleRequest true "Create Role Request"
+// @Success 201 {object} pkg.APIResponse "Role created successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid request data, role name or permission"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 409 {object} pkg.APIResponse "Role already exists"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /roles [post]
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	var req dto.CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid request data: %v", err))
+		return
+	}
+
+	response, err := h.accessControlService.CreateRole(req, currentActor(c))
+	if err != nil {
+		pkg.RespondWithError(c, roleErrorStatus(err), err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusCreated, response, "Role created successfully")
+}
+
+// UpdateRole handles PUT /roles/{name} endpoint
+// @Summary Update role
+// @Description This endpoint replaces the description and permissions of a role. Users get the new permissions with their next access token. The admin role always has every permission. Admin only.
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param name path string true "Role name"
+// @Param role body dto.UpdateRoleRequest true "Update Role Request"
+// @Success 200 {object} pkg.APIResponse "Role updated successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid request data or permission"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 404 {object} pkg.APIResponse "Role not found"
+// @Failure 409 {object} pkg.APIResponse "The admin role cannot be changed"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /roles/{name} [put]
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	var req dto.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid request data: %v", err))
+		return
+	}
+
+	response, err := h.accessControlService.UpdateRole(c.Param("name"), req, currentActor(c))
+	if err != nil {
+		pkg.RespondWithError(c, roleErrorStatus(err), err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "Role updated successfully")
+}
+
+// DeleteRole handles DELETE /roles/{name} endpoint
+// @Summary Delete role
+// @Description This endpoint deletes a role and takes it away from the users it was given to. System roles and roles that are the account role of some users cannot be deleted. Admin only.
+// @Tags roles
+// @Produce json
+// @Param name path string true "Role name"
+// @Success 200 {object} pkg.APIResponse "Role deleted successfully"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 404 {object} pkg.APIResponse "Role not found"
+// @Failure 409 {object} pkg.APIResponse "System role or role in use"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /roles/{name} [delete]
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	if err := h.accessControlService.DeleteRole(c.Param("name"), currentActor(c)); err != nil {
+		pkg.RespondWithError(c, roleErrorStatus(err), err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, nil, "Role deleted successfully")
+}
+
+// GetUserRoles handles GET /users/{userID}/roles endpoint
+// @Summary Get user roles
+// @Description This endpoint lists the role of an account, the roles given to the user in addition and the permissions they have through them.
+// @Tags roles
+// @Produce json
+// @Param userID path int true "User ID"
+// @Success 200 {object} pkg.APIResponse "User roles fetched successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid user ID format"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 404 {object} pkg.APIResponse "User not found"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /users/{userID}/roles [get]
+func (h *RoleHandler) GetUserRoles(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userID"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid user ID format: %v", err))
+		return
+	}
+
+	response, err := h.accessControlService.GetUserRoles(uint(userID))
+	if err != nil {
+		pkg.RespondWithError(c, roleErrorStatus(err), err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "User roles fetched successfully")
+}
+
+// AssignRole handles POST /users/{id}/roles endpoint
+// @Summary Assign role
+// @Description This endpoint gives a user a role in addition to the role of their account. The user gets its permissions with their next access token. Admin only.
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param role body dto.AssignRoleRequest true "Assign Role Request"
+// @Success 200 {object} pkg.APIResponse "Role assigned successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid user ID format or request data"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 404 {object} pkg.APIResponse "User or role not found"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /users/{id}/roles [post]
+func (h *RoleHandler) AssignRole(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid user ID format: %v", err))
+		return
+	}
+
+	var req dto.AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid request data: %v", err))
+		return
+	}
+	response, err := h.accessControlService.AssignRole(uint(userID), req, currentActor(c))
+	if err != nil {
+		pkg.RespondWithError(c, roleErrorStatus(err), err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "Role assigned successfully")
+}
+
+// UnassignRole handles DELETE /users/{id}/roles/{role} endpoint
+// @Summary Unassign role
+// @Description This endpoint takes a role given in addition away from a user. The role of the account is kept. Admin only.
+// @Tags roles
+// @Produce json
+// @Param id path int true "User ID"
+// @Param role path string true "Role name"
+// @Success 200 {object} pkg.APIResponse "Role unassigned successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid user ID format"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 404 {object} pkg.APIResponse "The user does not have the role"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /users/{id}/roles/{role} [delete]
+func (h *RoleHandler) UnassignRole(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid user ID format: %v", err))
+		return
+	}
+
+	response, err := h.accessControlService.UnassignRole(uint(userID), c.Param("role"), currentActor(c))
+	if err != nil {
+		pkg.RespondWithError(c, roleErrorStatus(err), err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "Role unassigned successfully")
+}
+
+// roleErrorStatus maps access control service errors to HTTP status codes.
+func roleErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidRoleName), errors.Is(err, services.ErrUnknownPermission),
+		errors.Is(err, services.ErrReservedRole):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrRoleNotFound), errors.Is(err, services.ErrRoleUserNotFound),
+		errors.Is(err, services.ErrRoleNotAssigned):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrRoleExists), errors.Is(err, services.ErrSystemRole), errors.Is(err, services.ErrRoleInUse):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"auth-service/internal/api/dto"
+	"auth-service/internal/services"
+	"auth-service/pkg"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"shared/authz"
+	"strconv"
+)
+
+type SessionHandler struct {
+	sessionService services.ISessionService
+}
+
+func NewSessionHandler(sessionService services.ISessionService) *SessionHandler {
+	return &SessionHandler{
+		sessionService: sessionService,
+	}
+}
+
+// ListSessions handles GET /users/{userID}/sessions endpoint
+// @Summary List sessions
+// @Description This endpoint lists the devices and applications a user is signed in on, most recently seen first. The session of the caller is marked as current.
+// @Tags sessions
+// @Produce json
+// @Param userID path int true "User ID"
+// @Success 200 {object} pkg.APIResponse "Sessions fetched successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid user ID format"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /users/{userID}/sessions [get]
+func (h *SessionHandler) ListSessions(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userID"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid user ID format: %v", err))
+		return
+	}
+	principal, _ := authz.CurrentPrincipal(c)
+
+	responses, err := h.sessionService.ListSessions(uint(userID), principal.SessionID)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, responses, "Sessions fetched successfully")
+}
+
+// RevokeSession handles DELETE /users/{id}/sessions/{sessionID} endpoint
+// @Summary Revoke session
+// @Description This endpoint signs a user out of one session, such as that of a lost phone. Its refresh token stops working at once and every service rejects its access tokens within a minute.
+// @Tags sessions
+// @Produce json
+// @Param id path int true "User ID"
+// @Param sessionID path int true "Session ID"
+// @Success 200 {object} pkg.APIResponse "Session revoked successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid user or session ID format"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 404 {object} pkg.APIResponse "Session not found"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /users/{id}/sessions/{sessionID} [delete]
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid user ID format: %v", err))
+		return
+	}
+	sessionID, err := strconv.ParseUint(c.Param("sessionID"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid session ID format: %v", err))
+		return
+	}
+
+	if err := h.sessionService.RevokeSession(uint(userID), uint(sessionID), currentActor(c)); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrSessionNotFound) {
+			status = http.StatusNotFound
+		}
+		pkg.RespondWithError(c, status, err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, nil, "Session revoked successfully")
+}
+
+// RevokeSessions handles DELETE /users/{id}/sessions endpoint
+// @Summary Revoke all sessions
+// @Description This endpoint signs a user out everywhere. With keepCurrent, the session of the caller stays signed in.
+// @Tags sessions
+// @Produce json
+// @Param id path int true "User ID"
+// @Param keepCurrent query bool false "Keep the session of the caller"
+// @Success 200 {object} pkg.APIResponse "Sessions revoked successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid user ID format or keepCurrent"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /users/{id}/sessions [delete]
+func (h *SessionHandler) RevokeSessions(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid user ID format: %v", err))
+		return
+	}
+	keepCurrent, err := strconv.ParseBool(c.DefaultQuery("keepCurrent", "false"))
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid keepCurrent: %v", err))
+		return
+	}
+
+	var exceptSessionID uint
+	if keepCurrent {
+		principal, _ := authz.CurrentPrincipal(c)
+		exceptSessionID = principal.SessionID
+	}
+	if err := h.sessionService.RevokeSessions(uint(userID), exceptSessionID, currentActor(c)); err != nil {
+		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, nil, "Sessions revoked successfully")
+}
+
+// Introspect handles GET /token/introspect endpoint
+// @Summary Introspect access token
+// @Description This endpoint lets other services check that the session of the bearer access token has not been revoked. Tokens of revoked sessions are rejected with 401.
+// @Tags sessions
+// @Produce json
+// @Success 200 {object} dto.SessionIntrospectionResponse
+// @Failure 401 {object} pkg.APIResponse "Missing, invalid or revoked token"
+// @Router /token/introspect [get]
+func (h *SessionHandler) Introspect(c *gin.Context) {
+	principal, _ := authz.CurrentPrincipal(c)
+	c.JSON(http.StatusOK, dto.SessionIntrospectionResponse{
+		Active:    true,
+		SessionID: principal.SessionID,
+		UserID:    principal.UserID,
+	})
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"auth-service/internal/api/dto"
+	"auth-service/internal/services"
+	"auth-service/pkg"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type TokenHandler struct {
+	tokenService services.ITokenService
+}
+
+func NewTokenHandler(tokenService services.ITokenService) *TokenHandler {
+	return &TokenHandler{
+		tokenService: tokenService,
+	}
+}
+
+// RefreshToken handles POST /token/refresh endpoint
+// @Summary Refresh tokens
+// @Description This endpoint exchanges a refresh token for a new access token and a new refresh token. Each refresh token can be used once; reusing one revokes the session.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param token body dto.RefreshTokenRequest true "Refresh Token Request"
+// @Success 200 {object} pkg.APIResponse "Tokens refreshed successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid request data"
+// @Failure 401 {object} pkg.APIResponse "Unauthorized - Invalid, expired or reused refresh token"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /token/refresh [post]
+func (h *TokenHandler) RefreshToken(c *gin.Context) {
+	var req dto.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid request data: %v", err))
+		return
+	}
+
+	req.IPAddress = c.ClientIP()
+
+	response, err := h.tokenService.RefreshTokens(req)
+	if err != nil {
+		pkg.RespondWithError(c, tokenErrorStatus(err), err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "Tokens refreshed successfully")
+}
+
+// Logout handles POST /logout endpoint
+// @Summary Log out
+// @Description This endpoint revokes the session of the given refresh token and records the logout time.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param token body dto.RefreshTokenRequest true "Refresh Token Request"
+// @Success 200 {object} pkg.APIResponse "Logged out successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid request data"
+// @Failure 401 {object} pkg.APIResponse "Unauthorized - Invalid refresh token"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /logout [post]
+func (h *TokenHandler) Logout(c *gin.Context) {
+	var req dto.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid request data: %v", err))
+		return
+	}
+
+	if err := h.tokenService.Logout(req); err != nil {
+		pkg.RespondWithError(c, tokenErrorStatus(err), err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusOK, nil, "Logged out successfully")
+}
+
+// tokenErrorStatus maps token service errors to HTTP status codes.
+func tokenErrorStatus(err error) int {
+	if errors.Is(err, services.ErrInvalidRefreshToken) || errors.Is(err, services.ErrRefreshTokenReused) {
+		return http.StatusUnauthorized
+	}
+	return http.StatusInternalServerError
+}
@@ -4,9 +4,12 @@ import (
 	"auth-service/internal/api/dto"
 	"auth-service/internal/services"
 	"auth-service/pkg"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 	"net/http"
+	"shared/authz"
 	"strconv"
 )
 
@@ -22,13 +25,13 @@ func NewUserHandler(userService services.IUserService) *UserHandler {
 
 // RegisterUser handles POST /auth/register endpoint
 // @Summary Register new user
-// @Description This endpoint registers a new user with the provided credentials.
+// @Description This endpoint registers a new user with the provided credentials. The password must meet the password policy: long enough and not a commonly breached password, the username or the email address.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param user body dto.CreateUserRequest true "Create User Request"
 // @Success 201 {object} pkg.APIResponse "User created successfully"
-// @Failure 400 {object} pkg.APIResponse "Invalid user data"
+// @Failure 400 {object} pkg.APIResponse "Invalid user data or password not meeting the policy"
 // @Failure 500 {object} pkg.APIResponse "Internal server error"
 // @Router /register [post]
 func (h *UserHandler) RegisterUser(c *gin.Context) {
@@ -40,7 +43,11 @@ func (h *UserHandler) RegisterUser(c *gin.Context) {
 
 	userResponse, err := h.userService.RegisterUser(userDTO)
 	if err != nil {
-		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrWeakPassword) {
+			status = http.StatusBadRequest
+		}
+		pkg.RespondWithError(c, status, err)
 		return
 	}
 
@@ -49,7 +56,7 @@ func (h *UserHandler) RegisterUser(c *gin.Context) {
 
 // AuthenticateUser handles POST /auth/login endpoint
 // @Summary Authenticate user
-// @Description This endpoint authenticates a user using username and password.
+// @Description This endpoint authenticates a user using username and password. Every attempt is recorded in the login history, and accounts are locked for a while after repeated failures. Users with MFA, or whose role requires it, get an MFA token to complete the login at /login/mfa.
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -57,6 +64,9 @@ func (h *UserHandler) RegisterUser(c *gin.Context) {
 // @Success 200 {object} pkg.APIResponse "User authenticated successfully"
 // @Failure 400 {object} pkg.APIResponse "Invalid login data"
 // @Failure 401 {object} pkg.APIResponse "Unauthorized - Invalid credentials"
+// @Failure 403 {object} pkg.APIResponse "Account disabled or password reset required"
+// @Failure 423 {object} pkg.APIResponse "Account temporarily locked"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
 // @Router /login [post]
 func (h *UserHandler) AuthenticateUser(c *gin.Context) {
 	var loginDTO dto.UserLoginDTO
@@ -64,10 +74,12 @@ func (h *UserHandler) AuthenticateUser(c *gin.Context) {
 		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid login data: %v", err))
 		return
 	}
+	loginDTO.IPAddress = c.ClientIP()
+	loginDTO.DeviceInformation = c.Request.UserAgent()
 
 	response, err := h.userService.AuthenticateUser(loginDTO)
 	if err != nil {
-		pkg.RespondWithError(c, http.StatusUnauthorized, err)
+		pkg.RespondWithError(c, loginErrorStatus(err), err)
 		return
 	}
 
@@ -76,14 +88,15 @@ func (h *UserHandler) AuthenticateUser(c *gin.Context) {
 
 // UpdateUserPassword handles PUT /users/{id}/password endpoint
 // @Summary Update user password
-// @Description This endpoint updates the password for the user with the specified ID.
+// @Description This endpoint changes the password of the user with the specified ID after checking the current password, and signs the user out of their other sessions. The new password must meet the password policy and differ from the latest passwords of the user.
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param id path int true "User ID"
 // @Param password body dto.UserPasswordUpdateDTO true "User Password Update DTO"
 // @Success 200 {object} pkg.APIResponse "Password updated successfully"
-// @Failure 400 {object} pkg.APIResponse "Invalid user ID format or password data"
+// @Failure 400 {object} pkg.APIResponse "Invalid user ID format, password data or password not meeting the policy"
+// @Failure 403 {object} pkg.APIResponse "Current password is incorrect"
 // @Failure 500 {object} pkg.APIResponse "Internal server error"
 // @Router /users/{id}/password [put]
 func (h *UserHandler) UpdateUserPassword(c *gin.Context) {
@@ -99,18 +112,57 @@ func (h *UserHandler) UpdateUserPassword(c *gin.Context) {
 		return
 	}
 	passwordDTO.UserID = uint(userID)
-
-	if err := h.userService.UpdateUserPassword(passwordDTO); err != nil {
-		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+	principal, _ := authz.CurrentPrincipal(c)
+	passwordDTO.SessionID = principal.SessionID // The session changing the password stays signed in
+
+	if err := h.userService.UpdateUserPassword(passwordDTO, currentActor(c)); err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, services.ErrInvalidCurrentPassword):
+			status = http.StatusForbidden
+		case errors.Is(err, services.ErrWeakPassword):
+			status = http.StatusBadRequest
+		}
+		pkg.RespondWithError(c, status, err)
 		return
 	}
 
 	pkg.RespondWithSuccess(c, http.StatusOK, nil, "Password updated successfully")
 }
 
+// UnlockUser handles POST /users/{id}/unlock endpoint
+// @Summary Unlock user
+// @Description This endpoint lifts the lockout of an account locked after too many failed logins. Admin only.
+// @Tags users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} pkg.APIResponse "User unlocked successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid user ID format"
+// @Failure 404 {object} pkg.APIResponse "User not found"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /users/{id}/unlock [post]
+func (h *UserHandler) UnlockUser(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid user ID format: %v", err))
+		return
+	}
+
+	if err := h.userService.UnlockUser(uint(userID), currentActor(c)); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			status = http.StatusNotFound
+		}
+		pkg.RespondWithError(c, status, err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusOK, nil, "User unlocked successfully")
+}
+
 // DeleteUser handles DELETE /users/{id} endpoint
 // @Summary Delete user
-// @Description This endpoint deletes a user with the specified ID.
+// @Description This endpoint deletes a user with the specified ID. Their data is erased across services once the grace period ends, until then admins can restore the account.
 // @Tags users
 // @Produce json
 // @Param id path int true "User ID"
@@ -125,10 +177,27 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	if err := h.userService.DeleteUser(uint(userID)); err != nil {
+	if err := h.userService.DeleteUser(uint(userID), currentActor(c)); err != nil {
 		pkg.RespondWithError(c, http.StatusInternalServerError, err)
 		return
 	}
 
 	pkg.RespondWithSuccess(c, http.StatusNoContent, nil, "User deleted successfully")
 }
+
+// loginErrorStatus maps authentication errors to HTTP status codes.
+func loginErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidCredentials), errors.Is(err, services.ErrInvalidMFACode),
+		errors.Is(err, services.ErrInvalidMFAToken), errors.Is(err, services.ErrInvalidLoginCode):
+		return http.StatusUnauthorized
+	case errors.Is(err, services.ErrMFANotEnrolled):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrAccountDisabled), errors.Is(err, services.ErrPasswordResetRequired):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrAccountLocked):
+		return http.StatusLocked
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"auth-service/internal/api/dto"
 	"auth-service/internal/services"
 	"auth-service/pkg"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"shared/authz"
 	"strconv"
 )
 
@@ -22,13 +24,14 @@ func NewUserVerificationHandler(verificationService services.IUserVerificationSe
 
 // CreateVerification handles POST /verifications
 // @Summary Create user verification
-// @Description This endpoint creates a user verification entry.
+// @Description This endpoint issues a new verification for a user, replacing any pending one of the same type. Email verifications are sent to the user, phone verification codes to the current phone number of the user by SMS.
 // @Tags verification
 // @Accept json
 // @Produce json
 // @Param verification body dto.CreateUserVerificationRequest true "Create User Verification Request"
 // @Success 201 {object} pkg.APIResponse "Verification created successfully"
 // @Failure 400 {object} pkg.APIResponse "Invalid request data"
+// @Failure 409 {object} pkg.APIResponse "User has no phone number to verify"
 // @Failure 500 {object} pkg.APIResponse "Internal server error"
 // @Router /verifications [post]
 func (h *UserVerificationHandler) CreateVerification(c *gin.Context) {
@@ -39,7 +42,7 @@ func (h *UserVerificationHandler) CreateVerification(c *gin.Context) {
 	}
 	response, err := h.verificationService.CreateVerification(req)
 	if err != nil {
-		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+		pkg.RespondWithError(c, verificationErrorStatus(err), err)
 		return
 	}
 	pkg.RespondWithSuccess(c, http.StatusCreated, response, "Verification created successfully")
@@ -123,3 +126,208 @@ func (h *UserVerificationHandler) GetVerificationsByUserID(c *gin.Context) {
 	}
 	pkg.RespondWithSuccess(c, http.StatusOK, responses, "List of verifications fetched successfully")
 }
+
+// VerifyEmail handles GET /verify endpoint
+// @Summary Verify email address
+// @Description This endpoint consumes the token of a verification email and marks the user as verified. Each token can be used once before it expires.
+// @Tags verification
+// @Produce json
+// @Param token query string true "Verification token"
+// @Success 200 {object} pkg.APIResponse "Email verified successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid or expired token"
+// @Failure 409 {object} pkg.APIResponse "Token already used"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /verify [get]
+func (h *UserVerificationHandler) VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		pkg.RespondWithError(c, http.StatusBadRequest, errors.New("token query parameter is required"))
+		return
+	}
+	if err := h.verificationService.VerifyEmail(token); err != nil {
+		pkg.RespondWithError(c, verificationErrorStatus(err), err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, nil, "Email verified successfully")
+}
+
+// ResendEmailVerification handles POST /verify/resend endpoint
+// @Summary Resend verification email
+// @Description This endpoint sends a new verification email to the authenticated user, invalidating the previous link. It can be called once per cooldown period.
+// @Tags verification
+// @Produce json
+// @Success 200 {object} pkg.APIResponse "Verification email sent successfully"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 409 {object} pkg.APIResponse "Email already verified"
+// @Failure 429 {object} pkg.APIResponse "Verification email sent too recently"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /verify/resend [post]
+func (h *UserVerificationHandler) ResendEmailVerification(c *gin.Context) {
+	principal, _ := authz.CurrentPrincipal(c)
+	if err := h.verificationService.ResendEmailVerification(principal.UserID); err != nil {
+		pkg.RespondWithError(c, verificationErrorStatus(err), err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, nil, "Verification email sent successfully")
+}
+
+// ForgotPassword handles POST /password/forgot endpoint
+// @Summary Request password reset
+// @Description This endpoint emails a single-use password reset link if an account uses the given address. The response is the same whether or not it does.
+// @Tags verification
+// @Accept json
+// @Produce json
+// @Param request body dto.ForgotPasswordRequest true "Forgot Password Request"
+// @Success 200 {object} pkg.APIResponse "Password reset email sent if the account exists"
+// @Failure 400 {object} pkg.APIResponse "Invalid request data"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /password/forgot [post]
+func (h *UserVerificationHandler) ForgotPassword(c *gin.Context) {
+	var req dto.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid request data: %v", err))
+		return
+	}
+	if err := h.verificationService.RequestPasswordReset(req); err != nil {
+		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, nil, "If an account exists for this email, a password reset link has been sent")
+}
+
+// ResetPassword handles POST /password/reset endpoint
+// @Summary Reset password
+// @Description This endpoint sets a new password with the token of a password reset email and signs the user out of all sessions. Each token can be used once before it expires. The new password must meet the password policy and differ from the latest passwords of the user.
+// @Tags verification
+// @Accept json
+// @Produce json
+// @Param request body dto.ResetPasswordRequest true "Reset Password Request"
+// @Success 200 {object} pkg.APIResponse "Password reset successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid request data, invalid, used or expired token or password not meeting the policy"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /password/reset [post]
+func (h *UserVerificationHandler) ResetPassword(c *gin.Context) {
+	var req dto.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid request data: %v", err))
+		return
+	}
+	if err := h.verificationService.ResetPassword(req, currentActor(c)); err != nil {
+		pkg.RespondWithError(c, verificationErrorStatus(err), err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, nil, "Password reset successfully")
+}
+
+// StartPhoneVerification handles POST /users/{id}/phone endpoint
+// @Summary Start phone verification
+// @Description This endpoint sends a 6-digit code by SMS to a phone number in E.164 format, invalidating codes sent before. The number becomes the phone number of the user once verified with the code. It can be called once per cooldown period.
+// @Tags verification
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body dto.PhoneNumberRequest true "Phone Number Request"
+// @Success 200 {object} pkg.APIResponse "Phone verification code sent successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid user ID format or request data"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 409 {object} pkg.APIResponse "Phone number already verified by this or another account"
+// @Failure 429 {object} pkg.APIResponse "Verification code sent too recently"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /users/{id}/phone [post]
+func (h *UserVerificationHandler) StartPhoneVerification(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid user ID format: %v", err))
+		return
+	}
+
+	var req dto.PhoneNumberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid request data: %v", err))
+		return
+	}
+
+	response, err := h.verificationService.StartPhoneVerification(uint(userID), req)
+	if err != nil {
+		pkg.RespondWithError(c, verificationErrorStatus(err), err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "Phone verification code sent successfully")
+}
+
+// VerifyPhone handles POST /users/{id}/phone/verify endpoint
+// @Summary Verify phone number
+// @Description This endpoint verifies the phone number the latest code was sent to and sets it as the phone number of the user. Each code works once, before it expires, and is discarded after 5 attempts.
+// @Tags verification
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body dto.PhoneCodeRequest true "Phone Code Request"
+// @Success 200 {object} pkg.APIResponse "Phone number verified successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid user ID format, request data or code"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 409 {object} pkg.APIResponse "Phone number verified by another account"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /users/{id}/phone/verify [post]
+func (h *UserVerificationHandler) VerifyPhone(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid user ID format: %v", err))
+		return
+	}
+
+	var req dto.PhoneCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid request data: %v", err))
+		return
+	}
+
+	if err := h.verificationService.VerifyPhone(uint(userID), req); err != nil {
+		pkg.RespondWithError(c, verificationErrorStatus(err), err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, nil, "Phone number verified successfully")
+}
+
+// RemovePhoneNumber handles DELETE /users/{id}/phone endpoint
+// @Summary Remove phone number
+// @Description This endpoint removes the phone number of the user, who then has no verified phone.
+// @Tags verification
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} pkg.APIResponse "Phone number removed successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid user ID format"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /users/{id}/phone [delete]
+func (h *UserVerificationHandler) RemovePhoneNumber(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid user ID format: %v", err))
+		return
+	}
+
+	if err := h.verificationService.RemovePhoneNumber(uint(userID)); err != nil {
+		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	pkg.RespondWithSuccess(c, http.StatusOK, nil, "Phone number removed successfully")
+}
+
+// verificationErrorStatus maps verification service errors to HTTP status codes.
+func verificationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidVerificationToken), errors.Is(err, services.ErrVerificationTokenExpired),
+		errors.Is(err, services.ErrInvalidResetToken), errors.Is(err, services.ErrWeakPassword),
+		errors.Is(err, services.ErrInvalidPhoneCode):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrVerificationTokenUsed), errors.Is(err, services.ErrAlreadyVerified),
+		errors.Is(err, services.ErrPhoneNumberTaken), errors.Is(err, services.ErrPhoneAlreadyVerified),
+		errors.Is(err, services.ErrPhoneNumberMissing):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrVerificationResendTooSoon):
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
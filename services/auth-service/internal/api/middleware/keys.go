@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"auth-service/internal/api/dto"
+	"errors"
+	"shared/authz"
+	"strings"
+)
+
+// errInactiveKey is returned for API keys that are revoked, expired or unknown.
+var errInactiveKey = errors.New("api key is not active")
+
+// APIKeyIntrospector reports the state of API keys, implemented by the API key service.
+type APIKeyIntrospector interface {
+	Introspect(key string) (dto.APIKeyIntrospectionResponse, error)
+}
+
+// SetAPIKeyIntrospector sets the service API keys are checked with. auth-service looks keys up itself rather
+// than calling its introspection endpoint.
+func SetAPIKeyIntrospector(introspector APIKeyIntrospector) {
+	authz.SetAPIKeySource(introspectorSource{introspector})
+}
+
+// introspectorSource adapts an APIKeyIntrospector to an authz.APIKeySource.
+type introspectorSource struct {
+	introspector APIKeyIntrospector
+}
+
+func (s introspectorSource) APIKeyPrincipal(key string) (*authz.Principal, error) {
+	result, err := s.introspector.Introspect(key)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Active {
+		return nil, errInactiveKey
+	}
+	return &authz.Principal{
+		UserID:   result.UserID,
+		Username: result.Username,
+		Role:     result.Role,
+		APIKeyID: result.KeyID,
+		Scopes:   strings.Fields(result.Scope),
+	}, nil
+}
+
+// SessionChecker reports whether sessions are active, implemented by the session service.
+type SessionChecker interface {
+	SessionActive(sessionID uint) (bool, error)
+}
+
+// SetSessionChecker sets the service sessions of access tokens are checked with. auth-service looks sessions up
+// itself rather than calling its introspection endpoint.
+func SetSessionChecker(checker SessionChecker) {
+	authz.SetSessionSource(checkerSource{checker})
+}
+
+// checkerSource adapts a SessionChecker to an authz.SessionSource.
+type checkerSource struct {
+	checker SessionChecker
+}
+
+func (s checkerSource) SessionActive(sessionID uint, _ string) (bool, error) {
+	return s.checker.SessionActive(sessionID)
+}
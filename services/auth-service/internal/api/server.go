@@ -5,8 +5,10 @@ import (
 	"auth-service/internal/api/handler"
 	"auth-service/internal/api/middleware"
 	"auth-service/internal/config"
+	"auth-service/internal/geoip"
 	"auth-service/internal/repository"
 	"auth-service/internal/services"
+	"auth-service/internal/sms"
 	"context"
 	"errors"
 	"github.com/gin-gonic/gin"
@@ -16,14 +18,30 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"shared/authz"
+	"shared/scheduler"
+	"strings"
 	"syscall"
 	"time"
 )
 
+// Advisory lock keys guarding the background jobs, unique across the auth database.
+const (
+	rotateKeysLockKey     int64 = 83001
+	eraseAccountsLockKey  int64 = 83002
+	exportDataLockKey     int64 = 83003
+	loginRetentionLockKey int64 = 83004
+)
+
 // Server holds the dependencies for a HTTP server.
 type Server struct {
-	Router *gin.Engine
-	DB     *config.Database
+	Router         *gin.Engine
+	DB             *config.Database
+	Scheduler      *scheduler.Scheduler
+	KeyService     services.IKeyService
+	ErasureService services.IAccountErasureService
+	ExportService  services.IDataExportService
+	HistoryService services.ILoginHistoryService
 }
 
 // NewServer creates a new HTTP server and sets up routing.
@@ -33,10 +51,12 @@ func NewServer(databaseClient *config.Database) *Server {
 	// Swagger documentation
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler, ginSwagger.URL("/swagger/doc.json")))
 	s := &Server{
-		Router: r,
-		DB:     databaseClient,
+		Router:     r,
+		DB:         databaseClient,
+		KeyService: services.NewKeyService(repository.NewSigningKeyRepository(databaseClient.Conn)),
 	}
 	s.routes()
+	s.jobs()
 	return s
 }
 
@@ -46,20 +66,99 @@ func (s *Server) routes() {
 	// API Versioning
 	v1 := s.Router.Group("/api/v1/auth")
 
+	// Repositories shared by the handlers
+	userRepo := repository.NewUserRepository(s.DB.Conn)
+	sessionRepo := repository.NewSessionRepository(s.DB.Conn)
+	loginHistoryRepo := repository.NewLoginHistoryRepository(s.DB.Conn)
+	roleRepo := repository.NewRoleRepository(s.DB.Conn)
+	tokenService := services.NewTokenService(userRepo, sessionRepo, loginHistoryRepo, roleRepo, s.KeyService)
+	passwordService := services.NewPasswordService(userRepo, repository.NewPasswordHistoryRepository(s.DB.Conn))
+
+	// Sensitive actions of every service below are recorded in the audit log
+	auditService := services.NewAuditService(repository.NewAuditRepository(s.DB.Conn))
+	s.setupAuditRoutes(v1, handler.NewAuditHandler(auditService))
+
+	// Tokens are verified with the keys they are signed with
+	authz.SetKeySource(s.KeyService)
+	s.Router.GET("/.well-known/jwks.json", handler.NewKeyHandler(s.KeyService).GetJWKS)
+
+	// Verification emails and security alerts are sent through notification-service
+	notificationService := services.NewNotificationService()
+	// Phone verification and passwordless login codes are sent by SMS directly
+	smsProvider := openSMSProvider()
+	verificationRepo := repository.NewUserVerificationRepository(s.DB.Conn)
+	verificationService := services.NewUserVerificationService(
+		verificationRepo,
+		userRepo,
+		sessionRepo,
+		notificationService,
+		passwordService,
+		smsProvider,
+		auditService,
+	)
+
+	// The data of deleted accounts is erased across services once their grace period ends
+	s.ErasureService = services.NewAccountErasureService(repository.NewAccountErasureRepository(s.DB.Conn))
+
 	// Setup user handlers
-	u := handler.NewUserHandler(services.NewUserService(repository.NewUserRepository(s.DB.Conn)))
+	mfaService := services.NewMFAService(repository.NewMFARepository(s.DB.Conn), userRepo, roleRepo, auditService)
+	anomalyService := services.NewLoginAnomalyService(loginHistoryRepo, notificationService, openGeoIPDatabase())
+	passwordlessService := services.NewPasswordlessService(repository.NewLoginCodeRepository(s.DB.Conn), userRepo, notificationService, smsProvider)
+	userService := services.NewUserService(userRepo, sessionRepo, loginHistoryRepo, tokenService, verificationService, mfaService, anomalyService, passwordService, passwordlessService, s.ErasureService, auditService)
+	u := handler.NewUserHandler(userService)
 
 	// Setup user routes
 	s.setupUserRoutes(v1, u)
 
-	// Setup login history handlers
-	h := handler.NewLoginHistoryHandler(services.NewLoginHistoryService(repository.NewLoginHistoryRepository(s.DB.Conn)))
+	// Setup personal data export handlers and routes; the archives are prepared by the export job
+	s.ExportService = services.NewDataExportService(repository.NewDataExportRepository(s.DB.Conn), userRepo, loginHistoryRepo, notificationService)
+	s.setupDataExportRoutes(v1, handler.NewDataExportHandler(s.ExportService))
+
+	// Setup passwordless login handlers and routes
+	s.setupPasswordlessRoutes(v1, handler.NewPasswordlessHandler(passwordlessService, userService))
+
+	// Setup MFA handlers and routes
+	s.setupMFARoutes(v1, handler.NewMFAHandler(mfaService, userService))
+
+	// Setup OpenID Connect provider handlers and routes
+	o := handler.NewOAuthHandler(services.NewOAuthService(repository.NewOAuthRepository(s.DB.Conn), userRepo, sessionRepo, tokenService, auditService))
+	s.Router.GET("/.well-known/openid-configuration", o.GetDiscoveryDocument)
+	s.setupOAuthRoutes(v1, o)
+
+	// Setup API key handlers and routes; keys are checked in process rather than through introspection
+	apiKeyService := services.NewAPIKeyService(repository.NewAPIKeyRepository(s.DB.Conn), userRepo, auditService)
+	middleware.SetAPIKeyIntrospector(apiKeyService)
+	s.setupAPIKeyRoutes(v1, handler.NewAPIKeyHandler(apiKeyService))
+
+	// Setup user administration handlers and routes
+	userAdminService := services.NewUserAdminService(userRepo, sessionRepo, loginHistoryRepo, verificationRepo, roleRepo, verificationService, s.ErasureService, auditService)
+	s.setupUserAdminRoutes(v1, handler.NewUserAdminHandler(userAdminService), handler.NewAccountErasureHandler(s.ErasureService))
+
+	// Setup role handlers and routes; the default roles are created on the first start
+	accessControlService := services.NewAccessControlService(roleRepo, userRepo, auditService)
+	if err := accessControlService.SeedDefaultRoles(); err != nil {
+		log.Fatalf("Could not create default roles: %v", err)
+	}
+	s.setupRoleRoutes(v1, handler.NewRoleHandler(accessControlService))
+
+	// Setup token handlers and routes
+	t := handler.NewTokenHandler(tokenService)
+	s.setupTokenRoutes(v1, t)
+
+	// Setup session handlers and routes; access tokens of revoked sessions are rejected
+	sessionService := services.NewSessionService(sessionRepo, auditService)
+	middleware.SetSessionChecker(sessionService)
+	s.setupSessionRoutes(v1, handler.NewSessionHandler(sessionService))
+
+	// Setup login history handlers; old attempts are archived or purged by the retention job
+	s.HistoryService = services.NewLoginHistoryService(loginHistoryRepo)
+	h := handler.NewLoginHistoryHandler(s.HistoryService)
 
 	// Setup login history routes
 	s.setupLoginHistoryRoutes(v1, h)
 
 	// Setup user verification handlers
-	v := handler.NewUserVerificationHandler(services.NewUserVerificationService(repository.NewUserVerificationRepository(s.DB.Conn)))
+	v := handler.NewUserVerificationHandler(verificationService)
 
 	// Setup user verification routes
 	s.setupUserVerificationRoutes(v1, v)
@@ -71,6 +170,80 @@ func (s *Server) routes() {
 	s.setupNoRouteHandler()
 }
 
+// openGeoIPDatabase loads the comma-separated GeoIP database files at GEOIP_DATABASE_PATH, which logins are
+// located with. Without it, logins are not located.
+func openGeoIPDatabase() *geoip.Database {
+	path := os.Getenv("GEOIP_DATABASE_PATH")
+	if path == "" {
+		log.Println("GEOIP_DATABASE_PATH is not set, impossible travel between logins is not detected")
+		return nil
+	}
+	db, err := geoip.Open(strings.Split(path, ",")...)
+	if err != nil {
+		log.Fatalf("Could not open GeoIP database: %v", err)
+	}
+	return db
+}
+
+// openSMSProvider returns the SMS provider named by SMS_PROVIDER. Without one, text messages are only logged.
+func openSMSProvider() sms.Provider {
+	switch provider := os.Getenv("SMS_PROVIDER"); provider {
+	case "", "fake":
+		log.Println("SMS_PROVIDER is not set, text messages are logged instead of sent")
+		return sms.NewFake()
+	case "twilio":
+		accountSID, authToken, from := os.Getenv("TWILIO_ACCOUNT_SID"), os.Getenv("TWILIO_AUTH_TOKEN"), os.Getenv("TWILIO_FROM_NUMBER")
+		if accountSID == "" || authToken == "" || from == "" {
+			log.Fatal("TWILIO_ACCOUNT_SID, TWILIO_AUTH_TOKEN and TWILIO_FROM_NUMBER are required to send text messages with Twilio")
+		}
+		return sms.NewTwilio(accountSID, authToken, from)
+	default:
+		log.Fatalf("Unknown SMS_PROVIDER %q", provider)
+		return nil
+	}
+}
+
+// jobs registers the background jobs run by the scheduler.
+func (s *Server) jobs() {
+	sqlDB, err := s.DB.Conn.DB()
+	if err != nil {
+		log.Fatalf("Could not get database connection: %v", err)
+	}
+	s.Scheduler = scheduler.NewScheduler(sqlDB)
+
+	// Generate the first signing key and rotate it on schedule
+	s.Scheduler.Register(scheduler.Job{
+		Name:     "rotate-signing-keys",
+		Interval: config.GetDuration("SIGNING_KEY_CHECK_INTERVAL", time.Hour),
+		LockKey:  rotateKeysLockKey,
+		Run:      s.KeyService.RotateKeys,
+	})
+
+	// Erase the data of deleted accounts across services once their grace period ends
+	s.Scheduler.Register(scheduler.Job{
+		Name:     "erase-deleted-accounts",
+		Interval: config.GetDuration("ACCOUNT_ERASURE_INTERVAL", 5*time.Minute),
+		LockKey:  eraseAccountsLockKey,
+		Run:      s.ErasureService.ProcessDue,
+	})
+
+	// Prepare the personal data exports users asked for and remove those whose link expired
+	s.Scheduler.Register(scheduler.Job{
+		Name:     "export-personal-data",
+		Interval: config.GetDuration("DATA_EXPORT_INTERVAL", time.Minute),
+		LockKey:  exportDataLockKey,
+		Run:      s.ExportService.ProcessPending,
+	})
+
+	// Archive or purge the login attempts older than the retention period
+	s.Scheduler.Register(scheduler.Job{
+		Name:     "apply-login-history-retention",
+		Interval: config.GetDuration("LOGIN_HISTORY_RETENTION_INTERVAL", time.Hour),
+		LockKey:  loginRetentionLockKey,
+		Run:      s.HistoryService.ApplyRetention,
+	})
+}
+
 func (s *Server) setupHealthCheckRoute() {
 	s.Router.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"code": 200, "message": "Auth services is running"})
@@ -94,31 +267,168 @@ func (s *Server) setupNoRouteHandler() {
 func (s *Server) setupUserRoutes(v1 *gin.RouterGroup, u *handler.UserHandler) {
 	v1.POST("/register", u.RegisterUser)
 	v1.POST("/login", u.AuthenticateUser)
-	v1.PUT("/users/:id/password", u.UpdateUserPassword)
-	v1.DELETE("/users/:id", u.DeleteUser)
+
+	// Accounts are only changed by their owner and admins
+	auth, owner := authz.Authenticate(), authz.RequireOwner("id")
+	v1.PUT("/users/:id/password", auth, owner, u.UpdateUserPassword)
+	v1.DELETE("/users/:id", auth, owner, u.DeleteUser)
+
+	// Locked accounts unlock by themselves, support can unlock them earlier
+	v1.POST("/users/:id/unlock", auth, authz.RequirePermission(authz.PermissionUsersUnlock), u.UnlockUser)
+}
+
+func (s *Server) setupDataExportRoutes(v1 *gin.RouterGroup, x *handler.DataExportHandler) {
+	// Users ask for copies of their own data, the download link emailed to them needs no token
+	auth := authz.Authenticate()
+	v1.POST("/users/:id/data-exports", auth, authz.RequireOwner("id"), x.RequestExport)
+	v1.GET("/users/:userID/data-exports", auth, authz.RequireOwner("userID"), x.ListExports)
+	v1.GET("/data-exports/download", x.DownloadExport)
+}
+
+func (s *Server) setupUserAdminRoutes(v1 *gin.RouterGroup, a *handler.UserAdminHandler, e *handler.AccountErasureHandler) {
+	// Support can look accounts up, only admins act on them
+	auth, admin := authz.Authenticate(), authz.RequireRole(authz.RoleAdmin)
+	read := authz.RequirePermission(authz.PermissionUsersRead)
+	v1.GET("/users", auth, read, a.ListUsers)
+	v1.GET("/users/:userID", auth, read, a.GetUser)
+	v1.POST("/users/:id/disable", auth, admin, a.DisableUser)
+	v1.POST("/users/:id/enable", auth, admin, a.EnableUser)
+	v1.PUT("/users/:id/role", auth, admin, a.ChangeRole)
+	v1.POST("/users/:id/password-reset", auth, admin, a.ForcePasswordReset)
+	v1.POST("/users/:id/restore", auth, admin, a.RestoreUser)
+	v1.GET("/users/:userID/erasure", auth, read, e.GetErasure)
+}
+
+func (s *Server) setupPasswordlessRoutes(v1 *gin.RouterGroup, p *handler.PasswordlessHandler) {
+	// Logins with a link or code sent to the user instead of a password
+	v1.POST("/login/passwordless", p.StartLogin)
+	v1.POST("/login/passwordless/code", p.LoginWithCode)
+	v1.POST("/login/passwordless/link", p.LoginWithLink)
+}
+
+func (s *Server) setupMFARoutes(v1 *gin.RouterGroup, m *handler.MFAHandler) {
+	// Second step of logins to accounts with MFA
+	v1.POST("/login/mfa", m.CompleteLogin)
+	v1.POST("/login/mfa/enroll", m.BeginLoginEnrollment)
+
+	// Users manage their own MFA, admins decide which roles require it
+	auth, admin := authz.Authenticate(), authz.RequireRole(authz.RoleAdmin)
+	v1.GET("/mfa", auth, m.GetStatus)
+	v1.POST("/mfa/enroll", auth, m.BeginEnrollment)
+	v1.POST("/mfa/enroll/confirm", auth, m.ConfirmEnrollment)
+	v1.POST("/mfa/recovery-codes", auth, m.RegenerateRecoveryCodes)
+	v1.POST("/mfa/disable", auth, m.Disable)
+	v1.GET("/mfa/policies", auth, admin, m.ListPolicies)
+	v1.PUT("/mfa/policies/:role", auth, admin, m.SetPolicy)
+}
+
+func (s *Server) setupOAuthRoutes(v1 *gin.RouterGroup, o *handler.OAuthHandler) {
+	auth, admin := authz.Authenticate(), authz.RequireRole(authz.RoleAdmin)
+
+	// Partner applications are registered by admins
+	v1.POST("/oauth/clients", auth, admin, o.RegisterClient)
+	v1.GET("/oauth/clients", auth, admin, o.ListClients)
+	v1.DELETE("/oauth/clients/:clientID", auth, admin, o.DeleteClient)
+
+	// The consent page authorizes clients on behalf of the signed in user
+	v1.GET("/oauth/authorize", auth, o.GetAuthorization)
+	v1.POST("/oauth/authorize", auth, o.Authorize)
+	v1.GET("/oauth/consents", auth, o.ListConsents)
+	v1.DELETE("/oauth/consents/:clientID", auth, o.RevokeConsent)
+
+	// Endpoints called by the clients. The tokens of clients are only accepted by the userinfo endpoint.
+	v1.POST("/oauth/token", o.Token)
+	v1.GET("/oauth/userinfo", authz.Authenticate(services.ScopeOpenID), o.UserInfo)
+}
+
+func (s *Server) setupAPIKeyRoutes(v1 *gin.RouterGroup, k *handler.APIKeyHandler) {
+	// Partner accounts manage their own keys, admins can manage anyone's. The user ID parameter is named like
+	// on the other user routes of the same method, as the router requires.
+	auth := authz.Authenticate()
+	v1.POST("/users/:id/api-keys", auth, authz.RequireOwner("id"), k.CreateKey)
+	v1.GET("/users/:userID/api-keys", auth, authz.RequireOwner("userID"), k.ListKeys)
+	v1.POST("/users/:id/api-keys/:keyID/rotate", auth, authz.RequireOwner("id"), k.RotateKey)
+	v1.DELETE("/users/:id/api-keys/:keyID", auth, authz.RequireOwner("id"), k.RevokeKey)
+
+	// Other services check the keys sent to them, with a service token allowed to
+	v1.POST("/api-keys/introspect", authz.Authenticate(authz.ScopeAPIKeysIntrospect), authz.RequireRole(authz.RoleService), k.Introspect)
+}
+
+func (s *Server) setupRoleRoutes(v1 *gin.RouterGroup, r *handler.RoleHandler) {
+	// Roles and their permissions are managed by admins
+	auth, admin := authz.Authenticate(), authz.RequireRole(authz.RoleAdmin)
+	v1.GET("/permissions", auth, admin, r.ListPermissions)
+	v1.GET("/roles", auth, admin, r.ListRoles)
+	v1.POST("/roles", auth, admin, r.CreateRole)
+	v1.PUT("/roles/:name", auth, admin, r.UpdateRole)
+	v1.DELETE("/roles/:name", auth, admin, r.DeleteRole)
+
+	// Users can see their own roles, admins give and take them
+	v1.GET("/users/:userID/roles", auth, authz.RequireOwner("userID"), r.GetUserRoles)
+	v1.POST("/users/:id/roles", auth, admin, r.AssignRole)
+	v1.DELETE("/users/:id/roles/:role", auth, admin, r.UnassignRole)
+}
+
+func (s *Server) setupAuditRoutes(v1 *gin.RouterGroup, a *handler.AuditHandler) {
+	// Security reviewers search, export and verify the audit log
+	auth, read := authz.Authenticate(), authz.RequirePermission(authz.PermissionAuditRead)
+	v1.GET("/audit-events", auth, read, a.ListEvents)
+	v1.GET("/audit-events/export", auth, read, a.ExportEvents)
+	v1.GET("/audit-events/verify", auth, read, a.VerifyChain)
+}
+
+func (s *Server) setupTokenRoutes(v1 *gin.RouterGroup, t *handler.TokenHandler) {
+	v1.POST("/token/refresh", t.RefreshToken)
+	v1.POST("/logout", t.Logout)
+}
+
+func (s *Server) setupSessionRoutes(v1 *gin.RouterGroup, h *handler.SessionHandler) {
+	// Users sign out of their own devices, admins can sign anyone out
+	auth := authz.Authenticate()
+	v1.GET("/users/:userID/sessions", auth, authz.RequireOwner("userID"), h.ListSessions)
+	v1.DELETE("/users/:id/sessions", auth, authz.RequireOwner("id"), h.RevokeSessions)
+	v1.DELETE("/users/:id/sessions/:sessionID", auth, authz.RequireOwner("id"), h.RevokeSession)
+
+	// Other services check that the sessions of access tokens were not revoked
+	v1.GET("/token/introspect", auth, h.Introspect)
 }
 
 func (s *Server) setupLoginHistoryRoutes(v1 *gin.RouterGroup, h *handler.LoginHistoryHandler) {
-	// Record a login attempt
-	v1.POST("/login-attempts", h.RecordLoginAttempt)
+	auth, owner := authz.Authenticate(), authz.RequireOwner("userID")
 
-	// Get login attempts for a specific user within a timeframe
-	v1.GET("/login-attempts/:userID", h.GetLoginAttemptsByUser)
+	// Logins are recorded by the login endpoint, admins can record attempts made elsewhere
+	v1.POST("/login-attempts", auth, authz.RequireRole(authz.RoleAdmin), h.RecordLoginAttempt)
 
-	// Record user logout
-	v1.PUT("/logout/:historyID", h.RecordUserLogout)
+	// Page through or export the login attempts of a user
+	v1.GET("/login-attempts/:userID", auth, owner, h.GetLoginAttemptsByUser)
+	v1.GET("/login-attempts/:userID/export", auth, owner, h.ExportLoginAttempts)
 
 	// Check for suspicious activity for a specific user
-	v1.GET("/suspicious-activity/:userID", h.CheckSuspiciousActivity)
+	v1.GET("/suspicious-activity/:userID", auth, owner, h.CheckSuspiciousActivity)
 }
 
 // setup userVerification routes
 func (s *Server) setupUserVerificationRoutes(v1 *gin.RouterGroup, v *handler.UserVerificationHandler) {
-	v1.POST("/verifications", v.CreateVerification)
-	v1.PUT("/verifications/:id", v.UpdateVerificationStatus)
-	v1.GET("/verifications/:id", v.GetVerificationDetails)
-	v1.GET("/users/:userID/verifications", v.GetVerificationsByUserID)
+	// Verification outcomes are decided by admins, users can see their own verifications
+	auth, admin := authz.Authenticate(), authz.RequireRole(authz.RoleAdmin)
+	v1.POST("/verifications", auth, admin, v.CreateVerification)
+	v1.PUT("/verifications/:id", auth, admin, v.UpdateVerificationStatus)
+	v1.GET("/verifications/:id", auth, admin, v.GetVerificationDetails)
+	v1.GET("/users/:userID/verifications", auth, authz.RequireOwner("userID"), v.GetVerificationsByUserID)
 
+	// Email verification links and resending them
+	v1.GET("/verify", v.VerifyEmail)
+	v1.POST("/verify/resend", auth, v.ResendEmailVerification)
+
+	// Users verify their own phone numbers with a code sent by SMS
+	owner := authz.RequireOwner("id")
+	v1.POST("/users/:id/phone", auth, owner, v.StartPhoneVerification)
+	v1.POST("/users/:id/phone/verify", auth, owner, v.VerifyPhone)
+	v1.DELETE("/users/:id/phone", auth, owner, v.RemovePhoneNumber)
+
+	// Password reset links, requested without signing in
+	v1.POST("/password/forgot", v.ForgotPassword)
+	v1.POST("/password/reset", v.ResetPassword)
 }
 
 // Start runs the HTTP server on a specific address.
@@ -135,12 +445,20 @@ func (s *Server) Start(addr string) {
 	}()
 	log.Println("Server started successfully on", addr)
 
+	// Start background jobs; they are stopped together with the HTTP server.
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	s.Scheduler.Start(jobsCtx)
+
 	// Wait for interrupt signal to gracefully shut down the server with a timeout.
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("Shutting down server...")
 
+	// Stop scheduling new runs and let in-flight jobs finish.
+	stopJobs()
+	s.Scheduler.Wait()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second) // Shortened timeout
 	defer cancel()
 	if err := srv.Shutdown(ctx); err != nil {
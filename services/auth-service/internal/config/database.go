@@ -29,6 +29,8 @@ func NewDatabase(models ...interface{}) *Database {
 
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
 		//Logger: gormLogger,
+		// Report unique violations as gorm.ErrDuplicatedKey so races on unique indexes can be told apart
+		TranslateError: true,
 	})
 	if err != nil {
 		log.Fatalf("Could not connect to the database: %v", err)
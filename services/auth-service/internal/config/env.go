@@ -0,0 +1,38 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// GetDuration reads a duration such as "90s" or "15m" from the environment variable key,
+// falling back to defaultValue when it is unset or invalid.
+func GetDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		log.Printf("Invalid duration %q for %s, defaulting to %s", value, key, defaultValue)
+		return defaultValue
+	}
+	return duration
+}
+
+// GetInt reads a positive integer from the environment variable key, falling back to defaultValue when it is
+// unset or invalid.
+func GetInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number <= 0 {
+		log.Printf("Invalid number %q for %s, defaulting to %d", value, key, defaultValue)
+		return defaultValue
+	}
+	return number
+}
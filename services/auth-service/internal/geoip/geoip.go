@@ -0,0 +1,149 @@
+package geoip
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/netip"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// earthRadiusKm is the mean radius of the Earth used for distances between locations.
+const earthRadiusKm = 6371.0
+
+// Location is the approximate place an IP address is located at.
+type Location struct {
+	Country   string // ISO country code, empty when the database does not have it
+	Latitude  float64
+	Longitude float64
+}
+
+// DistanceKm returns the great-circle distance between two locations in kilometers.
+func (l Location) DistanceKm(other Location) float64 {
+	lat1, lat2 := l.Latitude*math.Pi/180, other.Latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (other.Longitude - l.Longitude) * math.Pi / 180
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// block is a network of the database with the first and last address it covers.
+type block struct {
+	first, last netip.Addr
+	location    Location
+}
+
+// Database looks up the location of IP addresses in networks loaded from CSV files, without calling any
+// external service.
+type Database struct {
+	blocks []block // Sorted by first address, not overlapping
+}
+
+// Open loads the networks of the CSV files at paths. Each file starts with a header naming at least the
+// network, latitude and longitude columns, as the GeoLite2 City blocks files do; a country_iso_code or country
+// column is used when present. Rows without coordinates are skipped.
+func Open(paths ...string) (*Database, error) {
+	db := &Database{}
+	for _, path := range paths {
+		if err := db.load(path); err != nil {
+			return nil, fmt.Errorf("failed to load geoip database %s: %w", path, err)
+		}
+	}
+	sort.Slice(db.blocks, func(i, j int) bool {
+		return db.blocks[i].first.Less(db.blocks[j].first)
+	})
+	return db, nil
+}
+
+func (db *Database) load(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	network, hasNetwork := columns["network"]
+	latitude, hasLatitude := columns["latitude"]
+	longitude, hasLongitude := columns["longitude"]
+	if !hasNetwork || !hasLatitude || !hasLongitude {
+		return errors.New("the header must name the network, latitude and longitude columns")
+	}
+	country, hasCountry := columns["country_iso_code"]
+	if !hasCountry {
+		country, hasCountry = columns["country"]
+	}
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(record) < len(header) || record[latitude] == "" || record[longitude] == "" {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(record[network])
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		lat, err := strconv.ParseFloat(record[latitude], 64)
+		if err != nil {
+			return fmt.Errorf("line %d: invalid latitude: %w", line, err)
+		}
+		lon, err := strconv.ParseFloat(record[longitude], 64)
+		if err != nil {
+			return fmt.Errorf("line %d: invalid longitude: %w", line, err)
+		}
+		location := Location{Latitude: lat, Longitude: lon}
+		if hasCountry {
+			location.Country = record[country]
+		}
+		prefix = prefix.Masked()
+		db.blocks = append(db.blocks, block{first: prefix.Addr(), last: lastAddr(prefix), location: location})
+	}
+}
+
+// Lookup returns the location of ip, or false for addresses the database has no network for, such as private
+// ones.
+func (db *Database) Lookup(ip string) (Location, bool) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return Location{}, false
+	}
+	addr = addr.Unmap()
+	// The last block starting at or before addr is the only one that can contain it
+	i := sort.Search(len(db.blocks), func(i int) bool {
+		return addr.Less(db.blocks[i].first)
+	}) - 1
+	if i < 0 || db.blocks[i].last.Less(addr) || db.blocks[i].first.BitLen() != addr.BitLen() {
+		return Location{}, false
+	}
+	return db.blocks[i].location, true
+}
+
+// lastAddr returns the last address of a masked prefix.
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	bytes := prefix.Addr().AsSlice()
+	for bit := prefix.Bits(); bit < len(bytes)*8; bit++ {
+		bytes[bit/8] |= 1 << (7 - bit%8)
+	}
+	addr, _ := netip.AddrFromSlice(bytes)
+	return addr
+}
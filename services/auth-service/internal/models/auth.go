@@ -1,21 +1,45 @@
 package models
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"gorm.io/gorm"
 	"time"
 )
 
 // User defines a user model with related fields and relationships.
 type User struct {
-	gorm.Model                             // Embedding gorm.Model already gives you an auto-incrementing ID, created_at, updated_at, deleted_at.
-	Username            string             `gorm:"uniqueIndex;size:255" json:"username"` // Unique username
-	Email               string             `gorm:"uniqueIndex;size:255" json:"email"`    // Unique email
-	Password            string             `json:"password"`
-	Role                string             `json:"role"` // User role
-	Verified            bool               `json:"verified"`
-	AccountCreationDate time.Time          `json:"accountCreationDate"`
-	UserVerifications   []UserVerification `gorm:"constraint:OnDelete:CASCADE;" json:"userVerifications"` // One-to-Many relationship with cascade delete
-	LoginHistories      []LoginHistory     `gorm:"constraint:OnDelete:CASCADE;" json:"loginHistories"`    // One-to-Many relationship with cascade delete
+	gorm.Model                               // Embedding gorm.Model already gives you an auto-incrementing ID, created_at, updated_at, deleted_at.
+	Username              string             `gorm:"uniqueIndex;size:255" json:"username"` // Unique username
+	Email                 string             `gorm:"uniqueIndex;size:255" json:"email"`    // Unique email
+	Password              string             `json:"password"`
+	Role                  string             `json:"role"` // User role
+	Verified              bool               `json:"verified"`
+	AccountCreationDate   time.Time          `json:"accountCreationDate"`
+	FailedLoginAttempts   int                `gorm:"not null;default:0" json:"failedLoginAttempts"` // Consecutive failed logins since the last success or lockout
+	LastFailedLoginAt     *time.Time         `json:"lastFailedLoginAt"`
+	LockoutCount          int                `gorm:"not null;default:0" json:"lockoutCount"` // Lockouts since the last successful login, doubles the next lockout
+	LockedUntil           *time.Time         `json:"lockedUntil"`                            // Logins are refused until then
+	DisabledAt            *time.Time         `json:"disabledAt"`                             // Logins are refused while set
+	DisabledReason        string             `gorm:"size:255" json:"disabledReason"`
+	PasswordResetRequired bool               `gorm:"not null;default:false" json:"passwordResetRequired"`                                         // Logins are refused until the password is reset
+	PhoneNumber           string             `gorm:"size:20;uniqueIndex:idx_users_verified_phone_number,where:phone_verified" json:"phoneNumber"` // E.164 number, set once verified and unique among verified numbers
+	PhoneVerified         bool               `gorm:"not null;default:false" json:"phoneVerified"`
+	PhoneVerifiedAt       *time.Time         `json:"phoneVerifiedAt"`
+	UserVerifications     []UserVerification `gorm:"constraint:OnDelete:CASCADE;" json:"userVerifications"` // One-to-Many relationship with cascade delete
+	LoginHistories        []LoginHistory     `gorm:"constraint:OnDelete:CASCADE;" json:"loginHistories"`    // One-to-Many relationship with cascade delete
+}
+
+// IsLocked reports whether logins to the account are refused at the given time.
+func (u User) IsLocked(at time.Time) bool {
+	return u.LockedUntil != nil && at.Before(*u.LockedUntil)
+}
+
+// IsDisabled reports whether the account was disabled by an admin.
+func (u User) IsDisabled() bool {
+	return u.DisabledAt != nil
 }
 
 // TableName overrides the table name used by User to `users`.
@@ -26,28 +50,108 @@ func (User) TableName() string {
 // LoginHistory defines a login history model with related fields and a belongs-to relationship with User.
 type LoginHistory struct {
 	gorm.Model                  // Embedding gorm.Model gives you an auto-incrementing ID, created_at, updated_at, deleted_at.
-	UserID            uint      `json:"userId"` // Foreign key for User
-	LoginTime         time.Time `json:"loginTime"`
+	UserID            uint      `gorm:"index:idx_login_history_user_time" json:"userId"` // Foreign key for User
+	LoginTime         time.Time `gorm:"index:idx_login_history_user_time;index" json:"loginTime"`
 	LogoutTime        time.Time `json:"logoutTime"`
 	IPAddress         string    `json:"ipAddress"`
 	DeviceInformation string    `json:"deviceInformation"`
 	Successful        bool      `json:"successful"`
 	FailureReason     string    `json:"failureReason"`
-	User              User      `gorm:"foreignKey:UserID"` // Belongs to User
+	DeviceFingerprint string    `gorm:"size:64;index" json:"deviceFingerprint"` // Hash of the normalized user agent
+	Country           string    `gorm:"size:64" json:"country"`                 // Located from the IP address, if known
+	Latitude          *float64  `json:"latitude"`
+	Longitude         *float64  `json:"longitude"`
+	Anomalies         string    `gorm:"size:255" json:"anomalies"` // Space-separated anomalies of a successful login
+	User              User      `gorm:"foreignKey:UserID"`         // Belongs to User
 }
 
+// Failure reasons recorded on a LoginHistory by AuthenticateUser.
+const (
+	LoginFailureInvalidCredentials = "invalid_credentials"
+	LoginFailureAccountLocked      = "account_locked"
+	LoginFailureInvalidMFACode     = "invalid_mfa_code"
+	LoginFailureAccountDisabled    = "account_disabled"
+	LoginFailurePasswordReset      = "password_reset_required"
+	LoginFailureInvalidLoginCode   = "invalid_login_code"
+)
+
+// Anomalies detected on a successful login and recorded on its LoginHistory.
+const (
+	LoginAnomalyNewDevice        = "new_device"        // First login from the device
+	LoginAnomalyImpossibleTravel = "impossible_travel" // Too far from the previous login to have travelled
+	LoginAnomalyUnusualHour      = "unusual_hour"      // At an hour the user does not sign in at
+)
+
 // TableName overrides the table name used by LoginHistory to `login_histories`.
 func (LoginHistory) TableName() string {
 	return "login_histories"
 }
 
+// LoginHistoryArchive holds login attempts the retention job moved out of login_histories. They are kept for
+// investigations and audits, and exported to users with the rest of their data, but no longer listed to users
+// or used to detect unusual logins.
+type LoginHistoryArchive struct {
+	ID                uint      `gorm:"primaryKey" json:"id"` // ID the attempt had in login_histories
+	UserID            uint      `gorm:"not null;index" json:"userId"`
+	LoginTime         time.Time `gorm:"not null;index" json:"loginTime"`
+	LogoutTime        time.Time `json:"logoutTime"`
+	IPAddress         string    `json:"ipAddress"`
+	DeviceInformation string    `json:"deviceInformation"`
+	Successful        bool      `json:"successful"`
+	FailureReason     string    `json:"failureReason"`
+	DeviceFingerprint string    `gorm:"size:64" json:"deviceFingerprint"`
+	Country           string    `gorm:"size:64" json:"country"`
+	Latitude          *float64  `json:"latitude"`
+	Longitude         *float64  `json:"longitude"`
+	Anomalies         string    `gorm:"size:255" json:"anomalies"`
+	ArchivedAt        time.Time `gorm:"not null" json:"archivedAt"`
+}
+
+// TableName overrides the table name used by LoginHistoryArchive to `login_history_archives`.
+func (LoginHistoryArchive) TableName() string {
+	return "login_history_archives"
+}
+
+// Archive copies the login attempt to be archived at the given time.
+func (h LoginHistory) Archive(at time.Time) LoginHistoryArchive {
+	return LoginHistoryArchive{
+		ID:                h.ID,
+		UserID:            h.UserID,
+		LoginTime:         h.LoginTime,
+		LogoutTime:        h.LogoutTime,
+		IPAddress:         h.IPAddress,
+		DeviceInformation: h.DeviceInformation,
+		Successful:        h.Successful,
+		FailureReason:     h.FailureReason,
+		DeviceFingerprint: h.DeviceFingerprint,
+		Country:           h.Country,
+		Latitude:          h.Latitude,
+		Longitude:         h.Longitude,
+		Anomalies:         h.Anomalies,
+		ArchivedAt:        at,
+	}
+}
+
+// Verification types and statuses of a UserVerification.
+const (
+	VerificationTypeEmail         = "email"
+	VerificationTypePasswordReset = "password_reset"
+	VerificationTypePhone         = "phone" // One-time code sent by SMS
+	VerificationPending           = "pending"
+	VerificationVerified          = "verified"
+	VerificationFailed            = "failed"
+	VerificationSuperseded        = "superseded" // A newer verification was sent
+)
+
 // UserVerification defines a user verification model with related fields and a belongs-to relationship with User.
 type UserVerification struct {
 	gorm.Model                   // Embedding gorm.Model gives you an auto-incrementing ID, created_at, updated_at, deleted_at.
 	UserID             uint      `json:"userId"` // Foreign key for User
 	VerificationType   string    `json:"verificationType"`
 	VerificationStatus string    `json:"verificationStatus"`
-	VerificationToken  string    `json:"verificationToken"`
+	VerificationToken  string    `gorm:"size:64;index" json:"-"`             // SHA-256 of the token or code sent to the user
+	Target             string    `gorm:"size:20" json:"-"`                   // Phone number a code was sent to
+	Attempts           int       `gorm:"not null;default:0" json:"attempts"` // Codes entered so far, counted before they are compared
 	ExpirationDate     time.Time `json:"expirationDate"`
 	VerifiedAt         time.Time `json:"verifiedAt"`
 	User               User      `gorm:"foreignKey:UserID"` // Belongs to User
@@ -57,3 +161,448 @@ type UserVerification struct {
 func (UserVerification) TableName() string {
 	return "user_verifications"
 }
+
+// Session groups the refresh tokens issued from a single login. Revoking it signs the user out of that device.
+type Session struct {
+	gorm.Model
+	UserID            uint           `gorm:"not null;index" json:"userId"`          // Foreign key for User
+	LoginHistoryID    *uint          `gorm:"index" json:"loginHistoryId"`           // Login that started the session, if recorded
+	ClientID          string         `gorm:"size:64;index" json:"clientId"`         // OAuth client the session was granted to, empty for first-party logins
+	Scope             string         `gorm:"size:255" json:"scope"`                 // Scopes granted to the OAuth client
+	IPAddress         string         `gorm:"size:45" json:"ipAddress"`              // Address the session was last used from
+	DeviceInformation string         `json:"deviceInformation"`                     // User agent of the login
+	LastSeenAt        time.Time      `json:"lastSeenAt"`                            // Last refresh or token check, to the minute
+	RevokedAt         *time.Time     `json:"revokedAt"`                             // Set on logout or when refresh token reuse is detected
+	RevokedReason     string         `gorm:"size:100" json:"revokedReason"`         // Why the session was revoked
+	RefreshTokens     []RefreshToken `gorm:"constraint:OnDelete:CASCADE;" json:"-"` // One-to-Many relationship with cascade delete
+}
+
+// TableName overrides the table name used by Session to `sessions`.
+func (Session) TableName() string {
+	return "sessions"
+}
+
+// RefreshToken is a single-use token that is exchanged for a new access token and its successor refresh token.
+// Only the SHA-256 hash of the token is stored.
+type RefreshToken struct {
+	gorm.Model
+	SessionID uint       `gorm:"not null;index" json:"sessionId"` // Foreign key for Session
+	TokenHash string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expiresAt"`
+	UsedAt    *time.Time `json:"usedAt"`                        // Set when the token is rotated; presenting it again is treated as theft
+	Session   Session    `gorm:"foreignKey:SessionID" json:"-"` // Belongs to Session
+}
+
+// TableName overrides the table name used by RefreshToken to `refresh_tokens`.
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// SigningKey is an asymmetric key pair used to sign access tokens. Keys are rotated on a schedule; a retired key
+// stays published in the JWKS until the tokens it signed have expired.
+type SigningKey struct {
+	gorm.Model
+	KID        string     `gorm:"size:64;not null;uniqueIndex" json:"kid"` // RFC 7638 thumbprint of the public key
+	Algorithm  string     `gorm:"size:16;not null" json:"alg"`             // RS256 or EdDSA
+	PrivateKey string     `gorm:"type:text;not null" json:"-"`             // PKCS #8, PEM encoded
+	PublicKey  string     `gorm:"type:text;not null" json:"-"`             // PKIX, PEM encoded
+	RetiredAt  *time.Time `gorm:"index" json:"retiredAt"`                  // Set when a newer key takes over signing
+	ExpiresAt  *time.Time `json:"expiresAt"`                               // When a retired key is dropped from the JWKS
+}
+
+// TableName overrides the table name used by SigningKey to `signing_keys`.
+func (SigningKey) TableName() string {
+	return "signing_keys"
+}
+
+// System roles, which always exist. Admins have every permission.
+const (
+	RoleAdmin    = "admin"
+	RoleCustomer = "customer"
+)
+
+// Permissions that can be granted to roles, described in PermissionDescriptions.
+const (
+	PermissionUsersRead       = "users:read"
+	PermissionUsersUnlock     = "users:unlock"
+	PermissionFleetManage     = "fleet:manage"
+	PermissionFleetMaintain   = "fleet:maintain"
+	PermissionRoutesManage    = "routes:manage"
+	PermissionSchedulesManage = "schedules:manage"
+	PermissionManifestRead    = "manifest:read"
+	PermissionSeatsUpdate     = "seats:update"
+	PermissionBookingsRead    = "bookings:read"
+	PermissionInvoicesManage  = "invoices:manage"
+	PermissionReviewsModerate = "reviews:moderate"
+	PermissionAuditRead       = "audit:read"
+)
+
+// PermissionDescriptions describes every permission that can be granted, keyed by permission.
+var PermissionDescriptions = map[string]string{
+	PermissionUsersRead:       "View accounts, their verifications and login history",
+	PermissionUsersUnlock:     "Unlock accounts locked after failed logins",
+	PermissionFleetManage:     "Add, change and remove buses and seats",
+	PermissionFleetMaintain:   "Take buses out of and back into service",
+	PermissionRoutesManage:    "Add, change and remove routes and stops",
+	PermissionSchedulesManage: "Add, change and remove schedules",
+	PermissionManifestRead:    "Read passenger manifests of trips",
+	PermissionSeatsUpdate:     "Change the status of seats, such as at boarding",
+	PermissionBookingsRead:    "View the bookings of any user",
+	PermissionInvoicesManage:  "Issue credit notes",
+	PermissionReviewsModerate: "Approve and reject reviews",
+	PermissionAuditRead:       "Search, export and verify the security audit log",
+}
+
+// Role groups the permissions granted to the users having it. System roles cannot be deleted.
+type Role struct {
+	Name        string           `gorm:"primaryKey;size:50" json:"name"`
+	Description string           `gorm:"size:255" json:"description"`
+	System      bool             `gorm:"not null;default:false" json:"system"`
+	Permissions []RolePermission `gorm:"foreignKey:RoleName;references:Name;constraint:OnDelete:CASCADE;" json:"permissions"`
+	CreatedAt   time.Time        `json:"createdAt"`
+	UpdatedAt   time.Time        `json:"updatedAt"`
+}
+
+// TableName overrides the table name used by Role to `roles`.
+func (Role) TableName() string {
+	return "roles"
+}
+
+// RolePermission grants a permission to a role.
+type RolePermission struct {
+	RoleName   string `gorm:"primaryKey;size:50" json:"role"`
+	Permission string `gorm:"primaryKey;size:100" json:"permission"`
+}
+
+// TableName overrides the table name used by RolePermission to `role_permissions`.
+func (RolePermission) TableName() string {
+	return "role_permissions"
+}
+
+// UserRole gives a user a role in addition to the role of their account.
+type UserRole struct {
+	UserID    uint      `gorm:"primaryKey" json:"userId"`
+	RoleName  string    `gorm:"primaryKey;size:50;index" json:"role"`
+	GrantedBy uint      `json:"grantedBy"` // Admin who gave the role
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// TableName overrides the table name used by UserRole to `user_roles`.
+func (UserRole) TableName() string {
+	return "user_roles"
+}
+
+// MFAEnrollment holds the TOTP secret of a user. Codes are only asked for once the enrollment is confirmed
+// with a first valid code.
+type MFAEnrollment struct {
+	gorm.Model
+	UserID       uint       `gorm:"not null;uniqueIndex" json:"userId"` // Foreign key for User
+	Secret       string     `gorm:"size:64;not null" json:"-"`          // Base32 encoded TOTP secret
+	ConfirmedAt  *time.Time `json:"confirmedAt"`                        // Set when the first code is verified
+	LastUsedStep int64      `gorm:"not null;default:0" json:"-"`        // Time step of the last accepted code, which cannot be used again
+}
+
+// TableName overrides the table name used by MFAEnrollment to `mfa_enrollments`.
+func (MFAEnrollment) TableName() string {
+	return "mfa_enrollments"
+}
+
+// RecoveryCode is a single-use code that replaces a TOTP code when the authenticator is lost.
+// Only the SHA-256 hash of the code is stored.
+type RecoveryCode struct {
+	gorm.Model
+	UserID   uint       `gorm:"not null;index" json:"userId"` // Foreign key for User
+	CodeHash string     `gorm:"size:64;not null;index" json:"-"`
+	UsedAt   *time.Time `json:"usedAt"`
+}
+
+// TableName overrides the table name used by RecoveryCode to `recovery_codes`.
+func (RecoveryCode) TableName() string {
+	return "recovery_codes"
+}
+
+// MFAChallenge is the second step of a login to an account with MFA. The password was verified and the
+// challenge token is exchanged for the session tokens together with a valid code.
+type MFAChallenge struct {
+	gorm.Model
+	UserID            uint       `gorm:"not null;index" json:"userId"` // Foreign key for User
+	TokenHash         string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	IPAddress         string     `json:"ipAddress"`
+	DeviceInformation string     `json:"deviceInformation"`
+	Attempts          int        `gorm:"not null;default:0" json:"attempts"` // Codes entered so far, counted before they are compared
+	ExpiresAt         time.Time  `gorm:"not null" json:"expiresAt"`
+	CompletedAt       *time.Time `json:"completedAt"`
+}
+
+// TableName overrides the table name used by MFAChallenge to `mfa_challenges`.
+func (MFAChallenge) TableName() string {
+	return "mfa_challenges"
+}
+
+// MFAPolicy records whether users of a role must use MFA to log in.
+type MFAPolicy struct {
+	Role      string    `gorm:"primaryKey;size:50" json:"role"`
+	Required  bool      `gorm:"not null" json:"required"`
+	UpdatedBy uint      `json:"updatedBy"` // Admin who last changed the policy
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// TableName overrides the table name used by MFAPolicy to `mfa_policies`.
+func (MFAPolicy) TableName() string {
+	return "mfa_policies"
+}
+
+// Channels passwordless login codes are sent through.
+const (
+	LoginCodeChannelEmail = "email"
+	LoginCodeChannelSMS   = "sms"
+)
+
+// LoginCode is a passwordless login in progress. The user gets a link and a 6-digit code; the link alone, or
+// the code together with the login token returned to the device that asked for it, is exchanged for the
+// session tokens once.
+type LoginCode struct {
+	gorm.Model
+	UserID        uint       `gorm:"not null;index" json:"userId"` // Foreign key for User
+	Channel       string     `gorm:"size:20;not null" json:"channel"`
+	TokenHash     string     `gorm:"size:64;not null;uniqueIndex" json:"-"` // Login token returned to the device
+	LinkTokenHash string     `gorm:"size:64;not null;uniqueIndex" json:"-"` // Token of the link sent to the user
+	CodeHash      string     `gorm:"size:64;not null" json:"-"`
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"` // Codes entered so far, counted before they are compared
+	ExpiresAt     time.Time  `gorm:"not null" json:"expiresAt"`
+	UsedAt        *time.Time `json:"usedAt"`
+}
+
+// TableName overrides the table name used by LoginCode to `login_codes`.
+func (LoginCode) TableName() string {
+	return "login_codes"
+}
+
+// OAuthClient is a partner application that authenticates users through the OpenID Connect provider.
+// Public clients, such as mobile apps, have no secret and rely on PKCE alone. Service clients are other
+// services of the platform, which get access tokens for themselves with the client credentials grant.
+type OAuthClient struct {
+	gorm.Model
+	ClientID     string `gorm:"size:64;not null;uniqueIndex" json:"clientId"`
+	SecretHash   string `gorm:"size:64" json:"-"` // SHA-256 of the client secret, empty for public clients
+	Name         string `gorm:"size:255;not null" json:"name"`
+	RedirectURIs string `gorm:"type:text;not null" json:"redirectUris"` // Space separated, matched exactly
+	Scopes       string `gorm:"size:255;not null" json:"scopes"`        // Space separated scopes the client may request
+	Public       bool   `gorm:"not null" json:"public"`
+	Service      bool   `gorm:"not null;default:false" json:"service"` // Confidential client using the client credentials grant
+	CreatedBy    uint   `json:"createdBy"`                             // Admin who registered the client
+}
+
+// TableName overrides the table name used by OAuthClient to `oauth_clients`.
+func (OAuthClient) TableName() string {
+	return "oauth_clients"
+}
+
+// OAuthConsent records the scopes a user allowed an OAuth client to access.
+type OAuthConsent struct {
+	gorm.Model
+	UserID   uint   `gorm:"not null;uniqueIndex:idx_oauth_consent_user_client" json:"userId"`
+	ClientID string `gorm:"size:64;not null;uniqueIndex:idx_oauth_consent_user_client" json:"clientId"`
+	Scopes   string `gorm:"size:255;not null" json:"scopes"` // Space separated
+}
+
+// TableName overrides the table name used by OAuthConsent to `oauth_consents`.
+func (OAuthConsent) TableName() string {
+	return "oauth_consents"
+}
+
+// AuthorizationCode is a single-use code a client exchanges for tokens, bound to the PKCE challenge of the
+// authorization request. Only the SHA-256 hash of the code is stored.
+type AuthorizationCode struct {
+	gorm.Model
+	CodeHash      string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	ClientID      string     `gorm:"size:64;not null" json:"clientId"`
+	UserID        uint       `gorm:"not null;index" json:"userId"`
+	RedirectURI   string     `gorm:"type:text;not null" json:"redirectUri"`
+	Scope         string     `gorm:"size:255;not null" json:"scope"`
+	Nonce         string     `gorm:"size:255" json:"nonce"`
+	CodeChallenge string     `gorm:"size:128;not null" json:"-"` // S256 PKCE challenge
+	AuthTime      time.Time  `gorm:"not null" json:"authTime"`   // When the user authenticated
+	ExpiresAt     time.Time  `gorm:"not null" json:"expiresAt"`
+	UsedAt        *time.Time `json:"usedAt"`
+}
+
+// TableName overrides the table name used by AuthorizationCode to `authorization_codes`.
+func (AuthorizationCode) TableName() string {
+	return "authorization_codes"
+}
+
+// Scopes an APIKey can be limited to.
+const (
+	APIKeyScopeSearch       = "search"        // Search routes, buses and seat availability
+	APIKeyScopeBook         = "book"          // Create and manage bookings
+	APIKeyScopeReadManifest = "manifest:read" // Read passenger manifests of trips
+)
+
+// APIKey authenticates the server-side integration of a partner as the account it was issued to, limited to its
+// scopes. Only the SHA-256 hash of the key is stored; the prefix identifies the key in listings.
+type APIKey struct {
+	gorm.Model
+	UserID     uint       `gorm:"not null;index" json:"userId"` // Account the key acts as
+	Name       string     `gorm:"size:255;not null" json:"name"`
+	Prefix     string     `gorm:"size:16;not null" json:"prefix"`
+	KeyHash    string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	Scopes     string     `gorm:"size:255;not null" json:"scopes"` // Space separated
+	CreatedBy  uint       `json:"createdBy"`                       // User who issued the key, the owner or an admin
+	LastUsedAt *time.Time `json:"lastUsedAt"`                      // Updated at most once a minute
+	ExpiresAt  *time.Time `json:"expiresAt"`                       // Keys without an expiry are valid until revoked
+	RevokedAt  *time.Time `json:"revokedAt"`
+}
+
+// IsActive reports whether the key authenticates requests at the given time.
+func (k APIKey) IsActive(at time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || at.Before(*k.ExpiresAt))
+}
+
+// TableName overrides the table name used by APIKey to `api_keys`.
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// PasswordHistory is a password a user has set, kept so that recent passwords are not reused. Only the hash of
+// the password is stored.
+type PasswordHistory struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	UserID       uint      `gorm:"not null;index" json:"userId"` // Foreign key for User
+	PasswordHash string    `gorm:"not null" json:"-"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// TableName overrides the table name used by PasswordHistory to `password_histories`.
+func (PasswordHistory) TableName() string {
+	return "password_histories"
+}
+
+// Statuses of an AccountErasure.
+const (
+	ErasureStatusScheduled  = "scheduled"   // Waiting for the grace period to end; the account can still be restored
+	ErasureStatusInProgress = "in_progress" // Some services have not erased the data of the user yet
+	ErasureStatusCompleted  = "completed"   // Every service erased the data of the user
+)
+
+// Statuses of an AccountErasureStep.
+const (
+	ErasureStepPending   = "pending"
+	ErasureStepCompleted = "completed"
+)
+
+// AccountErasure tracks the erasure of the data of a deleted account across services. Once the grace period
+// ends, every service purges or anonymises the data it holds about the user; the account cannot be restored
+// from then on.
+type AccountErasure struct {
+	gorm.Model
+	UserID      uint                 `gorm:"not null;uniqueIndex" json:"userId"`
+	RequestedBy uint                 `json:"requestedBy"` // User or admin who deleted the account
+	Status      string               `gorm:"size:20;not null;index" json:"status"`
+	ScheduledAt time.Time            `gorm:"not null;index" json:"scheduledAt"` // When the erasure starts
+	StartedAt   *time.Time           `json:"startedAt"`
+	CompletedAt *time.Time           `json:"completedAt"`
+	Steps       []AccountErasureStep `gorm:"foreignKey:ErasureID;constraint:OnDelete:CASCADE;" json:"steps"`
+}
+
+// TableName overrides the table name used by AccountErasure to `account_erasures`.
+func (AccountErasure) TableName() string {
+	return "account_erasures"
+}
+
+// AccountErasureStep is the erasure of the data one service holds about the user, retried until it succeeds.
+type AccountErasureStep struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	ErasureID   uint       `gorm:"not null;uniqueIndex:idx_erasure_step_service" json:"erasureId"`
+	Service     string     `gorm:"size:50;not null;uniqueIndex:idx_erasure_step_service" json:"service"`
+	Status      string     `gorm:"size:20;not null" json:"status"`
+	Attempts    int        `gorm:"not null;default:0" json:"attempts"`
+	LastError   string     `gorm:"type:text" json:"lastError"` // Error of the last failed attempt
+	CompletedAt *time.Time `json:"completedAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+// TableName overrides the table name used by AccountErasureStep to `account_erasure_steps`.
+func (AccountErasureStep) TableName() string {
+	return "account_erasure_steps"
+}
+
+// Statuses of a personal data export.
+const (
+	DataExportStatusPending = "pending" // Waiting for the export job to gather the data and send the download link
+	DataExportStatusReady   = "ready"   // The archive can be downloaded until it expires
+	DataExportStatusFailed  = "failed"  // The data could not be gathered, or the link sent, after several attempts
+	DataExportStatusExpired = "expired" // The archive was removed once its download link expired
+)
+
+// DataExport is a request of a user for a copy of their personal data. The export job gathers it from every
+// service into a ZIP archive of JSON files and emails the user a link to download it for a limited time.
+type DataExport struct {
+	gorm.Model
+	UserID            uint       `gorm:"not null;index" json:"userId"`
+	Status            string     `gorm:"size:20;not null;index" json:"status"`
+	Attempts          int        `gorm:"not null;default:0" json:"attempts"`
+	LastError         string     `gorm:"type:text" json:"lastError"`     // Error of the last failed attempt
+	Archive           []byte     `json:"-"`                              // ZIP archive, removed once expired
+	Size              int64      `gorm:"not null;default:0" json:"size"` // Size of the archive in bytes
+	DownloadTokenHash string     `gorm:"size:64;index" json:"-"`         // SHA-256 of the token in the download link
+	CompletedAt       *time.Time `json:"completedAt"`
+	ExpiresAt         *time.Time `gorm:"index" json:"expiresAt"` // The download link works until then
+	DownloadedAt      *time.Time `json:"downloadedAt"`           // Last download of the archive
+}
+
+// TableName overrides the table name used by DataExport to `data_exports`.
+func (DataExport) TableName() string {
+	return "data_exports"
+}
+
+// ErrAuditEventImmutable is returned when trying to change or delete an audit event.
+var ErrAuditEventImmutable = errors.New("audit events cannot be changed or deleted")
+
+// AuditChange is the value of a field before and after an audited action.
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditEvent is an entry of the append-only security audit log: who did what to which account or resource,
+// from where, and what changed. Each event holds the hash of the one before it, so changing or removing an
+// event breaks the chain from there on. Once written it is never updated or deleted.
+type AuditEvent struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt  time.Time `gorm:"not null;index" json:"createdAt"`
+	ActorID    uint      `gorm:"not null;index" json:"actorId"` // 0 when the actor is not signed in, such as on password resets
+	Action     string    `gorm:"size:100;not null;index" json:"action"`
+	TargetType string    `gorm:"size:50;not null;index:idx_audit_target" json:"targetType"`
+	TargetID   string    `gorm:"size:100;not null;index:idx_audit_target" json:"targetId"`
+	IPAddress  string    `gorm:"size:45" json:"ipAddress"`
+	UserAgent  string    `gorm:"type:text" json:"userAgent"`
+	Changes    string    `gorm:"type:text" json:"changes"` // JSON object of the changed fields and their AuditChange
+	PrevHash   string    `gorm:"size:64;not null" json:"prevHash"`
+	Hash       string    `gorm:"size:64;not null;uniqueIndex" json:"hash"`
+}
+
+// TableName overrides the table name used by AuditEvent to `audit_events`.
+func (AuditEvent) TableName() string {
+	return "audit_events"
+}
+
+// ComputeHash returns the SHA-256 of the event and the hash of the event before it, hex-encoded.
+func (e AuditEvent) ComputeHash() string {
+	canonical, _ := json.Marshal([]interface{}{
+		e.PrevHash, e.CreatedAt.UTC().Format(time.RFC3339Nano), e.ActorID, e.Action,
+		e.TargetType, e.TargetID, e.IPAddress, e.UserAgent, e.Changes,
+	})
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:])
+}
+
+// BeforeUpdate rejects any update of an audit event.
+func (e *AuditEvent) BeforeUpdate(tx *gorm.DB) error {
+	return ErrAuditEventImmutable
+}
+
+// BeforeDelete rejects deleting an audit event.
+func (e *AuditEvent) BeforeDelete(tx *gorm.DB) error {
+	return ErrAuditEventImmutable
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAuditEventComputeHash(t *testing.T) {
+	event := AuditEvent{
+		CreatedAt:  time.Date(2026, 10, 19, 8, 0, 0, 123456000, time.UTC),
+		ActorID:    1,
+		Action:     "user.role_changed",
+		TargetType: "user",
+		TargetID:   "7",
+		IPAddress:  "203.0.113.5",
+		UserAgent:  "curl/8.0",
+		Changes:    `{"role":{"old":"customer","new":"admin"}}`,
+		PrevHash:   "abc",
+	}
+	hash := event.ComputeHash()
+
+	tests := []struct {
+		name     string
+		change   func(e *AuditEvent)
+		wantSame bool
+	}{
+		{name: "same event", change: func(e *AuditEvent) {}, wantSame: true},
+		{name: "other time zone", change: func(e *AuditEvent) { e.CreatedAt = e.CreatedAt.In(time.FixedZone("CEST", 2*60*60)) }, wantSame: true},
+		{name: "stored ID and hash", change: func(e *AuditEvent) { e.ID, e.Hash = 3, "stored" }, wantSame: true},
+		{name: "created at", change: func(e *AuditEvent) { e.CreatedAt = e.CreatedAt.Add(time.Microsecond) }},
+		{name: "actor", change: func(e *AuditEvent) { e.ActorID = 2 }},
+		{name: "action", change: func(e *AuditEvent) { e.Action = "user.deleted" }},
+		{name: "target type", change: func(e *AuditEvent) { e.TargetType = "role" }},
+		{name: "target ID", change: func(e *AuditEvent) { e.TargetID = "8" }},
+		{name: "IP address", change: func(e *AuditEvent) { e.IPAddress = "198.51.100.1" }},
+		{name: "user agent", change: func(e *AuditEvent) { e.UserAgent = "curl/8.1" }},
+		{name: "changes", change: func(e *AuditEvent) { e.Changes = `{"role":{"old":"customer","new":"support"}}` }},
+		{name: "previous hash", change: func(e *AuditEvent) { e.PrevHash = "abd" }},
+		{name: "fields shifted between columns", change: func(e *AuditEvent) { e.TargetType, e.TargetID = "user7", "" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed := event
+			tt.change(&changed)
+			if got := changed.ComputeHash() == hash; got != tt.wantSame {
+				t.Errorf("ComputeHash() unchanged = %v, want %v", got, tt.wantSame)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Argon2id parameters of new hashes, the second recommended option of RFC 9106 with 64 MiB of memory.
+const (
+	argonMemory      = 64 * 1024 // KiB
+	argonIterations  = 3
+	argonParallelism = 4
+	argonSaltLength  = 16
+	argonKeyLength   = 32
+)
+
+// ErrUnsupportedHash is returned for stored hashes that are neither Argon2id PHC strings nor bcrypt hashes.
+var ErrUnsupportedHash = errors.New("unsupported password hash format")
+
+// argonParams are the parameters an Argon2id hash was computed with.
+type argonParams struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+}
+
+var currentParams = argonParams{memory: argonMemory, iterations: argonIterations, parallelism: argonParallelism}
+
+// Hash returns the Argon2id hash of password as a PHC string such as
+// $argon2id$v=19$m=65536,t=3,p=4$<salt>$<hash>, with a random salt.
+func Hash(password string) (string, error) {
+	salt := make([]byte, argonSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+	key := argon2.IDKey([]byte(password), salt, currentParams.iterations, currentParams.memory, currentParams.parallelism, argonKeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version,
+		currentParams.memory, currentParams.iterations, currentParams.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify reports whether password matches the stored hash, which is an Argon2id PHC string or a bcrypt hash
+// from before Argon2id was used. needsRehash is set for matching passwords whose hash should be replaced by
+// Hash: bcrypt hashes and Argon2id hashes computed with other parameters.
+func Verify(password, hash string) (match, needsRehash bool, err error) {
+	switch {
+	case strings.HasPrefix(hash, "$argon2id$"):
+		return verifyArgon2id(password, hash)
+	case strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, false, nil
+		}
+		if err != nil {
+			return false, false, err
+		}
+		return true, true, nil
+	default:
+		return false, false, ErrUnsupportedHash
+	}
+}
+
+func verifyArgon2id(password, hash string) (bool, bool, error) {
+	// "", "argon2id", "v=19", "m=65536,t=3,p=4", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return false, false, ErrUnsupportedHash
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, false, ErrUnsupportedHash
+	}
+	var params argonParams
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.iterations, &params.parallelism); err != nil {
+		return false, false, ErrUnsupportedHash
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, false, ErrUnsupportedHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return false, false, ErrUnsupportedHash
+	}
+
+	computed := argon2.IDKey([]byte(password), salt, params.iterations, params.memory, params.parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(computed, key) != 1 {
+		return false, false, nil
+	}
+	return true, params != currentParams || len(key) != argonKeyLength, nil
+}
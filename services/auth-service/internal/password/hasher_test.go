@@ -0,0 +1,74 @@
+package password
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestHashVerify(t *testing.T) {
+	hash, err := Hash("correct horse battery")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=65536,t=3,p=4$") {
+		t.Fatalf("Hash() = %q, want an Argon2id PHC string with the current parameters", hash)
+	}
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("correct horse battery"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("GenerateFromPassword() error = %v", err)
+	}
+	// Same password hashed with fewer iterations, as by an older version
+	oldParams := currentParams
+	currentParams.iterations = 1
+	oldHash, err := Hash("correct horse battery")
+	currentParams = oldParams
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+
+	tests := []struct {
+		name            string
+		password        string
+		hash            string
+		wantMatch       bool
+		wantNeedsRehash bool
+		wantErr         error
+	}{
+		{name: "argon2id match", password: "correct horse battery", hash: hash, wantMatch: true},
+		{name: "argon2id mismatch", password: "wrong horse battery", hash: hash},
+		{name: "argon2id with other parameters", password: "correct horse battery", hash: oldHash, wantMatch: true, wantNeedsRehash: true},
+		{name: "bcrypt match", password: "correct horse battery", hash: string(bcryptHash), wantMatch: true, wantNeedsRehash: true},
+		{name: "bcrypt mismatch", password: "wrong horse battery", hash: string(bcryptHash)},
+		{name: "unknown format", password: "correct horse battery", hash: "plain", wantErr: ErrUnsupportedHash},
+		{name: "truncated argon2id", password: "correct horse battery", hash: "$argon2id$v=19$m=65536,t=3,p=4$c2FsdA", wantErr: ErrUnsupportedHash},
+		{name: "other argon2 version", password: "correct horse battery", hash: strings.Replace(hash, "v=19", "v=16", 1), wantErr: ErrUnsupportedHash},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, needsRehash, err := Verify(tt.password, tt.hash)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if match != tt.wantMatch || needsRehash != tt.wantNeedsRehash {
+				t.Errorf("Verify() = %v, %v, want %v, %v", match, needsRehash, tt.wantMatch, tt.wantNeedsRehash)
+			}
+		})
+	}
+}
+
+func TestHashUsesRandomSalt(t *testing.T) {
+	first, err := Hash("correct horse battery")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	second, err := Hash("correct horse battery")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	if first == second {
+		t.Error("Hash() returned the same hash twice")
+	}
+}
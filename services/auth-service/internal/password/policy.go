@@ -0,0 +1,66 @@
+package password
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// breachedList holds common passwords that appeared in public breaches, one per line, bundled so that
+// passwords can be checked without calling any external service.
+//
+//go:embed breached.txt
+var breachedList string
+
+var breached = func() map[string]struct{} {
+	passwords := make(map[string]struct{})
+	for _, line := range strings.Split(breachedList, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			passwords[strings.ToLower(line)] = struct{}{}
+		}
+	}
+	return passwords
+}()
+
+// Errors returned for passwords that do not meet the Policy.
+var (
+	ErrTooShort        = errors.New("password is too short")
+	ErrTooLong         = errors.New("password is too long")
+	ErrBreached        = errors.New("password is too common and has appeared in data breaches, choose another one")
+	ErrMatchesIdentity = errors.New("password must not be the username or email address")
+)
+
+// Policy decides which new passwords are accepted. Passwords already set are not checked against it.
+type Policy struct {
+	MinLength int // In characters
+	MaxLength int // In characters
+}
+
+// Validate checks a new password against the policy. identifiers are values the password must not equal, such
+// as the username and email address of the account.
+func (p Policy) Validate(password string, identifiers ...string) error {
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		return fmt.Errorf("%w, use at least %d characters", ErrTooShort, p.MinLength)
+	}
+	if length > p.MaxLength {
+		return fmt.Errorf("%w, use at most %d characters", ErrTooLong, p.MaxLength)
+	}
+	if IsBreached(password) {
+		return ErrBreached
+	}
+	for _, identifier := range identifiers {
+		if identifier != "" && strings.EqualFold(password, identifier) {
+			return ErrMatchesIdentity
+		}
+	}
+	return nil
+}
+
+// IsBreached reports whether password is in the bundled list of breached passwords, ignoring case.
+func IsBreached(password string) bool {
+	_, ok := breached[strings.ToLower(password)]
+	return ok
+}
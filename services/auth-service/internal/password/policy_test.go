@@ -0,0 +1,33 @@
+package password
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPolicyValidate(t *testing.T) {
+	policy := Policy{MinLength: 10, MaxLength: 20}
+	tests := []struct {
+		name        string
+		password    string
+		identifiers []string
+		wantErr     error
+	}{
+		{name: "accepted", password: "violet lantern river"},
+		{name: "too short", password: "short one", wantErr: ErrTooShort},
+		{name: "too long", password: strings.Repeat("a", 21), wantErr: ErrTooLong},
+		{name: "length counts characters not bytes", password: strings.Repeat("ü", 10)},
+		{name: "breached", password: "1234567890", wantErr: ErrBreached},
+		{name: "breached ignoring case", password: "QWERTYUIOP", wantErr: ErrBreached},
+		{name: "matches username", password: "Ann.Traveller", identifiers: []string{"ann.traveller", "ann@example.com"}, wantErr: ErrMatchesIdentity},
+		{name: "empty identifiers are ignored", password: "violet lantern river", identifiers: []string{""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := policy.Validate(tt.password, tt.identifiers...); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"auth-service/internal/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrAPIKeyRevoked is returned when an API key was already revoked, possibly by a concurrent request.
+var ErrAPIKeyRevoked = errors.New("api key has already been revoked")
+
+// IAPIKeyRepository defines the interface for API key operations.
+type IAPIKeyRepository interface {
+	Create(key *models.APIKey) error
+	FindByID(keyID uint) (*models.APIKey, error)
+	FindByHash(keyHash string) (*models.APIKey, error)
+	ListByUser(userID uint) ([]models.APIKey, error)
+	Revoke(keyID uint, at time.Time) error
+	Rotate(keyID uint, expiresAt time.Time, next *models.APIKey) error
+	TouchLastUsed(keyID uint, at, notSince time.Time) error
+}
+
+// APIKeyRepository is a GORM-based implementation of IAPIKeyRepository.
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository creates a new instance of APIKeyRepository.
+func NewAPIKeyRepository(db *gorm.DB) IAPIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// Create stores a new API key.
+func (r *APIKeyRepository) Create(key *models.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+// FindByID retrieves an API key by its ID.
+func (r *APIKeyRepository) FindByID(keyID uint) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.db.First(&key, keyID).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// FindByHash retrieves an API key by the hash of the key.
+func (r *APIKeyRepository) FindByHash(keyHash string) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.db.Where("key_hash = ?", keyHash).First(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// ListByUser retrieves the API keys issued to a user, newest first.
+func (r *APIKeyRepository) ListByUser(userID uint) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&keys).Error
+	return keys, err
+}
+
+// Revoke revokes an API key. Revoking a key twice returns ErrAPIKeyRevoked.
+func (r *APIKeyRepository) Revoke(keyID uint, at time.Time) error {
+	result := r.db.Model(&models.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", keyID).
+		Update("revoked_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAPIKeyRevoked
+	}
+	return nil
+}
+
+// Rotate stores the key replacing keyID and lets keyID expire at expiresAt, unless it expires earlier. Rotating a
+// revoked key returns ErrAPIKeyRevoked.
+func (r *APIKeyRepository) Rotate(keyID uint, expiresAt time.Time, next *models.APIKey) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.APIKey{}).
+			Where("id = ? AND revoked_at IS NULL", keyID).
+			Update("expires_at", gorm.Expr("LEAST(COALESCE(expires_at, ?), ?)", expiresAt, expiresAt))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrAPIKeyRevoked
+		}
+		return tx.Create(next).Error
+	})
+}
+
+// TouchLastUsed records that an API key was used at the given time, unless it was already recorded as used
+// since notSince. This keeps busy keys from updating their row on every request.
+func (r *APIKeyRepository) TouchLastUsed(keyID uint, at, notSince time.Time) error {
+	return r.db.Model(&models.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", keyID, notSince).
+		Update("last_used_at", at).Error
+}
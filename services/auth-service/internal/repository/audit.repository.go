@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"auth-service/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// auditChainLockKey is the transaction-level advisory lock serializing appends to the audit log, so each event
+// links to the one written just before it.
+const auditChainLockKey int64 = 83100
+
+// AuditEventFilter narrows the audit events listed. Zero values do not filter.
+type AuditEventFilter struct {
+	ActorID    *uint
+	Action     string
+	TargetType string
+	TargetID   string
+	From       time.Time
+	To         time.Time
+}
+
+// IAuditRepository defines the interface for the append-only audit log.
+type IAuditRepository interface {
+	Append(event *models.AuditEvent) error
+	List(filter AuditEventFilter, offset, limit int) ([]models.AuditEvent, int64, error)
+	FindInBatches(filter AuditEventFilter, batchSize int, fn func(events []models.AuditEvent) error) error
+}
+
+// AuditRepository is a GORM-based implementation of IAuditRepository.
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditRepository creates a new instance of AuditRepository.
+func NewAuditRepository(db *gorm.DB) IAuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Append timestamps an event, links it to the last one of the audit log, hashes it and inserts it.
+func (r *AuditRepository) Append(event *models.AuditEvent) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", auditChainLockKey).Error; err != nil {
+			return err
+		}
+		var last models.AuditEvent
+		result := tx.Select("hash").Order("id DESC").Limit(1).Find(&last)
+		if result.Error != nil {
+			return result.Error
+		}
+		// Timestamps are stored to the microsecond, hashed as they are read back
+		event.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+		event.PrevHash = last.Hash
+		event.Hash = event.ComputeHash()
+		return tx.Create(event).Error
+	})
+}
+
+// List retrieves a page of the events matching filter, newest first, with the total number of matches.
+func (r *AuditRepository) List(filter AuditEventFilter, offset, limit int) ([]models.AuditEvent, int64, error) {
+	query := r.filtered(filter)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var events []models.AuditEvent
+	err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&events).Error
+	return events, total, err
+}
+
+// FindInBatches calls fn with the events matching filter in the order they were written, batchSize at a time.
+func (r *AuditRepository) FindInBatches(filter AuditEventFilter, batchSize int, fn func(events []models.AuditEvent) error) error {
+	var events []models.AuditEvent
+	return r.filtered(filter).FindInBatches(&events, batchSize, func(tx *gorm.DB, batch int) error {
+		return fn(events)
+	}).Error
+}
+
+// filtered returns a query of the audit events matching filter.
+func (r *AuditRepository) filtered(filter AuditEventFilter) *gorm.DB {
+	query := r.db.Model(&models.AuditEvent{})
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+	return query
+}
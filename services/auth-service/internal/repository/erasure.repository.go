@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"auth-service/internal/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrErasureStarted is returned when an account erasure can no longer be cancelled.
+	ErrErasureStarted = errors.New("account erasure has already started")
+	// ErrErasureNotScheduled is returned when starting an account erasure that was cancelled or started since
+	// it was read.
+	ErrErasureNotScheduled = errors.New("account erasure is no longer scheduled")
+)
+
+// IAccountErasureRepository defines the interface for account erasure operations.
+type IAccountErasureRepository interface {
+	Create(erasure *models.AccountErasure) error
+	FindByUserID(userID uint) (*models.AccountErasure, error)
+	FindDue(at time.Time, limit int) ([]models.AccountErasure, error)
+	Cancel(userID uint) error
+	Start(erasureID uint, at time.Time) error
+	UpdateStep(step *models.AccountErasureStep) error
+	Complete(erasureID uint, at time.Time) error
+	EraseUserData(userID uint) error
+}
+
+// AccountErasureRepository is a GORM-based implementation of IAccountErasureRepository.
+type AccountErasureRepository struct {
+	db *gorm.DB
+}
+
+// NewAccountErasureRepository creates a new instance of AccountErasureRepository.
+func NewAccountErasureRepository(db *gorm.DB) IAccountErasureRepository {
+	return &AccountErasureRepository{db: db}
+}
+
+// Create inserts a new account erasure with its steps.
+func (r *AccountErasureRepository) Create(erasure *models.AccountErasure) error {
+	return r.db.Create(erasure).Error
+}
+
+// FindByUserID retrieves the erasure of a user's account with its steps.
+func (r *AccountErasureRepository) FindByUserID(userID uint) (*models.AccountErasure, error) {
+	var erasure models.AccountErasure
+	if err := r.db.Preload("Steps", orderSteps).Where("user_id = ?", userID).First(&erasure).Error; err != nil {
+		return nil, err
+	}
+	return &erasure, nil
+}
+
+// FindDue retrieves the erasures whose grace period ended at the given time and those still in progress,
+// oldest first, with their steps.
+func (r *AccountErasureRepository) FindDue(at time.Time, limit int) ([]models.AccountErasure, error) {
+	var erasures []models.AccountErasure
+	err := r.db.Preload("Steps", orderSteps).
+		Where("(status = ? AND scheduled_at <= ?) OR status = ?", models.ErasureStatusScheduled, at, models.ErasureStatusInProgress).
+		Order("scheduled_at").Limit(limit).
+		Find(&erasures).Error
+	return erasures, err
+}
+
+// Cancel removes the scheduled erasure of a user's account. Erasures that have started cannot be cancelled
+// and return ErrErasureStarted; gorm.ErrRecordNotFound is returned when there is none. The erasure is only
+// deleted while it is still scheduled, so of a concurrent Start and Cancel only one succeeds.
+func (r *AccountErasureRepository) Cancel(userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var erasure models.AccountErasure
+		if err := tx.Where("user_id = ?", userID).First(&erasure).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("erasure_id = ?", erasure.ID).Delete(&models.AccountErasureStep{}).Error; err != nil {
+			return err
+		}
+		// Deleted for good, so the account can be deleted again once restored
+		result := tx.Unscoped().
+			Where("id = ? AND status = ?", erasure.ID, models.ErasureStatusScheduled).
+			Delete(&models.AccountErasure{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			// Rolls the deletion of the steps back
+			return ErrErasureStarted
+		}
+		return nil
+	})
+}
+
+// Start marks an erasure as in progress. Erasures cancelled or started since they were read return
+// ErrErasureNotScheduled.
+func (r *AccountErasureRepository) Start(erasureID uint, at time.Time) error {
+	result := r.db.Model(&models.AccountErasure{}).
+		Where("id = ? AND status = ?", erasureID, models.ErasureStatusScheduled).
+		Updates(map[string]interface{}{"status": models.ErasureStatusInProgress, "started_at": at})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrErasureNotScheduled
+	}
+	return nil
+}
+
+// UpdateStep saves the outcome of an attempt of an erasure step.
+func (r *AccountErasureRepository) UpdateStep(step *models.AccountErasureStep) error {
+	return r.db.Save(step).Error
+}
+
+// Complete marks an erasure as completed.
+func (r *AccountErasureRepository) Complete(erasureID uint, at time.Time) error {
+	return r.db.Model(&models.AccountErasure{}).Where("id = ?", erasureID).
+		Updates(map[string]interface{}{"status": models.ErasureStatusCompleted, "completed_at": at}).Error
+}
+
+// EraseUserData purges what auth-service holds about a deleted user: credentials, verifications, sessions,
+// login history, MFA, API keys, OAuth grants and data exports. The user row is kept, soft deleted, with its
+// identifying fields replaced, so references to the user ID stay valid.
+func (r *AccountErasureRepository) EraseUserData(userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Model(&models.User{}).Where("id = ? AND deleted_at IS NOT NULL", userID).
+			Updates(map[string]interface{}{
+				"username":          fmt.Sprintf("erased-%d", userID),
+				"email":             fmt.Sprintf("erased-%d@erased.invalid", userID),
+				"password":          "",
+				"phone_number":      "",
+				"phone_verified":    false,
+				"phone_verified_at": nil,
+				"disabled_reason":   "",
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		sessions := tx.Unscoped().Model(&models.Session{}).Select("id").Where("user_id = ?", userID)
+		if err := tx.Unscoped().Where("session_id IN (?)", sessions).Delete(&models.RefreshToken{}).Error; err != nil {
+			return err
+		}
+		for _, model := range []interface{}{
+			&models.Session{}, &models.LoginHistory{}, &models.LoginHistoryArchive{}, &models.UserVerification{}, &models.LoginCode{},
+			&models.MFAEnrollment{}, &models.RecoveryCode{}, &models.MFAChallenge{}, &models.PasswordHistory{},
+			&models.APIKey{}, &models.OAuthConsent{}, &models.AuthorizationCode{}, &models.UserRole{},
+			&models.DataExport{},
+		} {
+			if err := tx.Unscoped().Where("user_id = ?", userID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// orderSteps preloads the steps of an erasure in the order they were created.
+func orderSteps(db *gorm.DB) *gorm.DB {
+	return db.Order("id")
+}
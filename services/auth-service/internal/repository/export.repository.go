@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"auth-service/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// IDataExportRepository defines the interface for personal data export operations.
+type IDataExportRepository interface {
+	Create(export *models.DataExport) error
+	ListByUserID(userID uint) ([]models.DataExport, error)
+	HasPending(userID uint) (bool, error)
+	FindPending(limit int) ([]models.DataExport, error)
+	RecordFailure(exportID uint, attempts int, lastError, status string) error
+	Complete(exportID uint, archive []byte, downloadTokenHash string, completedAt, expiresAt time.Time) error
+	FindByDownloadTokenHash(tokenHash string) (*models.DataExport, error)
+	MarkDownloaded(exportID uint, at time.Time) error
+	ExpireArchives(at time.Time) (int64, error)
+}
+
+// DataExportRepository is a GORM-based implementation of IDataExportRepository.
+type DataExportRepository struct {
+	db *gorm.DB
+}
+
+// NewDataExportRepository creates a new instance of DataExportRepository.
+func NewDataExportRepository(db *gorm.DB) IDataExportRepository {
+	return &DataExportRepository{db: db}
+}
+
+// Create inserts a new data export.
+func (r *DataExportRepository) Create(export *models.DataExport) error {
+	return r.db.Create(export).Error
+}
+
+// ListByUserID retrieves the data exports of a user, newest first, without their archives.
+func (r *DataExportRepository) ListByUserID(userID uint) ([]models.DataExport, error) {
+	var exports []models.DataExport
+	err := r.db.Omit("archive").Where("user_id = ?", userID).Order("created_at DESC").Find(&exports).Error
+	return exports, err
+}
+
+// HasPending reports whether the user has a data export waiting for the export job.
+func (r *DataExportRepository) HasPending(userID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.DataExport{}).
+		Where("user_id = ? AND status = ?", userID, models.DataExportStatusPending).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// FindPending retrieves the data exports waiting for the export job, oldest first.
+func (r *DataExportRepository) FindPending(limit int) ([]models.DataExport, error) {
+	var exports []models.DataExport
+	err := r.db.Omit("archive").Where("status = ?", models.DataExportStatusPending).
+		Order("created_at").Limit(limit).
+		Find(&exports).Error
+	return exports, err
+}
+
+// RecordFailure saves a failed attempt of a data export, and its new status.
+func (r *DataExportRepository) RecordFailure(exportID uint, attempts int, lastError, status string) error {
+	return r.db.Model(&models.DataExport{}).Where("id = ?", exportID).
+		Updates(map[string]interface{}{"attempts": attempts, "last_error": lastError, "status": status}).Error
+}
+
+// Complete stores the archive of a data export and makes it downloadable until expiresAt.
+func (r *DataExportRepository) Complete(exportID uint, archive []byte, downloadTokenHash string, completedAt, expiresAt time.Time) error {
+	return r.db.Model(&models.DataExport{}).Where("id = ?", exportID).
+		Updates(map[string]interface{}{
+			"status":              models.DataExportStatusReady,
+			"archive":             archive,
+			"size":                len(archive),
+			"download_token_hash": downloadTokenHash,
+			"completed_at":        completedAt,
+			"expires_at":          expiresAt,
+			"last_error":          "",
+		}).Error
+}
+
+// FindByDownloadTokenHash retrieves a data export with its archive by the hash of its download token.
+func (r *DataExportRepository) FindByDownloadTokenHash(tokenHash string) (*models.DataExport, error) {
+	var export models.DataExport
+	if err := r.db.Where("download_token_hash = ?", tokenHash).First(&export).Error; err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// MarkDownloaded records a download of the archive of a data export.
+func (r *DataExportRepository) MarkDownloaded(exportID uint, at time.Time) error {
+	return r.db.Model(&models.DataExport{}).Where("id = ?", exportID).Update("downloaded_at", at).Error
+}
+
+// ExpireArchives removes the archives whose download link expired at the given time and returns how many.
+func (r *DataExportRepository) ExpireArchives(at time.Time) (int64, error) {
+	result := r.db.Model(&models.DataExport{}).
+		Where("status = ? AND expires_at <= ?", models.DataExportStatusReady, at).
+		Updates(map[string]interface{}{
+			"status":              models.DataExportStatusExpired,
+			"archive":             nil,
+			"download_token_hash": "",
+		})
+	return result.RowsAffected, result.Error
+}
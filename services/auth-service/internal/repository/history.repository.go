@@ -2,18 +2,44 @@ package repository
 
 import (
 	"auth-service/internal/models"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+// LoginHistoryFilter narrows the login attempts of a user listed. Zero values do not filter.
+type LoginHistoryFilter struct {
+	From       time.Time
+	To         time.Time
+	Successful *bool
+	IPAddress  string
+	Device     string // Part of the device information, case-insensitive
+}
+
+// LoginHistoryCursor is the last login attempt of a page, newest first. The next page starts after it.
+type LoginHistoryCursor struct {
+	LoginTime time.Time
+	ID        uint
+}
+
 // ILoginHistoryRepository defines the interface for login history repository operations.
 type ILoginHistoryRepository interface {
-	RecordLoginAttempt(history models.LoginHistory) error
+	RecordLoginAttempt(history *models.LoginHistory) error
 	GetLoginAttempts(userID uint, from, to time.Time) ([]models.LoginHistory, error)
+	ListLoginAttempts(userID uint, filter LoginHistoryFilter, after *LoginHistoryCursor, limit int) ([]models.LoginHistory, error)
+	FindLoginAttemptsInBatches(userID uint, filter LoginHistoryFilter, batchSize int, fn func(histories []models.LoginHistory) error) error
+	FindArchivedLoginAttemptsInBatches(userID uint, filter LoginHistoryFilter, batchSize int, fn func(archives []models.LoginHistoryArchive) error) error
 	GetHistoryByUserID(userID uint) ([]models.LoginHistory, error)
+	GetArchivedHistoryByUserID(userID uint) ([]models.LoginHistoryArchive, error)
+	GetRecentHistory(userID uint, limit int) ([]models.LoginHistory, error)
+	GetRecentSuccessfulLogins(userID uint, limit int) ([]models.LoginHistory, error)
+	HasLoggedInFromDevice(userID uint, fingerprint string) (bool, error)
 	UpdateLoginHistory(history *models.LoginHistory) error
 	UpdateLogoutTime(historyID uint, logoutTime time.Time) error
+	ArchiveBefore(cutoff time.Time, limit int, archivedAt time.Time) (int64, error)
+	PurgeBefore(cutoff time.Time, limit int) (int64, error)
+	PurgeArchiveBefore(cutoff time.Time, limit int) (int64, error)
 }
 
 // LoginHistoryRepository is a concrete implementation of ILoginHistoryRepository.
@@ -27,8 +53,8 @@ func NewLoginHistoryRepository(db *gorm.DB) ILoginHistoryRepository {
 }
 
 // RecordLoginAttempt saves a new login attempt to the database.
-func (repo *LoginHistoryRepository) RecordLoginAttempt(history models.LoginHistory) error {
-	return repo.db.Create(&history).Error
+func (repo *LoginHistoryRepository) RecordLoginAttempt(history *models.LoginHistory) error {
+	return repo.db.Create(history).Error
 }
 
 // GetLoginAttempts retrieves all login attempts for a user within a specified time range.
@@ -38,6 +64,36 @@ func (repo *LoginHistoryRepository) GetLoginAttempts(userID uint, from, to time.
 	return histories, err
 }
 
+// ListLoginAttempts retrieves up to limit login attempts of a user matching filter, newest first, starting after
+// the cursor when one is given.
+func (repo *LoginHistoryRepository) ListLoginAttempts(userID uint, filter LoginHistoryFilter, after *LoginHistoryCursor, limit int) ([]models.LoginHistory, error) {
+	query := repo.filtered(&models.LoginHistory{}, userID, filter)
+	if after != nil {
+		query = query.Where("login_time < ? OR (login_time = ? AND id < ?)", after.LoginTime, after.LoginTime, after.ID)
+	}
+	var histories []models.LoginHistory
+	err := query.Order("login_time DESC, id DESC").Limit(limit).Find(&histories).Error
+	return histories, err
+}
+
+// FindLoginAttemptsInBatches calls fn with the login attempts of a user matching filter in the order they were
+// recorded, batchSize at a time.
+func (repo *LoginHistoryRepository) FindLoginAttemptsInBatches(userID uint, filter LoginHistoryFilter, batchSize int, fn func(histories []models.LoginHistory) error) error {
+	var histories []models.LoginHistory
+	return repo.filtered(&models.LoginHistory{}, userID, filter).FindInBatches(&histories, batchSize, func(tx *gorm.DB, batch int) error {
+		return fn(histories)
+	}).Error
+}
+
+// FindArchivedLoginAttemptsInBatches calls fn with the archived login attempts of a user matching filter in the
+// order they were recorded, batchSize at a time.
+func (repo *LoginHistoryRepository) FindArchivedLoginAttemptsInBatches(userID uint, filter LoginHistoryFilter, batchSize int, fn func(archives []models.LoginHistoryArchive) error) error {
+	var archives []models.LoginHistoryArchive
+	return repo.filtered(&models.LoginHistoryArchive{}, userID, filter).FindInBatches(&archives, batchSize, func(tx *gorm.DB, batch int) error {
+		return fn(archives)
+	}).Error
+}
+
 // GetHistoryByUserID retrieves all login attempts for a specific user.
 func (repo *LoginHistoryRepository) GetHistoryByUserID(userID uint) ([]models.LoginHistory, error) {
 	var histories []models.LoginHistory
@@ -45,6 +101,37 @@ func (repo *LoginHistoryRepository) GetHistoryByUserID(userID uint) ([]models.Lo
 	return histories, err
 }
 
+// GetArchivedHistoryByUserID retrieves the archived login attempts of a user in the order they were recorded.
+func (repo *LoginHistoryRepository) GetArchivedHistoryByUserID(userID uint) ([]models.LoginHistoryArchive, error) {
+	var archives []models.LoginHistoryArchive
+	err := repo.db.Where("user_id = ?", userID).Order("id").Find(&archives).Error
+	return archives, err
+}
+
+// GetRecentHistory retrieves the latest login attempts of a user, newest first.
+func (repo *LoginHistoryRepository) GetRecentHistory(userID uint, limit int) ([]models.LoginHistory, error) {
+	var histories []models.LoginHistory
+	err := repo.db.Where("user_id = ?", userID).Order("login_time DESC, id DESC").Limit(limit).Find(&histories).Error
+	return histories, err
+}
+
+// GetRecentSuccessfulLogins retrieves the latest successful logins of a user, newest first.
+func (repo *LoginHistoryRepository) GetRecentSuccessfulLogins(userID uint, limit int) ([]models.LoginHistory, error) {
+	var histories []models.LoginHistory
+	err := repo.db.Where("user_id = ? AND successful = ?", userID, true).
+		Order("login_time DESC, id DESC").Limit(limit).Find(&histories).Error
+	return histories, err
+}
+
+// HasLoggedInFromDevice reports whether a user logged in successfully from the device with the fingerprint.
+func (repo *LoginHistoryRepository) HasLoggedInFromDevice(userID uint, fingerprint string) (bool, error) {
+	var count int64
+	err := repo.db.Model(&models.LoginHistory{}).
+		Where("user_id = ? AND successful = ? AND device_fingerprint = ?", userID, true, fingerprint).
+		Limit(1).Count(&count).Error
+	return count > 0, err
+}
+
 // UpdateLoginHistory updates an existing login history entry in the database.
 func (repo *LoginHistoryRepository) UpdateLoginHistory(history *models.LoginHistory) error {
 	return repo.db.Save(history).Error
@@ -54,3 +141,69 @@ func (repo *LoginHistoryRepository) UpdateLoginHistory(history *models.LoginHist
 func (repo *LoginHistoryRepository) UpdateLogoutTime(historyID uint, logoutTime time.Time) error {
 	return repo.db.Model(&models.LoginHistory{}).Where("id = ?", historyID).Update("logout_time", logoutTime).Error
 }
+
+// ArchiveBefore moves up to limit login attempts made before cutoff, oldest first, to the archive, and returns
+// how many were moved.
+func (repo *LoginHistoryRepository) ArchiveBefore(cutoff time.Time, limit int, archivedAt time.Time) (int64, error) {
+	var moved int64
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		var histories []models.LoginHistory
+		if err := tx.Unscoped().Where("login_time < ?", cutoff).Order("id").Limit(limit).Find(&histories).Error; err != nil {
+			return err
+		}
+		if len(histories) == 0 {
+			return nil
+		}
+		archives := make([]models.LoginHistoryArchive, 0, len(histories))
+		ids := make([]uint, 0, len(histories))
+		for _, history := range histories {
+			archives = append(archives, history.Archive(archivedAt))
+			ids = append(ids, history.ID)
+		}
+		if err := tx.Create(&archives).Error; err != nil {
+			return err
+		}
+		result := tx.Unscoped().Where("id IN ?", ids).Delete(&models.LoginHistory{})
+		moved = result.RowsAffected
+		return result.Error
+	})
+	return moved, err
+}
+
+// PurgeBefore deletes up to limit login attempts made before cutoff, oldest first, and returns how many were
+// deleted.
+func (repo *LoginHistoryRepository) PurgeBefore(cutoff time.Time, limit int) (int64, error) {
+	oldest := repo.db.Unscoped().Model(&models.LoginHistory{}).Select("id").Where("login_time < ?", cutoff).Order("id").Limit(limit)
+	result := repo.db.Unscoped().Where("id IN (?)", oldest).Delete(&models.LoginHistory{})
+	return result.RowsAffected, result.Error
+}
+
+// PurgeArchiveBefore deletes up to limit archived login attempts made before cutoff, oldest first, and returns
+// how many were deleted.
+func (repo *LoginHistoryRepository) PurgeArchiveBefore(cutoff time.Time, limit int) (int64, error) {
+	oldest := repo.db.Model(&models.LoginHistoryArchive{}).Select("id").Where("login_time < ?", cutoff).Order("id").Limit(limit)
+	result := repo.db.Where("id IN (?)", oldest).Delete(&models.LoginHistoryArchive{})
+	return result.RowsAffected, result.Error
+}
+
+// filtered returns a query of the login attempts of a user matching filter, in login_histories or in
+// login_history_archives depending on model.
+func (repo *LoginHistoryRepository) filtered(model interface{}, userID uint, filter LoginHistoryFilter) *gorm.DB {
+	query := repo.db.Model(model).Where("user_id = ?", userID)
+	if !filter.From.IsZero() {
+		query = query.Where("login_time >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("login_time <= ?", filter.To)
+	}
+	if filter.Successful != nil {
+		query = query.Where("successful = ?", *filter.Successful)
+	}
+	if filter.IPAddress != "" {
+		query = query.Where("ip_address = ?", filter.IPAddress)
+	}
+	if filter.Device != "" {
+		query = query.Where("LOWER(device_information) LIKE ?", "%"+strings.ToLower(filter.Device)+"%")
+	}
+	return query
+}
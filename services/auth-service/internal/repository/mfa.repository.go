@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"auth-service/internal/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrMFACodeReused is returned when a TOTP code of an already used time step is presented again.
+	ErrMFACodeReused = errors.New("code has already been used")
+	// ErrRecoveryCodeNotFound is returned for unknown or used recovery codes.
+	ErrRecoveryCodeNotFound = errors.New("recovery code not found")
+	// ErrMFAChallengeCompleted is returned when a challenge was completed by a concurrent request.
+	ErrMFAChallengeCompleted = errors.New("mfa challenge has already been completed")
+	// ErrMFAChallengeAttemptsExceeded is returned when all the attempts at the code of a challenge were made.
+	ErrMFAChallengeAttemptsExceeded = errors.New("mfa challenge has no attempts left")
+)
+
+// IMFARepository defines the interface for MFA enrollment, recovery code, challenge and policy operations.
+type IMFARepository interface {
+	FindEnrollment(userID uint) (*models.MFAEnrollment, error)
+	ReplaceEnrollment(enrollment *models.MFAEnrollment) error
+	ConfirmEnrollment(enrollment *models.MFAEnrollment, step int64, codes []models.RecoveryCode, confirmedAt time.Time) error
+	UseStep(enrollmentID uint, step int64) error
+	DeleteEnrollment(userID uint) error
+	ReplaceRecoveryCodes(userID uint, codes []models.RecoveryCode) error
+	UseRecoveryCode(userID uint, codeHash string, usedAt time.Time) error
+	CountRecoveryCodes(userID uint) (int64, error)
+	CreateChallenge(challenge *models.MFAChallenge) error
+	FindChallengeByHash(tokenHash string) (*models.MFAChallenge, error)
+	IncrementChallengeAttempts(challengeID uint, maxAttempts int) error
+	CompleteChallenge(challengeID uint, completedAt time.Time) error
+	IsRequiredForAny(roles []string) (bool, error)
+	ListPolicies() ([]models.MFAPolicy, error)
+	SavePolicy(policy *models.MFAPolicy) error
+}
+
+// MFARepository is a GORM-based implementation of IMFARepository.
+type MFARepository struct {
+	db *gorm.DB
+}
+
+// NewMFARepository creates a new instance of MFARepository.
+func NewMFARepository(db *gorm.DB) IMFARepository {
+	return &MFARepository{db: db}
+}
+
+// FindEnrollment retrieves the MFA enrollment of a user, confirmed or not.
+func (r *MFARepository) FindEnrollment(userID uint) (*models.MFAEnrollment, error) {
+	var enrollment models.MFAEnrollment
+	if err := r.db.Where("user_id = ?", userID).First(&enrollment).Error; err != nil {
+		return nil, err
+	}
+	return &enrollment, nil
+}
+
+// ReplaceEnrollment stores a new unconfirmed enrollment in place of the user's previous one.
+func (r *MFARepository) ReplaceEnrollment(enrollment *models.MFAEnrollment) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_id = ?", enrollment.UserID).Delete(&models.MFAEnrollment{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(enrollment).Error
+	})
+}
+
+// ConfirmEnrollment enables MFA for the enrollment's user, marking step as used and replacing the user's
+// recovery codes, in one transaction.
+func (r *MFARepository) ConfirmEnrollment(enrollment *models.MFAEnrollment, step int64, codes []models.RecoveryCode, confirmedAt time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.MFAEnrollment{}).
+			Where("id = ? AND confirmed_at IS NULL", enrollment.ID).
+			Updates(map[string]interface{}{"confirmed_at": confirmedAt, "last_used_step": step})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		enrollment.ConfirmedAt, enrollment.LastUsedStep = &confirmedAt, step
+		return replaceRecoveryCodes(tx, enrollment.UserID, codes)
+	})
+}
+
+// UseStep records step as the last used time step of an enrollment. Steps at or before the last used one
+// are rejected with ErrMFACodeReused, so each code is accepted once.
+func (r *MFARepository) UseStep(enrollmentID uint, step int64) error {
+	result := r.db.Model(&models.MFAEnrollment{}).
+		Where("id = ? AND last_used_step < ?", enrollmentID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrMFACodeReused
+	}
+	return nil
+}
+
+// DeleteEnrollment disables MFA for a user, removing the enrollment and the recovery codes.
+func (r *MFARepository) DeleteEnrollment(userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("user_id = ?", userID).Delete(&models.MFAEnrollment{}).Error
+	})
+}
+
+// ReplaceRecoveryCodes replaces all recovery codes of a user.
+func (r *MFARepository) ReplaceRecoveryCodes(userID uint, codes []models.RecoveryCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, codes)
+	})
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID uint, codes []models.RecoveryCode) error {
+	if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+	for i := range codes {
+		codes[i].UserID = userID
+	}
+	return tx.Create(&codes).Error
+}
+
+// UseRecoveryCode marks an unused recovery code of a user as used, or returns ErrRecoveryCodeNotFound.
+func (r *MFARepository) UseRecoveryCode(userID uint, codeHash string, usedAt time.Time) error {
+	result := r.db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecoveryCodeNotFound
+	}
+	return nil
+}
+
+// CountRecoveryCodes counts the unused recovery codes of a user.
+func (r *MFARepository) CountRecoveryCodes(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+// CreateChallenge inserts a new MFA login challenge.
+func (r *MFARepository) CreateChallenge(challenge *models.MFAChallenge) error {
+	return r.db.Create(challenge).Error
+}
+
+// FindChallengeByHash retrieves an MFA challenge by the hash of its token.
+func (r *MFARepository) FindChallengeByHash(tokenHash string) (*models.MFAChallenge, error) {
+	var challenge models.MFAChallenge
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&challenge).Error; err != nil {
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+// IncrementChallengeAttempts counts an attempt at the code of a challenge before it is compared, so concurrent
+// requests cannot try more than maxAttempts codes. Once they were all made it returns
+// ErrMFAChallengeAttemptsExceeded.
+func (r *MFARepository) IncrementChallengeAttempts(challengeID uint, maxAttempts int) error {
+	result := r.db.Model(&models.MFAChallenge{}).
+		Where("id = ? AND attempts < ?", challengeID, maxAttempts).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrMFAChallengeAttemptsExceeded
+	}
+	return nil
+}
+
+// CompleteChallenge marks a challenge as completed. Of two concurrent completions only one succeeds; the other
+// gets ErrMFAChallengeCompleted.
+func (r *MFARepository) CompleteChallenge(challengeID uint, completedAt time.Time) error {
+	result := r.db.Model(&models.MFAChallenge{}).
+		Where("id = ? AND completed_at IS NULL", challengeID).
+		Update("completed_at", completedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrMFAChallengeCompleted
+	}
+	return nil
+}
+
+// IsRequiredForAny reports whether the MFA policy of any of roles requires MFA.
+func (r *MFARepository) IsRequiredForAny(roles []string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.MFAPolicy{}).Where("role IN ? AND required", roles).Count(&count).Error
+	return count > 0, err
+}
+
+// ListPolicies retrieves the MFA policies of all roles that have one.
+func (r *MFARepository) ListPolicies() ([]models.MFAPolicy, error) {
+	var policies []models.MFAPolicy
+	err := r.db.Order("role").Find(&policies).Error
+	return policies, err
+}
+
+// SavePolicy creates or updates the MFA policy of a role.
+func (r *MFARepository) SavePolicy(policy *models.MFAPolicy) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "role"}},
+		DoUpdates: clause.AssignmentColumns([]string{"required", "updated_by", "updated_at"}),
+	}).Create(policy).Error
+}
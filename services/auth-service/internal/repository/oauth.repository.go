@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"auth-service/internal/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrAuthorizationCodeUsed is returned when an authorization code was exchanged by a concurrent request.
+var ErrAuthorizationCodeUsed = errors.New("authorization code has already been used")
+
+// IOAuthRepository defines the interface for OAuth client, consent and authorization code operations.
+type IOAuthRepository interface {
+	CreateClient(client *models.OAuthClient) error
+	FindClientByClientID(clientID string) (*models.OAuthClient, error)
+	ListClients() ([]models.OAuthClient, error)
+	DeleteClient(clientID string) error
+	FindConsent(userID uint, clientID string) (*models.OAuthConsent, error)
+	ListConsents(userID uint) ([]models.OAuthConsent, error)
+	SaveConsent(consent *models.OAuthConsent) error
+	DeleteConsent(userID uint, clientID string) error
+	CreateAuthorizationCode(code *models.AuthorizationCode) error
+	FindAuthorizationCodeByHash(codeHash string) (*models.AuthorizationCode, error)
+	UseAuthorizationCode(codeID uint, usedAt time.Time) error
+}
+
+// OAuthRepository is a GORM-based implementation of IOAuthRepository.
+type OAuthRepository struct {
+	db *gorm.DB
+}
+
+// NewOAuthRepository creates a new instance of OAuthRepository.
+func NewOAuthRepository(db *gorm.DB) IOAuthRepository {
+	return &OAuthRepository{db: db}
+}
+
+// CreateClient registers a new OAuth client.
+func (r *OAuthRepository) CreateClient(client *models.OAuthClient) error {
+	return r.db.Create(client).Error
+}
+
+// FindClientByClientID retrieves an OAuth client by its public client ID.
+func (r *OAuthRepository) FindClientByClientID(clientID string) (*models.OAuthClient, error) {
+	var client models.OAuthClient
+	if err := r.db.Where("client_id = ?", clientID).First(&client).Error; err != nil {
+		return nil, err
+	}
+	return &client, nil
+}
+
+// ListClients retrieves all registered OAuth clients.
+func (r *OAuthRepository) ListClients() ([]models.OAuthClient, error) {
+	var clients []models.OAuthClient
+	err := r.db.Order("name").Find(&clients).Error
+	return clients, err
+}
+
+// DeleteClient removes an OAuth client together with the consents given to it. Sessions granted to the client
+// are revoked by the caller.
+func (r *OAuthRepository) DeleteClient(clientID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("client_id = ?", clientID).Delete(&models.OAuthClient{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Unscoped().Where("client_id = ?", clientID).Delete(&models.OAuthConsent{}).Error
+	})
+}
+
+// FindConsent retrieves the consent a user gave to an OAuth client.
+func (r *OAuthRepository) FindConsent(userID uint, clientID string) (*models.OAuthConsent, error) {
+	var consent models.OAuthConsent
+	if err := r.db.Where("user_id = ? AND client_id = ?", userID, clientID).First(&consent).Error; err != nil {
+		return nil, err
+	}
+	return &consent, nil
+}
+
+// ListConsents retrieves the consents a user gave.
+func (r *OAuthRepository) ListConsents(userID uint) ([]models.OAuthConsent, error) {
+	var consents []models.OAuthConsent
+	err := r.db.Where("user_id = ?", userID).Order("created_at").Find(&consents).Error
+	return consents, err
+}
+
+// SaveConsent creates or replaces the consent of a user to an OAuth client.
+func (r *OAuthRepository) SaveConsent(consent *models.OAuthConsent) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "client_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"scopes", "updated_at"}),
+	}).Create(consent).Error
+}
+
+// DeleteConsent withdraws the consent of a user to an OAuth client.
+func (r *OAuthRepository) DeleteConsent(userID uint, clientID string) error {
+	result := r.db.Unscoped().Where("user_id = ? AND client_id = ?", userID, clientID).Delete(&models.OAuthConsent{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// CreateAuthorizationCode stores a new authorization code.
+func (r *OAuthRepository) CreateAuthorizationCode(code *models.AuthorizationCode) error {
+	return r.db.Create(code).Error
+}
+
+// FindAuthorizationCodeByHash retrieves an authorization code by its hash.
+func (r *OAuthRepository) FindAuthorizationCodeByHash(codeHash string) (*models.AuthorizationCode, error) {
+	var code models.AuthorizationCode
+	if err := r.db.Where("code_hash = ?", codeHash).First(&code).Error; err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+// UseAuthorizationCode marks an authorization code as exchanged. Of two concurrent exchanges only one succeeds;
+// the other gets ErrAuthorizationCodeUsed.
+func (r *OAuthRepository) UseAuthorizationCode(codeID uint, usedAt time.Time) error {
+	result := r.db.Model(&models.AuthorizationCode{}).
+		Where("id = ? AND used_at IS NULL", codeID).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAuthorizationCodeUsed
+	}
+	return nil
+}
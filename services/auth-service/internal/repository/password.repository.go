@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"auth-service/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// IPasswordHistoryRepository defines the interface for the passwords users have set.
+type IPasswordHistoryRepository interface {
+	Add(history *models.PasswordHistory) error
+	FindRecent(userID uint, limit int) ([]models.PasswordHistory, error)
+	Prune(userID uint, keep int) error
+}
+
+// PasswordHistoryRepository is a GORM-based implementation of IPasswordHistoryRepository.
+type PasswordHistoryRepository struct {
+	db *gorm.DB
+}
+
+// NewPasswordHistoryRepository creates a new instance of PasswordHistoryRepository.
+func NewPasswordHistoryRepository(db *gorm.DB) IPasswordHistoryRepository {
+	return &PasswordHistoryRepository{db: db}
+}
+
+// Add records a password a user has set.
+func (r *PasswordHistoryRepository) Add(history *models.PasswordHistory) error {
+	return r.db.Create(history).Error
+}
+
+// FindRecent retrieves the latest passwords of a user, newest first.
+func (r *PasswordHistoryRepository) FindRecent(userID uint, limit int) ([]models.PasswordHistory, error) {
+	var histories []models.PasswordHistory
+	err := r.db.Where("user_id = ?", userID).Order("id DESC").Limit(limit).Find(&histories).Error
+	return histories, err
+}
+
+// Prune deletes the passwords of a user older than the latest keep ones.
+func (r *PasswordHistoryRepository) Prune(userID uint, keep int) error {
+	latest := r.db.Model(&models.PasswordHistory{}).Select("id").Where("user_id = ?", userID).Order("id DESC").Limit(keep)
+	return r.db.Where("user_id = ? AND id NOT IN (?)", userID, latest).Delete(&models.PasswordHistory{}).Error
+}
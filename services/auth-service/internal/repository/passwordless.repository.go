@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"auth-service/internal/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrLoginCodeUsed is returned when a login code was used by a concurrent request.
+	ErrLoginCodeUsed = errors.New("login code has already been used")
+	// ErrLoginCodeAttemptsExceeded is returned when all the attempts at the code of a login code were made.
+	ErrLoginCodeAttemptsExceeded = errors.New("login code has no attempts left")
+)
+
+// ILoginCodeRepository defines the interface for passwordless login code operations.
+type ILoginCodeRepository interface {
+	Replace(loginCode *models.LoginCode) error
+	FindByTokenHash(tokenHash string) (*models.LoginCode, error)
+	FindByLinkTokenHash(linkTokenHash string) (*models.LoginCode, error)
+	FindLatestByUserID(userID uint) (*models.LoginCode, error)
+	IncrementAttempts(loginCodeID uint, maxAttempts int) error
+	Use(loginCodeID uint, usedAt time.Time) error
+}
+
+// LoginCodeRepository is a GORM-based implementation of ILoginCodeRepository.
+type LoginCodeRepository struct {
+	db *gorm.DB
+}
+
+// NewLoginCodeRepository creates a new instance of LoginCodeRepository.
+func NewLoginCodeRepository(db *gorm.DB) ILoginCodeRepository {
+	return &LoginCodeRepository{db: db}
+}
+
+// Replace inserts a new login code, discarding the unused codes sent to the user before so only the latest
+// one works.
+func (r *LoginCodeRepository) Replace(loginCode *models.LoginCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND used_at IS NULL", loginCode.UserID).Delete(&models.LoginCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(loginCode).Error
+	})
+}
+
+// FindByTokenHash retrieves a login code by the hash of the login token returned to the device.
+func (r *LoginCodeRepository) FindByTokenHash(tokenHash string) (*models.LoginCode, error) {
+	var loginCode models.LoginCode
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&loginCode).Error; err != nil {
+		return nil, err
+	}
+	return &loginCode, nil
+}
+
+// FindByLinkTokenHash retrieves a login code by the hash of the token of its link.
+func (r *LoginCodeRepository) FindByLinkTokenHash(linkTokenHash string) (*models.LoginCode, error) {
+	var loginCode models.LoginCode
+	if err := r.db.Where("link_token_hash = ?", linkTokenHash).First(&loginCode).Error; err != nil {
+		return nil, err
+	}
+	return &loginCode, nil
+}
+
+// FindLatestByUserID retrieves the login code sent last to a user.
+func (r *LoginCodeRepository) FindLatestByUserID(userID uint) (*models.LoginCode, error) {
+	var loginCode models.LoginCode
+	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC").First(&loginCode).Error; err != nil {
+		return nil, err
+	}
+	return &loginCode, nil
+}
+
+// IncrementAttempts counts an attempt at the code of a login code before it is compared, so concurrent requests
+// cannot try more than maxAttempts codes. Once they were all made it returns ErrLoginCodeAttemptsExceeded.
+func (r *LoginCodeRepository) IncrementAttempts(loginCodeID uint, maxAttempts int) error {
+	result := r.db.Model(&models.LoginCode{}).
+		Where("id = ? AND attempts < ?", loginCodeID, maxAttempts).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrLoginCodeAttemptsExceeded
+	}
+	return nil
+}
+
+// Use marks a login code as used. Of two concurrent uses only one succeeds; the other gets ErrLoginCodeUsed.
+func (r *LoginCodeRepository) Use(loginCodeID uint, usedAt time.Time) error {
+	result := r.db.Model(&models.LoginCode{}).
+		Where("id = ? AND used_at IS NULL", loginCodeID).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrLoginCodeUsed
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"auth-service/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IRoleRepository defines the interface for role, permission and role assignment operations.
+type IRoleRepository interface {
+	ListRoles() ([]models.Role, error)
+	FindRole(name string) (*models.Role, error)
+	CreateRole(role *models.Role) error
+	SeedRole(role *models.Role) error
+	UpdateRole(role *models.Role) error
+	DeleteRole(name string) error
+	CountAccountsWithRole(name string) (int64, error)
+	FindUserRoles(userID uint) ([]models.UserRole, error)
+	AssignRole(userRole *models.UserRole) error
+	UnassignRole(userID uint, name string) error
+	FindPermissions(roles []string) ([]string, error)
+}
+
+// RoleRepository is a GORM-based implementation of IRoleRepository.
+type RoleRepository struct {
+	db *gorm.DB
+}
+
+// NewRoleRepository creates a new instance of RoleRepository.
+func NewRoleRepository(db *gorm.DB) IRoleRepository {
+	return &RoleRepository{db: db}
+}
+
+// ListRoles retrieves all roles with their permissions.
+func (r *RoleRepository) ListRoles() ([]models.Role, error) {
+	var roles []models.Role
+	err := r.db.Preload("Permissions").Order("name").Find(&roles).Error
+	return roles, err
+}
+
+// FindRole retrieves a role with its permissions.
+func (r *RoleRepository) FindRole(name string) (*models.Role, error) {
+	var role models.Role
+	if err := r.db.Preload("Permissions").Where("name = ?", name).First(&role).Error; err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// CreateRole stores a new role together with its permissions.
+func (r *RoleRepository) CreateRole(role *models.Role) error {
+	return r.db.Create(role).Error
+}
+
+// SeedRole creates a role with its permissions unless a role with the same name exists, which is left as it
+// is so that changes made by admins are kept.
+func (r *RoleRepository) SeedRole(role *models.Role) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Omit("Permissions").Clauses(clause.OnConflict{DoNothing: true}).Create(role)
+		if result.Error != nil || result.RowsAffected == 0 || len(role.Permissions) == 0 {
+			return result.Error
+		}
+		return tx.Create(&role.Permissions).Error
+	})
+}
+
+// UpdateRole updates the description of a role and replaces its permissions.
+func (r *RoleRepository) UpdateRole(role *models.Role) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Role{}).Where("name = ?", role.Name).Update("description", role.Description)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := tx.Where("role_name = ?", role.Name).Delete(&models.RolePermission{}).Error; err != nil {
+			return err
+		}
+		if len(role.Permissions) == 0 {
+			return nil
+		}
+		return tx.Create(&role.Permissions).Error
+	})
+}
+
+// DeleteRole removes a role, its permissions and its assignments to users.
+func (r *RoleRepository) DeleteRole(name string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_name = ?", name).Delete(&models.UserRole{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("role_name = ?", name).Delete(&models.RolePermission{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("name = ?", name).Delete(&models.Role{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// CountAccountsWithRole counts the users whose account has the role, not counting roles given in addition.
+func (r *RoleRepository) CountAccountsWithRole(name string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.User{}).Where("role = ?", name).Count(&count).Error
+	return count, err
+}
+
+// FindUserRoles retrieves the roles given to a user in addition to the role of their account.
+func (r *RoleRepository) FindUserRoles(userID uint) ([]models.UserRole, error) {
+	var userRoles []models.UserRole
+	err := r.db.Where("user_id = ?", userID).Order("role_name").Find(&userRoles).Error
+	return userRoles, err
+}
+
+// AssignRole gives a user a role. Giving a role the user already has changes nothing.
+func (r *RoleRepository) AssignRole(userRole *models.UserRole) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(userRole).Error
+}
+
+// UnassignRole takes a role given in addition away from a user.
+func (r *RoleRepository) UnassignRole(userID uint, name string) error {
+	result := r.db.Where("user_id = ? AND role_name = ?", userID, name).Delete(&models.UserRole{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// FindPermissions retrieves the distinct permissions granted to any of roles.
+func (r *RoleRepository) FindPermissions(roles []string) ([]string, error) {
+	var permissions []string
+	err := r.db.Model(&models.RolePermission{}).
+		Where("role_name IN ?", roles).
+		Distinct().Order("permission").
+		Pluck("permission", &permissions).Error
+	return permissions, err
+}
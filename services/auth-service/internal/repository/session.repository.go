@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"auth-service/internal/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrRefreshTokenAlreadyUsed is returned when a refresh token was rotated by a concurrent request.
+var ErrRefreshTokenAlreadyUsed = errors.New("refresh token has already been used")
+
+// ISessionRepository defines the interface for session and refresh token repository operations.
+type ISessionRepository interface {
+	CreateSession(session *models.Session, token *models.RefreshToken) error
+	FindSessionByID(id uint) (*models.Session, error)
+	ListActiveSessions(userID uint, at time.Time) ([]models.Session, error)
+	TouchSession(sessionID uint, ipAddress string, at, notSince time.Time) error
+	FindRefreshTokenByHash(tokenHash string) (*models.RefreshToken, error)
+	RotateRefreshToken(current *models.RefreshToken, next *models.RefreshToken, usedAt time.Time) error
+	RevokeSession(sessionID uint, reason string, revokedAt time.Time) error
+	RevokeUserSession(userID, sessionID uint, reason string, revokedAt time.Time) error
+	RevokeUserSessions(userID, exceptSessionID uint, reason string, revokedAt time.Time) error
+	RevokeClientSessions(clientID string, userID uint, reason string, revokedAt time.Time) error
+}
+
+// SessionRepository is a GORM-based implementation of ISessionRepository.
+type SessionRepository struct {
+	db *gorm.DB
+}
+
+// NewSessionRepository creates a new instance of SessionRepository.
+func NewSessionRepository(db *gorm.DB) ISessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// CreateSession inserts a new session together with its first refresh token.
+func (r *SessionRepository) CreateSession(session *models.Session, token *models.RefreshToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(session).Error; err != nil {
+			return err
+		}
+		token.SessionID = session.ID
+		return tx.Create(token).Error
+	})
+}
+
+// FindSessionByID retrieves a session by its ID.
+func (r *SessionRepository) FindSessionByID(id uint) (*models.Session, error) {
+	var session models.Session
+	if err := r.db.First(&session, id).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ListActiveSessions retrieves the sessions of a user that are not revoked and still have a refresh token that
+// can be used at the given time, most recently seen first.
+func (r *SessionRepository) ListActiveSessions(userID uint, at time.Time) ([]models.Session, error) {
+	var sessions []models.Session
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL", userID).
+		Where("EXISTS (SELECT 1 FROM refresh_tokens WHERE refresh_tokens.session_id = sessions.id AND refresh_tokens.used_at IS NULL AND refresh_tokens.expires_at > ? AND refresh_tokens.deleted_at IS NULL)", at).
+		Order("last_seen_at DESC, id DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// TouchSession records that a session was used at the given time, unless that was already recorded since
+// notSince. The address is kept when ipAddress is empty.
+func (r *SessionRepository) TouchSession(sessionID uint, ipAddress string, at, notSince time.Time) error {
+	updates := map[string]interface{}{"last_seen_at": at}
+	if ipAddress != "" {
+		updates["ip_address"] = ipAddress
+	}
+	return r.db.Model(&models.Session{}).
+		Where("id = ? AND last_seen_at < ?", sessionID, notSince).
+		Updates(updates).Error
+}
+
+// FindRefreshTokenByHash retrieves a refresh token and its session by the token hash.
+func (r *SessionRepository) FindRefreshTokenByHash(tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	if err := r.db.Preload("Session").Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RotateRefreshToken marks current as used and stores its successor in one transaction. The current token row is
+// locked, so of two concurrent rotations only one succeeds; the other gets ErrRefreshTokenAlreadyUsed.
+func (r *SessionRepository) RotateRefreshToken(current *models.RefreshToken, next *models.RefreshToken, usedAt time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var locked models.RefreshToken
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&locked, current.ID).Error; err != nil {
+			return err
+		}
+		if locked.UsedAt != nil {
+			return ErrRefreshTokenAlreadyUsed
+		}
+		if err := tx.Model(&locked).Update("used_at", usedAt).Error; err != nil {
+			return err
+		}
+		next.SessionID = current.SessionID
+		return tx.Create(next).Error
+	})
+}
+
+// RevokeSession revokes a session so none of its refresh tokens can be used any more.
+func (r *SessionRepository) RevokeSession(sessionID uint, reason string, revokedAt time.Time) error {
+	return r.db.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", sessionID).
+		Updates(map[string]interface{}{"revoked_at": revokedAt, "revoked_reason": reason}).Error
+}
+
+// RevokeUserSession revokes a session of a user, failing with gorm.ErrRecordNotFound if the user has no such
+// session that is still active.
+func (r *SessionRepository) RevokeUserSession(userID, sessionID uint, reason string, revokedAt time.Time) error {
+	result := r.db.Model(&models.Session{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).
+		Updates(map[string]interface{}{"revoked_at": revokedAt, "revoked_reason": reason})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// RevokeUserSessions revokes every session of a user except exceptSessionID, which may be 0 to revoke them all.
+func (r *SessionRepository) RevokeUserSessions(userID, exceptSessionID uint, reason string, revokedAt time.Time) error {
+	return r.db.Model(&models.Session{}).
+		Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, exceptSessionID).
+		Updates(map[string]interface{}{"revoked_at": revokedAt, "revoked_reason": reason}).Error
+}
+
+// RevokeClientSessions revokes the sessions granted to an OAuth client, for one user or for all when userID is 0.
+func (r *SessionRepository) RevokeClientSessions(clientID string, userID uint, reason string, revokedAt time.Time) error {
+	query := r.db.Model(&models.Session{}).Where("client_id = ? AND revoked_at IS NULL", clientID)
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	return query.Updates(map[string]interface{}{"revoked_at": revokedAt, "revoked_reason": reason}).Error
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"auth-service/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ISigningKeyRepository defines the interface for token signing key repository operations.
+type ISigningKeyRepository interface {
+	FindActiveKey() (*models.SigningKey, error)
+	ListPublishedKeys(now time.Time) ([]models.SigningKey, error)
+	RotateKey(next *models.SigningKey, retiredAt, expiresAt time.Time) error
+}
+
+// SigningKeyRepository is a GORM-based implementation of ISigningKeyRepository.
+type SigningKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewSigningKeyRepository creates a new instance of SigningKeyRepository.
+func NewSigningKeyRepository(db *gorm.DB) ISigningKeyRepository {
+	return &SigningKeyRepository{db: db}
+}
+
+// FindActiveKey retrieves the key currently used to sign tokens.
+func (r *SigningKeyRepository) FindActiveKey() (*models.SigningKey, error) {
+	var key models.SigningKey
+	if err := r.db.Where("retired_at IS NULL").Order("created_at DESC").First(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// ListPublishedKeys retrieves the keys tokens may still be verified with: the active key and the retired keys
+// that have not expired yet, newest first.
+func (r *SigningKeyRepository) ListPublishedKeys(now time.Time) ([]models.SigningKey, error) {
+	var keys []models.SigningKey
+	err := r.db.Where("expires_at IS NULL OR expires_at > ?", now).Order("created_at DESC").Find(&keys).Error
+	return keys, err
+}
+
+// RotateKey retires the active keys and stores next as the new signing key in one transaction.
+func (r *SigningKeyRepository) RotateKey(next *models.SigningKey, retiredAt, expiresAt time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.SigningKey{}).
+			Where("retired_at IS NULL").
+			Updates(map[string]interface{}{"retired_at": retiredAt, "expires_at": expiresAt}).Error; err != nil {
+			return err
+		}
+		return tx.Create(next).Error
+	})
+}
@@ -2,16 +2,49 @@ package repository
 
 import (
 	"auth-service/internal/models"
+	"strings"
+	"time"
+
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// User statuses a user listing can be narrowed to.
+const (
+	UserStatusActive   = "active"
+	UserStatusDisabled = "disabled"
+	UserStatusLocked   = "locked"
+	UserStatusDeleted  = "deleted"
 )
 
+// UserFilter narrows a user listing. Zero values are ignored.
+type UserFilter struct {
+	Search   string // Part of the username or email, case-insensitive
+	Role     string
+	Status   string
+	Verified *bool
+}
+
 // IUserRepository provides an interface for database operations involving users.
 type IUserRepository interface {
 	Create(user *models.User) error
+	FindByID(userID uint) (*models.User, error)
+	FindByIDUnscoped(userID uint) (*models.User, error)
+	List(filter UserFilter, at time.Time, offset, limit int) ([]models.User, int64, error)
 	FindByUsername(username string) (*models.User, error)
 	FindByEmail(email string) (*models.User, error)
 	ExistsByUsernameOrEmail(username, email string) (bool, error)
+	PhoneNumberInUse(phoneNumber string, exceptUserID uint) (bool, error)
+	RemovePhoneNumber(userID uint) error
 	UpdatePassword(userID uint, newPassword string) error
+	IncrementFailedLogins(userID uint, windowStart, at time.Time) (int, error)
+	LockUser(userID uint, until time.Time) error
+	ResetFailedLogins(userID uint) error
+	UnlockUser(userID uint) error
+	SetDisabled(userID uint, disabledAt *time.Time, reason string) error
+	UpdateRole(userID uint, role string) error
+	RequirePasswordReset(userID uint) error
+	Restore(userID uint) error
 	Delete(userID uint) error
 }
 
@@ -30,6 +63,58 @@ func (r *UserRepository) Create(user *models.User) error {
 	return r.db.Create(user).Error
 }
 
+// FindByID finds a user by their ID.
+func (r *UserRepository) FindByID(userID uint) (*models.User, error) {
+	var user models.User
+	if err := r.db.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// FindByIDUnscoped finds a user by their ID, including deleted users.
+func (r *UserRepository) FindByIDUnscoped(userID uint) (*models.User, error) {
+	var user models.User
+	if err := r.db.Unscoped().First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// List retrieves the users matching filter, oldest first, with the total number of matches. Deleted users are
+// only listed when filtering for them; at decides which accounts are locked.
+func (r *UserRepository) List(filter UserFilter, at time.Time, offset, limit int) ([]models.User, int64, error) {
+	query := r.db.Model(&models.User{})
+	if filter.Search != "" {
+		pattern := "%" + strings.ToLower(filter.Search) + "%"
+		query = query.Where("LOWER(username) LIKE ? OR LOWER(email) LIKE ?", pattern, pattern)
+	}
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	if filter.Verified != nil {
+		query = query.Where("verified = ?", *filter.Verified)
+	}
+	switch filter.Status {
+	case UserStatusActive:
+		query = query.Where("disabled_at IS NULL AND (locked_until IS NULL OR locked_until <= ?)", at)
+	case UserStatusDisabled:
+		query = query.Where("disabled_at IS NOT NULL")
+	case UserStatusLocked:
+		query = query.Where("locked_until > ?", at)
+	case UserStatusDeleted:
+		query = query.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var users []models.User
+	err := query.Order("id").Offset(offset).Limit(limit).Find(&users).Error
+	return users, total, err
+}
+
 // FindByUsername finds a user by their username.
 func (r *UserRepository) FindByUsername(username string) (*models.User, error) {
 	var user models.User
@@ -55,11 +140,96 @@ func (r *UserRepository) ExistsByUsernameOrEmail(username, email string) (bool,
 	return count > 0, err
 }
 
+// PhoneNumberInUse reports whether another user than exceptUserID has verified phoneNumber. Deleted accounts
+// keep their number until they are erased, as the unique index on verified numbers does.
+func (r *UserRepository) PhoneNumberInUse(phoneNumber string, exceptUserID uint) (bool, error) {
+	var count int64
+	err := r.db.Unscoped().Model(&models.User{}).
+		Where("phone_number = ? AND phone_verified AND id <> ?", phoneNumber, exceptUserID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// RemovePhoneNumber clears the phone number of a user and its verification.
+func (r *UserRepository) RemovePhoneNumber(userID uint) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).
+		Updates(map[string]interface{}{"phone_number": "", "phone_verified": false, "phone_verified_at": nil}).Error
+}
+
 // UpdatePassword updates a user's password.
 func (r *UserRepository) UpdatePassword(userID uint, newPassword string) error {
 	return r.db.Model(&models.User{}).Where("id = ?", userID).Update("password", newPassword).Error
 }
 
+// IncrementFailedLogins counts a failed login and returns the number of consecutive failures. Failures before
+// windowStart no longer count, so the count restarts at 1 after a quiet period.
+func (r *UserRepository) IncrementFailedLogins(userID uint, windowStart, at time.Time) (int, error) {
+	var user models.User
+	err := r.db.Model(&user).Clauses(clause.Returning{Columns: []clause.Column{{Name: "failed_login_attempts"}}}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"failed_login_attempts": gorm.Expr("CASE WHEN last_failed_login_at IS NULL OR last_failed_login_at < ? THEN 1 ELSE failed_login_attempts + 1 END", windowStart),
+			"last_failed_login_at":  at,
+		}).Error
+	return user.FailedLoginAttempts, err
+}
+
+// LockUser refuses logins to a user until the given time and starts counting failures anew.
+func (r *UserRepository) LockUser(userID uint, until time.Time) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"locked_until":          until,
+		"lockout_count":         gorm.Expr("lockout_count + 1"),
+		"failed_login_attempts": 0,
+	}).Error
+}
+
+// ResetFailedLogins clears the failure count of a user after a successful login. Lockouts start from the
+// base duration again.
+func (r *UserRepository) ResetFailedLogins(userID uint) error {
+	return r.db.Model(&models.User{}).
+		Where("id = ? AND (failed_login_attempts <> 0 OR lockout_count <> 0)", userID).
+		Updates(map[string]interface{}{"failed_login_attempts": 0, "lockout_count": 0}).Error
+}
+
+// UnlockUser lifts the lockout of a user and clears its failure count.
+func (r *UserRepository) UnlockUser(userID uint) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"locked_until":          nil,
+		"lockout_count":         0,
+		"failed_login_attempts": 0,
+	}).Error
+}
+
+// SetDisabled disables a user from disabledAt for the given reason, or enables them again when it is nil.
+func (r *UserRepository) SetDisabled(userID uint, disabledAt *time.Time, reason string) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"disabled_at":     disabledAt,
+		"disabled_reason": reason,
+	}).Error
+}
+
+// UpdateRole changes the role of a user's account.
+func (r *UserRepository) UpdateRole(userID uint, role string) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Update("role", role).Error
+}
+
+// RequirePasswordReset refuses logins to a user until they reset their password.
+func (r *UserRepository) RequirePasswordReset(userID uint) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Update("password_reset_required", true).Error
+}
+
+// Restore undoes the deletion of a user.
+func (r *UserRepository) Restore(userID uint) error {
+	result := r.db.Unscoped().Model(&models.User{}).Where("id = ? AND deleted_at IS NOT NULL", userID).Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
 // Delete removes a user from the database.
 func (r *UserRepository) Delete(userID uint) error {
 	return r.db.Delete(&models.User{}, userID).Error
@@ -4,6 +4,14 @@ import (
 	"auth-service/internal/models"
 	"errors"
 	"gorm.io/gorm"
+	"time"
+)
+
+var (
+	// ErrVerificationNotPending is returned when a verification was used or replaced concurrently.
+	ErrVerificationNotPending = errors.New("verification is no longer pending")
+	// ErrVerificationAttemptsExceeded is returned when all the attempts at the code of a verification were made.
+	ErrVerificationAttemptsExceeded = errors.New("verification has no attempts left")
 )
 
 type IUserVerificationRepository interface {
@@ -11,6 +19,12 @@ type IUserVerificationRepository interface {
 	UpdateVerification(verification *models.UserVerification) error
 	FindVerificationByID(id uint) (*models.UserVerification, error)
 	FindVerificationsByUserID(userID uint) ([]*models.UserVerification, error)
+	FindVerificationByToken(tokenHash string) (*models.UserVerification, error)
+	FindLatestVerification(userID uint, verificationType string) (*models.UserVerification, error)
+	ReplaceVerification(verification *models.UserVerification) error
+	IncrementAttempts(id uint, maxAttempts int) error
+	CompleteVerification(verification *models.UserVerification, verifiedAt time.Time) error
+	CompletePasswordReset(verification *models.UserVerification, passwordHash string, verifiedAt time.Time) error
 }
 type UserVerificationRepository struct {
 	DB *gorm.DB
@@ -58,3 +72,107 @@ func (r *UserVerificationRepository) FindVerificationsByUserID(userID uint) ([]*
 	}
 	return verifications, nil
 }
+
+// FindVerificationByToken retrieves a user verification by the hash of its token.
+func (r *UserVerificationRepository) FindVerificationByToken(tokenHash string) (*models.UserVerification, error) {
+	var verification models.UserVerification
+	if err := r.DB.Where("verification_token = ?", tokenHash).First(&verification).Error; err != nil {
+		return nil, err
+	}
+	return &verification, nil
+}
+
+// FindLatestVerification retrieves the most recent verification of a type for a user.
+func (r *UserVerificationRepository) FindLatestVerification(userID uint, verificationType string) (*models.UserVerification, error) {
+	var verification models.UserVerification
+	err := r.DB.Where("user_id = ? AND verification_type = ?", userID, verificationType).
+		Order("created_at DESC").
+		First(&verification).Error
+	if err != nil {
+		return nil, err
+	}
+	return &verification, nil
+}
+
+// ReplaceVerification marks the pending verifications of the same user and type as superseded and adds
+// verification in one transaction, so only the newest token can be used.
+func (r *UserVerificationRepository) ReplaceVerification(verification *models.UserVerification) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.UserVerification{}).
+			Where("user_id = ? AND verification_type = ? AND verification_status = ?",
+				verification.UserID, verification.VerificationType, models.VerificationPending).
+			Update("verification_status", models.VerificationSuperseded).Error; err != nil {
+			return err
+		}
+		return tx.Create(verification).Error
+	})
+}
+
+// IncrementAttempts counts an attempt at the code of a verification before it is compared, so concurrent
+// requests cannot try more than maxAttempts codes. Once they were all made it returns
+// ErrVerificationAttemptsExceeded.
+func (r *UserVerificationRepository) IncrementAttempts(id uint, maxAttempts int) error {
+	result := r.DB.Model(&models.UserVerification{}).
+		Where("id = ? AND attempts < ?", id, maxAttempts).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVerificationAttemptsExceeded
+	}
+	return nil
+}
+
+// CompleteVerification marks a pending verification as verified and, for email verifications, the user as
+// verified. Phone verifications set the verified phone number of the user. Of two concurrent completions only
+// one succeeds; the other gets ErrVerificationNotPending.
+func (r *UserVerificationRepository) CompleteVerification(verification *models.UserVerification, verifiedAt time.Time) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := completePending(tx, verification, verifiedAt); err != nil {
+			return err
+		}
+		user := tx.Model(&models.User{}).Where("id = ?", verification.UserID)
+		switch verification.VerificationType {
+		case models.VerificationTypeEmail:
+			return user.Update("verified", true).Error
+		case models.VerificationTypePhone:
+			return user.Updates(map[string]interface{}{
+				"phone_number":      verification.Target,
+				"phone_verified":    true,
+				"phone_verified_at": verifiedAt,
+			}).Error
+		default:
+			return nil
+		}
+	})
+}
+
+// CompletePasswordReset marks a pending password reset as used and sets the new password hash of its user in
+// one transaction, so a reset token can only change the password once. A reset required by an admin is
+// thereby done.
+func (r *UserVerificationRepository) CompletePasswordReset(verification *models.UserVerification, passwordHash string, verifiedAt time.Time) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := completePending(tx, verification, verifiedAt); err != nil {
+			return err
+		}
+		return tx.Model(&models.User{}).Where("id = ?", verification.UserID).
+			Updates(map[string]interface{}{"password": passwordHash, "password_reset_required": false}).Error
+	})
+}
+
+// completePending marks a verification as verified if it is still pending, or returns ErrVerificationNotPending.
+func completePending(tx *gorm.DB, verification *models.UserVerification, verifiedAt time.Time) error {
+	result := tx.Model(&models.UserVerification{}).
+		Where("id = ? AND verification_status = ?", verification.ID, models.VerificationPending).
+		Updates(map[string]interface{}{"verification_status": models.VerificationVerified, "verified_at": verifiedAt})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVerificationNotPending
+	}
+	verification.VerificationStatus = models.VerificationVerified
+	verification.VerifiedAt = verifiedAt
+	return nil
+}
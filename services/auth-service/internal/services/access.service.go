@@ -0,0 +1,317 @@
+package services
+
+import (
+	"auth-service/internal/api/dto"
+	"auth-service/internal/models"
+	"auth-service/internal/repository"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Errors returned by the access control service so handlers can map them to status codes.
+var (
+	ErrRoleNotFound      = errors.New("role not found")
+	ErrRoleExists        = errors.New("a role with the given name already exists")
+	ErrInvalidRoleName   = errors.New("role names may only contain lowercase letters, digits, - and _")
+	ErrUnknownPermission = errors.New("unknown permission")
+	ErrSystemRole        = errors.New("system roles cannot be deleted and the admin role always has every permission")
+	ErrRoleInUse         = errors.New("the role is the account role of some users")
+	ErrRoleNotAssigned   = errors.New("the user does not have the role")
+	ErrRoleUserNotFound  = errors.New("user not found")
+	ErrReservedRole      = errors.New("the service role is reserved for service clients")
+)
+
+// defaultRoles are created when auth-service starts, unless they exist. Admins can change them afterwards.
+var defaultRoles = []models.Role{
+	{Name: models.RoleAdmin, Description: "Manages every resource", System: true},
+	{Name: models.RoleCustomer, Description: "Books trips for themselves", System: true},
+	{Name: "dispatcher", Description: "Plans schedules and follows trips", Permissions: rolePermissions("dispatcher",
+		models.PermissionSchedulesManage, models.PermissionManifestRead, models.PermissionSeatsUpdate)},
+	{Name: "conductor", Description: "Boards passengers", Permissions: rolePermissions("conductor",
+		models.PermissionManifestRead, models.PermissionSeatsUpdate)},
+	{Name: "mechanic", Description: "Maintains the fleet", Permissions: rolePermissions("mechanic",
+		models.PermissionFleetMaintain)},
+	{Name: "support", Description: "Helps customers with their accounts and bookings", Permissions: rolePermissions("support",
+		models.PermissionUsersRead, models.PermissionUsersUnlock, models.PermissionBookingsRead, models.PermissionReviewsModerate)},
+}
+
+// IAccessControlService defines the interface for roles, their permissions and the roles of users.
+type IAccessControlService interface {
+	SeedDefaultRoles() error
+	ListPermissions() []dto.PermissionResponse
+	ListRoles() ([]dto.RoleResponse, error)
+	CreateRole(req dto.CreateRoleRequest, actor dto.Actor) (*dto.RoleResponse, error)
+	UpdateRole(name string, req dto.UpdateRoleRequest, actor dto.Actor) (*dto.RoleResponse, error)
+	DeleteRole(name string, actor dto.Actor) error
+	GetUserRoles(userID uint) (*dto.UserRolesResponse, error)
+	AssignRole(userID uint, req dto.AssignRoleRequest, actor dto.Actor) (*dto.UserRolesResponse, error)
+	UnassignRole(userID uint, role string, actor dto.Actor) (*dto.UserRolesResponse, error)
+}
+
+// AccessControlService manages roles and the permissions granted to them. Besides the role of their account,
+// users can be given any number of roles, and have the permissions of all of them.
+type AccessControlService struct {
+	roleRepo     repository.IRoleRepository
+	userRepo     repository.IUserRepository
+	auditService IAuditService
+}
+
+// NewAccessControlService creates a new instance of AccessControlService.
+func NewAccessControlService(roleRepo repository.IRoleRepository, userRepo repository.IUserRepository, auditService IAuditService) IAccessControlService {
+	return &AccessControlService{
+		roleRepo:     roleRepo,
+		userRepo:     userRepo,
+		auditService: auditService,
+	}
+}
+
+// SeedDefaultRoles creates the system roles and the default operational roles that do not exist yet.
+func (s *AccessControlService) SeedDefaultRoles() error {
+	for _, role := range defaultRoles {
+		role := role
+		if err := s.roleRepo.SeedRole(&role); err != nil {
+			return fmt.Errorf("failed to create role %s: %w", role.Name, err)
+		}
+	}
+	return nil
+}
+
+// ListPermissions lists every permission that can be granted.
+func (s *AccessControlService) ListPermissions() []dto.PermissionResponse {
+	responses := make([]dto.PermissionResponse, 0, len(models.PermissionDescriptions))
+	for name, description := range models.PermissionDescriptions {
+		responses = append(responses, dto.PermissionResponse{Name: name, Description: description})
+	}
+	sort.Slice(responses, func(i, j int) bool { return responses[i].Name < responses[j].Name })
+	return responses
+}
+
+// ListRoles lists every role with its permissions.
+func (s *AccessControlService) ListRoles() ([]dto.RoleResponse, error) {
+	roles, err := s.roleRepo.ListRoles()
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]dto.RoleResponse, 0, len(roles))
+	for _, role := range roles {
+		responses = append(responses, dto.FromRoleModel(role))
+	}
+	return responses, nil
+}
+
+// CreateRole creates a role granting the given permissions.
+func (s *AccessControlService) CreateRole(req dto.CreateRoleRequest, actor dto.Actor) (*dto.RoleResponse, error) {
+	if !validRoleName(req.Name) {
+		return nil, ErrInvalidRoleName
+	}
+	if req.Name == RoleService {
+		return nil, ErrReservedRole
+	}
+	if err := validatePermissions(req.Permissions); err != nil {
+		return nil, err
+	}
+	if _, err := s.roleRepo.FindRole(req.Name); err == nil {
+		return nil, ErrRoleExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	role := models.Role{
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: rolePermissions(req.Name, uniqueStrings(req.Permissions)...),
+	}
+	if err := s.roleRepo.CreateRole(&role); err != nil {
+		return nil, err
+	}
+	response := dto.FromRoleModel(role)
+	s.auditService.Record(actor, AuditRoleCreated, AuditTargetRole, role.Name, auditDiff(nil, roleAuditFields(response)))
+	return &response, nil
+}
+
+// UpdateRole replaces the description and permissions of a role. Users get the new permissions with their
+// next access token.
+func (s *AccessControlService) UpdateRole(name string, req dto.UpdateRoleRequest, actor dto.Actor) (*dto.RoleResponse, error) {
+	if name == models.RoleAdmin {
+		return nil, ErrSystemRole
+	}
+	if err := validatePermissions(req.Permissions); err != nil {
+		return nil, err
+	}
+	existing, err := s.roleRepo.FindRole(name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+
+	role := models.Role{
+		Name:        name,
+		Description: req.Description,
+		Permissions: rolePermissions(name, uniqueStrings(req.Permissions)...),
+	}
+	if err := s.roleRepo.UpdateRole(&role); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+
+	updated, err := s.roleRepo.FindRole(name)
+	if err != nil {
+		return nil, err
+	}
+	response := dto.FromRoleModel(*updated)
+	s.auditService.Record(actor, AuditRoleUpdated, AuditTargetRole, name,
+		auditDiff(roleAuditFields(dto.FromRoleModel(*existing)), roleAuditFields(response)))
+	return &response, nil
+}
+
+// DeleteRole deletes a role and takes it away from the users it was given to. Roles that are the account
+// role of some users cannot be deleted.
+func (s *AccessControlService) DeleteRole(name string, actor dto.Actor) error {
+	role, err := s.roleRepo.FindRole(name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRoleNotFound
+		}
+		return err
+	}
+	if role.System {
+		return ErrSystemRole
+	}
+	accounts, err := s.roleRepo.CountAccountsWithRole(name)
+	if err != nil {
+		return err
+	}
+	if accounts > 0 {
+		return ErrRoleInUse
+	}
+	if err := s.roleRepo.DeleteRole(name); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRoleNotFound
+		}
+		return err
+	}
+	s.auditService.Record(actor, AuditRoleDeleted, AuditTargetRole, name, auditDiff(roleAuditFields(dto.FromRoleModel(*role)), nil))
+	return nil
+}
+
+// GetUserRoles lists the roles of a user and the permissions they have through them.
+func (s *AccessControlService) GetUserRoles(userID uint) (*dto.UserRolesResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleUserNotFound
+		}
+		return nil, err
+	}
+	roles, permissions, err := resolveAccess(s.roleRepo, *user)
+	if err != nil {
+		return nil, err
+	}
+	if containsString(roles, models.RoleAdmin) {
+		permissions = make([]string, 0, len(models.PermissionDescriptions))
+		for permission := range models.PermissionDescriptions {
+			permissions = append(permissions, permission)
+		}
+		sort.Strings(permissions)
+	}
+	return &dto.UserRolesResponse{
+		UserID:      user.ID,
+		AccountRole: user.Role,
+		Roles:       roles[1:],
+		Permissions: permissions,
+	}, nil
+}
+
+// AssignRole gives a user a role in addition to the role of their account.
+func (s *AccessControlService) AssignRole(userID uint, req dto.AssignRoleRequest, actor dto.Actor) (*dto.UserRolesResponse, error) {
+	// Users acting as a service would pass the checks other services make on service clients
+	if req.Role == RoleService {
+		return nil, ErrReservedRole
+	}
+	if _, err := s.userRepo.FindByID(userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleUserNotFound
+		}
+		return nil, err
+	}
+	if _, err := s.roleRepo.FindRole(req.Role); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+	if err := s.roleRepo.AssignRole(&models.UserRole{UserID: userID, RoleName: req.Role, GrantedBy: actor.UserID}); err != nil {
+		return nil, err
+	}
+	s.auditService.Record(actor, AuditUserRoleAssigned, AuditTargetUser, userID, auditChange("roles", nil, req.Role))
+	return s.GetUserRoles(userID)
+}
+
+// UnassignRole takes a role given in addition away from a user. The role of the account is kept.
+func (s *AccessControlService) UnassignRole(userID uint, role string, actor dto.Actor) (*dto.UserRolesResponse, error) {
+	if err := s.roleRepo.UnassignRole(userID, role); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleNotAssigned
+		}
+		return nil, err
+	}
+	s.auditService.Record(actor, AuditUserRoleUnassigned, AuditTargetUser, userID, auditChange("roles", role, nil))
+	return s.GetUserRoles(userID)
+}
+
+// roleAuditFields returns the fields of a role recorded in the audit log when it changes.
+func roleAuditFields(role dto.RoleResponse) map[string]interface{} {
+	return map[string]interface{}{"description": role.Description, "permissions": role.Permissions}
+}
+
+// resolveAccess returns the roles of a user, starting with the role of their account, and the permissions they
+// have through them. Admins have every permission, so theirs are not listed.
+func resolveAccess(roleRepo repository.IRoleRepository, user models.User) ([]string, []string, error) {
+	userRoles, err := roleRepo.FindUserRoles(user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	roles := []string{user.Role}
+	for _, userRole := range userRoles {
+		if !containsString(roles, userRole.RoleName) {
+			roles = append(roles, userRole.RoleName)
+		}
+	}
+	if containsString(roles, models.RoleAdmin) {
+		return roles, nil, nil
+	}
+	permissions, err := roleRepo.FindPermissions(roles)
+	if err != nil {
+		return nil, nil, err
+	}
+	return roles, permissions, nil
+}
+
+func rolePermissions(role string, permissions ...string) []models.RolePermission {
+	grants := make([]models.RolePermission, 0, len(permissions))
+	for _, permission := range permissions {
+		grants = append(grants, models.RolePermission{RoleName: role, Permission: permission})
+	}
+	return grants
+}
+
+func validatePermissions(permissions []string) error {
+	for _, permission := range permissions {
+		if _, ok := models.PermissionDescriptions[permission]; !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownPermission, permission)
+		}
+	}
+	return nil
+}
+
+func validRoleName(name string) bool {
+	return name != "" && strings.Trim(name, "abcdefghijklmnopqrstuvwxyz0123456789-_") == ""
+}
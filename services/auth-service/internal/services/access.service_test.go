@@ -0,0 +1,83 @@
+package services
+
+import (
+	"auth-service/internal/api/dto"
+	"auth-service/internal/models"
+	"auth-service/internal/repository"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// fakeRoleRepository holds the extra roles of a single user and the permissions of each role.
+type fakeRoleRepository struct {
+	repository.IRoleRepository
+	userRoles   []models.UserRole
+	permissions map[string][]string
+}
+
+func (r *fakeRoleRepository) FindUserRoles(userID uint) ([]models.UserRole, error) {
+	return r.userRoles, nil
+}
+
+func (r *fakeRoleRepository) FindPermissions(roles []string) ([]string, error) {
+	var permissions []string
+	for _, role := range roles {
+		for _, permission := range r.permissions[role] {
+			if !containsString(permissions, permission) {
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	return permissions, nil
+}
+
+func TestResolveAccess(t *testing.T) {
+	repo := &fakeRoleRepository{permissions: map[string][]string{
+		"conductor": {models.PermissionManifestRead, models.PermissionSeatsUpdate},
+		"support":   {models.PermissionUsersRead, models.PermissionManifestRead},
+	}}
+	tests := []struct {
+		name            string
+		role            string
+		extra           []string
+		wantRoles       []string
+		wantPermissions []string
+	}{
+		{name: "customer", role: models.RoleCustomer, wantRoles: []string{models.RoleCustomer}},
+		{name: "account role", role: "conductor", wantRoles: []string{"conductor"},
+			wantPermissions: []string{models.PermissionManifestRead, models.PermissionSeatsUpdate}},
+		{name: "extra roles", role: models.RoleCustomer, extra: []string{"conductor", "support", models.RoleCustomer},
+			wantRoles:       []string{models.RoleCustomer, "conductor", "support"},
+			wantPermissions: []string{models.PermissionManifestRead, models.PermissionSeatsUpdate, models.PermissionUsersRead}},
+		// Admins have every permission, which the token does not list
+		{name: "extra admin role", role: models.RoleCustomer, extra: []string{models.RoleAdmin}, wantRoles: []string{models.RoleCustomer, models.RoleAdmin}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo.userRoles = nil
+			for _, role := range tt.extra {
+				repo.userRoles = append(repo.userRoles, models.UserRole{UserID: 5, RoleName: role})
+			}
+
+			roles, permissions, err := resolveAccess(repo, models.User{Role: tt.role})
+			if err != nil {
+				t.Fatalf("resolveAccess() error = %v", err)
+			}
+			if !reflect.DeepEqual(roles, tt.wantRoles) || !reflect.DeepEqual(permissions, tt.wantPermissions) {
+				t.Errorf("resolveAccess() = %v, %v, want %v, %v", roles, permissions, tt.wantRoles, tt.wantPermissions)
+			}
+		})
+	}
+}
+
+func TestServiceRoleIsReserved(t *testing.T) {
+	service := &AccessControlService{roleRepo: &fakeRoleRepository{}}
+
+	if _, err := service.CreateRole(dto.CreateRoleRequest{Name: RoleService}, dto.Actor{UserID: 1}); !errors.Is(err, ErrReservedRole) {
+		t.Errorf("CreateRole() error = %v, want %v", err, ErrReservedRole)
+	}
+	if _, err := service.AssignRole(7, dto.AssignRoleRequest{Role: RoleService}, dto.Actor{UserID: 1}); !errors.Is(err, ErrReservedRole) {
+		t.Errorf("AssignRole() error = %v, want %v", err, ErrReservedRole)
+	}
+}
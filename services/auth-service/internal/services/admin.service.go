@@ -0,0 +1,247 @@
+package services
+
+import (
+	"auth-service/internal/api/dto"
+	"auth-service/internal/models"
+	"auth-service/internal/repository"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Errors returned by the user administration service so handlers can map them to status codes.
+var (
+	ErrUserNotFound    = errors.New("user not found")
+	ErrUserNotDeleted  = errors.New("user is not deleted")
+	ErrUserDeleted     = errors.New("user is deleted, restore it first")
+	ErrOwnAccount      = errors.New("admins cannot disable their own account or change its role")
+	ErrUserNotDisabled = errors.New("user is not disabled")
+)
+
+// adminLoginHistoryLimit is the number of latest login attempts shown with a user.
+const adminLoginHistoryLimit = 20
+
+type IUserAdminService interface {
+	ListUsers(query dto.ListUsersQuery) (*dto.UserListResponse, error)
+	GetUser(userID uint) (*dto.AdminUserResponse, error)
+	DisableUser(userID uint, req dto.DisableUserRequest, actor dto.Actor) (*dto.AdminUserResponse, error)
+	EnableUser(userID uint, actor dto.Actor) (*dto.AdminUserResponse, error)
+	ChangeRole(userID uint, req dto.ChangeUserRoleRequest, actor dto.Actor) (*dto.AdminUserResponse, error)
+	ForcePasswordReset(userID uint, actor dto.Actor) error
+	RestoreUser(userID uint, actor dto.Actor) (*dto.AdminUserResponse, error)
+}
+
+// UserAdminService lets admins and support find accounts and act on them.
+type UserAdminService struct {
+	userRepo            repository.IUserRepository
+	sessionRepo         repository.ISessionRepository
+	loginHistoryRepo    repository.ILoginHistoryRepository
+	verificationRepo    repository.IUserVerificationRepository
+	roleRepo            repository.IRoleRepository
+	verificationService IUserVerificationService
+	erasureService      IAccountErasureService
+	auditService        IAuditService
+}
+
+// NewUserAdminService creates a new instance of UserAdminService.
+func NewUserAdminService(userRepo repository.IUserRepository, sessionRepo repository.ISessionRepository, loginHistoryRepo repository.ILoginHistoryRepository, verificationRepo repository.IUserVerificationRepository, roleRepo repository.IRoleRepository, verificationService IUserVerificationService, erasureService IAccountErasureService, auditService IAuditService) IUserAdminService {
+	return &UserAdminService{
+		userRepo:            userRepo,
+		sessionRepo:         sessionRepo,
+		loginHistoryRepo:    loginHistoryRepo,
+		verificationRepo:    verificationRepo,
+		roleRepo:            roleRepo,
+		verificationService: verificationService,
+		erasureService:      erasureService,
+		auditService:        auditService,
+	}
+}
+
+// ListUsers retrieves a page of the accounts matching query in the order they were created.
+func (s *UserAdminService) ListUsers(query dto.ListUsersQuery) (*dto.UserListResponse, error) {
+	limit := query.Limit
+	if limit == 0 {
+		limit = 20
+	}
+	filter := repository.UserFilter{
+		Search:   query.Search,
+		Role:     query.Role,
+		Status:   query.Status,
+		Verified: query.Verified,
+	}
+	users, total, err := s.userRepo.List(filter, time.Now(), query.Offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.UserListResponse{Users: make([]dto.AdminUserSummary, 0, len(users)), Total: total}
+	for _, user := range users {
+		response.Users = append(response.Users, dto.FromUserModelForAdmin(user))
+	}
+	return response, nil
+}
+
+// GetUser retrieves an account, deleted or not, with its verifications and latest login attempts.
+func (s *UserAdminService) GetUser(userID uint) (*dto.AdminUserResponse, error) {
+	user, err := s.userRepo.FindByIDUnscoped(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return s.userDetails(*user)
+}
+
+// DisableUser refuses logins to an account and ends its sessions until it is enabled again.
+func (s *UserAdminService) DisableUser(userID uint, req dto.DisableUserRequest, actor dto.Actor) (*dto.AdminUserResponse, error) {
+	if userID == actor.UserID {
+		return nil, ErrOwnAccount
+	}
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.userRepo.SetDisabled(userID, &now, req.Reason); err != nil {
+		return nil, err
+	}
+	s.auditService.Record(actor, AuditUserDisabled, AuditTargetUser, userID, auditDiff(
+		map[string]interface{}{"disabledAt": user.DisabledAt, "disabledReason": user.DisabledReason},
+		map[string]interface{}{"disabledAt": now, "disabledReason": req.Reason},
+	))
+	if err := s.sessionRepo.RevokeUserSessions(userID, 0, RevokedByAccountDisable, now); err != nil {
+		return nil, err
+	}
+	return s.GetUser(userID)
+}
+
+// EnableUser allows logins to a disabled account again.
+func (s *UserAdminService) EnableUser(userID uint, actor dto.Actor) (*dto.AdminUserResponse, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsDisabled() {
+		return nil, ErrUserNotDisabled
+	}
+	if err := s.userRepo.SetDisabled(userID, nil, ""); err != nil {
+		return nil, err
+	}
+	s.auditService.Record(actor, AuditUserEnabled, AuditTargetUser, userID, auditDiff(
+		map[string]interface{}{"disabledAt": user.DisabledAt, "disabledReason": user.DisabledReason},
+		map[string]interface{}{"disabledAt": nil, "disabledReason": ""},
+	))
+	return s.GetUser(userID)
+}
+
+// ChangeRole changes the role of an account and ends its sessions, so the user signs in again with the
+// permissions of the new role.
+func (s *UserAdminService) ChangeRole(userID uint, req dto.ChangeUserRoleRequest, actor dto.Actor) (*dto.AdminUserResponse, error) {
+	if userID == actor.UserID {
+		return nil, ErrOwnAccount
+	}
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == req.Role {
+		return s.userDetails(*user)
+	}
+
+	if err := s.userRepo.UpdateRole(userID, req.Role); err != nil {
+		return nil, err
+	}
+	s.auditService.Record(actor, AuditUserRoleChanged, AuditTargetUser, userID, auditChange("role", user.Role, req.Role))
+	if err := s.sessionRepo.RevokeUserSessions(userID, 0, RevokedByRoleChange, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.GetUser(userID)
+}
+
+// ForcePasswordReset ends the sessions of an account and refuses logins to it until the user sets a new
+// password with the reset link emailed to them.
+func (s *UserAdminService) ForcePasswordReset(userID uint, actor dto.Actor) error {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.RequirePasswordReset(userID); err != nil {
+		return err
+	}
+	s.auditService.Record(actor, AuditUserPasswordResetForced, AuditTargetUser, userID, auditChange("passwordResetRequired", user.PasswordResetRequired, true))
+	if err := s.sessionRepo.RevokeUserSessions(userID, 0, RevokedByForcedReset, time.Now()); err != nil {
+		return err
+	}
+	return s.verificationService.SendPasswordReset(*user)
+}
+
+// RestoreUser undoes the deletion of an account and cancels the erasure of its data. Its sessions ended when it
+// was deleted. Accounts whose data is being, or was, erased cannot be restored.
+func (s *UserAdminService) RestoreUser(userID uint, actor dto.Actor) (*dto.AdminUserResponse, error) {
+	if err := s.erasureService.Cancel(userID); err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.Restore(userID); err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if _, findErr := s.findUser(userID); findErr != nil {
+			return nil, ErrUserNotFound
+		}
+		return nil, ErrUserNotDeleted
+	}
+	s.auditService.Record(actor, AuditUserRestored, AuditTargetUser, userID, nil)
+	return s.GetUser(userID)
+}
+
+// findUser retrieves an account that is not deleted, reporting deleted ones with ErrUserDeleted.
+func (s *UserAdminService) findUser(userID uint) (*models.User, error) {
+	user, err := s.userRepo.FindByIDUnscoped(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if user.DeletedAt.Valid {
+		return nil, ErrUserDeleted
+	}
+	return user, nil
+}
+
+// userDetails adds the roles, verifications and latest login attempts of user.
+func (s *UserAdminService) userDetails(user models.User) (*dto.AdminUserResponse, error) {
+	userRoles, err := s.roleRepo.FindUserRoles(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	verifications, err := s.verificationRepo.FindVerificationsByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	histories, err := s.loginHistoryRepo.GetRecentHistory(user.ID, adminLoginHistoryLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.AdminUserResponse{
+		AdminUserSummary:    dto.FromUserModelForAdmin(user),
+		FailedLoginAttempts: user.FailedLoginAttempts,
+		Roles:               make([]string, 0, len(userRoles)),
+		Verifications:       make([]dto.UserVerificationResponse, 0, len(verifications)),
+		LoginHistory:        make([]dto.LoginHistoryResponse, 0, len(histories)),
+	}
+	for _, userRole := range userRoles {
+		response.Roles = append(response.Roles, userRole.RoleName)
+	}
+	for _, verification := range verifications {
+		response.Verifications = append(response.Verifications, dto.FromVerificationModel(*verification))
+	}
+	for _, history := range histories {
+		response.LoginHistory = append(response.LoginHistory, dto.FromHistoryModel(history))
+	}
+	return response, nil
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"auth-service/internal/api/dto"
+	"auth-service/internal/models"
+	"auth-service/internal/repository"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// fakeAdminUserRepository finds a single user, deleted or not, and records the changes made to it.
+type fakeAdminUserRepository struct {
+	fakeUserRepository
+	disabledAt *time.Time
+	role       string
+}
+
+func (r *fakeAdminUserRepository) FindByIDUnscoped(userID uint) (*models.User, error) {
+	if r.user == nil || r.user.ID != userID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.user, nil
+}
+
+func (r *fakeAdminUserRepository) SetDisabled(userID uint, disabledAt *time.Time, reason string) error {
+	r.disabledAt = disabledAt
+	return nil
+}
+
+func (r *fakeAdminUserRepository) UpdateRole(userID uint, role string) error {
+	r.role = role
+	return nil
+}
+
+type fakeAdminVerificationRepository struct {
+	repository.IUserVerificationRepository
+}
+
+func (r *fakeAdminVerificationRepository) FindVerificationsByUserID(userID uint) ([]*models.UserVerification, error) {
+	return nil, nil
+}
+
+func (r *fakeLoginHistoryRepository) GetRecentHistory(userID uint, limit int) ([]models.LoginHistory, error) {
+	return r.recorded, nil
+}
+
+// newAdminService returns a UserAdminService managing user 5, deleted when deleted is true, for admin 1.
+func newAdminService(deleted bool) (*UserAdminService, *fakeAdminUserRepository, *fakeSessionRepository, *fakeAuditService) {
+	user := &models.User{Username: "ann", Role: models.RoleCustomer}
+	user.ID = 5
+	if deleted {
+		user.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	}
+	users := &fakeAdminUserRepository{fakeUserRepository: fakeUserRepository{user: user}}
+	sessions := &fakeSessionRepository{}
+	audit := &fakeAuditService{}
+	return &UserAdminService{
+		userRepo:         users,
+		sessionRepo:      sessions,
+		loginHistoryRepo: &fakeLoginHistoryRepository{},
+		verificationRepo: &fakeAdminVerificationRepository{},
+		roleRepo:         &fakeRoleRepository{},
+		auditService:     audit,
+	}, users, sessions, audit
+}
+
+func TestDisableUser(t *testing.T) {
+	tests := []struct {
+		name    string
+		userID  uint
+		deleted bool
+		want    error
+	}{
+		{name: "active user", userID: 5},
+		{name: "own account", userID: 1, want: ErrOwnAccount},
+		{name: "deleted user", userID: 5, deleted: true, want: ErrUserDeleted},
+		{name: "unknown user", userID: 6, want: ErrUserNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, users, sessions, audit := newAdminService(tt.deleted)
+
+			_, err := service.DisableUser(tt.userID, dto.DisableUserRequest{Reason: "fraud"}, dto.Actor{UserID: 1})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("DisableUser() error = %v, want %v", err, tt.want)
+			}
+			if disabled := users.disabledAt != nil; disabled != (tt.want == nil) {
+				t.Errorf("user disabled = %v, want %v", disabled, tt.want == nil)
+			}
+			// The user is signed out everywhere
+			if signedOut := len(sessions.kept) == 1 && sessions.kept[0] == 0; signedOut != (tt.want == nil) {
+				t.Errorf("sessions kept = %v", sessions.kept)
+			}
+			if audited := len(audit.actions) == 1 && audit.actions[0] == AuditUserDisabled && audit.actors[0] == 1; audited != (tt.want == nil) {
+				t.Errorf("audited %v by %v", audit.actions, audit.actors)
+			}
+		})
+	}
+}
+
+func TestChangeRoleSignsTheUserOut(t *testing.T) {
+	service, users, sessions, _ := newAdminService(false)
+
+	response, err := service.ChangeRole(5, dto.ChangeUserRoleRequest{Role: "conductor"}, dto.Actor{UserID: 1})
+	if err != nil {
+		t.Fatalf("ChangeRole() error = %v", err)
+	}
+	if users.role != "conductor" || response == nil {
+		t.Errorf("role changed to %q, want conductor", users.role)
+	}
+	if len(sessions.kept) != 1 || sessions.kept[0] != 0 {
+		t.Errorf("sessions kept = %v, want the user signed out everywhere", sessions.kept)
+	}
+
+	// Admins cannot take their own admin role away
+	if _, err := service.ChangeRole(1, dto.ChangeUserRoleRequest{Role: models.RoleCustomer}, dto.Actor{UserID: 1}); !errors.Is(err, ErrOwnAccount) {
+		t.Errorf("ChangeRole() of the own account error = %v, want %v", err, ErrOwnAccount)
+	}
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"auth-service/internal/geoip"
+	"auth-service/internal/models"
+	"auth-service/internal/repository"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// securityAlertNotification is the notification-service type of alerts about anomalous logins.
+	securityAlertNotification = "security_alert"
+	// maxTravelSpeedKmh is the fastest a user can travel between two logins, about the speed of an airliner.
+	maxTravelSpeedKmh = 1000.0
+	// minTravelDistanceKm ignores distances within the accuracy of IP geolocation.
+	minTravelDistanceKm = 300.0
+	// loginHourSample is how many of the latest successful logins tell the hours a user signs in at.
+	loginHourSample = 50
+	// minLoginHourSample is how many successful logins are needed before hours are considered unusual.
+	minLoginHourSample = 10
+)
+
+// ILoginAnomalyService defines the interface for detecting anomalous logins and alerting users about them.
+type ILoginAnomalyService interface {
+	Analyze(history *models.LoginHistory) error
+	Alert(user models.User, history models.LoginHistory) error
+}
+
+// LoginAnomalyService compares successful logins with the earlier logins of the user to detect new devices,
+// impossible travel and unusual hours.
+type LoginAnomalyService struct {
+	loginHistoryRepo    repository.ILoginHistoryRepository
+	notificationService INotificationService
+	geoDB               *geoip.Database // Nil when no GeoIP database is configured
+}
+
+// NewLoginAnomalyService creates a new instance of LoginAnomalyService. Without a GeoIP database, logins are
+// not located and impossible travel is not detected.
+func NewLoginAnomalyService(loginHistoryRepo repository.ILoginHistoryRepository, notificationService INotificationService, geoDB *geoip.Database) ILoginAnomalyService {
+	return &LoginAnomalyService{
+		loginHistoryRepo:    loginHistoryRepo,
+		notificationService: notificationService,
+		geoDB:               geoDB,
+	}
+}
+
+// Analyze fingerprints the device and locates the address of a successful login, then records on it the
+// anomalies found by comparing it with the user's earlier logins. The first login of a user has none.
+func (s *LoginAnomalyService) Analyze(history *models.LoginHistory) error {
+	history.DeviceFingerprint = deviceFingerprint(history.DeviceInformation)
+	if s.geoDB != nil {
+		if location, ok := s.geoDB.Lookup(history.IPAddress); ok {
+			history.Country = location.Country
+			history.Latitude = &location.Latitude
+			history.Longitude = &location.Longitude
+		}
+	}
+
+	previous, err := s.loginHistoryRepo.GetRecentSuccessfulLogins(history.UserID, loginHourSample)
+	if err != nil {
+		return err
+	}
+	if len(previous) == 0 {
+		return nil
+	}
+
+	var anomalies []string
+	known, err := s.loginHistoryRepo.HasLoggedInFromDevice(history.UserID, history.DeviceFingerprint)
+	if err != nil {
+		return err
+	}
+	if !known {
+		anomalies = append(anomalies, models.LoginAnomalyNewDevice)
+	}
+	if impossibleTravel(previous[0], *history) {
+		anomalies = append(anomalies, models.LoginAnomalyImpossibleTravel)
+	}
+	if unusualHour(previous, history.LoginTime) {
+		anomalies = append(anomalies, models.LoginAnomalyUnusualHour)
+	}
+	history.Anomalies = strings.Join(anomalies, " ")
+	return nil
+}
+
+// Alert emails the user about an anomalous login so they can secure their account if it was not them.
+func (s *LoginAnomalyService) Alert(user models.User, history models.LoginHistory) error {
+	if history.Anomalies == "" {
+		return nil
+	}
+	place := history.IPAddress
+	if history.Country != "" {
+		place = fmt.Sprintf("%s (%s)", history.IPAddress, history.Country)
+	}
+	content := fmt.Sprintf("Hi %s, there was a sign-in to your account on %s from %s using %s that looks unusual: %s. "+
+		"If this was not you, reset your password and sign out of your other sessions.",
+		user.Username, history.LoginTime.Format(time.RFC1123), place, history.DeviceInformation,
+		strings.ReplaceAll(history.Anomalies, " ", ", "))
+	return s.notificationService.SendNotification(user.ID, securityAlertNotification, NotificationChannelEmail, content)
+}
+
+// deviceFingerprint identifies a device by the hash of its normalized user agent.
+func deviceFingerprint(userAgent string) string {
+	normalized := strings.ToLower(strings.Join(strings.Fields(userAgent), " "))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// impossibleTravel reports whether the user could not have travelled from the location of the previous login to
+// that of the current one in the time between them. Logins that were not located are never impossible.
+func impossibleTravel(previous, current models.LoginHistory) bool {
+	if previous.Latitude == nil || previous.Longitude == nil || current.Latitude == nil || current.Longitude == nil {
+		return false
+	}
+	from := geoip.Location{Latitude: *previous.Latitude, Longitude: *previous.Longitude}
+	to := geoip.Location{Latitude: *current.Latitude, Longitude: *current.Longitude}
+	distance := from.DistanceKm(to)
+	if distance < minTravelDistanceKm {
+		return false
+	}
+	hours := current.LoginTime.Sub(previous.LoginTime).Hours()
+	return hours <= 0 || distance/hours > maxTravelSpeedKmh
+}
+
+// unusualHour reports whether none of the previous logins was within an hour of the time of day of at, in UTC.
+// Users with few logins have no usual hours yet.
+func unusualHour(previous []models.LoginHistory, at time.Time) bool {
+	if len(previous) < minLoginHourSample {
+		return false
+	}
+	hour := at.UTC().Hour()
+	for _, history := range previous {
+		diff := history.LoginTime.UTC().Hour() - hour
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff <= 1 || diff == 23 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"auth-service/internal/models"
+	"testing"
+	"time"
+)
+
+// locatedLogin returns a login at loginTime located at latitude and longitude.
+func locatedLogin(loginTime time.Time, latitude, longitude float64) models.LoginHistory {
+	return models.LoginHistory{LoginTime: loginTime, Latitude: &latitude, Longitude: &longitude}
+}
+
+func TestImpossibleTravel(t *testing.T) {
+	at := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	berlin := locatedLogin(at, 52.52, 13.405)
+
+	tests := []struct {
+		name    string
+		current models.LoginHistory
+		want    bool
+	}{
+		{name: "Berlin to New York in an hour", current: locatedLogin(at.Add(time.Hour), 40.7128, -74.006), want: true},
+		{name: "Berlin to New York in a day", current: locatedLogin(at.Add(24*time.Hour), 40.7128, -74.006)},
+		{name: "Berlin to Potsdam in a minute", current: locatedLogin(at.Add(time.Minute), 52.3906, 13.0645)},
+		{name: "Berlin to Munich at the same time", current: locatedLogin(at, 48.1351, 11.582), want: true},
+		{name: "Berlin to Munich before the previous login", current: locatedLogin(at.Add(-time.Hour), 48.1351, 11.582), want: true},
+		{name: "not located", current: models.LoginHistory{LoginTime: at.Add(time.Minute)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := impossibleTravel(berlin, tt.current); got != tt.want {
+				t.Errorf("impossibleTravel() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnusualHour(t *testing.T) {
+	day := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	loginsAt := func(n, hour int) []models.LoginHistory {
+		logins := make([]models.LoginHistory, n)
+		for i := range logins {
+			logins[i].LoginTime = day.AddDate(0, 0, i).Add(time.Duration(hour) * time.Hour)
+		}
+		return logins
+	}
+
+	tests := []struct {
+		name     string
+		previous []models.LoginHistory
+		at       time.Time
+		want     bool
+	}{
+		{name: "too few logins", previous: loginsAt(minLoginHourSample-1, 9), at: day.Add(3 * time.Hour)},
+		{name: "same hour", previous: loginsAt(minLoginHourSample, 9), at: day.Add(9*time.Hour + 30*time.Minute)},
+		{name: "an hour later", previous: loginsAt(minLoginHourSample, 9), at: day.Add(10 * time.Hour)},
+		{name: "three hours later", previous: loginsAt(minLoginHourSample, 9), at: day.Add(12 * time.Hour), want: true},
+		{name: "middle of the night", previous: loginsAt(minLoginHourSample, 9), at: day.Add(3 * time.Hour), want: true},
+		{name: "just after midnight", previous: loginsAt(minLoginHourSample, 23), at: day.Add(30 * time.Minute)},
+		{name: "an hour before midnight", previous: loginsAt(minLoginHourSample, 0), at: day.Add(23 * time.Hour)},
+		{name: "in another time zone", previous: loginsAt(minLoginHourSample, 9), at: day.Add(9 * time.Hour).In(time.FixedZone("JST", 9*60*60))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unusualHour(tt.previous, tt.at); got != tt.want {
+				t.Errorf("unusualHour() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package services
+
+import (
+	"auth-service/internal/api/dto"
+	"auth-service/internal/config"
+	"auth-service/internal/models"
+	"auth-service/internal/repository"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// apiKeyPrefix starts every API key, so leaked keys are easy to recognize.
+	apiKeyPrefix = "etk_"
+	// apiKeyDisplayLength is how many characters of a key are kept to identify it in listings.
+	apiKeyDisplayLength = len(apiKeyPrefix) + 8
+	// apiKeyLastUsedPrecision is how often the last use of a key is written.
+	apiKeyLastUsedPrecision = time.Minute
+)
+
+// Errors returned by the API key service so handlers can map them to status codes.
+var (
+	ErrAPIKeyNotFound      = errors.New("api key not found")
+	ErrAPIKeyRevoked       = errors.New("api key has been revoked or has expired")
+	ErrAPIKeyAdminAccount  = errors.New("api keys cannot be issued to admin accounts")
+	ErrAPIKeyExpiryInPast  = errors.New("api key expiry must be in the future")
+	ErrAPIKeyOwnerNotFound = errors.New("user not found")
+)
+
+// IAPIKeyService defines the interface for API keys of partner integrations.
+type IAPIKeyService interface {
+	CreateKey(userID uint, req dto.CreateAPIKeyRequest, actor dto.Actor) (*dto.APIKeyResponse, error)
+	ListKeys(userID uint) ([]dto.APIKeyResponse, error)
+	RotateKey(userID, keyID uint, actor dto.Actor) (*dto.RotateAPIKeyResponse, error)
+	RevokeKey(userID, keyID uint, actor dto.Actor) error
+	Introspect(key string) (dto.APIKeyIntrospectionResponse, error)
+}
+
+// APIKeyService issues API keys that partners' servers authenticate with instead of a user's password. A key
+// acts as the account it was issued to, limited to its scopes.
+type APIKeyService struct {
+	apiKeyRepo    repository.IAPIKeyRepository
+	userRepo      repository.IUserRepository
+	auditService  IAuditService
+	rotationGrace time.Duration
+}
+
+// NewAPIKeyService creates a new instance of APIKeyService.
+func NewAPIKeyService(apiKeyRepo repository.IAPIKeyRepository, userRepo repository.IUserRepository, auditService IAuditService) IAPIKeyService {
+	return &APIKeyService{
+		apiKeyRepo:    apiKeyRepo,
+		userRepo:      userRepo,
+		auditService:  auditService,
+		rotationGrace: config.GetDuration("API_KEY_ROTATION_GRACE", 24*time.Hour),
+	}
+}
+
+// CreateKey issues an API key for a user. The key is only returned now.
+func (s *APIKeyService) CreateKey(userID uint, req dto.CreateAPIKeyRequest, actor dto.Actor) (*dto.APIKeyResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAPIKeyOwnerNotFound
+		}
+		return nil, err
+	}
+	// Keys are stored by partners' servers and would hand out every permission of an admin if leaked.
+	if user.Role == models.RoleAdmin {
+		return nil, ErrAPIKeyAdminAccount
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, ErrAPIKeyExpiryInPast
+	}
+
+	key, apiKey, err := newAPIKey(models.APIKey{
+		UserID:    user.ID,
+		Name:      req.Name,
+		Scopes:    strings.Join(uniqueStrings(req.Scopes), " "),
+		CreatedBy: actor.UserID,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apiKeyRepo.Create(apiKey); err != nil {
+		return nil, err
+	}
+	s.auditService.Record(actor, AuditAPIKeyCreated, AuditTargetAPIKey, apiKey.ID, auditDiff(nil, map[string]interface{}{
+		"userId": apiKey.UserID, "name": apiKey.Name, "scopes": apiKey.Scopes, "expiresAt": apiKey.ExpiresAt,
+	}))
+
+	response := dto.FromAPIKeyModel(*apiKey)
+	response.Key = key
+	return &response, nil
+}
+
+// ListKeys lists the API keys issued to a user, including revoked and expired ones.
+func (s *APIKeyService) ListKeys(userID uint) ([]dto.APIKeyResponse, error) {
+	keys, err := s.apiKeyRepo.ListByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]dto.APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		responses = append(responses, dto.FromAPIKeyModel(key))
+	}
+	return responses, nil
+}
+
+// RotateKey issues a key with the name, scopes and expiry of keyID to replace it. The rotated key keeps
+// working for the rotation grace period so the integration can be switched over without downtime.
+func (s *APIKeyService) RotateKey(userID, keyID uint, actor dto.Actor) (*dto.RotateAPIKeyResponse, error) {
+	current, err := s.findUserKey(userID, keyID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !current.IsActive(now) {
+		return nil, ErrAPIKeyRevoked
+	}
+
+	key, next, err := newAPIKey(models.APIKey{
+		UserID:    current.UserID,
+		Name:      current.Name,
+		Scopes:    current.Scopes,
+		CreatedBy: current.CreatedBy,
+		ExpiresAt: current.ExpiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	previousExpiresAt := now.Add(s.rotationGrace)
+	if current.ExpiresAt != nil && current.ExpiresAt.Before(previousExpiresAt) {
+		previousExpiresAt = *current.ExpiresAt
+	}
+	if err := s.apiKeyRepo.Rotate(current.ID, previousExpiresAt, next); err != nil {
+		if errors.Is(err, repository.ErrAPIKeyRevoked) {
+			return nil, ErrAPIKeyRevoked
+		}
+		return nil, err
+	}
+	s.auditService.Record(actor, AuditAPIKeyRotated, AuditTargetAPIKey, current.ID, map[string]models.AuditChange{
+		"expiresAt":  {Before: current.ExpiresAt, After: previousExpiresAt},
+		"replacedBy": {After: next.ID},
+	})
+
+	response := dto.RotateAPIKeyResponse{
+		APIKeyResponse:       dto.FromAPIKeyModel(*next),
+		PreviousKeyExpiresAt: previousExpiresAt,
+	}
+	response.Key = key
+	return &response, nil
+}
+
+// RevokeKey revokes an API key of a user. Services accept it for at most as long as they cache introspections.
+func (s *APIKeyService) RevokeKey(userID, keyID uint, actor dto.Actor) error {
+	key, err := s.findUserKey(userID, keyID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if err := s.apiKeyRepo.Revoke(key.ID, now); err != nil {
+		if errors.Is(err, repository.ErrAPIKeyRevoked) {
+			return ErrAPIKeyRevoked
+		}
+		return err
+	}
+	s.auditService.Record(actor, AuditAPIKeyRevoked, AuditTargetAPIKey, key.ID, auditChange("revokedAt", key.RevokedAt, now))
+	return nil
+}
+
+// Introspect tells whether key is active and, if so, which account it acts as and with which scopes. Unknown,
+// revoked and expired keys and keys of deleted accounts are reported inactive rather than as errors.
+func (s *APIKeyService) Introspect(key string) (dto.APIKeyIntrospectionResponse, error) {
+	inactive := dto.APIKeyIntrospectionResponse{Active: false}
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return inactive, nil
+	}
+
+	apiKey, err := s.apiKeyRepo.FindByHash(hashToken(key))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return inactive, nil
+		}
+		return inactive, err
+	}
+	now := time.Now()
+	if !apiKey.IsActive(now) {
+		return inactive, nil
+	}
+	user, err := s.userRepo.FindByID(apiKey.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return inactive, nil
+		}
+		return inactive, err
+	}
+	// Owners promoted to admin since the key was issued lose it like disabled accounts do.
+	if user.IsDisabled() || user.Role == models.RoleAdmin {
+		return inactive, nil
+	}
+
+	// A failure to record the use should not fail the partner's request.
+	if err := s.apiKeyRepo.TouchLastUsed(apiKey.ID, now, now.Add(-apiKeyLastUsedPrecision)); err != nil {
+		log.Printf("Could not record use of API key %d: %v", apiKey.ID, err)
+	}
+
+	return dto.APIKeyIntrospectionResponse{
+		Active:   true,
+		KeyID:    apiKey.ID,
+		UserID:   user.ID,
+		Username: user.Username,
+		Role:     user.Role,
+		Scope:    apiKey.Scopes,
+	}, nil
+}
+
+// findUserKey retrieves an API key, reporting keys of other users as not found.
+func (s *APIKeyService) findUserKey(userID, keyID uint) (*models.APIKey, error) {
+	key, err := s.apiKeyRepo.FindByID(keyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	if key.UserID != userID {
+		return nil, ErrAPIKeyNotFound
+	}
+	return key, nil
+}
+
+// newAPIKey generates a key and fills in its hash and prefix on apiKey.
+func newAPIKey(apiKey models.APIKey) (string, *models.APIKey, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, err
+	}
+	key := apiKeyPrefix + hex.EncodeToString(b)
+	apiKey.KeyHash = hashToken(key)
+	apiKey.Prefix = key[:apiKeyDisplayLength]
+	return key, &apiKey, nil
+}
+
+// uniqueStrings returns values without duplicates, keeping their order.
+func uniqueStrings(values []string) []string {
+	unique := make([]string, 0, len(values))
+	for _, v := range values {
+		if !containsString(unique, v) {
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
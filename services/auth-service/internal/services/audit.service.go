@@ -0,0 +1,267 @@
+package services
+
+import (
+	"auth-service/internal/api/dto"
+	"auth-service/internal/models"
+	"auth-service/internal/repository"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Audited actions, named after their target and what happened to it.
+const (
+	AuditUserPasswordChanged         = "user.password_changed"
+	AuditUserPasswordReset           = "user.password_reset"
+	AuditUserPasswordResetForced     = "user.password_reset_forced"
+	AuditUserUnlocked                = "user.unlocked"
+	AuditUserDisabled                = "user.disabled"
+	AuditUserEnabled                 = "user.enabled"
+	AuditUserRoleChanged             = "user.role_changed"
+	AuditUserRoleAssigned            = "user.role_assigned"
+	AuditUserRoleUnassigned          = "user.role_unassigned"
+	AuditUserDeleted                 = "user.deleted"
+	AuditUserRestored                = "user.restored"
+	AuditUserSessionsRevoked         = "user.sessions_revoked"
+	AuditSessionRevoked              = "session.revoked"
+	AuditMFAEnabled                  = "mfa.enabled"
+	AuditMFADisabled                 = "mfa.disabled"
+	AuditMFARecoveryCodesRegenerated = "mfa.recovery_codes_regenerated"
+	AuditMFAPolicyChanged            = "mfa_policy.changed"
+	AuditRoleCreated                 = "role.created"
+	AuditRoleUpdated                 = "role.updated"
+	AuditRoleDeleted                 = "role.deleted"
+	AuditAPIKeyCreated               = "api_key.created"
+	AuditAPIKeyRotated               = "api_key.rotated"
+	AuditAPIKeyRevoked               = "api_key.revoked"
+	AuditOAuthClientRegistered       = "oauth_client.registered"
+	AuditOAuthClientDeleted          = "oauth_client.deleted"
+	AuditLogExported                 = "audit_log.exported"
+)
+
+// Types of the targets of audited actions.
+const (
+	AuditTargetUser        = "user"
+	AuditTargetSession     = "session"
+	AuditTargetRole        = "role"
+	AuditTargetMFAPolicy   = "mfa_policy"
+	AuditTargetAPIKey      = "api_key"
+	AuditTargetOAuthClient = "oauth_client"
+	AuditTargetAuditLog    = "audit_log"
+)
+
+const (
+	// defaultAuditPageSize is the number of audit events listed when no limit is given.
+	defaultAuditPageSize = 50
+	// auditBatchSize is how many audit events are read at a time when exporting or verifying the log.
+	auditBatchSize = 500
+)
+
+// auditCSVHeader is the first row of audit log exports in CSV.
+var auditCSVHeader = []string{"id", "createdAt", "actorID", "action", "targetType", "targetID", "ipAddress", "userAgent", "changes", "prevHash", "hash"}
+
+// IAuditService defines the interface for the security audit log.
+type IAuditService interface {
+	Record(actor dto.Actor, action, targetType string, targetID interface{}, changes map[string]models.AuditChange)
+	ListEvents(query dto.AuditEventQuery) (*dto.AuditEventListResponse, error)
+	ExportEvents(actor dto.Actor, query dto.AuditExportQuery, w io.Writer) error
+	VerifyChain() (*dto.AuditVerificationResponse, error)
+}
+
+// AuditService keeps the append-only audit log of authentication and admin actions: who changed a password,
+// a role or an account, from where, and the values before and after. Events are hash-chained, so changes to the
+// log made around the service are detected by VerifyChain. Secrets such as passwords are never recorded.
+type AuditService struct {
+	auditRepo repository.IAuditRepository
+}
+
+// NewAuditService creates a new instance of AuditService.
+func NewAuditService(auditRepo repository.IAuditRepository) IAuditService {
+	return &AuditService{auditRepo: auditRepo}
+}
+
+// Record appends an event to the audit log. The action it records has already happened, so a failure to record
+// it is logged rather than failing the request.
+func (s *AuditService) Record(actor dto.Actor, action, targetType string, targetID interface{}, changes map[string]models.AuditChange) {
+	event := models.AuditEvent{
+		ActorID:    actor.UserID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   fmt.Sprint(targetID),
+		IPAddress:  actor.IPAddress,
+		UserAgent:  actor.UserAgent,
+	}
+	if len(changes) > 0 {
+		encoded, err := json.Marshal(changes)
+		if err != nil {
+			log.Printf("Could not record audit event %s of %s %v: %v", action, targetType, targetID, err)
+			return
+		}
+		event.Changes = string(encoded)
+	}
+	if err := s.auditRepo.Append(&event); err != nil {
+		log.Printf("Could not record audit event %s of %s %v: %v", action, targetType, targetID, err)
+	}
+}
+
+// ListEvents retrieves a page of the audit events matching query, newest first.
+func (s *AuditService) ListEvents(query dto.AuditEventQuery) (*dto.AuditEventListResponse, error) {
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultAuditPageSize
+	}
+	events, total, err := s.auditRepo.List(auditFilter(query), query.Offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	response := &dto.AuditEventListResponse{Events: make([]dto.AuditEventResponse, 0, len(events)), Total: total}
+	for _, event := range events {
+		response.Events = append(response.Events, dto.FromAuditEventModel(event))
+	}
+	return response, nil
+}
+
+// ExportEvents writes every audit event matching query to w in the order they were written, as CSV or as a JSON
+// array. The export itself is recorded in the audit log.
+func (s *AuditService) ExportEvents(actor dto.Actor, query dto.AuditExportQuery, w io.Writer) error {
+	s.Record(actor, AuditLogExported, AuditTargetAuditLog, "", nil)
+
+	filter := auditFilter(query.AuditEventQuery)
+	if query.Format == "json" {
+		return s.exportJSON(filter, w)
+	}
+	return s.exportCSV(filter, w)
+}
+
+// VerifyChain recomputes the hash of every audit event and checks that it links to the event before it. The
+// first event that does not match is reported: it, or one right before it, was changed or removed.
+func (s *AuditService) VerifyChain() (*dto.AuditVerificationResponse, error) {
+	response := &dto.AuditVerificationResponse{Valid: true}
+	prevHash := ""
+	err := s.auditRepo.FindInBatches(repository.AuditEventFilter{}, auditBatchSize, func(events []models.AuditEvent) error {
+		for _, event := range events {
+			if !response.Valid {
+				return nil
+			}
+			if event.PrevHash != prevHash || event.ComputeHash() != event.Hash {
+				response.Valid, response.BrokenAtID = false, event.ID
+				return nil
+			}
+			response.Checked++
+			prevHash = event.Hash
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	response.VerifiedAt = time.Now()
+	return response, nil
+}
+
+// exportCSV writes the audit events matching filter to w as CSV, with a header row.
+func (s *AuditService) exportCSV(filter repository.AuditEventFilter, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(auditCSVHeader); err != nil {
+		return err
+	}
+	err := s.auditRepo.FindInBatches(filter, auditBatchSize, func(events []models.AuditEvent) error {
+		for _, event := range events {
+			if err := writer.Write([]string{
+				strconv.FormatUint(uint64(event.ID), 10), event.CreatedAt.UTC().Format(time.RFC3339Nano),
+				strconv.FormatUint(uint64(event.ActorID), 10), event.Action, event.TargetType, event.TargetID,
+				event.IPAddress, event.UserAgent, event.Changes, event.PrevHash, event.Hash,
+			}); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	})
+	if err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// exportJSON writes the audit events matching filter to w as a JSON array.
+func (s *AuditService) exportJSON(filter repository.AuditEventFilter, w io.Writer) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	first := true
+	err := s.auditRepo.FindInBatches(filter, auditBatchSize, func(events []models.AuditEvent) error {
+		for _, event := range events {
+			encoded, err := json.Marshal(dto.FromAuditEventModel(event))
+			if err != nil {
+				return err
+			}
+			if !first {
+				if _, err := io.WriteString(w, ","); err != nil {
+					return err
+				}
+			}
+			first = false
+			if _, err := w.Write(encoded); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "]")
+	return err
+}
+
+// auditFilter converts an audit log query to a repository filter.
+func auditFilter(query dto.AuditEventQuery) repository.AuditEventFilter {
+	return repository.AuditEventFilter{
+		ActorID:    query.ActorID,
+		Action:     query.Action,
+		TargetType: query.TargetType,
+		TargetID:   query.TargetID,
+		From:       query.From,
+		To:         query.To,
+	}
+}
+
+// auditDiff returns the fields of before and after, compared by their JSON names, whose values differ.
+func auditDiff(before, after interface{}) map[string]models.AuditChange {
+	beforeFields, afterFields := auditFields(before), auditFields(after)
+	changes := map[string]models.AuditChange{}
+	for name, value := range afterFields {
+		if !reflect.DeepEqual(beforeFields[name], value) {
+			changes[name] = models.AuditChange{Before: beforeFields[name], After: value}
+		}
+	}
+	for name, value := range beforeFields {
+		if _, ok := afterFields[name]; !ok {
+			changes[name] = models.AuditChange{Before: value}
+		}
+	}
+	return changes
+}
+
+// auditFields returns the fields of a value by their JSON names.
+func auditFields(value interface{}) map[string]interface{} {
+	fields := map[string]interface{}{}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fields
+	}
+	_ = json.Unmarshal(encoded, &fields)
+	return fields
+}
+
+// auditChange describes a single changed field.
+func auditChange(field string, before, after interface{}) map[string]models.AuditChange {
+	return map[string]models.AuditChange{field: {Before: before, After: after}}
+}
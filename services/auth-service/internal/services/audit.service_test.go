@@ -0,0 +1,106 @@
+package services
+
+import (
+	"auth-service/internal/api/dto"
+	"auth-service/internal/models"
+	"auth-service/internal/repository"
+	"testing"
+	"time"
+)
+
+// fakeAuditService records the actions audited and who did them.
+type fakeAuditService struct {
+	IAuditService
+	actions []string
+	actors  []uint
+}
+
+func (s *fakeAuditService) Record(actor dto.Actor, action, targetType string, targetID interface{}, changes map[string]models.AuditChange) {
+	s.actions = append(s.actions, action)
+	s.actors = append(s.actors, actor.UserID)
+}
+
+// fakeAuditRepository returns its events in batches of the requested size.
+type fakeAuditRepository struct {
+	repository.IAuditRepository
+	events []models.AuditEvent
+}
+
+func (r *fakeAuditRepository) FindInBatches(filter repository.AuditEventFilter, batchSize int, fn func(events []models.AuditEvent) error) error {
+	for start := 0; start < len(r.events); start += batchSize {
+		end := start + batchSize
+		if end > len(r.events) {
+			end = len(r.events)
+		}
+		if err := fn(r.events[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// auditChain links and hashes n audit events the way AuditRepository.Append does.
+func auditChain(n int) []models.AuditEvent {
+	events := make([]models.AuditEvent, n)
+	prevHash := ""
+	for i := range events {
+		events[i] = models.AuditEvent{
+			ID:         uint(i + 1),
+			CreatedAt:  time.Date(2026, 10, 19, 8, 0, i, 0, time.UTC),
+			ActorID:    1,
+			Action:     AuditUserUnlocked,
+			TargetType: AuditTargetUser,
+			TargetID:   "7",
+			PrevHash:   prevHash,
+		}
+		events[i].Hash = events[i].ComputeHash()
+		prevHash = events[i].Hash
+	}
+	return events
+}
+
+func TestAuditServiceVerifyChain(t *testing.T) {
+	tests := []struct {
+		name           string
+		events         func() []models.AuditEvent
+		wantValid      bool
+		wantBrokenAtID uint
+		wantChecked    int64
+	}{
+		{name: "empty log", events: func() []models.AuditEvent { return nil }, wantValid: true},
+		{name: "intact chain", events: func() []models.AuditEvent { return auditChain(3) }, wantValid: true, wantChecked: 3},
+		{name: "intact chain over several batches", events: func() []models.AuditEvent { return auditChain(auditBatchSize + 2) }, wantValid: true, wantChecked: auditBatchSize + 2},
+		{name: "changed event", events: func() []models.AuditEvent {
+			events := auditChain(3)
+			events[1].TargetID = "8"
+			return events
+		}, wantBrokenAtID: 2, wantChecked: 1},
+		{name: "changed and rehashed event", events: func() []models.AuditEvent {
+			events := auditChain(3)
+			events[1].TargetID = "8"
+			events[1].Hash = events[1].ComputeHash()
+			return events
+		}, wantBrokenAtID: 3, wantChecked: 2},
+		{name: "removed event", events: func() []models.AuditEvent {
+			events := auditChain(3)
+			return append(events[:1], events[2:]...)
+		}, wantBrokenAtID: 3, wantChecked: 1},
+		{name: "first event linked to nothing", events: func() []models.AuditEvent {
+			events := auditChain(2)
+			return events[1:]
+		}, wantBrokenAtID: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewAuditService(&fakeAuditRepository{events: tt.events()})
+			got, err := service.VerifyChain()
+			if err != nil {
+				t.Fatalf("VerifyChain() error = %v", err)
+			}
+			if got.Valid != tt.wantValid || got.BrokenAtID != tt.wantBrokenAtID || got.Checked != tt.wantChecked {
+				t.Errorf("VerifyChain() = valid %v, broken at %d, checked %d, want %v, %d, %d",
+					got.Valid, got.BrokenAtID, got.Checked, tt.wantValid, tt.wantBrokenAtID, tt.wantChecked)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"auth-service/internal/api/dto"
+	"auth-service/internal/config"
+	"auth-service/internal/models"
+	"auth-service/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"gorm.io/gorm"
+)
+
+// Services holding data about users, each erasing it in a step of its own.
+const (
+	ErasureServiceProfile      = "profile-service"
+	ErasureServiceNotification = "notification-service"
+	ErasureServiceBooking      = "booking-service"
+	ErasureServiceAuth         = "auth-service"
+)
+
+// erasureBatchSize is how many erasures a run of the erasure job works on.
+const erasureBatchSize = 20
+
+var (
+	profileServiceBaseURL = os.Getenv("PROFILE_SERVICE_BASE_URL")
+	bookingServiceBaseURL = os.Getenv("BOOKING_SERVICE_BASE_URL")
+)
+
+// userDataScopes are the scopes of the tokens auth-service calls the data endpoints of each service with.
+var userDataScopes = map[string]string{
+	ErasureServiceProfile:      ScopeProfilesUserData,
+	ErasureServiceNotification: ScopeNotificationsUserData,
+	ErasureServiceBooking:      ScopeBookingsUserData,
+}
+
+var (
+	// ErrAccountErasureNotFound is returned when no erasure was scheduled for an account.
+	ErrAccountErasureNotFound = errors.New("no erasure was scheduled for this account")
+	// ErrAccountErasureStarted is returned when restoring an account whose data is being, or was, erased.
+	ErrAccountErasureStarted = errors.New("the data of this account has been erased, it cannot be restored")
+)
+
+// IAccountErasureService defines the interface for erasing the data of deleted accounts across services.
+type IAccountErasureService interface {
+	Schedule(userID, requestedBy uint) error
+	Cancel(userID uint) error
+	GetErasure(userID uint) (*dto.AccountErasureResponse, error)
+	ProcessDue(ctx context.Context) error
+}
+
+// AccountErasureService erases the data of deleted accounts once their grace period ends. Each service that
+// holds data about the user purges it, or anonymises what it must keep such as financial records, through an
+// endpoint only services may call. Steps that fail are retried on the next run until every service is done.
+type AccountErasureService struct {
+	erasureRepo repository.IAccountErasureRepository
+	clients     map[string]*resty.Client // Keyed by service
+	gracePeriod time.Duration
+}
+
+// NewAccountErasureService creates a new instance of AccountErasureService.
+func NewAccountErasureService(erasureRepo repository.IAccountErasureRepository) IAccountErasureService {
+	return &AccountErasureService{
+		erasureRepo: erasureRepo,
+		clients:     newUserDataClients(),
+		gracePeriod: config.GetDuration("ACCOUNT_ERASURE_GRACE_PERIOD", 7*24*time.Hour),
+	}
+}
+
+// newUserDataClients returns a client for each service holding data about users, calling it with a token
+// limited to its data endpoints.
+func newUserDataClients() map[string]*resty.Client {
+	clients := make(map[string]*resty.Client, len(userDataScopes))
+	for service, scope := range userDataScopes {
+		clients[service] = newServiceClient(scope).SetTimeout(30 * time.Second)
+	}
+	return clients
+}
+
+// Schedule plans the erasure of a deleted account for the end of the grace period, unless one is planned already.
+func (s *AccountErasureService) Schedule(userID, requestedBy uint) error {
+	if _, err := s.erasureRepo.FindByUserID(userID); err == nil {
+		return nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	erasure := models.AccountErasure{
+		UserID:      userID,
+		RequestedBy: requestedBy,
+		Status:      models.ErasureStatusScheduled,
+		ScheduledAt: time.Now().Add(s.gracePeriod),
+	}
+	for _, service := range []string{ErasureServiceProfile, ErasureServiceNotification, ErasureServiceBooking, ErasureServiceAuth} {
+		erasure.Steps = append(erasure.Steps, models.AccountErasureStep{Service: service, Status: models.ErasureStepPending})
+	}
+	if err := s.erasureRepo.Create(&erasure); err != nil {
+		return fmt.Errorf("failed to schedule account erasure: %w", err)
+	}
+	return nil
+}
+
+// Cancel drops the scheduled erasure of an account being restored. Accounts without one are left alone;
+// those whose erasure started return ErrAccountErasureStarted.
+func (s *AccountErasureService) Cancel(userID uint) error {
+	if err := s.erasureRepo.Cancel(userID); err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil
+		case errors.Is(err, repository.ErrErasureStarted):
+			return ErrAccountErasureStarted
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+// GetErasure reports the progress of the erasure of an account, service by service.
+func (s *AccountErasureService) GetErasure(userID uint) (*dto.AccountErasureResponse, error) {
+	erasure, err := s.erasureRepo.FindByUserID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAccountErasureNotFound
+		}
+		return nil, err
+	}
+	response := dto.FromAccountErasureModel(*erasure)
+	return &response, nil
+}
+
+// ProcessDue starts the erasures whose grace period ended and retries the pending steps of those in progress.
+// It is run by the scheduler.
+func (s *AccountErasureService) ProcessDue(ctx context.Context) error {
+	erasures, err := s.erasureRepo.FindDue(time.Now(), erasureBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, erasure := range erasures {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err := s.process(erasure); err != nil {
+			log.Printf("Account erasure of user %d: %v", erasure.UserID, err)
+		}
+	}
+	return nil
+}
+
+// process runs the pending steps of an erasure and completes it once every service erased its data.
+func (s *AccountErasureService) process(erasure models.AccountErasure) error {
+	if erasure.Status == models.ErasureStatusScheduled {
+		if err := s.erasureRepo.Start(erasure.ID, time.Now()); err != nil {
+			if errors.Is(err, repository.ErrErasureNotScheduled) {
+				// The account was restored since the erasure was found, its data must be kept
+				log.Printf("Account erasure of user %d was cancelled before it started", erasure.UserID)
+				return nil
+			}
+			return err
+		}
+	}
+
+	pending := 0
+	for i := range erasure.Steps {
+		step := &erasure.Steps[i]
+		if step.Status == models.ErasureStepCompleted {
+			continue
+		}
+		step.Attempts++
+		if err := s.erase(step.Service, erasure.UserID); err != nil {
+			step.LastError = err.Error()
+			pending++
+		} else {
+			now := time.Now()
+			step.Status, step.LastError, step.CompletedAt = models.ErasureStepCompleted, "", &now
+		}
+		if err := s.erasureRepo.UpdateStep(step); err != nil {
+			return err
+		}
+	}
+	if pending > 0 {
+		return fmt.Errorf("%d services have not erased the data yet, retrying on the next run", pending)
+	}
+
+	if err := s.erasureRepo.Complete(erasure.ID, time.Now()); err != nil {
+		return err
+	}
+	log.Printf("Account erasure of user %d completed", erasure.UserID)
+	return nil
+}
+
+// erase has a service erase the data it holds about the user.
+func (s *AccountErasureService) erase(service string, userID uint) error {
+	switch service {
+	case ErasureServiceAuth:
+		return s.erasureRepo.EraseUserData(userID)
+	case ErasureServiceProfile:
+		return s.eraseRemote(service, fmt.Sprintf("%s/%d/data", profileServiceBaseURL, userID))
+	case ErasureServiceNotification:
+		return s.eraseRemote(service, fmt.Sprintf("%s/users/%d/data", notificationServiceBaseURL, userID))
+	case ErasureServiceBooking:
+		return s.eraseRemote(service, fmt.Sprintf("%s/users/%d/data", bookingServiceBaseURL, userID))
+	default:
+		return fmt.Errorf("unknown service %q", service)
+	}
+}
+
+// eraseRemote calls the erasure endpoint of another service, authenticated with a service token.
+func (s *AccountErasureService) eraseRemote(service, url string) error {
+	resp, err := s.clients[service].R().Delete(url)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("%s responded with status code: %d", service, resp.StatusCode())
+	}
+	return nil
+}
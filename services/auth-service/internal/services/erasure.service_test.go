@@ -0,0 +1,220 @@
+package services
+
+import (
+	"auth-service/internal/models"
+	"auth-service/internal/repository"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// fakeErasureRepository holds a single erasure, whose auth-service data is erased with eraseErr.
+type fakeErasureRepository struct {
+	repository.IAccountErasureRepository
+	erasure   *models.AccountErasure
+	eraseErr  error
+	startErr  error
+	cancelErr error
+	erased    bool
+	cancelled bool
+	completed bool
+}
+
+func (r *fakeErasureRepository) Create(erasure *models.AccountErasure) error {
+	r.erasure = erasure
+	return nil
+}
+
+func (r *fakeErasureRepository) FindByUserID(userID uint) (*models.AccountErasure, error) {
+	if r.erasure == nil || r.erasure.UserID != userID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.erasure, nil
+}
+
+func (r *fakeErasureRepository) Start(erasureID uint, at time.Time) error {
+	return r.startErr
+}
+
+func (r *fakeErasureRepository) Cancel(userID uint) error {
+	if r.cancelErr != nil {
+		return r.cancelErr
+	}
+	r.cancelled = true
+	return nil
+}
+
+func (r *fakeErasureRepository) EraseUserData(userID uint) error {
+	if r.eraseErr != nil {
+		return r.eraseErr
+	}
+	r.erased = true
+	return nil
+}
+
+func (r *fakeErasureRepository) UpdateStep(step *models.AccountErasureStep) error {
+	return nil
+}
+
+func (r *fakeErasureRepository) Complete(erasureID uint, at time.Time) error {
+	r.completed = true
+	return nil
+}
+
+func TestAccountErasureServiceSchedule(t *testing.T) {
+	repo := &fakeErasureRepository{}
+	service := &AccountErasureService{erasureRepo: repo, gracePeriod: 7 * 24 * time.Hour}
+
+	if err := service.Schedule(7, 1); err != nil {
+		t.Fatalf("Schedule() error = %v", err)
+	}
+	scheduled := repo.erasure
+	if scheduled == nil || scheduled.Status != models.ErasureStatusScheduled || len(scheduled.Steps) != 4 {
+		t.Fatalf("scheduled %+v, want a scheduled erasure with a step per service", scheduled)
+	}
+	if wait := time.Until(scheduled.ScheduledAt); wait < 7*24*time.Hour-time.Minute {
+		t.Errorf("erasure starts in %s, want after the grace period", wait)
+	}
+
+	// Deleting the account again keeps the erasure planned first
+	if err := service.Schedule(7, 2); err != nil {
+		t.Fatalf("Schedule() again error = %v", err)
+	}
+	if repo.erasure != scheduled {
+		t.Error("Schedule() again replaced the scheduled erasure")
+	}
+}
+
+func TestAccountErasureServiceProcess(t *testing.T) {
+	tests := []struct {
+		name          string
+		eraseErr      error
+		wantErr       bool
+		wantCompleted bool
+	}{
+		{name: "every service erased the data", wantCompleted: true},
+		{name: "a service failed", eraseErr: errors.New("connection reset"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeErasureRepository{eraseErr: tt.eraseErr}
+			service := &AccountErasureService{erasureRepo: repo}
+			erasure := models.AccountErasure{
+				UserID: 7,
+				Status: models.ErasureStatusScheduled,
+				Steps:  []models.AccountErasureStep{{Service: ErasureServiceAuth, Status: models.ErasureStepPending}},
+			}
+
+			if err := service.process(erasure); (err != nil) != tt.wantErr {
+				t.Fatalf("process() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if repo.completed != tt.wantCompleted {
+				t.Errorf("completed = %v, want %v", repo.completed, tt.wantCompleted)
+			}
+			// Failed steps are kept pending with the error, to be retried on the next run
+			step := erasure.Steps[0]
+			if step.Attempts != 1 || (step.Status == models.ErasureStepCompleted) != tt.wantCompleted || (step.LastError != "") != tt.wantErr {
+				t.Errorf("step = %+v", step)
+			}
+		})
+	}
+}
+
+func TestAccountErasureServiceCallsWithScopedTokens(t *testing.T) {
+	tokens := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "token-" + r.FormValue("scope"), "expires_in": 300})
+	}))
+	t.Cleanup(tokens.Close)
+	t.Setenv("AUTH_SERVICE_TOKEN_URL", tokens.URL)
+	t.Setenv("SERVICE_CLIENT_ID", "auth")
+	t.Setenv("SERVICE_CLIENT_SECRET", "secret")
+	var sent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = r.Header.Get("Authorization")
+	}))
+	t.Cleanup(server.Close)
+	previous := []string{profileServiceBaseURL, notificationServiceBaseURL, bookingServiceBaseURL}
+	profileServiceBaseURL, notificationServiceBaseURL, bookingServiceBaseURL = server.URL, server.URL, server.URL
+	t.Cleanup(func() {
+		profileServiceBaseURL, notificationServiceBaseURL, bookingServiceBaseURL = previous[0], previous[1], previous[2]
+	})
+	service := NewAccountErasureService(&fakeErasureRepository{}).(*AccountErasureService)
+
+	// Each service is called with a token only valid for its own data endpoints
+	tests := []struct {
+		service string
+		want    string
+	}{
+		{service: ErasureServiceProfile, want: "Bearer token-" + ScopeProfilesUserData},
+		{service: ErasureServiceNotification, want: "Bearer token-" + ScopeNotificationsUserData},
+		{service: ErasureServiceBooking, want: "Bearer token-" + ScopeBookingsUserData},
+	}
+	for _, tt := range tests {
+		t.Run(tt.service, func(t *testing.T) {
+			if err := service.erase(tt.service, 7); err != nil {
+				t.Fatalf("erase() error = %v", err)
+			}
+			if sent != tt.want {
+				t.Errorf("Authorization = %q, want %q", sent, tt.want)
+			}
+		})
+	}
+}
+
+func TestAccountErasureServiceProcessStartsScheduledErasures(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        string
+		startErr      error
+		wantErr       bool
+		wantErased    bool
+		wantCompleted bool
+	}{
+		{name: "scheduled erasure", status: models.ErasureStatusScheduled, wantErased: true, wantCompleted: true},
+		{name: "erasure in progress", status: models.ErasureStatusInProgress, wantErased: true, wantCompleted: true},
+		{name: "erasure cancelled since it was found", status: models.ErasureStatusScheduled, startErr: repository.ErrErasureNotScheduled},
+		{name: "failure to start", status: models.ErasureStatusScheduled, startErr: errors.New("connection reset"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeErasureRepository{startErr: tt.startErr}
+			service := &AccountErasureService{erasureRepo: repo}
+			erasure := models.AccountErasure{
+				UserID: 7,
+				Status: tt.status,
+				Steps:  []models.AccountErasureStep{{Service: ErasureServiceAuth, Status: models.ErasureStepPending}},
+			}
+			if err := service.process(erasure); (err != nil) != tt.wantErr {
+				t.Fatalf("process() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if repo.erased != tt.wantErased || repo.completed != tt.wantCompleted {
+				t.Errorf("erased = %v, completed = %v, want %v, %v", repo.erased, repo.completed, tt.wantErased, tt.wantCompleted)
+			}
+		})
+	}
+}
+
+func TestAccountErasureServiceCancel(t *testing.T) {
+	tests := []struct {
+		name      string
+		cancelErr error
+		want      error
+	}{
+		{name: "scheduled erasure", want: nil},
+		{name: "no erasure", cancelErr: gorm.ErrRecordNotFound, want: nil},
+		{name: "erasure started", cancelErr: repository.ErrErasureStarted, want: ErrAccountErasureStarted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &AccountErasureService{erasureRepo: &fakeErasureRepository{cancelErr: tt.cancelErr}}
+			if err := service.Cancel(7); !errors.Is(err, tt.want) {
+				t.Errorf("Cancel() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
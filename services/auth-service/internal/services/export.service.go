@@ -0,0 +1,275 @@
+package services
+
+import (
+	"archive/zip"
+	"auth-service/internal/api/dto"
+	"auth-service/internal/config"
+	"auth-service/internal/models"
+	"auth-service/internal/repository"
+	"auth-service/pkg"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	// dataExportReadyNotification is the notification-service type of the email sent once an export is ready.
+	dataExportReadyNotification = "data_export_ready"
+	// dataExportBatchSize is how many exports a run of the export job works on.
+	dataExportBatchSize = 5
+	// maxDataExportAttempts is how many times an export is tried before it is given up.
+	maxDataExportAttempts = 5
+)
+
+var (
+	// ErrDataExportPending is returned when the user asks for an export while one is being prepared.
+	ErrDataExportPending = errors.New("a copy of your data is already being prepared")
+	// ErrInvalidDownloadLink is returned for unknown and expired download links alike.
+	ErrInvalidDownloadLink = errors.New("invalid or expired download link, please request a new copy of your data")
+)
+
+// IDataExportService defines the interface for exporting the personal data of users.
+type IDataExportService interface {
+	Request(userID uint) (*dto.DataExportResponse, error)
+	ListExports(userID uint) ([]dto.DataExportResponse, error)
+	Download(token string) (*models.DataExport, error)
+	ProcessPending(ctx context.Context) error
+}
+
+// DataExportService gives users a copy of their personal data. The export job gathers what every service holds
+// about the user into a ZIP archive of JSON files, one per service, and emails the user a link to download it.
+// The link works until the archive expires, when the archive is removed.
+type DataExportService struct {
+	exportRepo          repository.IDataExportRepository
+	userRepo            repository.IUserRepository
+	loginHistoryRepo    repository.ILoginHistoryRepository
+	notificationService INotificationService
+	clients             map[string]*resty.Client // Keyed by service
+	ttl                 time.Duration
+	downloadURL         string
+}
+
+// NewDataExportService creates a new instance of DataExportService.
+func NewDataExportService(exportRepo repository.IDataExportRepository, userRepo repository.IUserRepository, loginHistoryRepo repository.ILoginHistoryRepository, notificationService INotificationService) IDataExportService {
+	return &DataExportService{
+		exportRepo:          exportRepo,
+		userRepo:            userRepo,
+		loginHistoryRepo:    loginHistoryRepo,
+		notificationService: notificationService,
+		clients:             newUserDataClients(),
+		ttl:                 config.GetDuration("DATA_EXPORT_TTL", 7*24*time.Hour),
+		downloadURL:         os.Getenv("DATA_EXPORT_DOWNLOAD_URL"),
+	}
+}
+
+// Request queues an export of the user's data for the export job. Users wait for the export being prepared
+// before asking for another one.
+func (s *DataExportService) Request(userID uint) (*dto.DataExportResponse, error) {
+	pending, err := s.exportRepo.HasPending(userID)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, ErrDataExportPending
+	}
+
+	export := models.DataExport{UserID: userID, Status: models.DataExportStatusPending}
+	if err := s.exportRepo.Create(&export); err != nil {
+		return nil, fmt.Errorf("failed to request data export: %w", err)
+	}
+	response := dto.FromDataExportModel(export)
+	return &response, nil
+}
+
+// ListExports retrieves the data exports of a user, newest first.
+func (s *DataExportService) ListExports(userID uint) ([]dto.DataExportResponse, error) {
+	exports, err := s.exportRepo.ListByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]dto.DataExportResponse, 0, len(exports))
+	for _, export := range exports {
+		responses = append(responses, dto.FromDataExportModel(export))
+	}
+	return responses, nil
+}
+
+// Download returns the export, with its archive, of a download link that has not expired.
+func (s *DataExportService) Download(token string) (*models.DataExport, error) {
+	export, err := s.exportRepo.FindByDownloadTokenHash(hashToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidDownloadLink
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if export.Status != models.DataExportStatusReady || export.ExpiresAt == nil || now.After(*export.ExpiresAt) {
+		return nil, ErrInvalidDownloadLink
+	}
+	if err := s.exportRepo.MarkDownloaded(export.ID, now); err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+// ProcessPending removes the archives whose link expired, then prepares the pending exports. Exports that fail,
+// including those whose download link could not be sent, are retried on the next run, up to
+// maxDataExportAttempts times. It is run by the scheduler.
+func (s *DataExportService) ProcessPending(ctx context.Context) error {
+	if expired, err := s.exportRepo.ExpireArchives(time.Now()); err != nil {
+		return err
+	} else if expired > 0 {
+		log.Printf("Removed %d expired data export archives", expired)
+	}
+
+	exports, err := s.exportRepo.FindPending(dataExportBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, export := range exports {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err := s.process(export); err != nil {
+			log.Printf("Data export %d of user %d: %v", export.ID, export.UserID, err)
+		}
+	}
+	return nil
+}
+
+// process prepares the archive of an export and emails the user the link to download it. The export stays
+// pending until the link was sent, so users are never left with an archive they were not told about.
+func (s *DataExportService) process(export models.DataExport) error {
+	user, archive, err := s.buildArchive(export.UserID)
+	if err != nil {
+		return s.recordFailure(export, err)
+	}
+
+	token, err := pkg.GenerateToken()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	expiresAt := now.Add(s.ttl)
+	link := fmt.Sprintf("%s?token=%s", s.downloadURL, url.QueryEscape(token))
+	content := fmt.Sprintf("Hi %s, the copy of your personal data you asked for is ready. Download it from %s before %s, when the link expires.",
+		user.Username, link, expiresAt.Format(time.RFC1123))
+	if err := s.notificationService.SendNotification(user.ID, dataExportReadyNotification, NotificationChannelEmail, content); err != nil {
+		return s.recordFailure(export, fmt.Errorf("failed to send download link: %w", err))
+	}
+
+	if err := s.exportRepo.Complete(export.ID, archive, hashToken(token), now, expiresAt); err != nil {
+		// The link sent does not work, the next run sends one that does
+		return s.recordFailure(export, err)
+	}
+	return nil
+}
+
+// recordFailure counts a failed attempt of an export, which is retried on the next run unless it was tried
+// maxDataExportAttempts times or its user is gone, and returns err.
+func (s *DataExportService) recordFailure(export models.DataExport, err error) error {
+	export.Attempts++
+	status := models.DataExportStatusPending
+	if export.Attempts >= maxDataExportAttempts || errors.Is(err, gorm.ErrRecordNotFound) {
+		status = models.DataExportStatusFailed
+	}
+	if recordErr := s.exportRepo.RecordFailure(export.ID, export.Attempts, err.Error(), status); recordErr != nil {
+		return recordErr
+	}
+	return err
+}
+
+// buildArchive gathers the data of the user from every service into a ZIP archive of JSON files.
+func (s *DataExportService) buildArchive(userID uint) (*models.User, []byte, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	archived, err := s.loginHistoryRepo.GetArchivedHistoryByUserID(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	history, err := s.loginHistoryRepo.GetHistoryByUserID(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Archived attempts are older than those still in the login history
+	loginHistory := make([]dto.LoginHistoryResponse, 0, len(archived)+len(history))
+	for _, entry := range archived {
+		loginHistory = append(loginHistory, dto.FromHistoryArchiveModel(entry))
+	}
+	for _, entry := range history {
+		loginHistory = append(loginHistory, dto.FromHistoryModel(entry))
+	}
+
+	files := []struct {
+		name string
+		data func() (json.RawMessage, error)
+	}{
+		{"account.json", func() (json.RawMessage, error) { return json.Marshal(dto.FromUserModelForAdmin(*user)) }},
+		{"login-history.json", func() (json.RawMessage, error) { return json.Marshal(loginHistory) }},
+		{"profile.json", func() (json.RawMessage, error) {
+			return s.fetchRemote(ErasureServiceProfile, fmt.Sprintf("%s/%d/data", profileServiceBaseURL, userID))
+		}},
+		{"notifications.json", func() (json.RawMessage, error) {
+			return s.fetchRemote(ErasureServiceNotification, fmt.Sprintf("%s/users/%d/data", notificationServiceBaseURL, userID))
+		}},
+		{"bookings.json", func() (json.RawMessage, error) {
+			return s.fetchRemote(ErasureServiceBooking, fmt.Sprintf("%s/users/%d/data", bookingServiceBaseURL, userID))
+		}},
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, file := range files {
+		data, err := file.data()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to export %s: %w", file.name, err)
+		}
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, data, "", "  "); err != nil {
+			return nil, nil, fmt.Errorf("failed to export %s: %w", file.name, err)
+		}
+		w, err := archive.Create(file.name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, err := w.Write(indented.Bytes()); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, nil, err
+	}
+	return user, buf.Bytes(), nil
+}
+
+// fetchRemote retrieves the data another service holds about the user, authenticated with a service token.
+// Services without data about the user respond with none, exported as null.
+func (s *DataExportService) fetchRemote(service, endpoint string) (json.RawMessage, error) {
+	var body struct {
+		Data json.RawMessage `json:"data"`
+	}
+	resp, err := s.clients[service].R().SetResult(&body).Get(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("%s responded with status code: %d", service, resp.StatusCode())
+	}
+	if len(body.Data) == 0 {
+		return json.RawMessage("null"), nil
+	}
+	return body.Data, nil
+}
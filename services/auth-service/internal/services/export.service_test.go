@@ -0,0 +1,245 @@
+package services
+
+import (
+	"archive/zip"
+	"auth-service/internal/api/dto"
+	"auth-service/internal/models"
+	"auth-service/internal/repository"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (r *fakeLoginHistoryRepository) GetHistoryByUserID(userID uint) ([]models.LoginHistory, error) {
+	return r.recorded, nil
+}
+
+func (r *fakeLoginHistoryRepository) GetArchivedHistoryByUserID(userID uint) ([]models.LoginHistoryArchive, error) {
+	return r.archived, nil
+}
+
+func (r *fakeLoginHistoryRepository) FindLoginAttemptsInBatches(userID uint, filter repository.LoginHistoryFilter, batchSize int, fn func(histories []models.LoginHistory) error) error {
+	return fn(r.recorded)
+}
+
+func (r *fakeLoginHistoryRepository) FindArchivedLoginAttemptsInBatches(userID uint, filter repository.LoginHistoryFilter, batchSize int, fn func(archives []models.LoginHistoryArchive) error) error {
+	return fn(r.archived)
+}
+
+// fakeDataExportRepository holds a single data export and records how it was completed or failed.
+type fakeDataExportRepository struct {
+	repository.IDataExportRepository
+	export      *models.DataExport
+	created     bool
+	downloaded  bool
+	completeErr error
+	completed   bool
+	attempts    int
+	status      string
+}
+
+func (r *fakeDataExportRepository) HasPending(userID uint) (bool, error) {
+	return r.export != nil && r.export.Status == models.DataExportStatusPending, nil
+}
+
+func (r *fakeDataExportRepository) Create(export *models.DataExport) error {
+	r.export, r.created = export, true
+	return nil
+}
+
+func (r *fakeDataExportRepository) FindByDownloadTokenHash(tokenHash string) (*models.DataExport, error) {
+	if r.export == nil || r.export.DownloadTokenHash != tokenHash {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.export, nil
+}
+
+func (r *fakeDataExportRepository) MarkDownloaded(exportID uint, at time.Time) error {
+	r.downloaded = true
+	return nil
+}
+
+func (r *fakeDataExportRepository) Complete(exportID uint, archive []byte, downloadTokenHash string, completedAt, expiresAt time.Time) error {
+	if r.completeErr != nil {
+		return r.completeErr
+	}
+	r.completed, r.status = true, models.DataExportStatusReady
+	return nil
+}
+
+func (r *fakeDataExportRepository) RecordFailure(exportID uint, attempts int, lastError, status string) error {
+	r.attempts, r.status = attempts, status
+	return nil
+}
+
+func TestDataExportServiceRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		existing    *models.DataExport
+		want        error
+		wantCreated bool
+	}{
+		{name: "first export", wantCreated: true},
+		{name: "previous export ready", existing: &models.DataExport{UserID: 7, Status: models.DataExportStatusReady}, wantCreated: true},
+		{name: "export being prepared", existing: &models.DataExport{UserID: 7, Status: models.DataExportStatusPending}, want: ErrDataExportPending},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeDataExportRepository{export: tt.existing}
+			service := &DataExportService{exportRepo: repo}
+
+			if _, err := service.Request(7); !errors.Is(err, tt.want) {
+				t.Fatalf("Request() error = %v, want %v", err, tt.want)
+			}
+			if repo.created != tt.wantCreated {
+				t.Errorf("created = %v, want %v", repo.created, tt.wantCreated)
+			}
+		})
+	}
+}
+
+func TestDataExportServiceDownload(t *testing.T) {
+	future, past := time.Now().Add(time.Hour), time.Now().Add(-time.Minute)
+	tests := []struct {
+		name      string
+		status    string
+		expiresAt *time.Time
+		token     string
+		want      error
+	}{
+		{name: "ready export", status: models.DataExportStatusReady, expiresAt: &future, token: "token"},
+		{name: "expired link", status: models.DataExportStatusReady, expiresAt: &past, token: "token", want: ErrInvalidDownloadLink},
+		{name: "removed archive", status: models.DataExportStatusExpired, expiresAt: &future, token: "token", want: ErrInvalidDownloadLink},
+		{name: "unknown link", status: models.DataExportStatusReady, expiresAt: &future, token: "guess", want: ErrInvalidDownloadLink},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeDataExportRepository{export: &models.DataExport{UserID: 7, Status: tt.status, DownloadTokenHash: hashToken("token"), ExpiresAt: tt.expiresAt}}
+			service := &DataExportService{exportRepo: repo}
+
+			if _, err := service.Download(tt.token); !errors.Is(err, tt.want) {
+				t.Fatalf("Download() error = %v, want %v", err, tt.want)
+			}
+			if repo.downloaded != (tt.want == nil) {
+				t.Errorf("downloaded = %v, want %v", repo.downloaded, tt.want == nil)
+			}
+		})
+	}
+}
+
+// remoteUserData serves an empty data export of every other service.
+func remoteUserData(t *testing.T) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"success":true,"data":{}}`))
+	}))
+	t.Cleanup(server.Close)
+	previous := []string{profileServiceBaseURL, notificationServiceBaseURL, bookingServiceBaseURL}
+	profileServiceBaseURL, notificationServiceBaseURL, bookingServiceBaseURL = server.URL, server.URL, server.URL
+	t.Cleanup(func() {
+		profileServiceBaseURL, notificationServiceBaseURL, bookingServiceBaseURL = previous[0], previous[1], previous[2]
+	})
+}
+
+func TestDataExportServiceProcess(t *testing.T) {
+	remoteUserData(t)
+	user := models.User{Username: "ann"}
+	user.ID = 7
+
+	tests := []struct {
+		name          string
+		userGone      bool
+		attempts      int
+		sendErr       error
+		completeErr   error
+		wantStatus    string
+		wantAttempts  int
+		wantCompleted bool
+		wantSent      int
+	}{
+		{name: "export sent", wantStatus: models.DataExportStatusReady, wantCompleted: true, wantSent: 1},
+		{name: "link not sent", sendErr: errors.New("notification-service unavailable"), wantStatus: models.DataExportStatusPending, wantAttempts: 1},
+		{name: "link not sent on the last attempt", attempts: maxDataExportAttempts - 1, sendErr: errors.New("notification-service unavailable"), wantStatus: models.DataExportStatusFailed, wantAttempts: maxDataExportAttempts},
+		{name: "export not saved after the link was sent", completeErr: errors.New("connection reset"), wantStatus: models.DataExportStatusPending, wantAttempts: 1, wantSent: 1},
+		{name: "user gone", userGone: true, wantStatus: models.DataExportStatusFailed, wantAttempts: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exportRepo := &fakeDataExportRepository{completeErr: tt.completeErr}
+			notifications := &fakeNotificationService{err: tt.sendErr}
+			users := &fakeUserRepository{user: &user}
+			if tt.userGone {
+				users.user = nil
+			}
+			service := NewDataExportService(exportRepo, users,
+				&fakeLoginHistoryRepository{}, notifications).(*DataExportService)
+
+			export := models.DataExport{UserID: 7, Status: models.DataExportStatusPending, Attempts: tt.attempts}
+			err := service.process(export)
+			if (err != nil) != (tt.wantStatus != models.DataExportStatusReady) {
+				t.Fatalf("process() error = %v", err)
+			}
+			if exportRepo.status != tt.wantStatus || exportRepo.attempts != tt.wantAttempts || exportRepo.completed != tt.wantCompleted {
+				t.Errorf("status = %q, attempts = %d, completed = %v, want %q, %d, %v",
+					exportRepo.status, exportRepo.attempts, exportRepo.completed, tt.wantStatus, tt.wantAttempts, tt.wantCompleted)
+			}
+			if len(notifications.sent) != tt.wantSent {
+				t.Errorf("sent %d notifications, want %d", len(notifications.sent), tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestDataExportServiceBuildArchiveLoginHistory(t *testing.T) {
+	remoteUserData(t)
+	user := models.User{Username: "ann"}
+	user.ID = 7
+	history := models.LoginHistory{UserID: 7, LoginTime: time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC), Successful: true}
+	history.ID = 12
+	archived := models.LoginHistoryArchive{ID: 3, UserID: 7, LoginTime: time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC), Anomalies: "new_device"}
+	service := NewDataExportService(&fakeDataExportRepository{}, &fakeUserRepository{user: &user},
+		&fakeLoginHistoryRepository{recorded: []models.LoginHistory{history}, archived: []models.LoginHistoryArchive{archived}},
+		&fakeNotificationService{}).(*DataExportService)
+
+	_, archive, err := service.buildArchive(7)
+	if err != nil {
+		t.Fatalf("buildArchive() error = %v", err)
+	}
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatalf("zip.NewReader() error = %v", err)
+	}
+	file, err := reader.Open("login-history.json")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	var got []dto.LoginHistoryResponse
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	want := []struct {
+		id       uint
+		archived bool
+	}{{id: 3, archived: true}, {id: 12}}
+	if len(got) != len(want) {
+		t.Fatalf("login-history.json has %d attempts, want %d", len(got), len(want))
+	}
+	for i, attempt := range got {
+		if attempt.LoginHistoryID != want[i].id || attempt.Archived != want[i].archived {
+			t.Errorf("attempt %d = %d, archived %v, want %d, archived %v", i, attempt.LoginHistoryID, attempt.Archived, want[i].id, want[i].archived)
+		}
+	}
+}
@@ -2,7 +2,19 @@ package services
 
 import (
 	"auth-service/internal/api/dto"
+	"auth-service/internal/config"
+	"auth-service/internal/models"
 	"auth-service/internal/repository"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -12,27 +24,65 @@ const (
 	SuspiciousActivityTimeFrame = 10 * time.Minute // Time frame for counting failed attempts
 )
 
+// What the retention job does with login attempts older than the retention period.
+const (
+	LoginHistoryRetentionArchive = "archive" // Move them to login_history_archives
+	LoginHistoryRetentionPurge   = "purge"   // Delete them
+)
+
+const (
+	// defaultLoginHistoryPageSize is the number of login attempts listed when no limit is given.
+	defaultLoginHistoryPageSize = 50
+	// loginHistoryBatchSize is how many login attempts are exported, archived or purged at a time.
+	loginHistoryBatchSize = 1000
+)
+
+// ErrInvalidCursor is returned for page cursors that were not returned by a previous page.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// loginHistoryCSVHeader is the first row of login history exports.
+var loginHistoryCSVHeader = []string{"id", "loginTime", "logoutTime", "ipAddress", "deviceInformation", "successful", "failureReason", "country", "anomalies", "archived"}
+
 type ILoginHistoryService interface {
 	RecordLoginAttempt(req dto.CreateLoginHistoryRequest) (dto.LoginHistoryResponse, error)
-	GetLoginAttemptsByUser(userID uint, from, to time.Time) ([]dto.LoginHistoryResponse, error)
+	GetLoginAttemptsByUser(userID uint, query dto.LoginHistoryQuery) (*dto.LoginHistoryPageResponse, error)
+	ExportLoginAttempts(userID uint, query dto.LoginHistoryFilterQuery, w io.Writer) error
 	UpdateLoginHistory(historyID uint, req dto.UpdateLoginHistoryRequest) error
-	RecordUserLogout(historyID uint, logoutTime time.Time) error
 	CheckSuspiciousActivity(userID uint) (bool, error)
+	ApplyRetention(ctx context.Context) error
 }
 
+// LoginHistoryService records login attempts and lets users page through and export theirs. Attempts older than
+// the retention period are archived or purged by the retention job, so they no longer count as known devices
+// when unusual logins are detected.
 type LoginHistoryService struct {
 	loginHistoryRepo repository.ILoginHistoryRepository
+	retention        time.Duration
+	retentionMode    string
+	archiveRetention time.Duration // Archived attempts are kept forever when 0
 }
 
 func NewLoginHistoryService(loginHistoryRepo repository.ILoginHistoryRepository) ILoginHistoryService {
+	retentionMode := os.Getenv("LOGIN_HISTORY_RETENTION_MODE")
+	switch retentionMode {
+	case LoginHistoryRetentionArchive, LoginHistoryRetentionPurge:
+	case "":
+		retentionMode = LoginHistoryRetentionArchive
+	default:
+		log.Printf("Invalid mode %q for LOGIN_HISTORY_RETENTION_MODE, defaulting to %s", retentionMode, LoginHistoryRetentionArchive)
+		retentionMode = LoginHistoryRetentionArchive
+	}
 	return &LoginHistoryService{
 		loginHistoryRepo: loginHistoryRepo,
+		retention:        config.GetDuration("LOGIN_HISTORY_RETENTION", 365*24*time.Hour),
+		retentionMode:    retentionMode,
+		archiveRetention: config.GetDuration("LOGIN_HISTORY_ARCHIVE_RETENTION", 0),
 	}
 }
 
 func (s *LoginHistoryService) RecordLoginAttempt(req dto.CreateLoginHistoryRequest) (dto.LoginHistoryResponse, error) {
 	history := req.ToHistoryModel()
-	err := s.loginHistoryRepo.RecordLoginAttempt(history)
+	err := s.loginHistoryRepo.RecordLoginAttempt(&history)
 	if err != nil {
 		return dto.LoginHistoryResponse{}, err
 	}
@@ -40,16 +90,86 @@ func (s *LoginHistoryService) RecordLoginAttempt(req dto.CreateLoginHistoryReque
 	return response, nil
 }
 
-func (s *LoginHistoryService) GetLoginAttemptsByUser(userID uint, from, to time.Time) ([]dto.LoginHistoryResponse, error) {
-	histories, err := s.loginHistoryRepo.GetLoginAttempts(userID, from, to)
+// GetLoginAttemptsByUser retrieves a page of the login attempts of a user matching query, newest first.
+func (s *LoginHistoryService) GetLoginAttemptsByUser(userID uint, query dto.LoginHistoryQuery) (*dto.LoginHistoryPageResponse, error) {
+	var after *repository.LoginHistoryCursor
+	if query.Cursor != "" {
+		cursor, err := decodeLoginHistoryCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		after = &cursor
+	}
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultLoginHistoryPageSize
+	}
+
+	// One more attempt than the page holds tells whether there is a next page
+	histories, err := s.loginHistoryRepo.ListLoginAttempts(userID, loginHistoryFilter(query.LoginHistoryFilterQuery), after, limit+1)
 	if err != nil {
 		return nil, err
 	}
-	var responses []dto.LoginHistoryResponse
+	response := &dto.LoginHistoryPageResponse{Attempts: make([]dto.LoginHistoryResponse, 0, limit)}
+	if len(histories) > limit {
+		histories = histories[:limit]
+		last := histories[limit-1]
+		response.NextCursor = encodeLoginHistoryCursor(repository.LoginHistoryCursor{LoginTime: last.LoginTime, ID: last.ID})
+	}
 	for _, history := range histories {
-		responses = append(responses, dto.FromHistoryModel(history))
+		response.Attempts = append(response.Attempts, dto.FromHistoryModel(history))
 	}
-	return responses, nil
+	return response, nil
+}
+
+// ExportLoginAttempts writes the login attempts of a user matching query to w as CSV, in the order they were
+// recorded. Archived attempts, older than those in the login history, come first.
+func (s *LoginHistoryService) ExportLoginAttempts(userID uint, query dto.LoginHistoryFilterQuery, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(loginHistoryCSVHeader); err != nil {
+		return err
+	}
+	writeAttempts := func(attempts []dto.LoginHistoryResponse) error {
+		for _, attempt := range attempts {
+			logoutTime := ""
+			if !attempt.LogoutTime.IsZero() {
+				logoutTime = attempt.LogoutTime.UTC().Format(time.RFC3339)
+			}
+			if err := writer.Write([]string{
+				strconv.FormatUint(uint64(attempt.LoginHistoryID), 10), attempt.LoginTime.UTC().Format(time.RFC3339), logoutTime,
+				attempt.IPAddress, attempt.DeviceInformation, strconv.FormatBool(attempt.Successful),
+				attempt.FailureReason, attempt.Country, strings.Join(attempt.Anomalies, " "), strconv.FormatBool(attempt.Archived),
+			}); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	}
+
+	filter := loginHistoryFilter(query)
+	err := s.loginHistoryRepo.FindArchivedLoginAttemptsInBatches(userID, filter, loginHistoryBatchSize, func(archives []models.LoginHistoryArchive) error {
+		attempts := make([]dto.LoginHistoryResponse, 0, len(archives))
+		for _, archive := range archives {
+			attempts = append(attempts, dto.FromHistoryArchiveModel(archive))
+		}
+		return writeAttempts(attempts)
+	})
+	if err != nil {
+		return err
+	}
+	err = s.loginHistoryRepo.FindLoginAttemptsInBatches(userID, filter, loginHistoryBatchSize, func(histories []models.LoginHistory) error {
+		attempts := make([]dto.LoginHistoryResponse, 0, len(histories))
+		for _, history := range histories {
+			attempts = append(attempts, dto.FromHistoryModel(history))
+		}
+		return writeAttempts(attempts)
+	})
+	if err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
 }
 
 func (s *LoginHistoryService) UpdateLoginHistory(historyID uint, req dto.UpdateLoginHistoryRequest) error {
@@ -58,11 +178,6 @@ func (s *LoginHistoryService) UpdateLoginHistory(historyID uint, req dto.UpdateL
 	return s.loginHistoryRepo.UpdateLoginHistory(&history)
 }
 
-func (s *LoginHistoryService) RecordUserLogout(historyID uint, logoutTime time.Time) error {
-	// Update the logout time for the given history ID
-	return s.loginHistoryRepo.UpdateLogoutTime(historyID, logoutTime)
-}
-
 func (s *LoginHistoryService) CheckSuspiciousActivity(userID uint) (bool, error) {
 	// Fetch login attempts within the suspicious activity time frame
 	from := time.Now().Add(-SuspiciousActivityTimeFrame)
@@ -82,3 +197,87 @@ func (s *LoginHistoryService) CheckSuspiciousActivity(userID uint) (bool, error)
 	isSuspicious := failedAttempts >= SuspiciousActivityThreshold
 	return isSuspicious, nil
 }
+
+// ApplyRetention archives or purges, depending on the retention mode, the login attempts older than the retention
+// period, then purges the archived attempts older than the archive retention period. It is run by the scheduler.
+func (s *LoginHistoryService) ApplyRetention(ctx context.Context) error {
+	now := time.Now()
+	cutoff := now.Add(-s.retention)
+	var removed int64
+	for ctx.Err() == nil {
+		var count int64
+		var err error
+		if s.retentionMode == LoginHistoryRetentionPurge {
+			count, err = s.loginHistoryRepo.PurgeBefore(cutoff, loginHistoryBatchSize)
+		} else {
+			count, err = s.loginHistoryRepo.ArchiveBefore(cutoff, loginHistoryBatchSize, now)
+		}
+		if err != nil {
+			return err
+		}
+		removed += count
+		if count < loginHistoryBatchSize {
+			break
+		}
+	}
+	if removed > 0 {
+		log.Printf("Login history retention (%s): %d login attempts older than %s", s.retentionMode, removed, cutoff.Format(time.RFC3339))
+	}
+
+	if s.archiveRetention == 0 {
+		return nil
+	}
+	archiveCutoff := now.Add(-s.archiveRetention)
+	var purged int64
+	for ctx.Err() == nil {
+		count, err := s.loginHistoryRepo.PurgeArchiveBefore(archiveCutoff, loginHistoryBatchSize)
+		if err != nil {
+			return err
+		}
+		purged += count
+		if count < loginHistoryBatchSize {
+			break
+		}
+	}
+	if purged > 0 {
+		log.Printf("Purged %d archived login attempts older than %s", purged, archiveCutoff.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// loginHistoryFilter converts a login history query to a repository filter.
+func loginHistoryFilter(query dto.LoginHistoryFilterQuery) repository.LoginHistoryFilter {
+	return repository.LoginHistoryFilter{
+		From:       query.From,
+		To:         query.To,
+		Successful: query.Successful,
+		IPAddress:  query.IPAddress,
+		Device:     query.Device,
+	}
+}
+
+// encodeLoginHistoryCursor returns the opaque cursor of the page after a login attempt.
+func encodeLoginHistoryCursor(cursor repository.LoginHistoryCursor) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", cursor.LoginTime.UnixMicro(), cursor.ID)))
+}
+
+// decodeLoginHistoryCursor parses a cursor returned by encodeLoginHistoryCursor.
+func decodeLoginHistoryCursor(encoded string) (repository.LoginHistoryCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return repository.LoginHistoryCursor{}, ErrInvalidCursor
+	}
+	micros, id, found := strings.Cut(string(raw), ":")
+	if !found {
+		return repository.LoginHistoryCursor{}, ErrInvalidCursor
+	}
+	loginTime, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return repository.LoginHistoryCursor{}, ErrInvalidCursor
+	}
+	historyID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return repository.LoginHistoryCursor{}, ErrInvalidCursor
+	}
+	return repository.LoginHistoryCursor{LoginTime: time.UnixMicro(loginTime), ID: uint(historyID)}, nil
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"auth-service/internal/api/dto"
+	"auth-service/internal/models"
+	"auth-service/internal/repository"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+func (r *fakeLoginHistoryRepository) ListLoginAttempts(userID uint, filter repository.LoginHistoryFilter, after *repository.LoginHistoryCursor, limit int) ([]models.LoginHistory, error) {
+	return r.recorded[:min(limit, len(r.recorded))], nil
+}
+
+func TestLoginHistoryCursor(t *testing.T) {
+	cursor := repository.LoginHistoryCursor{LoginTime: time.Date(2026, 10, 19, 8, 0, 0, 123456000, time.UTC), ID: 42}
+	encode := func(raw string) string { return base64.RawURLEncoding.EncodeToString([]byte(raw)) }
+
+	tests := []struct {
+		name    string
+		encoded string
+		want    repository.LoginHistoryCursor
+		wantErr error
+	}{
+		{name: "round trip", encoded: encodeLoginHistoryCursor(cursor), want: cursor},
+		{name: "not base64", encoded: "!!!", wantErr: ErrInvalidCursor},
+		{name: "no separator", encoded: encode("1760860800123456"), wantErr: ErrInvalidCursor},
+		{name: "invalid time", encoded: encode("yesterday:42"), wantErr: ErrInvalidCursor},
+		{name: "invalid ID", encoded: encode("1760860800123456:x"), wantErr: ErrInvalidCursor},
+		{name: "negative ID", encoded: encode("1760860800123456:-1"), wantErr: ErrInvalidCursor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeLoginHistoryCursor(tt.encoded)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("decodeLoginHistoryCursor() error = %v, want %v", err, tt.wantErr)
+			}
+			if !got.LoginTime.Equal(tt.want.LoginTime) || got.ID != tt.want.ID {
+				t.Errorf("decodeLoginHistoryCursor() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoginHistoryServiceGetLoginAttemptsByUser(t *testing.T) {
+	recorded := make([]models.LoginHistory, 3)
+	for i := range recorded {
+		recorded[i] = models.LoginHistory{UserID: 7, LoginTime: time.Date(2026, 10, 19-i, 8, 0, 0, 0, time.UTC)}
+		recorded[i].ID = uint(30 - i)
+	}
+	tests := []struct {
+		name         string
+		limit        int
+		wantAttempts int
+		wantNext     *repository.LoginHistoryCursor
+	}{
+		{name: "more attempts than the page holds", limit: 2, wantAttempts: 2, wantNext: &repository.LoginHistoryCursor{LoginTime: recorded[1].LoginTime, ID: 29}},
+		{name: "last page", limit: 3, wantAttempts: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &LoginHistoryService{loginHistoryRepo: &fakeLoginHistoryRepository{recorded: recorded}}
+
+			page, err := service.GetLoginAttemptsByUser(7, dto.LoginHistoryQuery{Limit: tt.limit})
+			if err != nil {
+				t.Fatalf("GetLoginAttemptsByUser() error = %v", err)
+			}
+			if len(page.Attempts) != tt.wantAttempts {
+				t.Errorf("got %d attempts, want %d", len(page.Attempts), tt.wantAttempts)
+			}
+			if tt.wantNext == nil {
+				if page.NextCursor != "" {
+					t.Errorf("NextCursor = %q on the last page", page.NextCursor)
+				}
+				return
+			}
+			if next, err := decodeLoginHistoryCursor(page.NextCursor); err != nil || !next.LoginTime.Equal(tt.wantNext.LoginTime) || next.ID != tt.wantNext.ID {
+				t.Errorf("NextCursor decodes to %+v, %v, want %+v", next, err, *tt.wantNext)
+			}
+		})
+	}
+}
+
+func TestLoginHistoryServiceExportLoginAttempts(t *testing.T) {
+	history := models.LoginHistory{UserID: 7, LoginTime: time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC), IPAddress: "203.0.113.5", Successful: true}
+	history.ID = 12
+	archived := models.LoginHistoryArchive{ID: 3, UserID: 7, LoginTime: time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC),
+		LogoutTime: time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC), IPAddress: "198.51.100.1", Successful: true, Anomalies: "new_device new_country"}
+
+	tests := []struct {
+		name     string
+		repo     *fakeLoginHistoryRepository
+		wantRows string
+	}{
+		{name: "no attempts", repo: &fakeLoginHistoryRepository{}},
+		{name: "attempts in the login history", repo: &fakeLoginHistoryRepository{recorded: []models.LoginHistory{history}},
+			wantRows: "12,2026-10-01T08:00:00Z,,203.0.113.5,,true,,,,false\n"},
+		{name: "archived attempts first", repo: &fakeLoginHistoryRepository{recorded: []models.LoginHistory{history}, archived: []models.LoginHistoryArchive{archived}},
+			wantRows: "3,2025-06-01T08:00:00Z,2025-06-01T09:00:00Z,198.51.100.1,,true,,,new_device new_country,true\n" +
+				"12,2026-10-01T08:00:00Z,,203.0.113.5,,true,,,,false\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &LoginHistoryService{loginHistoryRepo: tt.repo}
+			var buf bytes.Buffer
+			if err := service.ExportLoginAttempts(7, dto.LoginHistoryFilterQuery{}, &buf); err != nil {
+				t.Fatalf("ExportLoginAttempts() error = %v", err)
+			}
+			want := "id,loginTime,logoutTime,ipAddress,deviceInformation,successful,failureReason,country,anomalies,archived\n" + tt.wantRows
+			if got := buf.String(); got != want {
+				t.Errorf("ExportLoginAttempts() =\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,302 @@
+package services
+
+import (
+	"auth-service/internal/api/dto"
+	"auth-service/internal/config"
+	"auth-service/internal/models"
+	"auth-service/internal/repository"
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+// Algorithms access tokens can be signed with, selected by JWT_SIGNING_ALGORITHM.
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+const (
+	// keyCacheTTL is how long keys loaded from the database are used before reloading them, so that a rotation
+	// done by another replica is picked up.
+	keyCacheTTL = time.Minute
+	// keyReloadMinInterval limits reloads caused by tokens naming an unknown key.
+	keyReloadMinInterval = 5 * time.Second
+	rsaKeyBits           = 2048
+)
+
+var (
+	// ErrNoSigningKey is returned when no key has been generated yet to sign tokens with.
+	ErrNoSigningKey = errors.New("no token signing key is available")
+	// ErrUnknownSigningKey is returned when a token names a key that is not (or no longer) published.
+	ErrUnknownSigningKey = errors.New("unknown token signing key")
+)
+
+// ActiveSigningKey is the private key access tokens are currently signed with.
+type ActiveSigningKey struct {
+	KID        string
+	Method     jwt.SigningMethod
+	PrivateKey crypto.PrivateKey
+}
+
+// IKeyService defines the interface for managing the keys access tokens are signed with.
+type IKeyService interface {
+	ActiveKey() (*ActiveSigningKey, error)
+	PublicKey(kid string) (crypto.PublicKey, error)
+	JWKS() (*dto.JWKSResponse, error)
+	RotateKeys(ctx context.Context) error
+}
+
+// keySet is a snapshot of the signing keys loaded from the database.
+type keySet struct {
+	active   *ActiveSigningKey
+	public   map[string]crypto.PublicKey
+	jwks     dto.JWKSResponse
+	loadedAt time.Time
+}
+
+// KeyService signs tokens with an asymmetric key that is rotated on a schedule. Retired keys stay published
+// in the JWKS for an overlap period so that tokens they signed can still be verified.
+type KeyService struct {
+	keyRepo          repository.ISigningKeyRepository
+	algorithm        string
+	rotationInterval time.Duration
+	overlap          time.Duration
+	now              func() time.Time
+
+	mu   sync.Mutex
+	keys *keySet
+}
+
+// NewKeyService creates a new instance of KeyService.
+func NewKeyService(keyRepo repository.ISigningKeyRepository) IKeyService {
+	algorithm := os.Getenv("JWT_SIGNING_ALGORITHM")
+	if algorithm == "" {
+		algorithm = AlgorithmRS256
+	}
+	if algorithm != AlgorithmRS256 && algorithm != AlgorithmEdDSA {
+		log.Printf("Unsupported JWT_SIGNING_ALGORITHM %q, defaulting to %s", algorithm, AlgorithmRS256)
+		algorithm = AlgorithmRS256
+	}
+
+	// A retired key must stay published at least until the last token it signed has expired.
+	overlap := config.GetDuration("SIGNING_KEY_OVERLAP", 24*time.Hour)
+	if accessTokenTTL := config.GetDuration("ACCESS_TOKEN_TTL", defaultAccessTokenTTL); overlap < accessTokenTTL {
+		overlap = accessTokenTTL
+	}
+
+	return &KeyService{
+		keyRepo:          keyRepo,
+		algorithm:        algorithm,
+		rotationInterval: config.GetDuration("SIGNING_KEY_ROTATION_INTERVAL", 30*24*time.Hour),
+		overlap:          overlap,
+		now:              time.Now,
+	}
+}
+
+// ActiveKey returns the key new tokens are signed with.
+func (s *KeyService) ActiveKey() (*ActiveSigningKey, error) {
+	keys, err := s.loadKeys(false)
+	if err != nil {
+		return nil, err
+	}
+	if keys.active == nil {
+		return nil, ErrNoSigningKey
+	}
+	return keys.active, nil
+}
+
+// PublicKey returns the published public key with the given key ID.
+func (s *KeyService) PublicKey(kid string) (crypto.PublicKey, error) {
+	keys, err := s.loadKeys(false)
+	if err != nil {
+		return nil, err
+	}
+	if key, ok := keys.public[kid]; ok {
+		return key, nil
+	}
+
+	// The key may have been created by another replica since the keys were loaded.
+	if keys, err = s.loadKeys(true); err != nil {
+		return nil, err
+	}
+	if key, ok := keys.public[kid]; ok {
+		return key, nil
+	}
+	return nil, ErrUnknownSigningKey
+}
+
+// JWKS returns the public keys tokens may currently be verified with.
+func (s *KeyService) JWKS() (*dto.JWKSResponse, error) {
+	keys, err := s.loadKeys(false)
+	if err != nil {
+		return nil, err
+	}
+	return &keys.jwks, nil
+}
+
+// RotateKeys generates a new signing key when there is none yet, when the active key is older than the
+// rotation interval, or when the configured algorithm changed. The previous key is retired but stays
+// published for the overlap period.
+func (s *KeyService) RotateKeys(ctx context.Context) error {
+	active, err := s.keyRepo.FindActiveKey()
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	now := s.now()
+	if active != nil && active.Algorithm == s.algorithm && now.Sub(active.CreatedAt) < s.rotationInterval {
+		return nil
+	}
+
+	next, err := generateSigningKey(s.algorithm)
+	if err != nil {
+		return fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	if err := s.keyRepo.RotateKey(next, now, now.Add(s.overlap)); err != nil {
+		return fmt.Errorf("failed to store signing key: %w", err)
+	}
+	if _, err := s.loadKeys(true); err != nil {
+		return err
+	}
+	log.Printf("Rotated token signing key, now signing with %s key %s", next.Algorithm, next.KID)
+	return nil
+}
+
+// loadKeys returns the cached keys, reloading them from the database when they are stale or force is set.
+func (s *KeyService) loadKeys(force bool) (*keySet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if s.keys != nil {
+		age := now.Sub(s.keys.loadedAt)
+		if age < keyCacheTTL && s.keys.active != nil && (!force || age < keyReloadMinInterval) {
+			return s.keys, nil
+		}
+	}
+
+	active, err := s.keyRepo.FindActiveKey()
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	published, err := s.keyRepo.ListPublishedKeys(now)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := &keySet{
+		public:   make(map[string]crypto.PublicKey, len(published)),
+		jwks:     dto.JWKSResponse{Keys: make([]dto.JWK, 0, len(published))},
+		loadedAt: now,
+	}
+	for _, key := range published {
+		publicKey, err := parsePublicKey(key.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key %s: %w", key.KID, err)
+		}
+		jwk, err := dto.NewJWK(publicKey)
+		if err != nil {
+			return nil, err
+		}
+		jwk.Kid, jwk.Use, jwk.Alg = key.KID, "sig", key.Algorithm
+		keys.public[key.KID] = publicKey
+		keys.jwks.Keys = append(keys.jwks.Keys, jwk)
+	}
+	if active != nil {
+		privateKey, err := parsePrivateKey(active.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid private key %s: %w", active.KID, err)
+		}
+		keys.active = &ActiveSigningKey{
+			KID:        active.KID,
+			Method:     jwt.GetSigningMethod(active.Algorithm),
+			PrivateKey: privateKey,
+		}
+	}
+
+	s.keys = keys
+	return keys, nil
+}
+
+// generateSigningKey creates a new key pair for algorithm, identified by the thumbprint of its public key.
+func generateSigningKey(algorithm string) (*models.SigningKey, error) {
+	var privateKey crypto.Signer
+	var err error
+	switch algorithm {
+	case AlgorithmEdDSA:
+		_, privateKey, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		privateKey, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(privateKey.Public())
+	if err != nil {
+		return nil, err
+	}
+	kid, err := thumbprint(privateKey.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SigningKey{
+		KID:        kid,
+		Algorithm:  algorithm,
+		PrivateKey: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})),
+		PublicKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})),
+	}, nil
+}
+
+// thumbprint computes the RFC 7638 JWK thumbprint of a public key: the SHA-256 of its required JWK members
+// in lexicographic order.
+func thumbprint(publicKey crypto.PublicKey) (string, error) {
+	jwk, err := dto.NewJWK(publicKey)
+	if err != nil {
+		return "", err
+	}
+	var canonical string
+	if jwk.Kty == "RSA" {
+		canonical = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, jwk.E, jwk.N)
+	} else {
+		canonical = fmt.Sprintf(`{"crv":%q,"kty":"OKP","x":%q}`, jwk.Crv, jwk.X)
+	}
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+func parsePrivateKey(encoded string) (crypto.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(encoded))
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	return x509.ParsePKCS8PrivateKey(block.Bytes)
+}
+
+func parsePublicKey(encoded string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(encoded))
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"auth-service/internal/models"
+	"auth-service/internal/repository"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// fakeSigningKeyRepository keeps signing keys in memory, newest last.
+type fakeSigningKeyRepository struct {
+	repository.ISigningKeyRepository
+	keys []models.SigningKey
+}
+
+func (r *fakeSigningKeyRepository) FindActiveKey() (*models.SigningKey, error) {
+	for i := len(r.keys) - 1; i >= 0; i-- {
+		if r.keys[i].RetiredAt == nil {
+			return &r.keys[i], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeSigningKeyRepository) ListPublishedKeys(now time.Time) ([]models.SigningKey, error) {
+	var published []models.SigningKey
+	for _, key := range r.keys {
+		if key.ExpiresAt == nil || key.ExpiresAt.After(now) {
+			published = append(published, key)
+		}
+	}
+	return published, nil
+}
+
+func (r *fakeSigningKeyRepository) RotateKey(next *models.SigningKey, retiredAt, expiresAt time.Time) error {
+	for i := range r.keys {
+		if r.keys[i].RetiredAt == nil {
+			r.keys[i].RetiredAt, r.keys[i].ExpiresAt = &retiredAt, &expiresAt
+		}
+	}
+	next.CreatedAt = retiredAt
+	r.keys = append(r.keys, *next)
+	return nil
+}
+
+// storedKey generates a signing key created at createdAt.
+func storedKey(t *testing.T, algorithm string, createdAt time.Time) models.SigningKey {
+	t.Helper()
+	key, err := generateSigningKey(algorithm)
+	if err != nil {
+		t.Fatalf("generateSigningKey() error = %v", err)
+	}
+	key.CreatedAt = createdAt
+	return *key
+}
+
+func TestRotateKeys(t *testing.T) {
+	now := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		existing    []models.SigningKey
+		wantRotated bool
+	}{
+		{name: "no key yet", wantRotated: true},
+		{name: "recent key", existing: []models.SigningKey{storedKey(t, AlgorithmEdDSA, now.Add(-time.Hour))}},
+		{name: "key older than the rotation interval", existing: []models.SigningKey{storedKey(t, AlgorithmEdDSA, now.Add(-31*24*time.Hour))}, wantRotated: true},
+		{name: "algorithm changed", existing: []models.SigningKey{storedKey(t, AlgorithmRS256, now.Add(-time.Hour))}, wantRotated: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeSigningKeyRepository{keys: tt.existing}
+			service := &KeyService{keyRepo: repo, algorithm: AlgorithmEdDSA, rotationInterval: 30 * 24 * time.Hour,
+				overlap: 24 * time.Hour, now: func() time.Time { return now }}
+
+			if err := service.RotateKeys(context.Background()); err != nil {
+				t.Fatalf("RotateKeys() error = %v", err)
+			}
+			if rotated := len(repo.keys) != len(tt.existing); rotated != tt.wantRotated {
+				t.Fatalf("rotated = %v, want %v", rotated, tt.wantRotated)
+			}
+
+			active, err := service.ActiveKey()
+			if err != nil {
+				t.Fatalf("ActiveKey() error = %v", err)
+			}
+			if want := repo.keys[len(repo.keys)-1].KID; active.KID != want || active.Method.Alg() != AlgorithmEdDSA {
+				t.Errorf("ActiveKey() = %s %s, want %s %s", active.Method.Alg(), active.KID, AlgorithmEdDSA, want)
+			}
+			// A retired key stays published until the tokens it signed have expired
+			jwks, err := service.JWKS()
+			if err != nil {
+				t.Fatalf("JWKS() error = %v", err)
+			}
+			if len(jwks.Keys) != len(repo.keys) {
+				t.Errorf("JWKS() publishes %d keys, want %d", len(jwks.Keys), len(repo.keys))
+			}
+		})
+	}
+}
+
+func TestPublicKeyReloadsKeysRotatedByAnotherReplica(t *testing.T) {
+	now := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	repo := &fakeSigningKeyRepository{keys: []models.SigningKey{storedKey(t, AlgorithmEdDSA, now)}}
+	service := &KeyService{keyRepo: repo, algorithm: AlgorithmEdDSA, now: func() time.Time { return now }}
+	if _, err := service.ActiveKey(); err != nil {
+		t.Fatalf("ActiveKey() error = %v", err)
+	}
+
+	next := storedKey(t, AlgorithmEdDSA, now)
+	repo.RotateKey(&next, now, now.Add(time.Hour))
+	rotated := next.KID
+	if _, err := service.PublicKey(rotated); !errors.Is(err, ErrUnknownSigningKey) {
+		t.Errorf("PublicKey() right after loading error = %v, want %v", err, ErrUnknownSigningKey)
+	}
+
+	now = now.Add(keyReloadMinInterval)
+	if _, err := service.PublicKey(rotated); err != nil {
+		t.Errorf("PublicKey() error = %v, want the rotated key", err)
+	}
+	if _, err := service.PublicKey(repo.keys[0].KID); err != nil {
+		t.Errorf("PublicKey() of the retired key error = %v", err)
+	}
+}
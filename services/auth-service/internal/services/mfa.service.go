@@ -0,0 +1,492 @@
+package services
+
+import (
+	"auth-service/internal/api/dto"
+	"auth-service/internal/config"
+	"auth-service/internal/models"
+	"auth-service/internal/repository"
+	"auth-service/pkg"
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image/png"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"gorm.io/gorm"
+)
+
+const (
+	totpPeriod = 30 // Seconds per TOTP time step
+	// totpSkew is how many time steps before and after the current one are accepted, for clock drift.
+	totpSkew = 1
+	// recoveryCodeCount is how many recovery codes are generated at a time.
+	recoveryCodeCount = 10
+	// maxMFAChallengeAttempts is how many codes a login challenge accepts before it is discarded.
+	maxMFAChallengeAttempts = 5
+	qrCodeSize              = 256
+)
+
+// Errors returned by the MFA service so handlers can map them to status codes.
+var (
+	ErrMFAAlreadyEnabled = errors.New("multi-factor authentication is already enabled")
+	ErrMFANotEnrolled    = errors.New("multi-factor authentication is not set up")
+	ErrMFARequired       = errors.New("multi-factor authentication is required for your role and cannot be disabled")
+	ErrInvalidMFACode    = errors.New("invalid authentication code")
+	ErrInvalidMFAToken   = errors.New("invalid or expired mfa token, please log in again")
+	ErrUnknownRole       = errors.New("unknown role")
+)
+
+// IMFAService defines the interface for TOTP multi-factor authentication.
+type IMFAService interface {
+	GetStatus(userID uint) (*dto.MFAStatusResponse, error)
+	BeginEnrollment(userID uint) (*dto.MFAEnrollmentResponse, error)
+	ConfirmEnrollment(userID uint, req dto.MFACodeRequest, actor dto.Actor) (*dto.RecoveryCodesResponse, error)
+	RegenerateRecoveryCodes(userID uint, req dto.MFACodeRequest, actor dto.Actor) (*dto.RecoveryCodesResponse, error)
+	Disable(userID uint, req dto.MFACodeRequest, actor dto.Actor) error
+	LoginRequirement(user models.User) (enrolled bool, required bool, err error)
+	StartChallenge(user models.User, loginDTO dto.UserLoginDTO) (string, error)
+	BeginChallengeEnrollment(req dto.MFAEnrollLoginRequest) (*dto.MFAEnrollmentResponse, error)
+	CompleteChallenge(req dto.MFALoginRequest) (*models.MFAChallenge, []string, error)
+	ListPolicies() ([]dto.MFAPolicyResponse, error)
+	SetPolicy(role string, req dto.MFAPolicyRequest, actor dto.Actor) (dto.MFAPolicyResponse, error)
+}
+
+// MFAService manages TOTP enrollments and recovery codes, and the second step of logins to accounts using them.
+type MFAService struct {
+	mfaRepo      repository.IMFARepository
+	userRepo     repository.IUserRepository
+	roleRepo     repository.IRoleRepository
+	auditService IAuditService
+	issuer       string
+	challengeTTL time.Duration
+}
+
+// NewMFAService creates a new instance of MFAService.
+func NewMFAService(mfaRepo repository.IMFARepository, userRepo repository.IUserRepository, roleRepo repository.IRoleRepository, auditService IAuditService) IMFAService {
+	issuer := os.Getenv("MFA_ISSUER")
+	if issuer == "" {
+		issuer = "e-ticket"
+	}
+	return &MFAService{
+		mfaRepo:      mfaRepo,
+		userRepo:     userRepo,
+		roleRepo:     roleRepo,
+		auditService: auditService,
+		issuer:       issuer,
+		challengeTTL: config.GetDuration("MFA_CHALLENGE_TTL", 5*time.Minute),
+	}
+}
+
+// GetStatus describes whether a user has MFA enabled, whether their role requires it and how many recovery
+// codes they have left.
+func (s *MFAService) GetStatus(userID uint) (*dto.MFAStatusResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	enrollment, err := s.findEnrollment(userID)
+	if err != nil {
+		return nil, err
+	}
+	required, err := s.isRequired(*user)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &dto.MFAStatusResponse{Required: required}
+	if enrollment != nil && enrollment.ConfirmedAt != nil {
+		status.Enabled, status.ConfirmedAt = true, enrollment.ConfirmedAt
+		if status.RecoveryCodesRemaining, err = s.mfaRepo.CountRecoveryCodes(userID); err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+// BeginEnrollment generates a new TOTP secret for a user, replacing an unconfirmed one. MFA is enabled once
+// the enrollment is confirmed with a code.
+func (s *MFAService) BeginEnrollment(userID uint) (*dto.MFAEnrollmentResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	enrollment, err := s.findEnrollment(userID)
+	if err != nil {
+		return nil, err
+	}
+	if enrollment != nil && enrollment.ConfirmedAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{Issuer: s.issuer, AccountName: user.Email, Period: totpPeriod})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate totp secret: %w", err)
+	}
+	if err := s.mfaRepo.ReplaceEnrollment(&models.MFAEnrollment{UserID: userID, Secret: key.Secret()}); err != nil {
+		return nil, err
+	}
+
+	qrCode, err := qrCodeDataURI(key)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.MFAEnrollmentResponse{Secret: key.Secret(), OTPAuthURI: key.URL(), QRCode: qrCode}, nil
+}
+
+// ConfirmEnrollment enables MFA with the first code of the authenticator and returns the user's recovery codes.
+func (s *MFAService) ConfirmEnrollment(userID uint, req dto.MFACodeRequest, actor dto.Actor) (*dto.RecoveryCodesResponse, error) {
+	enrollment, err := s.findEnrollment(userID)
+	if err != nil {
+		return nil, err
+	}
+	if enrollment == nil {
+		return nil, ErrMFANotEnrolled
+	}
+	if enrollment.ConfirmedAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	codes, err := s.confirm(enrollment, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	s.auditService.Record(actor, AuditMFAEnabled, AuditTargetUser, userID, nil)
+	return &dto.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of a user after checking a code.
+func (s *MFAService) RegenerateRecoveryCodes(userID uint, req dto.MFACodeRequest, actor dto.Actor) (*dto.RecoveryCodesResponse, error) {
+	enrollment, err := s.confirmedEnrollment(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.verifyCode(enrollment, req.Code); err != nil {
+		return nil, err
+	}
+
+	codes, recoveryCodes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.ReplaceRecoveryCodes(userID, recoveryCodes); err != nil {
+		return nil, err
+	}
+	s.auditService.Record(actor, AuditMFARecoveryCodesRegenerated, AuditTargetUser, userID, nil)
+	return &dto.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// Disable turns MFA off for a user after checking a code, unless the user's role requires it.
+func (s *MFAService) Disable(userID uint, req dto.MFACodeRequest, actor dto.Actor) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	required, err := s.isRequired(*user)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrMFARequired
+	}
+
+	enrollment, err := s.confirmedEnrollment(userID)
+	if err != nil {
+		return err
+	}
+	if err := s.verifyCode(enrollment, req.Code); err != nil {
+		return err
+	}
+	if err := s.mfaRepo.DeleteEnrollment(userID); err != nil {
+		return err
+	}
+	s.auditService.Record(actor, AuditMFADisabled, AuditTargetUser, userID, nil)
+	return nil
+}
+
+// LoginRequirement reports whether a user has MFA enabled and whether their role requires it. A login needs
+// a code when either is true.
+func (s *MFAService) LoginRequirement(user models.User) (bool, bool, error) {
+	enrollment, err := s.findEnrollment(user.ID)
+	if err != nil {
+		return false, false, err
+	}
+	required, err := s.isRequired(user)
+	if err != nil {
+		return false, false, err
+	}
+	return enrollment != nil && enrollment.ConfirmedAt != nil, required, nil
+}
+
+// StartChallenge creates the second step of a login whose password was verified and returns its token.
+func (s *MFAService) StartChallenge(user models.User, loginDTO dto.UserLoginDTO) (string, error) {
+	token, err := pkg.GenerateToken()
+	if err != nil {
+		return "", err
+	}
+	challenge := models.MFAChallenge{
+		UserID:            user.ID,
+		TokenHash:         hashToken(token),
+		IPAddress:         loginDTO.IPAddress,
+		DeviceInformation: loginDTO.DeviceInformation,
+		ExpiresAt:         time.Now().Add(s.challengeTTL),
+	}
+	if err := s.mfaRepo.CreateChallenge(&challenge); err != nil {
+		return "", fmt.Errorf("failed to create mfa challenge: %w", err)
+	}
+	return token, nil
+}
+
+// BeginChallengeEnrollment starts the enrollment of a user whose role requires MFA during their login. The login
+// completes with the first code of the authenticator.
+func (s *MFAService) BeginChallengeEnrollment(req dto.MFAEnrollLoginRequest) (*dto.MFAEnrollmentResponse, error) {
+	challenge, err := s.activeChallenge(req.MFAToken)
+	if err != nil {
+		return nil, err
+	}
+	return s.BeginEnrollment(challenge.UserID)
+}
+
+// CompleteChallenge checks the code of a login challenge, confirming the user's enrollment if it was set up
+// during the login, in which case the new recovery codes are returned. Every code counts as an attempt before
+// it is compared. The challenge is returned along with ErrInvalidMFACode so the failed attempt can be recorded.
+func (s *MFAService) CompleteChallenge(req dto.MFALoginRequest) (*models.MFAChallenge, []string, error) {
+	challenge, err := s.activeChallenge(req.MFAToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	enrollment, err := s.findEnrollment(challenge.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if enrollment == nil {
+		return nil, nil, ErrMFANotEnrolled
+	}
+	if err := s.mfaRepo.IncrementChallengeAttempts(challenge.ID, maxMFAChallengeAttempts); err != nil {
+		if errors.Is(err, repository.ErrMFAChallengeAttemptsExceeded) {
+			return nil, nil, ErrInvalidMFAToken
+		}
+		return nil, nil, err
+	}
+
+	var codes []string
+	if enrollment.ConfirmedAt == nil {
+		codes, err = s.confirm(enrollment, req.Code)
+	} else {
+		err = s.verifyCode(enrollment, req.Code)
+	}
+	if err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			return challenge, nil, ErrInvalidMFACode
+		}
+		return nil, nil, err
+	}
+
+	if err := s.mfaRepo.CompleteChallenge(challenge.ID, time.Now()); err != nil {
+		if errors.Is(err, repository.ErrMFAChallengeCompleted) {
+			return nil, nil, ErrInvalidMFAToken
+		}
+		return nil, nil, err
+	}
+	if codes != nil {
+		actor := dto.Actor{UserID: challenge.UserID, IPAddress: challenge.IPAddress, UserAgent: challenge.DeviceInformation}
+		s.auditService.Record(actor, AuditMFAEnabled, AuditTargetUser, challenge.UserID, nil)
+	}
+	return challenge, codes, nil
+}
+
+// ListPolicies lists the MFA policies of the roles.
+func (s *MFAService) ListPolicies() ([]dto.MFAPolicyResponse, error) {
+	policies, err := s.mfaRepo.ListPolicies()
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]dto.MFAPolicyResponse, 0, len(policies))
+	for _, policy := range policies {
+		responses = append(responses, dto.FromMFAPolicyModel(policy))
+	}
+	return responses, nil
+}
+
+// SetPolicy sets whether users of a role must use MFA. Users of the role without MFA set it up at their
+// next login.
+func (s *MFAService) SetPolicy(role string, req dto.MFAPolicyRequest, actor dto.Actor) (dto.MFAPolicyResponse, error) {
+	if _, err := s.roleRepo.FindRole(role); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.MFAPolicyResponse{}, ErrUnknownRole
+		}
+		return dto.MFAPolicyResponse{}, err
+	}
+	policies, err := s.mfaRepo.ListPolicies()
+	if err != nil {
+		return dto.MFAPolicyResponse{}, err
+	}
+	required := false
+	for _, policy := range policies {
+		if policy.Role == role {
+			required = policy.Required
+		}
+	}
+
+	policy := models.MFAPolicy{Role: role, Required: req.Required, UpdatedBy: actor.UserID, UpdatedAt: time.Now()}
+	if err := s.mfaRepo.SavePolicy(&policy); err != nil {
+		return dto.MFAPolicyResponse{}, err
+	}
+	s.auditService.Record(actor, AuditMFAPolicyChanged, AuditTargetMFAPolicy, role, auditChange("required", required, req.Required))
+	return dto.FromMFAPolicyModel(policy), nil
+}
+
+// confirm enables an unconfirmed enrollment with its first valid TOTP code and returns new recovery codes.
+func (s *MFAService) confirm(enrollment *models.MFAEnrollment, code string) ([]string, error) {
+	step, ok := matchTOTP(enrollment.Secret, normalizeCode(code), enrollment.LastUsedStep, time.Now())
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+	codes, recoveryCodes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.ConfirmEnrollment(enrollment, step, recoveryCodes, time.Now()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMFAAlreadyEnabled
+		}
+		return nil, err
+	}
+	return codes, nil
+}
+
+// verifyCode accepts a TOTP code that was not used before or an unused recovery code, which is then used up.
+func (s *MFAService) verifyCode(enrollment *models.MFAEnrollment, code string) error {
+	code = normalizeCode(code)
+	now := time.Now()
+	if len(code) == otp.DigitsSix.Length() {
+		step, ok := matchTOTP(enrollment.Secret, code, enrollment.LastUsedStep, now)
+		if !ok {
+			return ErrInvalidMFACode
+		}
+		if err := s.mfaRepo.UseStep(enrollment.ID, step); err != nil {
+			if errors.Is(err, repository.ErrMFACodeReused) {
+				return ErrInvalidMFACode
+			}
+			return err
+		}
+		return nil
+	}
+
+	if err := s.mfaRepo.UseRecoveryCode(enrollment.UserID, hashToken(code), now); err != nil {
+		if errors.Is(err, repository.ErrRecoveryCodeNotFound) {
+			return ErrInvalidMFACode
+		}
+		return err
+	}
+	return nil
+}
+
+// activeChallenge returns the login challenge of a token, unless it was completed, expired or given too many
+// invalid codes.
+func (s *MFAService) activeChallenge(token string) (*models.MFAChallenge, error) {
+	challenge, err := s.mfaRepo.FindChallengeByHash(hashToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidMFAToken
+		}
+		return nil, err
+	}
+	if challenge.CompletedAt != nil || time.Now().After(challenge.ExpiresAt) || challenge.Attempts >= maxMFAChallengeAttempts {
+		return nil, ErrInvalidMFAToken
+	}
+	return challenge, nil
+}
+
+func (s *MFAService) findEnrollment(userID uint) (*models.MFAEnrollment, error) {
+	enrollment, err := s.mfaRepo.FindEnrollment(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return enrollment, nil
+}
+
+func (s *MFAService) confirmedEnrollment(userID uint) (*models.MFAEnrollment, error) {
+	enrollment, err := s.findEnrollment(userID)
+	if err != nil {
+		return nil, err
+	}
+	if enrollment == nil || enrollment.ConfirmedAt == nil {
+		return nil, ErrMFANotEnrolled
+	}
+	return enrollment, nil
+}
+
+// isRequired reports whether any role of the user requires MFA.
+func (s *MFAService) isRequired(user models.User) (bool, error) {
+	roles, _, err := resolveAccess(s.roleRepo, user)
+	if err != nil {
+		return false, err
+	}
+	return s.mfaRepo.IsRequiredForAny(roles)
+}
+
+// matchTOTP returns the time step of the TOTP code matching code around now. Steps at or before lastUsedStep
+// are skipped, so that a code cannot be used twice.
+func matchTOTP(secret, code string, lastUsedStep int64, now time.Time) (int64, bool) {
+	opts := totp.ValidateOpts{Period: totpPeriod, Digits: otp.DigitsSix, Algorithm: otp.AlgorithmSHA1}
+	for skew := -totpSkew; skew <= totpSkew; skew++ {
+		at := now.Add(time.Duration(skew*totpPeriod) * time.Second)
+		step := at.Unix() / totpPeriod
+		if step <= lastUsedStep {
+			continue
+		}
+		expected, err := totp.GenerateCodeCustom(secret, at, opts)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// newRecoveryCodes generates a set of recovery codes formatted as XXXX-XXXX, with their models to store.
+func newRecoveryCodes() ([]string, []models.RecoveryCode, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	recoveryCodes := make([]models.RecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		code := base32.StdEncoding.EncodeToString(b)
+		codes = append(codes, code[:4]+"-"+code[4:])
+		recoveryCodes = append(recoveryCodes, models.RecoveryCode{CodeHash: hashToken(code)})
+	}
+	return codes, recoveryCodes, nil
+}
+
+// normalizeCode removes the separators users may type in codes and upper-cases recovery codes.
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(code))
+}
+
+// qrCodeDataURI renders the otpauth URI of a key as a PNG data URI.
+func qrCodeDataURI(key *otp.Key) (string, error) {
+	img, err := key.Image(qrCodeSize, qrCodeSize)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return "", err
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"auth-service/internal/api/dto"
+	"auth-service/internal/models"
+	"auth-service/internal/repository"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"gorm.io/gorm"
+)
+
+// fakeMFARepository holds a single login challenge of a user with a confirmed enrollment and one recovery code.
+type fakeMFARepository struct {
+	repository.IMFARepository
+	mu           sync.Mutex
+	challenge    *models.MFAChallenge
+	recoveryHash string
+}
+
+func (r *fakeMFARepository) FindChallengeByHash(tokenHash string) (*models.MFAChallenge, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.challenge.TokenHash != tokenHash {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *r.challenge
+	return &copied, nil
+}
+
+func (r *fakeMFARepository) FindEnrollment(userID uint) (*models.MFAEnrollment, error) {
+	confirmedAt := time.Now()
+	return &models.MFAEnrollment{UserID: userID, Secret: "JBSWY3DPEHPK3PXP", ConfirmedAt: &confirmedAt}, nil
+}
+
+func (r *fakeMFARepository) UseRecoveryCode(userID uint, codeHash string, usedAt time.Time) error {
+	if codeHash != r.recoveryHash {
+		return repository.ErrRecoveryCodeNotFound
+	}
+	return nil
+}
+
+func (r *fakeMFARepository) IncrementChallengeAttempts(challengeID uint, maxAttempts int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.challenge.Attempts >= maxAttempts {
+		return repository.ErrMFAChallengeAttemptsExceeded
+	}
+	r.challenge.Attempts++
+	return nil
+}
+
+func (r *fakeMFARepository) CompleteChallenge(challengeID uint, completedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.challenge.CompletedAt != nil {
+		return repository.ErrMFAChallengeCompleted
+	}
+	r.challenge.CompletedAt = &completedAt
+	return nil
+}
+
+func newFakeMFARepository() *fakeMFARepository {
+	return &fakeMFARepository{
+		challenge: &models.MFAChallenge{
+			UserID:    7,
+			TokenHash: hashToken("mfa-token"),
+			ExpiresAt: time.Now().Add(time.Minute),
+		},
+		recoveryHash: hashToken("RECOVERY1"),
+	}
+}
+
+func TestMFAServiceDiscardsGuessedChallenges(t *testing.T) {
+	service := &MFAService{mfaRepo: newFakeMFARepository()}
+
+	for i := 0; i < maxMFAChallengeAttempts; i++ {
+		if _, _, err := service.CompleteChallenge(dto.MFALoginRequest{MFAToken: "mfa-token", Code: "GUESS"}); !errors.Is(err, ErrInvalidMFACode) {
+			t.Fatalf("CompleteChallenge() attempt %d error = %v, want %v", i+1, err, ErrInvalidMFACode)
+		}
+	}
+	if _, _, err := service.CompleteChallenge(dto.MFALoginRequest{MFAToken: "mfa-token", Code: "RECOVERY1"}); !errors.Is(err, ErrInvalidMFAToken) {
+		t.Errorf("CompleteChallenge() with the right code after %d wrong ones error = %v, want %v", maxMFAChallengeAttempts, err, ErrInvalidMFAToken)
+	}
+}
+
+func TestMFAServiceCompleteChallengeConcurrently(t *testing.T) {
+	repo := newFakeMFARepository()
+	service := &MFAService{mfaRepo: repo}
+
+	// Requests that read the challenge before any of them counted an attempt still try at most
+	// maxMFAChallengeAttempts codes.
+	var wg sync.WaitGroup
+	for i := 0; i < 4*maxMFAChallengeAttempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			service.CompleteChallenge(dto.MFALoginRequest{MFAToken: "mfa-token", Code: "GUESS"})
+		}()
+	}
+	wg.Wait()
+	if repo.challenge.Attempts != maxMFAChallengeAttempts {
+		t.Errorf("attempts = %d, want %d", repo.challenge.Attempts, maxMFAChallengeAttempts)
+	}
+}
+
+func TestMatchTOTP(t *testing.T) {
+	const secret = "JBSWY3DPEHPK3PXP"
+	now := time.Date(2026, 10, 19, 8, 0, 15, 0, time.UTC)
+	step := now.Unix() / totpPeriod
+	code := func(at time.Time) string {
+		generated, err := totp.GenerateCodeCustom(secret, at, totp.ValidateOpts{Period: totpPeriod, Digits: otp.DigitsSix, Algorithm: otp.AlgorithmSHA1})
+		if err != nil {
+			t.Fatalf("GenerateCodeCustom() error = %v", err)
+		}
+		return generated
+	}
+
+	tests := []struct {
+		name         string
+		code         string
+		lastUsedStep int64
+		wantStep     int64
+		wantOK       bool
+	}{
+		{name: "current step", code: code(now), wantStep: step, wantOK: true},
+		{name: "previous step", code: code(now.Add(-totpPeriod * time.Second)), wantStep: step - 1, wantOK: true},
+		{name: "next step", code: code(now.Add(totpPeriod * time.Second)), wantStep: step + 1, wantOK: true},
+		{name: "outside the skew", code: code(now.Add(-2 * totpPeriod * time.Second))},
+		{name: "already used", code: code(now), lastUsedStep: step},
+		{name: "later code after an earlier one was used", code: code(now), lastUsedStep: step - 1, wantStep: step, wantOK: true},
+		{name: "wrong code", code: "000000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, ok := matchTOTP(secret, tt.code, tt.lastUsedStep, now)
+			if ok != tt.wantOK || gotStep != tt.wantStep {
+				t.Errorf("matchTOTP() = %d, %v, want %d, %v", gotStep, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
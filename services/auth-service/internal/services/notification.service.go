@@ -0,0 +1,55 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+var (
+	notificationServiceBaseURL = os.Getenv("NOTIFICATION_SERVICE_BASE_URL")
+)
+
+// NotificationChannelEmail is the notification-service channel for emails.
+const NotificationChannelEmail = "email"
+
+// INotificationService defines the notifications auth-service sends to users through notification-service.
+type INotificationService interface {
+	SendNotification(userID uint, notificationType, channel, content string) error
+}
+
+// NotificationService talks to notification-service over HTTP, authenticated with a service token.
+type NotificationService struct {
+	restyClient *resty.Client
+}
+
+// NewNotificationService creates a new instance of NotificationService.
+func NewNotificationService() INotificationService {
+	return &NotificationService{
+		restyClient: newServiceClient(ScopeNotificationsSend).SetTimeout(10 * time.Second),
+	}
+}
+
+// SendNotification queues a notification of the given type for a user.
+func (s *NotificationService) SendNotification(userID uint, notificationType, channel, content string) error {
+	resp, err := s.restyClient.R().
+		SetBody(map[string]interface{}{
+			"userID":   userID,
+			"type":     notificationType,
+			"status":   "pending",
+			"channel":  channel,
+			"content":  content,
+			"sendDate": time.Now(),
+		}).
+		Post(notificationServiceBaseURL + "/")
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusCreated {
+		return fmt.Errorf("notification Service responded with status code: %d", resp.StatusCode())
+	}
+	return nil
+}
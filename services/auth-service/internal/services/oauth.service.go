@@ -0,0 +1,617 @@
+package services
+
+import (
+	"auth-service/internal/api/dto"
+	"auth-service/internal/config"
+	"auth-service/internal/models"
+	"auth-service/internal/repository"
+	"auth-service/pkg"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+// Scopes supported by the OpenID Connect provider.
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+)
+
+// Scopes service clients may be granted, checked by the services they call.
+const (
+	ScopeRoutesRead            = "routes:read"             // Read routes from route-service
+	ScopeBusesRead             = "buses:read"              // Read buses from bus-service
+	ScopeSeatsWrite            = "seats:write"             // Change seat statuses in bus-service
+	ScopeRatingsRead           = "ratings:read"            // Read the ratings of buses and routes from booking-service
+	ScopeAPIKeysIntrospect     = "api-keys:introspect"     // Check the API keys sent to the service with auth-service
+	ScopeNotificationsSend     = "notifications:send"      // Send notifications to users through notification-service
+	ScopeProfilesUserData      = "profiles:user-data"      // Export and erase the data of accounts in profile-service
+	ScopeNotificationsUserData = "notifications:user-data" // Export and erase the data of accounts in notification-service
+	ScopeBookingsUserData      = "bookings:user-data"      // Export and erase the data of accounts in booking-service
+)
+
+// Grant types supported by the token endpoint.
+const (
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeRefreshToken      = "refresh_token"
+	GrantTypeClientCredentials = "client_credentials"
+)
+
+const (
+	responseTypeCode = "code"
+	pkceMethodS256   = "S256"
+)
+
+var (
+	supportedScopes = []string{ScopeOpenID, ScopeProfile, ScopeEmail}
+	serviceScopes   = []string{ScopeRoutesRead, ScopeBusesRead, ScopeSeatsWrite, ScopeRatingsRead, ScopeAPIKeysIntrospect,
+		ScopeNotificationsSend, ScopeProfilesUserData, ScopeNotificationsUserData, ScopeBookingsUserData}
+)
+
+// OAuthError is an error of the OAuth 2.0 protocol, reported to clients with its error code.
+type OAuthError struct {
+	Code        string
+	Description string
+}
+
+func (e *OAuthError) Error() string {
+	return e.Description
+}
+
+// OAuth errors, wrapped with a more specific description where useful.
+var (
+	ErrInvalidOAuthRequest     = &OAuthError{Code: "invalid_request", Description: "invalid request"}
+	ErrInvalidClient           = &OAuthError{Code: "invalid_client", Description: "client authentication failed"}
+	ErrUnauthorizedClient      = &OAuthError{Code: "unauthorized_client", Description: "the client is not allowed to use this grant type"}
+	ErrInvalidGrant            = &OAuthError{Code: "invalid_grant", Description: "invalid, expired or used authorization grant"}
+	ErrInvalidScope            = &OAuthError{Code: "invalid_scope", Description: "invalid scope"}
+	ErrUnsupportedGrantType    = &OAuthError{Code: "unsupported_grant_type", Description: "unsupported grant type"}
+	ErrUnsupportedResponseType = &OAuthError{Code: "unsupported_response_type", Description: "only the code response type is supported"}
+	ErrOAuthClientNotFound     = errors.New("oauth client not found")
+	ErrOAuthConsentNotFound    = errors.New("no consent was given to this client")
+)
+
+// IDTokenClaims are the claims of an OpenID Connect ID token.
+type IDTokenClaims struct {
+	AuthorizedParty   string `json:"azp"`
+	Nonce             string `json:"nonce,omitempty"`
+	AuthTime          int64  `json:"auth_time"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	Email             string `json:"email,omitempty"`
+	EmailVerified     *bool  `json:"email_verified,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// IOAuthService defines the interface for the OpenID Connect provider.
+type IOAuthService interface {
+	RegisterClient(req dto.RegisterOAuthClientRequest, actor dto.Actor) (*dto.OAuthClientResponse, error)
+	ListClients() ([]dto.OAuthClientResponse, error)
+	DeleteClient(clientID string, actor dto.Actor) error
+	Authorize(req dto.AuthorizationRequest, decided bool) (*dto.AuthorizationResponse, error)
+	Exchange(req dto.TokenRequest) (*dto.OAuthTokenResponse, error)
+	UserInfo(userID, sessionID uint) (*dto.UserInfoResponse, error)
+	ListConsents(userID uint) ([]dto.OAuthConsentResponse, error)
+	RevokeConsent(userID uint, clientID string) error
+	Discovery() dto.DiscoveryDocument
+}
+
+// OAuthService lets partner applications authenticate users with the authorization code flow and PKCE, and
+// issues OpenID Connect ID tokens signed with the access token keys.
+type OAuthService struct {
+	oauthRepo        repository.IOAuthRepository
+	userRepo         repository.IUserRepository
+	sessionRepo      repository.ISessionRepository
+	tokenService     ITokenService
+	auditService     IAuditService
+	issuer           string
+	authorizationURL string
+	codeTTL          time.Duration
+	idTokenTTL       time.Duration
+}
+
+// NewOAuthService creates a new instance of OAuthService.
+func NewOAuthService(oauthRepo repository.IOAuthRepository, userRepo repository.IUserRepository, sessionRepo repository.ISessionRepository, tokenService ITokenService, auditService IAuditService) IOAuthService {
+	issuer := strings.TrimSuffix(os.Getenv("OIDC_ISSUER"), "/")
+	authorizationURL := os.Getenv("OIDC_AUTHORIZATION_URL")
+	if authorizationURL == "" {
+		authorizationURL = issuer + "/api/v1/auth/oauth/authorize"
+	}
+	return &OAuthService{
+		oauthRepo:        oauthRepo,
+		userRepo:         userRepo,
+		sessionRepo:      sessionRepo,
+		tokenService:     tokenService,
+		auditService:     auditService,
+		issuer:           issuer,
+		authorizationURL: authorizationURL,
+		codeTTL:          config.GetDuration("OAUTH_CODE_TTL", time.Minute),
+		idTokenTTL:       config.GetDuration("OIDC_ID_TOKEN_TTL", time.Hour),
+	}
+}
+
+// RegisterClient registers a partner application or a service. Confidential clients get a secret, returned only
+// now. Service clients must be confidential, have no redirect URIs and only get service scopes.
+func (s *OAuthService) RegisterClient(req dto.RegisterOAuthClientRequest, actor dto.Actor) (*dto.OAuthClientResponse, error) {
+	scopes, allowed := req.Scopes, supportedScopes
+	if req.Service {
+		if req.Public || len(req.RedirectURIs) > 0 {
+			return nil, fmt.Errorf("%w: service clients are confidential and have no redirect URIs", ErrInvalidOAuthRequest)
+		}
+		if len(scopes) == 0 {
+			return nil, fmt.Errorf("%w: service clients must name their scopes", ErrInvalidScope)
+		}
+		allowed = serviceScopes
+	}
+	if len(scopes) == 0 {
+		scopes = supportedScopes
+	}
+	for _, scope := range scopes {
+		if !containsString(allowed, scope) {
+			return nil, fmt.Errorf("%w: %s cannot be granted to the client", ErrInvalidScope, scope)
+		}
+	}
+
+	clientID, err := newClientID()
+	if err != nil {
+		return nil, err
+	}
+	client := models.OAuthClient{
+		ClientID:     clientID,
+		Name:         req.Name,
+		RedirectURIs: strings.Join(req.RedirectURIs, " "),
+		Scopes:       strings.Join(scopes, " "),
+		Public:       req.Public,
+		Service:      req.Service,
+		CreatedBy:    actor.UserID,
+	}
+
+	var secret string
+	if !req.Public {
+		if secret, err = pkg.GenerateToken(); err != nil {
+			return nil, err
+		}
+		client.SecretHash = hashToken(secret)
+	}
+	if err := s.oauthRepo.CreateClient(&client); err != nil {
+		return nil, err
+	}
+	s.auditService.Record(actor, AuditOAuthClientRegistered, AuditTargetOAuthClient, client.ClientID, auditDiff(nil, oauthClientAuditFields(client)))
+
+	response := dto.FromOAuthClientModel(client)
+	response.ClientSecret = secret
+	return &response, nil
+}
+
+// ListClients lists the registered OAuth clients.
+func (s *OAuthService) ListClients() ([]dto.OAuthClientResponse, error) {
+	clients, err := s.oauthRepo.ListClients()
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]dto.OAuthClientResponse, 0, len(clients))
+	for _, client := range clients {
+		responses = append(responses, dto.FromOAuthClientModel(client))
+	}
+	return responses, nil
+}
+
+// DeleteClient removes an OAuth client, the consents given to it and the sessions granted to it.
+func (s *OAuthService) DeleteClient(clientID string, actor dto.Actor) error {
+	if err := s.oauthRepo.DeleteClient(clientID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrOAuthClientNotFound
+		}
+		return err
+	}
+	s.auditService.Record(actor, AuditOAuthClientDeleted, AuditTargetOAuthClient, clientID, nil)
+	return s.sessionRepo.RevokeClientSessions(clientID, 0, RevokedByClientRemoval, time.Now())
+}
+
+// Authorize validates an authorization request of the signed in user. Until the user decided, it asks for
+// consent unless the user already allowed the requested scopes. Once allowed, it issues an authorization
+// code; when declined, it returns the access_denied error. Both are returned in the redirect URI.
+func (s *OAuthService) Authorize(req dto.AuthorizationRequest, decided bool) (*dto.AuthorizationResponse, error) {
+	client, err := s.oauthRepo.FindClientByClientID(req.ClientID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: unknown client_id", ErrInvalidOAuthRequest)
+		}
+		return nil, err
+	}
+	if !containsField(client.RedirectURIs, req.RedirectURI) {
+		return nil, fmt.Errorf("%w: redirect_uri is not registered for the client", ErrInvalidOAuthRequest)
+	}
+	if req.ResponseType != responseTypeCode {
+		return nil, ErrUnsupportedResponseType
+	}
+	scopes := strings.Fields(req.Scope)
+	if !containsString(scopes, ScopeOpenID) {
+		return nil, fmt.Errorf("%w: the openid scope is required", ErrInvalidScope)
+	}
+	for _, scope := range scopes {
+		if !containsField(client.Scopes, scope) {
+			return nil, fmt.Errorf("%w: %s is not allowed for the client", ErrInvalidScope, scope)
+		}
+	}
+	if req.CodeChallengeMethod != pkceMethodS256 || len(req.CodeChallenge) < 43 || len(req.CodeChallenge) > 128 {
+		return nil, fmt.Errorf("%w: a S256 code_challenge is required", ErrInvalidOAuthRequest)
+	}
+
+	response := &dto.AuthorizationResponse{
+		Client: dto.OAuthClientSummary{ClientID: client.ClientID, Name: client.Name},
+		Scopes: scopes,
+	}
+	if decided && !req.Approve {
+		response.RedirectTo = redirectURI(req.RedirectURI, url.Values{"error": {"access_denied"}}, req.State)
+		return response, nil
+	}
+
+	consent, err := s.oauthRepo.FindConsent(req.UserID, client.ClientID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if !decided && (consent == nil || !coversScopes(consent.Scopes, scopes)) {
+		response.ConsentRequired = true
+		return response, nil
+	}
+	if decided {
+		granted := scopes
+		if consent != nil {
+			granted = mergeScopes(strings.Fields(consent.Scopes), scopes)
+		}
+		consent := models.OAuthConsent{UserID: req.UserID, ClientID: client.ClientID, Scopes: strings.Join(granted, " ")}
+		if err := s.oauthRepo.SaveConsent(&consent); err != nil {
+			return nil, err
+		}
+	}
+
+	code, err := s.issueCode(req, scopes)
+	if err != nil {
+		return nil, err
+	}
+	response.RedirectTo = redirectURI(req.RedirectURI, url.Values{"code": {code}}, req.State)
+	return response, nil
+}
+
+// Exchange handles a token request: an authorization code with its PKCE verifier, or a refresh token, is
+// exchanged for tokens. The authorization code grant also returns an ID token. Service clients get a token for
+// themselves with the client credentials grant, which only they may use.
+func (s *OAuthService) Exchange(req dto.TokenRequest) (*dto.OAuthTokenResponse, error) {
+	client, err := s.authenticateClient(req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	switch req.GrantType {
+	case GrantTypeClientCredentials:
+		if !client.Service {
+			return nil, ErrUnauthorizedClient
+		}
+		return s.exchangeClientCredentials(client, req.Scope)
+	case GrantTypeAuthorizationCode:
+		return s.exchangeCode(client, req)
+	case GrantTypeRefreshToken:
+		if req.RefreshToken == "" {
+			return nil, fmt.Errorf("%w: refresh_token is required", ErrInvalidOAuthRequest)
+		}
+		tokens, err := s.tokenService.RefreshClientTokens(dto.RefreshTokenRequest{RefreshToken: req.RefreshToken}, client.ClientID)
+		if err != nil {
+			if errors.Is(err, ErrInvalidRefreshToken) || errors.Is(err, ErrRefreshTokenReused) {
+				return nil, ErrInvalidGrant
+			}
+			return nil, err
+		}
+		return tokenResponse(tokens, "", ""), nil
+	default:
+		return nil, ErrUnsupportedGrantType
+	}
+}
+
+// UserInfo returns the claims of a user allowed by the scopes of the session. First-party sessions get them all.
+func (s *OAuthService) UserInfo(userID, sessionID uint) (*dto.UserInfoResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	scopes := supportedScopes
+	if sessionID != 0 {
+		session, err := s.sessionRepo.FindSessionByID(sessionID)
+		if err != nil {
+			return nil, err
+		}
+		if session.ClientID != "" {
+			scopes = strings.Fields(session.Scope)
+		}
+	}
+
+	claims := userClaims(*user, scopes)
+	return &dto.UserInfoResponse{
+		Subject:           claims.Subject,
+		PreferredUsername: claims.PreferredUsername,
+		Email:             claims.Email,
+		EmailVerified:     claims.EmailVerified,
+	}, nil
+}
+
+// ListConsents lists the clients a user allowed to access their account.
+func (s *OAuthService) ListConsents(userID uint) ([]dto.OAuthConsentResponse, error) {
+	consents, err := s.oauthRepo.ListConsents(userID)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]dto.OAuthConsentResponse, 0, len(consents))
+	for _, consent := range consents {
+		response := dto.OAuthConsentResponse{
+			Client:    dto.OAuthClientSummary{ClientID: consent.ClientID},
+			Scopes:    strings.Fields(consent.Scopes),
+			UpdatedAt: consent.UpdatedAt,
+		}
+		if client, err := s.oauthRepo.FindClientByClientID(consent.ClientID); err == nil {
+			response.Client.Name = client.Name
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// RevokeConsent withdraws a user's consent to a client and signs the client out of the user's account.
+func (s *OAuthService) RevokeConsent(userID uint, clientID string) error {
+	if err := s.oauthRepo.DeleteConsent(userID, clientID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrOAuthConsentNotFound
+		}
+		return err
+	}
+	return s.sessionRepo.RevokeClientSessions(clientID, userID, RevokedByConsentRevoke, time.Now())
+}
+
+// Discovery returns the OpenID Connect provider metadata.
+func (s *OAuthService) Discovery() dto.DiscoveryDocument {
+	return dto.DiscoveryDocument{
+		Issuer:                            s.issuer,
+		AuthorizationEndpoint:             s.authorizationURL,
+		TokenEndpoint:                     s.issuer + "/api/v1/auth/oauth/token",
+		UserInfoEndpoint:                  s.issuer + "/api/v1/auth/oauth/userinfo",
+		JWKSURI:                           s.issuer + "/.well-known/jwks.json",
+		ScopesSupported:                   supportedScopes,
+		ResponseTypesSupported:            []string{responseTypeCode},
+		GrantTypesSupported:               []string{GrantTypeAuthorizationCode, GrantTypeRefreshToken, GrantTypeClientCredentials},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{AlgorithmRS256, AlgorithmEdDSA},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{pkceMethodS256},
+		ClaimsSupported:                   []string{"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce", "azp", "preferred_username", "email", "email_verified"},
+	}
+}
+
+// exchangeCode exchanges an authorization code for a session granted to the client and an ID token.
+func (s *OAuthService) exchangeCode(client *models.OAuthClient, req dto.TokenRequest) (*dto.OAuthTokenResponse, error) {
+	if req.Code == "" || req.CodeVerifier == "" {
+		return nil, fmt.Errorf("%w: code and code_verifier are required", ErrInvalidOAuthRequest)
+	}
+	code, err := s.oauthRepo.FindAuthorizationCodeByHash(hashToken(req.Code))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidGrant
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if code.UsedAt != nil || now.After(code.ExpiresAt) || code.ClientID != client.ClientID ||
+		code.RedirectURI != req.RedirectURI || !verifyCodeChallenge(code.CodeChallenge, req.CodeVerifier) {
+		return nil, ErrInvalidGrant
+	}
+	if err := s.oauthRepo.UseAuthorizationCode(code.ID, now); err != nil {
+		if errors.Is(err, repository.ErrAuthorizationCodeUsed) {
+			return nil, ErrInvalidGrant
+		}
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(code.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidGrant
+		}
+		return nil, err
+	}
+	tokens, err := s.tokenService.IssueClientTokens(*user, client.ClientID, code.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := userClaims(*user, strings.Fields(code.Scope))
+	claims.AuthorizedParty, claims.Nonce, claims.AuthTime = client.ClientID, code.Nonce, code.AuthTime.Unix()
+	claims.Issuer, claims.Audience = s.issuer, jwt.ClaimStrings{client.ClientID}
+	claims.IssuedAt, claims.ExpiresAt = jwt.NewNumericDate(now), jwt.NewNumericDate(now.Add(s.idTokenTTL))
+	idToken, err := s.tokenService.SignToken(claims)
+	if err != nil {
+		return nil, err
+	}
+	return tokenResponse(tokens, idToken, code.Scope), nil
+}
+
+// exchangeClientCredentials issues an access token for a service client itself, limited to the requested scopes,
+// or to all of its scopes when none are requested. No refresh token is issued; the client asks again instead.
+func (s *OAuthService) exchangeClientCredentials(client *models.OAuthClient, scope string) (*dto.OAuthTokenResponse, error) {
+	scopes := strings.Fields(scope)
+	if len(scopes) == 0 {
+		scopes = strings.Fields(client.Scopes)
+	}
+	for _, scope := range scopes {
+		if !containsField(client.Scopes, scope) {
+			return nil, fmt.Errorf("%w: %s is not allowed for the client", ErrInvalidScope, scope)
+		}
+	}
+	granted := strings.Join(scopes, " ")
+	tokens, err := s.tokenService.IssueClientCredentialsToken(client.ClientID, client.Name, granted)
+	if err != nil {
+		return nil, err
+	}
+	return tokenResponse(tokens, "", granted), nil
+}
+
+// issueCode stores a new authorization code for the request and returns it.
+func (s *OAuthService) issueCode(req dto.AuthorizationRequest, scopes []string) (string, error) {
+	// The ID token reports when the user signed in, which is when their session started
+	session, err := s.sessionRepo.FindSessionByID(req.SessionID)
+	if err != nil {
+		return "", err
+	}
+	if session.RevokedAt != nil || session.ClientID != "" {
+		return "", fmt.Errorf("%w: clients can only be authorized from a first-party session", ErrInvalidOAuthRequest)
+	}
+	code, err := pkg.GenerateToken()
+	if err != nil {
+		return "", err
+	}
+	authorizationCode := models.AuthorizationCode{
+		CodeHash:      hashToken(code),
+		ClientID:      req.ClientID,
+		UserID:        req.UserID,
+		RedirectURI:   req.RedirectURI,
+		Scope:         strings.Join(scopes, " "),
+		Nonce:         req.Nonce,
+		CodeChallenge: req.CodeChallenge,
+		AuthTime:      session.CreatedAt,
+		ExpiresAt:     time.Now().Add(s.codeTTL),
+	}
+	if err := s.oauthRepo.CreateAuthorizationCode(&authorizationCode); err != nil {
+		return "", fmt.Errorf("failed to create authorization code: %w", err)
+	}
+	return code, nil
+}
+
+// authenticateClient checks the credentials of a client. Public clients have no secret and must not send one.
+func (s *OAuthService) authenticateClient(clientID, secret string) (*models.OAuthClient, error) {
+	if clientID == "" {
+		return nil, ErrInvalidClient
+	}
+	client, err := s.oauthRepo.FindClientByClientID(clientID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidClient
+		}
+		return nil, err
+	}
+	if client.Public {
+		if secret != "" {
+			return nil, ErrInvalidClient
+		}
+		return client, nil
+	}
+	if subtle.ConstantTimeCompare([]byte(hashToken(secret)), []byte(client.SecretHash)) != 1 {
+		return nil, ErrInvalidClient
+	}
+	return client, nil
+}
+
+// userClaims builds the OpenID Connect claims of a user allowed by scopes.
+func userClaims(user models.User, scopes []string) *IDTokenClaims {
+	claims := &IDTokenClaims{}
+	claims.Subject = strconv.FormatUint(uint64(user.ID), 10)
+	if containsString(scopes, ScopeProfile) {
+		claims.PreferredUsername = user.Username
+	}
+	if containsString(scopes, ScopeEmail) {
+		verified := user.Verified
+		claims.Email, claims.EmailVerified = user.Email, &verified
+	}
+	return claims
+}
+
+func tokenResponse(tokens *dto.UserLoginResponseDto, idToken, scope string) *dto.OAuthTokenResponse {
+	return &dto.OAuthTokenResponse{
+		AccessToken:  tokens.Token,
+		TokenType:    tokens.TokenType,
+		ExpiresIn:    tokens.ExpiresIn,
+		RefreshToken: tokens.RefreshToken,
+		IDToken:      idToken,
+		Scope:        scope,
+	}
+}
+
+// verifyCodeChallenge checks a PKCE verifier against its S256 challenge.
+func verifyCodeChallenge(challenge, verifier string) bool {
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+// redirectURI adds params and the state to the client's redirect URI.
+func redirectURI(base string, params url.Values, state string) string {
+	if state != "" {
+		params.Set("state", state)
+	}
+	separator := "?"
+	if strings.Contains(base, "?") {
+		separator = "&"
+	}
+	return base + separator + params.Encode()
+}
+
+// oauthClientAuditFields returns the fields of a client recorded in the audit log. The secret is never recorded.
+func oauthClientAuditFields(client models.OAuthClient) map[string]interface{} {
+	return map[string]interface{}{
+		"name":         client.Name,
+		"redirectUris": client.RedirectURIs,
+		"scopes":       client.Scopes,
+		"public":       client.Public,
+		"service":      client.Service,
+	}
+}
+
+func newClientID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// coversScopes reports whether the space separated granted scopes include all of requested.
+func coversScopes(granted string, requested []string) bool {
+	for _, scope := range requested {
+		if !containsField(granted, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+func mergeScopes(granted, requested []string) []string {
+	merged := append([]string{}, granted...)
+	for _, scope := range requested {
+		if !containsString(merged, scope) {
+			merged = append(merged, scope)
+		}
+	}
+	return merged
+}
+
+// containsField reports whether the space separated list contains value.
+func containsField(list, value string) bool {
+	return containsString(strings.Fields(list), value)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+package services
+
+import "testing"
+
+func TestVerifyCodeChallenge(t *testing.T) {
+	// The example of RFC 7636, appendix B
+	const verifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	const challenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+	tests := []struct {
+		name      string
+		challenge string
+		verifier  string
+		want      bool
+	}{
+		{name: "matching verifier", challenge: challenge, verifier: verifier, want: true},
+		{name: "other verifier", challenge: challenge, verifier: verifier + "x"},
+		{name: "plain challenge", challenge: verifier, verifier: verifier},
+		{name: "empty verifier", challenge: challenge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verifyCodeChallenge(tt.challenge, tt.verifier); got != tt.want {
+				t.Errorf("verifyCodeChallenge() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"auth-service/internal/config"
+	"auth-service/internal/models"
+	"auth-service/internal/password"
+	"auth-service/internal/repository"
+	"errors"
+	"fmt"
+	"log"
+)
+
+var (
+	// ErrWeakPassword wraps every reason a new password is refused, so handlers can answer with 400.
+	ErrWeakPassword = errors.New("password does not meet the password policy")
+	// ErrPasswordReused is returned for new passwords equal to one of the latest passwords of the user.
+	ErrPasswordReused = errors.New("password was used recently, choose another one")
+)
+
+// IPasswordService defines the interface for hashing, verifying and choosing passwords.
+type IPasswordService interface {
+	Validate(user models.User, newPassword string) error
+	Hash(newPassword string) (string, error)
+	Verify(user models.User, candidate string) (bool, error)
+	Record(userID uint, passwordHash string) error
+}
+
+// PasswordService hashes passwords with Argon2id, upgrades older hashes on successful verification and
+// enforces the password policy on new passwords.
+type PasswordService struct {
+	userRepo    repository.IUserRepository
+	historyRepo repository.IPasswordHistoryRepository
+	policy      password.Policy
+	historySize int // Number of latest passwords that cannot be reused
+}
+
+// NewPasswordService creates a new instance of PasswordService.
+func NewPasswordService(userRepo repository.IUserRepository, historyRepo repository.IPasswordHistoryRepository) IPasswordService {
+	return &PasswordService{
+		userRepo:    userRepo,
+		historyRepo: historyRepo,
+		policy: password.Policy{
+			MinLength: config.GetInt("PASSWORD_MIN_LENGTH", 8),
+			MaxLength: config.GetInt("PASSWORD_MAX_LENGTH", 128),
+		},
+		historySize: config.GetInt("PASSWORD_HISTORY_SIZE", 5),
+	}
+}
+
+// Validate checks a new password of user against the policy and the latest passwords of the user. New users
+// have no ID and no earlier passwords.
+func (s *PasswordService) Validate(user models.User, newPassword string) error {
+	if err := s.policy.Validate(newPassword, user.Username, user.Email); err != nil {
+		return fmt.Errorf("%w: %w", ErrWeakPassword, err)
+	}
+	if user.ID == 0 || s.historySize <= 0 {
+		return nil
+	}
+
+	histories, err := s.historyRepo.FindRecent(user.ID, s.historySize)
+	if err != nil {
+		return err
+	}
+	// The current password counts even for users who set it before passwords were recorded
+	hashes := []string{user.Password}
+	for _, history := range histories {
+		hashes = append(hashes, history.PasswordHash)
+	}
+	for _, hash := range hashes {
+		match, _, err := password.Verify(newPassword, hash)
+		if err != nil && !errors.Is(err, password.ErrUnsupportedHash) {
+			return err
+		}
+		if match {
+			return fmt.Errorf("%w: %w", ErrWeakPassword, ErrPasswordReused)
+		}
+	}
+	return nil
+}
+
+// Hash returns the hash of a new password as stored on the user.
+func (s *PasswordService) Hash(newPassword string) (string, error) {
+	return password.Hash(newPassword)
+}
+
+// Verify reports whether candidate is the password of user. When it is and the stored hash is bcrypt or uses
+// older Argon2id parameters, the password is hashed again with the current ones.
+func (s *PasswordService) Verify(user models.User, candidate string) (bool, error) {
+	match, needsRehash, err := password.Verify(candidate, user.Password)
+	if err != nil || !match {
+		return false, err
+	}
+	if needsRehash {
+		// The login goes on with the old hash if the new one cannot be stored; it is upgraded next time
+		passwordHash, err := password.Hash(candidate)
+		if err == nil {
+			err = s.userRepo.UpdatePassword(user.ID, passwordHash)
+		}
+		if err != nil {
+			log.Printf("Could not rehash the password of user %d: %v", user.ID, err)
+		}
+	}
+	return true, nil
+}
+
+// Record remembers a password the user has set so it cannot be reused, forgetting those older than the
+// history size.
+func (s *PasswordService) Record(userID uint, passwordHash string) error {
+	if s.historySize <= 0 {
+		return nil
+	}
+	if err := s.historyRepo.Add(&models.PasswordHistory{UserID: userID, PasswordHash: passwordHash}); err != nil {
+		return err
+	}
+	return s.historyRepo.Prune(userID, s.historySize)
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"auth-service/internal/models"
+	"auth-service/internal/password"
+	"auth-service/internal/repository"
+	"errors"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+type fakePasswordHistoryRepository struct {
+	repository.IPasswordHistoryRepository
+	histories []models.PasswordHistory // Newest first
+}
+
+func (r *fakePasswordHistoryRepository) FindRecent(userID uint, limit int) ([]models.PasswordHistory, error) {
+	return r.histories[:min(limit, len(r.histories))], nil
+}
+
+// newTestPasswordService returns a password service with the default policy and no password history.
+func newTestPasswordService(users repository.IUserRepository) *PasswordService {
+	return &PasswordService{userRepo: users, policy: password.Policy{MinLength: 8, MaxLength: 128}}
+}
+
+func TestPasswordServiceValidate(t *testing.T) {
+	current, err := password.Hash("current password")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	previous, err := bcrypt.GenerateFromPassword([]byte("previous password"), bcrypt.DefaultCost)
+	if err != nil {
+		t.Fatalf("GenerateFromPassword() error = %v", err)
+	}
+	user := models.User{Username: "ann.lee.travels", Email: "ann@example.com", Password: current}
+	user.ID = 5
+	tests := []struct {
+		name     string
+		password string
+		want     error
+	}{
+		{name: "new password", password: "night train to zagreb"},
+		{name: "too short", password: "short", want: password.ErrTooShort},
+		{name: "breached", password: "password", want: password.ErrBreached},
+		{name: "username", password: "Ann.Lee.Travels", want: password.ErrMatchesIdentity},
+		{name: "current password", password: "current password", want: ErrPasswordReused},
+		// Passwords recorded before the switch to Argon2id are still bcrypt hashes
+		{name: "previous bcrypt password", password: "previous password", want: ErrPasswordReused},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newTestPasswordService(&fakeUserRepository{})
+			service.historySize = 5
+			service.historyRepo = &fakePasswordHistoryRepository{histories: []models.PasswordHistory{{UserID: 5, PasswordHash: string(previous)}}}
+
+			err := service.Validate(user, tt.password)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrWeakPassword) || !errors.Is(err, tt.want) {
+				t.Errorf("Validate() error = %v, want %v wrapped in %v", err, tt.want, ErrWeakPassword)
+			}
+		})
+	}
+}
+
+func TestPasswordServiceVerifyRehashesBcrypt(t *testing.T) {
+	legacy, err := bcrypt.GenerateFromPassword([]byte("current password"), bcrypt.DefaultCost)
+	if err != nil {
+		t.Fatalf("GenerateFromPassword() error = %v", err)
+	}
+	users := &fakeUserRepository{}
+	service := newTestPasswordService(users)
+	user := models.User{Password: string(legacy)}
+
+	if match, err := service.Verify(user, "guess"); match || err != nil {
+		t.Fatalf("Verify() with a wrong password = %v, %v, want false, nil", match, err)
+	}
+	if users.password != "" {
+		t.Fatal("Verify() rehashed the password after a failed verification")
+	}
+	if match, err := service.Verify(user, "current password"); !match || err != nil {
+		t.Fatalf("Verify() = %v, %v, want true, nil", match, err)
+	}
+	if match, needsRehash, _ := password.Verify("current password", users.password); !match || needsRehash {
+		t.Errorf("stored hash %q is not a current Argon2id hash of the password", users.password)
+	}
+}
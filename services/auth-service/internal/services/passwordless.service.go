@@ -0,0 +1,219 @@
+package services
+
+import (
+	"auth-service/internal/api/dto"
+	"auth-service/internal/config"
+	"auth-service/internal/models"
+	"auth-service/internal/repository"
+	"auth-service/internal/sms"
+	"auth-service/pkg"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// passwordlessLoginNotification is the notification-service type of passwordless sign-in links and codes.
+	passwordlessLoginNotification = "passwordless_login"
+	// maxLoginCodeAttempts is how many codes can be entered for a passwordless login before it is discarded.
+	maxLoginCodeAttempts = 5
+	// loginCodeDigits is the length of the codes sent for passwordless logins.
+	loginCodeDigits = 6
+)
+
+// ErrInvalidLoginCode is returned for wrong codes and for unknown, used or expired login tokens and links alike.
+var ErrInvalidLoginCode = errors.New("invalid or expired login code, please request a new one")
+
+// IPasswordlessService defines the interface for logins with a link or code sent to the user.
+type IPasswordlessService interface {
+	Start(req dto.PasswordlessLoginRequest) (*dto.PasswordlessLoginResponse, error)
+	CompleteWithCode(req dto.PasswordlessCodeRequest) (*models.LoginCode, error)
+	CompleteWithLink(req dto.PasswordlessLinkRequest) (*models.LoginCode, error)
+}
+
+// PasswordlessService sends single-use sign-in links and codes, by email through notification-service or by SMS
+// to the verified phone number of the user, and checks them.
+type PasswordlessService struct {
+	loginCodeRepo       repository.ILoginCodeRepository
+	userRepo            repository.IUserRepository
+	notificationService INotificationService
+	smsProvider         sms.Provider
+	codeTTL             time.Duration
+	resendCooldown      time.Duration
+	loginURL            string
+}
+
+// NewPasswordlessService creates a new instance of PasswordlessService.
+func NewPasswordlessService(loginCodeRepo repository.ILoginCodeRepository, userRepo repository.IUserRepository, notificationService INotificationService, smsProvider sms.Provider) IPasswordlessService {
+	return &PasswordlessService{
+		loginCodeRepo:       loginCodeRepo,
+		userRepo:            userRepo,
+		notificationService: notificationService,
+		smsProvider:         smsProvider,
+		codeTTL:             config.GetDuration("PASSWORDLESS_LOGIN_TTL", 10*time.Minute),
+		resendCooldown:      config.GetDuration("PASSWORDLESS_LOGIN_RESEND_COOLDOWN", time.Minute),
+		loginURL:            os.Getenv("PASSWORDLESS_LOGIN_URL"),
+	}
+}
+
+// Start sends a sign-in link and code to the user with the given username or email address, replacing those
+// sent before, and returns the login token to enter the code with. Unknown users, users without a verified
+// phone number asking for an SMS and repeated requests within the cooldown get a login token that no code
+// matches, so the response does not tell whether an account exists.
+func (s *PasswordlessService) Start(req dto.PasswordlessLoginRequest) (*dto.PasswordlessLoginResponse, error) {
+	token, err := pkg.GenerateToken()
+	if err != nil {
+		return nil, err
+	}
+	response := &dto.PasswordlessLoginResponse{LoginToken: token, ExpiresAt: time.Now().Add(s.codeTTL)}
+
+	user, err := s.findUser(req.Identifier)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response, nil
+		}
+		return nil, err
+	}
+	channel := req.Channel
+	if channel == "" {
+		channel = models.LoginCodeChannelEmail
+	}
+	if channel == models.LoginCodeChannelSMS && !user.PhoneVerified {
+		return response, nil
+	}
+	latest, err := s.loginCodeRepo.FindLatestByUserID(user.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if latest != nil && time.Since(latest.CreatedAt) < s.resendCooldown {
+		return response, nil
+	}
+
+	linkToken, err := pkg.GenerateToken()
+	if err != nil {
+		return nil, err
+	}
+	code, err := generateNumericCode(loginCodeDigits)
+	if err != nil {
+		return nil, err
+	}
+	loginCode := models.LoginCode{
+		UserID:        user.ID,
+		Channel:       channel,
+		TokenHash:     hashToken(token),
+		LinkTokenHash: hashToken(linkToken),
+		CodeHash:      hashToken(code),
+		ExpiresAt:     response.ExpiresAt,
+	}
+	if err := s.loginCodeRepo.Replace(&loginCode); err != nil {
+		return nil, fmt.Errorf("failed to create login code: %w", err)
+	}
+
+	if err := s.send(*user, loginCode, linkToken, code); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// CompleteWithCode uses the login code of a login token if the code matches. Every code entered is counted
+// before it is compared. Wrong codes, and codes entered once the attempts ran out, are returned with
+// ErrInvalidLoginCode, so the failed login can be recorded for the user.
+func (s *PasswordlessService) CompleteWithCode(req dto.PasswordlessCodeRequest) (*models.LoginCode, error) {
+	loginCode, err := s.activeLoginCode(s.loginCodeRepo.FindByTokenHash(hashToken(req.LoginToken)))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.loginCodeRepo.IncrementAttempts(loginCode.ID, maxLoginCodeAttempts); err != nil {
+		if errors.Is(err, repository.ErrLoginCodeAttemptsExceeded) {
+			return loginCode, ErrInvalidLoginCode
+		}
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hashToken(req.Code)), []byte(loginCode.CodeHash)) != 1 {
+		return loginCode, ErrInvalidLoginCode
+	}
+	return s.use(loginCode)
+}
+
+// CompleteWithLink uses the login code of a sign-in link.
+func (s *PasswordlessService) CompleteWithLink(req dto.PasswordlessLinkRequest) (*models.LoginCode, error) {
+	loginCode, err := s.activeLoginCode(s.loginCodeRepo.FindByLinkTokenHash(hashToken(req.Token)))
+	if err != nil {
+		return nil, err
+	}
+	return s.use(loginCode)
+}
+
+// findUser looks a user up by email address when identifier has an @, by username otherwise.
+func (s *PasswordlessService) findUser(identifier string) (*models.User, error) {
+	if strings.Contains(identifier, "@") {
+		return s.userRepo.FindByEmail(identifier)
+	}
+	return s.userRepo.FindByUsername(identifier)
+}
+
+// activeLoginCode returns the login code found, unless it was used, expired or has no attempts left.
+func (s *PasswordlessService) activeLoginCode(loginCode *models.LoginCode, err error) (*models.LoginCode, error) {
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidLoginCode
+		}
+		return nil, err
+	}
+	if loginCode.UsedAt != nil || time.Now().After(loginCode.ExpiresAt) || loginCode.Attempts >= maxLoginCodeAttempts {
+		return nil, ErrInvalidLoginCode
+	}
+	return loginCode, nil
+}
+
+// use marks a login code as used so neither its link nor its code work again.
+func (s *PasswordlessService) use(loginCode *models.LoginCode) (*models.LoginCode, error) {
+	if err := s.loginCodeRepo.Use(loginCode.ID, time.Now()); err != nil {
+		if errors.Is(err, repository.ErrLoginCodeUsed) {
+			return nil, ErrInvalidLoginCode
+		}
+		return nil, err
+	}
+	return loginCode, nil
+}
+
+// send delivers the link and code of a login code through its channel.
+func (s *PasswordlessService) send(user models.User, loginCode models.LoginCode, linkToken, code string) error {
+	link := fmt.Sprintf("%s?token=%s", s.loginURL, url.QueryEscape(linkToken))
+	if loginCode.Channel == models.LoginCodeChannelSMS {
+		body := fmt.Sprintf("Your sign-in code is %s, or sign in at %s. It expires in %d minutes.",
+			code, link, int(s.codeTTL.Minutes()))
+		if err := s.smsProvider.Send(user.PhoneNumber, body); err != nil {
+			return fmt.Errorf("failed to send login code: %w", err)
+		}
+		return nil
+	}
+
+	content := fmt.Sprintf("Hi %s, sign in to your account by opening %s or by entering the code %s. Both expire on %s and work once. If you did not ask for it, you can ignore this email.",
+		user.Username, link, code, loginCode.ExpiresAt.Format(time.RFC1123))
+	if err := s.notificationService.SendNotification(user.ID, passwordlessLoginNotification, NotificationChannelEmail, content); err != nil {
+		return fmt.Errorf("failed to send login code: %w", err)
+	}
+	return nil
+}
+
+// generateNumericCode returns a random code of digits decimal digits.
+func generateNumericCode(digits int) (string, error) {
+	limit := big.NewInt(1)
+	for i := 0; i < digits; i++ {
+		limit.Mul(limit, big.NewInt(10))
+	}
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", digits, n), nil
+}
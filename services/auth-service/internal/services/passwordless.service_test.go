@@ -0,0 +1,260 @@
+package services
+
+import (
+	"auth-service/internal/api/dto"
+	"auth-service/internal/models"
+	"auth-service/internal/repository"
+	"errors"
+	"net/url"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// fakeLoginCodeRepository holds a single login code.
+type fakeLoginCodeRepository struct {
+	repository.ILoginCodeRepository
+	mu        sync.Mutex
+	loginCode *models.LoginCode
+}
+
+func (r *fakeLoginCodeRepository) Replace(loginCode *models.LoginCode) error {
+	loginCode.CreatedAt = time.Now()
+	r.loginCode = loginCode
+	return nil
+}
+
+func (r *fakeLoginCodeRepository) FindLatestByUserID(userID uint) (*models.LoginCode, error) {
+	if r.loginCode == nil || r.loginCode.UserID != userID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *r.loginCode
+	return &copied, nil
+}
+
+func (r *fakeLoginCodeRepository) FindByTokenHash(tokenHash string) (*models.LoginCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.loginCode == nil || r.loginCode.TokenHash != tokenHash {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *r.loginCode
+	return &copied, nil
+}
+
+func (r *fakeLoginCodeRepository) FindByLinkTokenHash(linkTokenHash string) (*models.LoginCode, error) {
+	if r.loginCode == nil || r.loginCode.LinkTokenHash != linkTokenHash {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *r.loginCode
+	return &copied, nil
+}
+
+func (r *fakeLoginCodeRepository) IncrementAttempts(loginCodeID uint, maxAttempts int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.loginCode.Attempts >= maxAttempts {
+		return repository.ErrLoginCodeAttemptsExceeded
+	}
+	r.loginCode.Attempts++
+	return nil
+}
+
+func (r *fakeLoginCodeRepository) Use(loginCodeID uint, usedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.loginCode.UsedAt != nil {
+		return repository.ErrLoginCodeUsed
+	}
+	r.loginCode.UsedAt = &usedAt
+	return nil
+}
+
+// startPasswordlessLogin starts a passwordless login for ann and returns the login token with the link token
+// and code sent to her.
+func startPasswordlessLogin(t *testing.T, service *PasswordlessService, notifications *fakeNotificationService) (string, string, string) {
+	t.Helper()
+	response, err := service.Start(dto.PasswordlessLoginRequest{Identifier: "ann"})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if len(notifications.sent) != 1 {
+		t.Fatalf("sent %d notifications, want 1", len(notifications.sent))
+	}
+	match := regexp.MustCompile(`token=([^ ]+) or by entering the code (\d+)\.`).FindStringSubmatch(notifications.sent[0])
+	if match == nil {
+		t.Fatalf("notification %q has no link and code", notifications.sent[0])
+	}
+	linkToken, err := url.QueryUnescape(match[1])
+	if err != nil {
+		t.Fatalf("QueryUnescape() error = %v", err)
+	}
+	return response.LoginToken, linkToken, match[2]
+}
+
+func TestPasswordlessServiceComplete(t *testing.T) {
+	tests := []struct {
+		name     string
+		complete func(service *PasswordlessService, loginToken, linkToken, code string) (*models.LoginCode, error)
+	}{
+		{name: "code", complete: func(service *PasswordlessService, loginToken, linkToken, code string) (*models.LoginCode, error) {
+			return service.CompleteWithCode(dto.PasswordlessCodeRequest{LoginToken: loginToken, Code: code})
+		}},
+		{name: "link", complete: func(service *PasswordlessService, loginToken, linkToken, code string) (*models.LoginCode, error) {
+			return service.CompleteWithLink(dto.PasswordlessLinkRequest{Token: linkToken})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &models.User{Username: "ann"}
+			user.ID = 5
+			notifications := &fakeNotificationService{}
+			service := &PasswordlessService{loginCodeRepo: &fakeLoginCodeRepository{}, userRepo: &fakeUserRepository{user: user}, notificationService: notifications, codeTTL: time.Minute}
+			loginToken, linkToken, code := startPasswordlessLogin(t, service, notifications)
+
+			loginCode, err := tt.complete(service, loginToken, linkToken, code)
+			if err != nil {
+				t.Fatalf("complete error = %v", err)
+			}
+			if loginCode.UserID != 5 {
+				t.Errorf("signed in user %d, want 5", loginCode.UserID)
+			}
+			// The link and the code work once, together
+			if _, err := service.CompleteWithCode(dto.PasswordlessCodeRequest{LoginToken: loginToken, Code: code}); !errors.Is(err, ErrInvalidLoginCode) {
+				t.Errorf("CompleteWithCode() after use error = %v, want %v", err, ErrInvalidLoginCode)
+			}
+			if _, err := service.CompleteWithLink(dto.PasswordlessLinkRequest{Token: linkToken}); !errors.Is(err, ErrInvalidLoginCode) {
+				t.Errorf("CompleteWithLink() after use error = %v, want %v", err, ErrInvalidLoginCode)
+			}
+		})
+	}
+}
+
+func TestPasswordlessServiceDiscardsGuessedCodes(t *testing.T) {
+	notifications := &fakeNotificationService{}
+	service := &PasswordlessService{loginCodeRepo: &fakeLoginCodeRepository{}, userRepo: &fakeUserRepository{user: &models.User{Username: "ann"}}, notificationService: notifications, codeTTL: time.Minute}
+	loginToken, _, code := startPasswordlessLogin(t, service, notifications)
+
+	for i := 0; i < maxLoginCodeAttempts; i++ {
+		if _, err := service.CompleteWithCode(dto.PasswordlessCodeRequest{LoginToken: loginToken, Code: "guess"}); !errors.Is(err, ErrInvalidLoginCode) {
+			t.Fatalf("CompleteWithCode() attempt %d error = %v, want %v", i+1, err, ErrInvalidLoginCode)
+		}
+	}
+	if _, err := service.CompleteWithCode(dto.PasswordlessCodeRequest{LoginToken: loginToken, Code: code}); !errors.Is(err, ErrInvalidLoginCode) {
+		t.Errorf("CompleteWithCode() with the right code after %d wrong ones error = %v, want %v", maxLoginCodeAttempts, err, ErrInvalidLoginCode)
+	}
+}
+
+func TestPasswordlessServiceStartHidesUnknownUsers(t *testing.T) {
+	notifications := &fakeNotificationService{}
+	service := &PasswordlessService{loginCodeRepo: &fakeLoginCodeRepository{}, userRepo: &fakeUserRepository{user: &models.User{Username: "ann"}}, notificationService: notifications, codeTTL: time.Minute}
+
+	response, err := service.Start(dto.PasswordlessLoginRequest{Identifier: "bo"})
+	if err != nil || response.LoginToken == "" {
+		t.Fatalf("Start() = %v, %v, want a login token", response, err)
+	}
+	if len(notifications.sent) != 0 {
+		t.Errorf("sent %d notifications for an unknown user, want none", len(notifications.sent))
+	}
+}
+
+func TestPasswordlessServiceStartCooldown(t *testing.T) {
+	user := models.User{Username: "ann", Email: "ann@example.com"}
+	user.ID = 7
+
+	tests := []struct {
+		name     string
+		previous *models.LoginCode
+		wantSent int
+	}{
+		{name: "first login code", wantSent: 1},
+		{name: "login code sent within the cooldown", previous: &models.LoginCode{UserID: 7, Model: gorm.Model{CreatedAt: time.Now().Add(-30 * time.Second)}}},
+		{name: "login code sent before the cooldown", previous: &models.LoginCode{UserID: 7, Model: gorm.Model{CreatedAt: time.Now().Add(-2 * time.Minute)}}, wantSent: 1},
+		{name: "login code of another user", previous: &models.LoginCode{UserID: 8, Model: gorm.Model{CreatedAt: time.Now()}}, wantSent: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifications := &fakeNotificationService{}
+			service := &PasswordlessService{
+				loginCodeRepo:       &fakeLoginCodeRepository{loginCode: tt.previous},
+				userRepo:            &fakeUserRepository{user: &user},
+				notificationService: notifications,
+				codeTTL:             10 * time.Minute,
+				resendCooldown:      time.Minute,
+			}
+			response, err := service.Start(dto.PasswordlessLoginRequest{Identifier: "ann"})
+			if err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+			if response.LoginToken == "" {
+				t.Error("Start() returned no login token")
+			}
+			if len(notifications.sent) != tt.wantSent {
+				t.Errorf("sent %d notifications, want %d", len(notifications.sent), tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestPasswordlessServiceCompleteWithCode(t *testing.T) {
+	tests := []struct {
+		name         string
+		attempts     int
+		code         string
+		wantErr      error
+		wantAttempts int
+		wantUsed     bool
+	}{
+		{name: "right code", code: "123456", wantAttempts: 1, wantUsed: true},
+		{name: "wrong code", code: "654321", wantErr: ErrInvalidLoginCode, wantAttempts: 1},
+		{name: "right code on the last attempt", attempts: maxLoginCodeAttempts - 1, code: "123456", wantAttempts: maxLoginCodeAttempts, wantUsed: true},
+		{name: "right code after the last attempt", attempts: maxLoginCodeAttempts, code: "123456", wantErr: ErrInvalidLoginCode, wantAttempts: maxLoginCodeAttempts},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeLoginCodeRepository{loginCode: &models.LoginCode{
+				UserID:    7,
+				TokenHash: hashToken("login-token"),
+				CodeHash:  hashToken("123456"),
+				Attempts:  tt.attempts,
+				ExpiresAt: time.Now().Add(time.Minute),
+			}}
+			service := &PasswordlessService{loginCodeRepo: repo}
+			_, err := service.CompleteWithCode(dto.PasswordlessCodeRequest{LoginToken: "login-token", Code: tt.code})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CompleteWithCode() error = %v, want %v", err, tt.wantErr)
+			}
+			if repo.loginCode.Attempts != tt.wantAttempts || (repo.loginCode.UsedAt != nil) != tt.wantUsed {
+				t.Errorf("attempts = %d, used = %v, want %d, %v", repo.loginCode.Attempts, repo.loginCode.UsedAt != nil, tt.wantAttempts, tt.wantUsed)
+			}
+		})
+	}
+}
+
+func TestPasswordlessServiceCompleteWithCodeConcurrently(t *testing.T) {
+	repo := &fakeLoginCodeRepository{loginCode: &models.LoginCode{
+		UserID:    7,
+		TokenHash: hashToken("login-token"),
+		CodeHash:  hashToken("123456"),
+		ExpiresAt: time.Now().Add(time.Minute),
+	}}
+	service := &PasswordlessService{loginCodeRepo: repo}
+
+	// Requests that read the login code before any of them counted an attempt still try at most
+	// maxLoginCodeAttempts codes.
+	var wg sync.WaitGroup
+	for i := 0; i < 4*maxLoginCodeAttempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			service.CompleteWithCode(dto.PasswordlessCodeRequest{LoginToken: "login-token", Code: "000000"})
+		}()
+	}
+	wg.Wait()
+	if repo.loginCode.Attempts != maxLoginCodeAttempts {
+		t.Errorf("attempts = %d, want %d", repo.loginCode.Attempts, maxLoginCodeAttempts)
+	}
+}
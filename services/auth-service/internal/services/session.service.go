@@ -0,0 +1,95 @@
+package services
+
+import (
+	"auth-service/internal/api/dto"
+	"auth-service/internal/repository"
+	"errors"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// RevokedBySignOut is recorded on sessions the user signed out of remotely.
+	RevokedBySignOut = "remote_sign_out"
+	// sessionLastSeenPrecision is how often the last use of a session is written by token checks.
+	sessionLastSeenPrecision = time.Minute
+)
+
+// ErrSessionNotFound is returned for sessions that do not belong to the user or are no longer active.
+var ErrSessionNotFound = errors.New("session not found")
+
+// ISessionService defines the interface for the sessions users are signed in with.
+type ISessionService interface {
+	ListSessions(userID, currentSessionID uint) ([]dto.SessionResponse, error)
+	RevokeSession(userID, sessionID uint, actor dto.Actor) error
+	RevokeSessions(userID, exceptSessionID uint, actor dto.Actor) error
+	SessionActive(sessionID uint) (bool, error)
+}
+
+// SessionService lets users see where they are signed in and sign out of devices remotely.
+type SessionService struct {
+	sessionRepo  repository.ISessionRepository
+	auditService IAuditService
+}
+
+// NewSessionService creates a new instance of SessionService.
+func NewSessionService(sessionRepo repository.ISessionRepository, auditService IAuditService) ISessionService {
+	return &SessionService{sessionRepo: sessionRepo, auditService: auditService}
+}
+
+// ListSessions retrieves the active sessions of a user, most recently seen first, marking the caller's own.
+func (s *SessionService) ListSessions(userID, currentSessionID uint) ([]dto.SessionResponse, error) {
+	sessions, err := s.sessionRepo.ListActiveSessions(userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]dto.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		responses = append(responses, dto.FromSessionModel(session, currentSessionID))
+	}
+	return responses, nil
+}
+
+// RevokeSession signs a user out of one session. Its access tokens are rejected from then on.
+func (s *SessionService) RevokeSession(userID, sessionID uint, actor dto.Actor) error {
+	if err := s.sessionRepo.RevokeUserSession(userID, sessionID, RevokedBySignOut, time.Now()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+	s.auditService.Record(actor, AuditSessionRevoked, AuditTargetSession, sessionID, nil)
+	return nil
+}
+
+// RevokeSessions signs a user out of every session except exceptSessionID, which may be 0 to revoke them all.
+func (s *SessionService) RevokeSessions(userID, exceptSessionID uint, actor dto.Actor) error {
+	if err := s.sessionRepo.RevokeUserSessions(userID, exceptSessionID, RevokedBySignOut, time.Now()); err != nil {
+		return err
+	}
+	s.auditService.Record(actor, AuditUserSessionsRevoked, AuditTargetUser, userID, nil)
+	return nil
+}
+
+// SessionActive reports whether the access tokens of a session are still accepted, and records that it was used.
+func (s *SessionService) SessionActive(sessionID uint) (bool, error) {
+	session, err := s.sessionRepo.FindSessionByID(sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	if session.RevokedAt != nil {
+		return false, nil
+	}
+
+	// A failure to record the use should not fail the user's request.
+	now := time.Now()
+	if err := s.sessionRepo.TouchSession(session.ID, "", now, now.Add(-sessionLastSeenPrecision)); err != nil {
+		log.Printf("Could not record use of session %d: %v", session.ID, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"auth-service/internal/models"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// fakeSessionStore finds a single session and records when it was last used.
+type fakeSessionStore struct {
+	fakeSessionRepository
+	session *models.Session
+	touched bool
+}
+
+func (r *fakeSessionStore) FindSessionByID(id uint) (*models.Session, error) {
+	if r.session == nil || r.session.ID != id {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.session, nil
+}
+
+func (r *fakeSessionStore) TouchSession(sessionID uint, ipAddress string, at, notSince time.Time) error {
+	r.touched = true
+	return nil
+}
+
+func TestSessionActive(t *testing.T) {
+	revokedAt := time.Now()
+	tests := []struct {
+		name      string
+		session   *models.Session
+		want      bool
+		wantTouch bool
+	}{
+		{name: "active session", session: &models.Session{Model: gorm.Model{ID: 3}}, want: true, wantTouch: true},
+		{name: "revoked session", session: &models.Session{Model: gorm.Model{ID: 3}, RevokedAt: &revokedAt}},
+		{name: "unknown session"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeSessionStore{session: tt.session}
+			service := &SessionService{sessionRepo: repo}
+
+			active, err := service.SessionActive(3)
+			if err != nil {
+				t.Fatalf("SessionActive() error = %v", err)
+			}
+			if active != tt.want || repo.touched != tt.wantTouch {
+				t.Errorf("SessionActive() = %v, touched %v, want %v, touched %v", active, repo.touched, tt.want, tt.wantTouch)
+			}
+		})
+	}
+}
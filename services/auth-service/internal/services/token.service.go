@@ -0,0 +1,295 @@
+package services
+
+import (
+	"auth-service/internal/api/dto"
+	"auth-service/internal/config"
+	"auth-service/internal/models"
+	"auth-service/internal/repository"
+	"auth-service/pkg"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+// Session revocation reasons stored on models.Session.
+const (
+	RevokedByLogout         = "logout"
+	RevokedByTokenReuse     = "refresh_token_reuse"
+	RevokedByPasswordChange = "password_change"
+	RevokedByPasswordReset  = "password_reset"
+	RevokedByClientRemoval  = "oauth_client_removed"
+	RevokedByConsentRevoke  = "oauth_consent_revoked"
+	RevokedByAccountDisable = "account_disabled"
+	RevokedByAccountDelete  = "account_deleted"
+	RevokedByRoleChange     = "role_changed"
+	RevokedByForcedReset    = "password_reset_required"
+)
+
+var (
+	// ErrInvalidRefreshToken is returned for unknown, expired or revoked refresh tokens.
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	// ErrRefreshTokenReused is returned when a rotated refresh token is presented again. The session is revoked.
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected, the session has been revoked")
+)
+
+// defaultAccessTokenTTL is the lifetime of access tokens when ACCESS_TOKEN_TTL is not set.
+const defaultAccessTokenTTL = 15 * time.Minute
+
+// RoleService is the role of the tokens issued to service clients, other services calling on their own behalf.
+const RoleService = "service"
+
+type Claims struct {
+	UserID        uint     `json:"userID"`
+	Username      string   `json:"username"`
+	Role          string   `json:"role"`                     // Role of the account, empty for OAuth clients
+	PhoneVerified bool     `json:"phone_verified,omitempty"` // The user has a verified phone number
+	Roles         []string `json:"roles,omitempty"`          // Every role of the user, starting with Role
+	Permissions   []string `json:"perms,omitempty"`          // Permissions granted by Roles; admins have all of them
+	SessionID     uint     `json:"sid"`                      // Session the token was issued for
+	ClientID      string   `json:"client_id,omitempty"`      // OAuth client the session was granted to
+	Scope         string   `json:"scope,omitempty"`          // Scopes granted to the OAuth client
+	jwt.RegisteredClaims
+}
+
+// ITokenService defines the interface for issuing, rotating and revoking tokens.
+type ITokenService interface {
+	IssueTokens(user models.User, login models.LoginHistory) (*dto.UserLoginResponseDto, error)
+	IssueClientTokens(user models.User, clientID, scope string) (*dto.UserLoginResponseDto, error)
+	RefreshTokens(req dto.RefreshTokenRequest) (*dto.UserLoginResponseDto, error)
+	RefreshClientTokens(req dto.RefreshTokenRequest, clientID string) (*dto.UserLoginResponseDto, error)
+	Logout(req dto.RefreshTokenRequest) error
+	IssueClientCredentialsToken(clientID, name, scope string) (*dto.UserLoginResponseDto, error)
+	SignToken(claims jwt.Claims) (string, error)
+}
+
+// TokenService issues short-lived access tokens and rotating refresh tokens grouped in sessions.
+type TokenService struct {
+	userRepo         repository.IUserRepository
+	sessionRepo      repository.ISessionRepository
+	loginHistoryRepo repository.ILoginHistoryRepository
+	roleRepo         repository.IRoleRepository
+	keyService       IKeyService
+	accessTokenTTL   time.Duration
+	refreshTokenTTL  time.Duration
+}
+
+// NewTokenService creates a new instance of TokenService.
+func NewTokenService(userRepo repository.IUserRepository, sessionRepo repository.ISessionRepository, loginHistoryRepo repository.ILoginHistoryRepository, roleRepo repository.IRoleRepository, keyService IKeyService) ITokenService {
+	return &TokenService{
+		userRepo:         userRepo,
+		sessionRepo:      sessionRepo,
+		loginHistoryRepo: loginHistoryRepo,
+		roleRepo:         roleRepo,
+		keyService:       keyService,
+		accessTokenTTL:   config.GetDuration("ACCESS_TOKEN_TTL", defaultAccessTokenTTL),
+		refreshTokenTTL:  config.GetDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+	}
+}
+
+// IssueTokens starts a new session for an authenticated user on the device of their login and returns its first
+// token pair.
+func (s *TokenService) IssueTokens(user models.User, login models.LoginHistory) (*dto.UserLoginResponseDto, error) {
+	refreshToken, token, err := s.newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	session := models.Session{
+		UserID:            user.ID,
+		LoginHistoryID:    &login.ID,
+		IPAddress:         login.IPAddress,
+		DeviceInformation: login.DeviceInformation,
+		LastSeenAt:        login.LoginTime,
+	}
+	if err := s.sessionRepo.CreateSession(&session, token); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	return s.tokenResponse(user, session, refreshToken)
+}
+
+// IssueClientTokens starts a session granted to an OAuth client with the given scopes and returns its first
+// token pair.
+func (s *TokenService) IssueClientTokens(user models.User, clientID, scope string) (*dto.UserLoginResponseDto, error) {
+	refreshToken, token, err := s.newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	session := models.Session{UserID: user.ID, ClientID: clientID, Scope: scope, LastSeenAt: time.Now()}
+	if err := s.sessionRepo.CreateSession(&session, token); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	return s.tokenResponse(user, session, refreshToken)
+}
+
+// RefreshTokens exchanges a refresh token for a new access token and a new refresh token. Each refresh token
+// can be used once; presenting a used one means it was stolen, so the whole session is revoked.
+func (s *TokenService) RefreshTokens(req dto.RefreshTokenRequest) (*dto.UserLoginResponseDto, error) {
+	return s.refresh(req, "")
+}
+
+// RefreshClientTokens rotates a refresh token of a session granted to the OAuth client clientID.
+func (s *TokenService) RefreshClientTokens(req dto.RefreshTokenRequest, clientID string) (*dto.UserLoginResponseDto, error) {
+	return s.refresh(req, clientID)
+}
+
+// refresh rotates a refresh token of a session granted to clientID, or of a first-party session when it is empty.
+func (s *TokenService) refresh(req dto.RefreshTokenRequest, clientID string) (*dto.UserLoginResponseDto, error) {
+	current, err := s.sessionRepo.FindRefreshTokenByHash(hashToken(req.RefreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	if current.Session.RevokedAt != nil || current.Session.ClientID != clientID {
+		return nil, ErrInvalidRefreshToken
+	}
+	if current.UsedAt != nil {
+		return nil, s.revokeForReuse(current.SessionID)
+	}
+	now := time.Now()
+	if now.After(current.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.userRepo.FindByID(current.Session.UserID)
+	if err != nil || user.IsDisabled() {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	nextToken, next, err := s.newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sessionRepo.RotateRefreshToken(current, next, now); err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenAlreadyUsed) {
+			return nil, s.revokeForReuse(current.SessionID)
+		}
+		return nil, err
+	}
+	if err := s.sessionRepo.TouchSession(current.SessionID, req.IPAddress, now, now); err != nil {
+		return nil, err
+	}
+	return s.tokenResponse(*user, current.Session, nextToken)
+}
+
+// Logout revokes the session of a refresh token and records the logout on the login that started it.
+func (s *TokenService) Logout(req dto.RefreshTokenRequest) error {
+	token, err := s.sessionRepo.FindRefreshTokenByHash(hashToken(req.RefreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidRefreshToken
+		}
+		return err
+	}
+	if token.Session.RevokedAt != nil {
+		return nil // Already signed out
+	}
+
+	now := time.Now()
+	if err := s.sessionRepo.RevokeSession(token.SessionID, RevokedByLogout, now); err != nil {
+		return err
+	}
+	if token.Session.LoginHistoryID != nil {
+		return s.loginHistoryRepo.UpdateLogoutTime(*token.Session.LoginHistoryID, now)
+	}
+	return nil
+}
+
+func (s *TokenService) revokeForReuse(sessionID uint) error {
+	if err := s.sessionRepo.RevokeSession(sessionID, RevokedByTokenReuse, time.Now()); err != nil {
+		return err
+	}
+	log.Printf("Refresh token reuse detected, revoked session %d", sessionID)
+	return ErrRefreshTokenReused
+}
+
+func (s *TokenService) newRefreshToken() (string, *models.RefreshToken, error) {
+	refreshToken, err := pkg.GenerateToken()
+	if err != nil {
+		return "", nil, err
+	}
+	return refreshToken, &models.RefreshToken{
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: time.Now().Add(s.refreshTokenTTL),
+	}, nil
+}
+
+func (s *TokenService) tokenResponse(user models.User, session models.Session, refreshToken string) (*dto.UserLoginResponseDto, error) {
+	accessToken, err := s.generateAccessToken(user, session)
+	if err != nil {
+		return nil, err
+	}
+	return dto.UserLoginResponse(user.ID, accessToken, refreshToken, int64(s.accessTokenTTL.Seconds())), nil
+}
+
+// IssueClientCredentialsToken returns a short-lived access token with the service role for the service client
+// clientID, limited to scope. It belongs to no user or session, so it has no refresh token.
+func (s *TokenService) IssueClientCredentialsToken(clientID, name, scope string) (*dto.UserLoginResponseDto, error) {
+	accessToken, err := s.signAccessToken(&Claims{Username: name, Role: RoleService, ClientID: clientID, Scope: scope}, clientID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.UserLoginResponse(0, accessToken, "", int64(s.accessTokenTTL.Seconds())), nil
+}
+
+// generateAccessToken generates a short-lived JWT access token for a user's session. Roles and permissions are
+// resolved on every issue, so changes reach users with their next access token. Tokens of OAuth clients are
+// limited to their scopes and carry neither the roles nor the permissions of the user.
+func (s *TokenService) generateAccessToken(user models.User, session models.Session) (string, error) {
+	claims := &Claims{
+		UserID:        user.ID,
+		Username:      user.Username,
+		PhoneVerified: user.PhoneVerified,
+		SessionID:     session.ID,
+		ClientID:      session.ClientID,
+		Scope:         session.Scope,
+	}
+	if session.ClientID == "" {
+		roles, permissions, err := resolveAccess(s.roleRepo, user)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve permissions: %w", err)
+		}
+		claims.Role, claims.Roles, claims.Permissions = user.Role, roles, permissions
+	}
+	return s.signAccessToken(claims, strconv.FormatUint(uint64(user.ID), 10))
+}
+
+// signAccessToken completes the registered claims of an access token and signs it.
+func (s *TokenService) signAccessToken(claims *Claims, subject string) (string, error) {
+	now := time.Now()
+	tokenID, err := pkg.GenerateToken()
+	if err != nil {
+		return "", err
+	}
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        tokenID,
+		Subject:   subject,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(s.accessTokenTTL)),
+	}
+	return s.SignToken(claims)
+}
+
+// SignToken signs claims with the active signing key, naming the key in the kid header.
+func (s *TokenService) SignToken(claims jwt.Claims) (string, error) {
+	key, err := s.keyService.ActiveKey()
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.KID
+	return token.SignedString(key.PrivateKey)
+}
+
+// hashToken returns the hex encoded SHA-256 of an opaque token, which is what gets stored.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,268 @@
+package services
+
+import (
+	"auth-service/internal/api/dto"
+	"auth-service/internal/models"
+	"auth-service/internal/repository"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+// fakeSessionRepository stores a single refresh token and records the sessions it revokes.
+type fakeSessionRepository struct {
+	repository.ISessionRepository
+	token     *models.RefreshToken
+	rotateErr error
+	revoked   map[uint]string
+	kept      []uint // Sessions kept by RevokeUserSessions
+}
+
+func (r *fakeSessionRepository) FindRefreshTokenByHash(tokenHash string) (*models.RefreshToken, error) {
+	if r.token == nil || r.token.TokenHash != tokenHash {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.token, nil
+}
+
+func (r *fakeSessionRepository) RotateRefreshToken(current *models.RefreshToken, next *models.RefreshToken, usedAt time.Time) error {
+	return r.rotateErr
+}
+
+func (r *fakeSessionRepository) RevokeSession(sessionID uint, reason string, revokedAt time.Time) error {
+	if r.revoked == nil {
+		r.revoked = map[uint]string{}
+	}
+	r.revoked[sessionID] = reason
+	return nil
+}
+
+type fakeUserRepository struct {
+	repository.IUserRepository
+	user        *models.User
+	password    string // Hash set by UpdatePassword
+	failures    int    // Consecutive failed logins
+	lockedUntil *time.Time
+	takenPhones []string // Phone numbers verified by other users
+	deleteErr   error
+}
+
+func (r *fakeUserRepository) Delete(userID uint) error {
+	return r.deleteErr
+}
+
+func (r *fakeUserRepository) FindByUsername(username string) (*models.User, error) {
+	if r.user == nil || r.user.Username != username {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.user, nil
+}
+
+func (r *fakeUserRepository) FindByID(userID uint) (*models.User, error) {
+	if r.user == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.user, nil
+}
+
+func (r *fakeUserRepository) FindByEmail(email string) (*models.User, error) {
+	if r.user == nil || r.user.Email != email {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.user, nil
+}
+
+func (r *fakeUserRepository) UpdatePassword(userID uint, passwordHash string) error {
+	r.password = passwordHash
+	return nil
+}
+
+func (r *fakeUserRepository) PhoneNumberInUse(phoneNumber string, exceptUserID uint) (bool, error) {
+	for _, taken := range r.takenPhones {
+		if taken == phoneNumber {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeUserRepository) IncrementFailedLogins(userID uint, windowStart, at time.Time) (int, error) {
+	r.failures++
+	return r.failures, nil
+}
+
+func (r *fakeUserRepository) LockUser(userID uint, until time.Time) error {
+	r.lockedUntil, r.failures = &until, 0
+	return nil
+}
+
+type fakeLoginHistoryRepository struct {
+	repository.ILoginHistoryRepository
+	loggedOut map[uint]time.Time
+	recorded  []models.LoginHistory
+	archived  []models.LoginHistoryArchive
+}
+
+func (r *fakeLoginHistoryRepository) RecordLoginAttempt(history *models.LoginHistory) error {
+	r.recorded = append(r.recorded, *history)
+	return nil
+}
+
+func (r *fakeLoginHistoryRepository) UpdateLogoutTime(historyID uint, logoutTime time.Time) error {
+	if r.loggedOut == nil {
+		r.loggedOut = map[uint]time.Time{}
+	}
+	r.loggedOut[historyID] = logoutTime
+	return nil
+}
+
+func (r *fakeSessionRepository) RevokeUserSessions(userID, exceptSessionID uint, reason string, revokedAt time.Time) error {
+	r.kept = append(r.kept, exceptSessionID)
+	return nil
+}
+
+// refreshToken returns a refresh token of session 3 stored under the hash of raw.
+func refreshToken(raw string, change func(*models.RefreshToken)) *models.RefreshToken {
+	token := &models.RefreshToken{SessionID: 3, TokenHash: hashToken(raw), ExpiresAt: time.Now().Add(time.Hour)}
+	token.Session.ID = 3
+	token.Session.UserID = 5
+	if change != nil {
+		change(token)
+	}
+	return token
+}
+
+func TestRefreshTokensRejects(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	tests := []struct {
+		name        string
+		token       *models.RefreshToken
+		rotateErr   error
+		want        error
+		wantRevoked bool
+	}{
+		{name: "unknown token", token: nil, want: ErrInvalidRefreshToken},
+		{name: "expired token", token: refreshToken("secret", func(t *models.RefreshToken) { t.ExpiresAt = past }), want: ErrInvalidRefreshToken},
+		{name: "revoked session", token: refreshToken("secret", func(t *models.RefreshToken) { t.Session.RevokedAt = &past }), want: ErrInvalidRefreshToken},
+		{name: "reused token", token: refreshToken("secret", func(t *models.RefreshToken) { t.UsedAt = &past }), want: ErrRefreshTokenReused, wantRevoked: true},
+		{name: "rotated concurrently", token: refreshToken("secret", nil), rotateErr: repository.ErrRefreshTokenAlreadyUsed, want: ErrRefreshTokenReused, wantRevoked: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessions := &fakeSessionRepository{token: tt.token, rotateErr: tt.rotateErr}
+			service := &TokenService{
+				userRepo:        &fakeUserRepository{user: &models.User{Username: "ann"}},
+				sessionRepo:     sessions,
+				refreshTokenTTL: time.Hour,
+			}
+
+			_, err := service.RefreshTokens(dto.RefreshTokenRequest{RefreshToken: "secret"})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("RefreshTokens() error = %v, want %v", err, tt.want)
+			}
+			if reason, revoked := sessions.revoked[3]; revoked != tt.wantRevoked || (revoked && reason != RevokedByTokenReuse) {
+				t.Errorf("session revoked = %v (%q), want %v", revoked, reason, tt.wantRevoked)
+			}
+		})
+	}
+}
+
+func TestLogoutRevokesTheSession(t *testing.T) {
+	historyID := uint(11)
+	sessions := &fakeSessionRepository{token: refreshToken("secret", func(t *models.RefreshToken) {
+		t.Session.LoginHistoryID = &historyID
+	})}
+	history := &fakeLoginHistoryRepository{}
+	service := &TokenService{sessionRepo: sessions, loginHistoryRepo: history}
+
+	if err := service.Logout(dto.RefreshTokenRequest{RefreshToken: "secret"}); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+	if sessions.revoked[3] != RevokedByLogout {
+		t.Errorf("session revoked for %q, want %q", sessions.revoked[3], RevokedByLogout)
+	}
+	if _, ok := history.loggedOut[historyID]; !ok {
+		t.Errorf("logout time not recorded on login %d", historyID)
+	}
+}
+
+// fakeKeyService signs tokens with a single Ed25519 key.
+type fakeKeyService struct {
+	IKeyService
+	key *ActiveSigningKey
+}
+
+func (s *fakeKeyService) ActiveKey() (*ActiveSigningKey, error) {
+	return s.key, nil
+}
+
+func TestGenerateAccessTokenClaims(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	service := &TokenService{
+		roleRepo: &fakeRoleRepository{
+			userRoles:   []models.UserRole{{RoleName: "dispatcher"}},
+			permissions: map[string][]string{"dispatcher": {"schedules:manage"}},
+		},
+		keyService:     &fakeKeyService{key: &ActiveSigningKey{KID: "test", Method: jwt.SigningMethodEdDSA, PrivateKey: private}},
+		accessTokenTTL: time.Minute,
+	}
+	user := models.User{Username: "ann", Role: models.RoleCustomer}
+	user.ID = 7
+
+	tests := []struct {
+		name            string
+		session         models.Session
+		wantRole        string
+		wantRoles       []string
+		wantPermissions []string
+		wantScope       string
+	}{
+		{
+			name:            "first-party session",
+			session:         models.Session{},
+			wantRole:        models.RoleCustomer,
+			wantRoles:       []string{models.RoleCustomer, "dispatcher"},
+			wantPermissions: []string{"schedules:manage"},
+		},
+		{
+			name:      "oauth client session",
+			session:   models.Session{ClientID: "partner", Scope: "openid profile"},
+			wantScope: "openid profile",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signed, err := service.generateAccessToken(user, tt.session)
+			if err != nil {
+				t.Fatalf("generateAccessToken() error = %v", err)
+			}
+			var claims Claims
+			if _, err := jwt.ParseWithClaims(signed, &claims, func(*jwt.Token) (interface{}, error) {
+				return public, nil
+			}); err != nil {
+				t.Fatalf("ParseWithClaims() error = %v", err)
+			}
+			if claims.Role != tt.wantRole {
+				t.Errorf("role = %q, want %q", claims.Role, tt.wantRole)
+			}
+			if !reflect.DeepEqual(claims.Roles, tt.wantRoles) {
+				t.Errorf("roles = %v, want %v", claims.Roles, tt.wantRoles)
+			}
+			if !reflect.DeepEqual(claims.Permissions, tt.wantPermissions) {
+				t.Errorf("permissions = %v, want %v", claims.Permissions, tt.wantPermissions)
+			}
+			if claims.Scope != tt.wantScope {
+				t.Errorf("scope = %q, want %q", claims.Scope, tt.wantScope)
+			}
+		})
+	}
+}
@@ -2,38 +2,71 @@ package services
 
 import (
 	"auth-service/internal/api/dto"
+	"auth-service/internal/config"
 	"auth-service/internal/models"
 	"auth-service/internal/repository"
 	"errors"
-	"github.com/dgrijalva/jwt-go"
-	"golang.org/x/crypto/bcrypt"
-	"os"
+	"fmt"
+	"gorm.io/gorm"
+	"log"
 	"time"
 )
 
+var (
+	// ErrInvalidCurrentPassword is returned when a password change does not prove the current password.
+	ErrInvalidCurrentPassword = errors.New("current password is incorrect")
+	// ErrInvalidCredentials is returned for an unknown username or a wrong password alike.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrAccountLocked is returned for logins to an account locked after too many failed attempts.
+	ErrAccountLocked = errors.New("account is temporarily locked after too many failed login attempts")
+	// ErrAccountDisabled is returned for logins to an account disabled by an admin.
+	ErrAccountDisabled = errors.New("account is disabled")
+	// ErrPasswordResetRequired is returned for logins to an account whose password must be reset first.
+	ErrPasswordResetRequired = errors.New("password must be reset before signing in, check your email for the reset link")
+)
+
 type IUserService interface {
 	RegisterUser(userDTO dto.CreateUserRequest) (*dto.UserResponse, error)
 	AuthenticateUser(loginDTO dto.UserLoginDTO) (*dto.UserLoginResponseDto, error)
-	UpdateUserPassword(passwordDTO dto.UserPasswordUpdateDTO) error
-	DeleteUser(userID uint) error
+	CompleteMFALogin(req dto.MFALoginRequest) (*dto.UserLoginResponseDto, error)
+	LoginWithCode(req dto.PasswordlessCodeRequest) (*dto.UserLoginResponseDto, error)
+	LoginWithLink(req dto.PasswordlessLinkRequest) (*dto.UserLoginResponseDto, error)
+	UpdateUserPassword(passwordDTO dto.UserPasswordUpdateDTO, actor dto.Actor) error
+	UnlockUser(userID uint, actor dto.Actor) error
+	DeleteUser(userID uint, actor dto.Actor) error
 }
 
-var JwtSecretKey = os.Getenv("JWT_SECRET")
-
 type UserService struct {
-	userRepo repository.IUserRepository
+	userRepo            repository.IUserRepository
+	sessionRepo         repository.ISessionRepository
+	loginHistoryRepo    repository.ILoginHistoryRepository
+	tokenService        ITokenService
+	verificationService IUserVerificationService
+	mfaService          IMFAService
+	anomalyService      ILoginAnomalyService
+	passwordService     IPasswordService
+	passwordlessService IPasswordlessService
+	erasureService      IAccountErasureService
+	auditService        IAuditService
+	lockoutDuration     time.Duration
+	maxLockoutDuration  time.Duration
 }
 
-type Claims struct {
-	UserID   uint   `json:"userID"`
-	Username string `json:"username"`
-	Role     string `json:"role"`
-	jwt.StandardClaims
-}
-
-func NewUserService(userRepo repository.IUserRepository) IUserService {
+func NewUserService(userRepo repository.IUserRepository, sessionRepo repository.ISessionRepository, loginHistoryRepo repository.ILoginHistoryRepository, tokenService ITokenService, verificationService IUserVerificationService, mfaService IMFAService, anomalyService ILoginAnomalyService, passwordService IPasswordService, passwordlessService IPasswordlessService, erasureService IAccountErasureService, auditService IAuditService) IUserService {
 	return &UserService{
-		userRepo: userRepo,
+		userRepo:            userRepo,
+		sessionRepo:         sessionRepo,
+		loginHistoryRepo:    loginHistoryRepo,
+		tokenService:        tokenService,
+		verificationService: verificationService,
+		mfaService:          mfaService,
+		anomalyService:      anomalyService,
+		passwordService:     passwordService,
+		passwordlessService: passwordlessService,
+		erasureService:      erasureService,
+		auditService:        auditService,
+		lockoutDuration:     config.GetDuration("ACCOUNT_LOCKOUT_DURATION", 15*time.Minute),
+		maxLockoutDuration:  config.GetDuration("ACCOUNT_LOCKOUT_MAX_DURATION", 24*time.Hour),
 	}
 }
 
@@ -48,65 +81,313 @@ func (s *UserService) RegisterUser(userDTO dto.CreateUserRequest) (*dto.UserResp
 	}
 
 	user := userDTO.ToUserModel()
+	if err := s.passwordService.Validate(user, userDTO.Password); err != nil {
+		return nil, err
+	}
+	if user.Password, err = s.passwordService.Hash(userDTO.Password); err != nil {
+		return nil, err
+	}
 	if err := s.userRepo.Create(&user); err != nil {
 		return nil, err
 	}
+	if err := s.passwordService.Record(user.ID, user.Password); err != nil {
+		return nil, err
+	}
+
+	// The account exists even if the email cannot be sent now; the user can ask for it again.
+	if err := s.verificationService.SendEmailVerification(user); err != nil {
+		log.Printf("Could not send verification email to user %d: %v", user.ID, err)
+	}
 
 	response := dto.FromUserModel(user)
 	return &response, nil
 }
 
-// AuthenticateUser verifies the username and password and returns the user.
+// AuthenticateUser verifies the username and password and starts a session. Every attempt on an existing
+// account is recorded in its login history. After SuspiciousActivityThreshold failures within
+// SuspiciousActivityTimeFrame the account is locked, for twice as long on each further lockout. Users with MFA,
+// or whose role requires it, get an MFA token to complete the login with instead of the session tokens.
 func (s *UserService) AuthenticateUser(loginDTO dto.UserLoginDTO) (*dto.UserLoginResponseDto, error) {
 	user, err := s.userRepo.FindByUsername(loginDTO.Username)
 	if err != nil {
-		return nil, errors.New("user not found")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
 	}
 
-	// Compare the provided password with the stored hash
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(loginDTO.Password)); err != nil {
-		return nil, errors.New("invalid credentials")
+	now := time.Now()
+	if user.IsLocked(now) {
+		if err := s.recordLogin(user.ID, loginDTO, now, models.LoginFailureAccountLocked); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w, try again after %s", ErrAccountLocked, user.LockedUntil.Format(time.RFC3339))
 	}
 
-	// Generate JWT token
-	token, err := s.GenerateJWT(*user)
+	// Compare the provided password with the stored hash, upgrading the hash when it is outdated
+	match, err := s.passwordService.Verify(*user, loginDTO.Password)
 	if err != nil {
 		return nil, err
 	}
+	if !match {
+		if err := s.recordLogin(user.ID, loginDTO, now, models.LoginFailureInvalidCredentials); err != nil {
+			return nil, err
+		}
+		if err := s.registerFailedLogin(*user, now); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidCredentials
+	}
+	// Only callers who know the password learn that the account cannot be used
+	if reason, err := loginRefusal(*user); err != nil {
+		if err := s.recordLogin(user.ID, loginDTO, now, reason); err != nil {
+			return nil, err
+		}
+		return nil, err
+	}
+	return s.startLogin(*user, loginDTO, now)
+}
+
+// CompleteMFALogin completes a login with the MFA token returned by AuthenticateUser and a TOTP or recovery
+// code. Invalid codes count as failed logins.
+func (s *UserService) CompleteMFALogin(req dto.MFALoginRequest) (*dto.UserLoginResponseDto, error) {
+	challenge, recoveryCodes, err := s.mfaService.CompleteChallenge(req)
+	if challenge == nil {
+		return nil, err
+	}
+	user, findErr := s.userRepo.FindByID(challenge.UserID)
+	if findErr != nil {
+		return nil, findErr
+	}
+
+	// The login continues from the device that entered the password
+	loginDTO := dto.UserLoginDTO{Username: user.Username, IPAddress: challenge.IPAddress, DeviceInformation: challenge.DeviceInformation}
+	now := time.Now()
+	if err != nil {
+		if err := s.recordLogin(user.ID, loginDTO, now, models.LoginFailureInvalidMFACode); err != nil {
+			return nil, err
+		}
+		if err := s.registerFailedLogin(*user, now); err != nil {
+			return nil, err
+		}
+		return nil, err
+	}
+	if user.IsLocked(now) {
+		return nil, fmt.Errorf("%w, try again after %s", ErrAccountLocked, user.LockedUntil.Format(time.RFC3339))
+	}
+	if _, err := loginRefusal(*user); err != nil {
+		return nil, err
+	}
+
+	response, err := s.completeLogin(*user, loginDTO, now)
+	if err != nil {
+		return nil, err
+	}
+	response.RecoveryCodes = recoveryCodes
+	return response, nil
+}
+
+// LoginWithCode completes a passwordless login with its login token and the code sent to the user. Invalid
+// codes count as failed logins.
+func (s *UserService) LoginWithCode(req dto.PasswordlessCodeRequest) (*dto.UserLoginResponseDto, error) {
+	loginCode, err := s.passwordlessService.CompleteWithCode(req)
+	return s.completePasswordlessLogin(loginCode, err, req.IPAddress, req.DeviceInformation)
+}
+
+// LoginWithLink completes a passwordless login with the token of the link sent to the user.
+func (s *UserService) LoginWithLink(req dto.PasswordlessLinkRequest) (*dto.UserLoginResponseDto, error) {
+	loginCode, err := s.passwordlessService.CompleteWithLink(req)
+	return s.completePasswordlessLogin(loginCode, err, req.IPAddress, req.DeviceInformation)
+}
+
+// UnlockUser lifts the lockout of an account before it expires.
+func (s *UserService) UnlockUser(userID uint, actor dto.Actor) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.UnlockUser(userID); err != nil {
+		return err
+	}
+	s.auditService.Record(actor, AuditUserUnlocked, AuditTargetUser, userID, auditChange("lockedUntil", user.LockedUntil, nil))
+	return nil
+}
+
+// UpdateUserPassword changes a user's password after checking the current one, and signs the user out of
+// their other sessions.
+func (s *UserService) UpdateUserPassword(passwordDTO dto.UserPasswordUpdateDTO, actor dto.Actor) error {
+	user, err := s.userRepo.FindByID(passwordDTO.UserID)
+	if err != nil {
+		return err
+	}
+	match, err := s.passwordService.Verify(*user, passwordDTO.CurrentPassword)
+	if err != nil {
+		return err
+	}
+	if !match {
+		return ErrInvalidCurrentPassword
+	}
+	if err := s.passwordService.Validate(*user, passwordDTO.Password); err != nil {
+		return err
+	}
 
-	return dto.UserLoginResponse(user.ID, token), nil
+	passwordHash, err := s.passwordService.Hash(passwordDTO.Password)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.UpdatePassword(user.ID, passwordHash); err != nil {
+		return err
+	}
+	if err := s.passwordService.Record(user.ID, passwordHash); err != nil {
+		return err
+	}
+	s.auditService.Record(actor, AuditUserPasswordChanged, AuditTargetUser, user.ID, nil)
+	return s.sessionRepo.RevokeUserSessions(user.ID, passwordDTO.SessionID, RevokedByPasswordChange, time.Now())
 }
 
-// UpdateUserPassword updates a user's password.
-func (s *UserService) UpdateUserPassword(passwordDTO dto.UserPasswordUpdateDTO) error {
-	// Ensure the new password is hashed in the DTO
-	user := passwordDTO.ToUpdatePasswordModel()
-	user.ID = passwordDTO.UserID // Attach the UserID to the model
-	return s.userRepo.UpdatePassword(user.ID, user.Password)
+// DeleteUser removes a user from the database and ends their sessions, so they stay signed out if the account
+// is restored. The data of the account is erased across services once the grace period ends, unless it is
+// restored before.
+func (s *UserService) DeleteUser(userID uint, actor dto.Actor) error {
+	// The erasure is scheduled first, so that no account is deleted without its data being erased
+	if err := s.erasureService.Schedule(userID, actor.UserID); err != nil {
+		return err
+	}
+	if err := s.userRepo.Delete(userID); err != nil {
+		if cancelErr := s.erasureService.Cancel(userID); cancelErr != nil {
+			log.Printf("Could not cancel the erasure of user %d after deleting them failed: %v", userID, cancelErr)
+		}
+		return err
+	}
+	s.auditService.Record(actor, AuditUserDeleted, AuditTargetUser, userID, nil)
+	return s.sessionRepo.RevokeUserSessions(userID, 0, RevokedByAccountDelete, time.Now())
 }
 
-// DeleteUser removes a user from the database.
-func (s *UserService) DeleteUser(userID uint) error {
-	return s.userRepo.Delete(userID)
+// completePasswordlessLogin logs the user of a checked login code in as AuthenticateUser does after verifying a
+// password. codeErr is the error of checking the code, recorded as a failed login when the code was found.
+func (s *UserService) completePasswordlessLogin(loginCode *models.LoginCode, codeErr error, ipAddress, deviceInformation string) (*dto.UserLoginResponseDto, error) {
+	if loginCode == nil {
+		return nil, codeErr
+	}
+	user, err := s.userRepo.FindByID(loginCode.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	loginDTO := dto.UserLoginDTO{Username: user.Username, IPAddress: ipAddress, DeviceInformation: deviceInformation}
+	now := time.Now()
+	if codeErr != nil {
+		if err := s.recordLogin(user.ID, loginDTO, now, models.LoginFailureInvalidLoginCode); err != nil {
+			return nil, err
+		}
+		if err := s.registerFailedLogin(*user, now); err != nil {
+			return nil, err
+		}
+		return nil, codeErr
+	}
+	if user.IsLocked(now) {
+		if err := s.recordLogin(user.ID, loginDTO, now, models.LoginFailureAccountLocked); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w, try again after %s", ErrAccountLocked, user.LockedUntil.Format(time.RFC3339))
+	}
+	if reason, err := loginRefusal(*user); err != nil {
+		if err := s.recordLogin(user.ID, loginDTO, now, reason); err != nil {
+			return nil, err
+		}
+		return nil, err
+	}
+	return s.startLogin(*user, loginDTO, now)
 }
 
-// GenerateJWT generates a new JWT token for a given user.
-func (s *UserService) GenerateJWT(user models.User) (string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour) // Token expires in 24 hours
-	claims := &Claims{
-		UserID:   user.ID,
-		Username: user.Username,
-		Role:     user.Role,
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: expirationTime.Unix(),
-		},
+// startLogin continues a login whose first factor was verified. Users with MFA, or whose role requires it, get
+// an MFA token to complete the login with; the others get the session tokens.
+func (s *UserService) startLogin(user models.User, loginDTO dto.UserLoginDTO, at time.Time) (*dto.UserLoginResponseDto, error) {
+	enrolled, required, err := s.mfaService.LoginRequirement(user)
+	if err != nil {
+		return nil, err
+	}
+	if enrolled || required {
+		mfaToken, err := s.mfaService.StartChallenge(user, loginDTO)
+		if err != nil {
+			return nil, err
+		}
+		return dto.MFAChallengeResponse(user.ID, mfaToken, !enrolled), nil
 	}
+	return s.completeLogin(user, loginDTO, at)
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(JwtSecretKey))
+// completeLogin records a successful login and starts a session with a short-lived access token and a
+// refresh token. The user is alerted when the login looks anomalous.
+func (s *UserService) completeLogin(user models.User, loginDTO dto.UserLoginDTO, at time.Time) (*dto.UserLoginResponseDto, error) {
+	history := loginHistory(user.ID, loginDTO, at, "")
+	if err := s.anomalyService.Analyze(&history); err != nil {
+		return nil, err
+	}
+	if err := s.loginHistoryRepo.RecordLoginAttempt(&history); err != nil {
+		return nil, err
+	}
+	// The login succeeds even if the alert cannot be sent now; it stays flagged in the login history.
+	if err := s.anomalyService.Alert(user, history); err != nil {
+		log.Printf("Could not send security alert to user %d: %v", user.ID, err)
+	}
+	if err := s.userRepo.ResetFailedLogins(user.ID); err != nil {
+		return nil, err
+	}
+	return s.tokenService.IssueTokens(user, history)
+}
+
+// registerFailedLogin counts a failed login and locks the account once the threshold is reached.
+func (s *UserService) registerFailedLogin(user models.User, at time.Time) error {
+	failures, err := s.userRepo.IncrementFailedLogins(user.ID, at.Add(-SuspiciousActivityTimeFrame), at)
 	if err != nil {
-		return "", err
+		return err
+	}
+	if failures < SuspiciousActivityThreshold {
+		return nil
 	}
 
-	return tokenString, nil
+	lockout := s.lockoutDuration
+	for i := 0; i < user.LockoutCount && lockout < s.maxLockoutDuration; i++ {
+		lockout *= 2
+	}
+	if lockout > s.maxLockoutDuration {
+		lockout = s.maxLockoutDuration
+	}
+	if err := s.userRepo.LockUser(user.ID, at.Add(lockout)); err != nil {
+		return err
+	}
+	log.Printf("Locked user %d for %s after %d failed login attempts", user.ID, lockout, failures)
+	return nil
+}
+
+// recordLogin adds a failed login attempt to the user's login history.
+func (s *UserService) recordLogin(userID uint, loginDTO dto.UserLoginDTO, at time.Time, failureReason string) error {
+	history := loginHistory(userID, loginDTO, at, failureReason)
+	return s.loginHistoryRepo.RecordLoginAttempt(&history)
+}
+
+// loginRefusal returns why logins to an account are refused by an admin's decision, with the failure reason to
+// record, or nil if they are allowed.
+func loginRefusal(user models.User) (string, error) {
+	switch {
+	case user.IsDisabled():
+		return models.LoginFailureAccountDisabled, ErrAccountDisabled
+	case user.PasswordResetRequired:
+		return models.LoginFailurePasswordReset, ErrPasswordResetRequired
+	default:
+		return "", nil
+	}
+}
+
+// loginHistory describes a login attempt, which succeeded when it has no failure reason.
+func loginHistory(userID uint, loginDTO dto.UserLoginDTO, at time.Time, failureReason string) models.LoginHistory {
+	return models.LoginHistory{
+		UserID:            userID,
+		LoginTime:         at,
+		IPAddress:         loginDTO.IPAddress,
+		DeviceInformation: loginDTO.DeviceInformation,
+		Successful:        failureReason == "",
+		FailureReason:     failureReason,
+	}
 }
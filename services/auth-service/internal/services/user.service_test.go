@@ -0,0 +1,181 @@
+package services
+
+import (
+	"auth-service/internal/api/dto"
+	"auth-service/internal/models"
+	"auth-service/internal/password"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestUpdateUserPassword(t *testing.T) {
+	current, err := password.Hash("current password")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	tests := []struct {
+		name            string
+		currentPassword string
+		want            error
+	}{
+		{name: "current password", currentPassword: "current password"},
+		{name: "wrong current password", currentPassword: "guess", want: ErrInvalidCurrentPassword},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &fakeUserRepository{user: &models.User{Password: current}}
+			sessions := &fakeSessionRepository{}
+			service := &UserService{userRepo: users, sessionRepo: sessions, passwordService: newTestPasswordService(users), auditService: &fakeAuditService{}}
+
+			err := service.UpdateUserPassword(dto.UserPasswordUpdateDTO{UserID: 5, SessionID: 8, CurrentPassword: tt.currentPassword, Password: "new password"}, dto.Actor{UserID: 5})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("UpdateUserPassword() error = %v, want %v", err, tt.want)
+			}
+			if tt.want != nil {
+				if users.password != "" {
+					t.Error("UpdateUserPassword() changed the password without the current one")
+				}
+				return
+			}
+			if match, _, _ := password.Verify("new password", users.password); !match {
+				t.Error("UpdateUserPassword() did not store the hash of the new password")
+			}
+			// The session changing the password stays signed in, the others are signed out
+			if len(sessions.kept) != 1 || sessions.kept[0] != 8 {
+				t.Errorf("sessions kept = %v, want [8]", sessions.kept)
+			}
+		})
+	}
+}
+
+func TestAuthenticateUserLocksTheAccount(t *testing.T) {
+	hash, err := password.Hash("password")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	tests := []struct {
+		name         string
+		lockoutCount int
+		wantLockout  time.Duration
+	}{
+		{name: "first lockout", wantLockout: 15 * time.Minute},
+		{name: "third lockout", lockoutCount: 2, wantLockout: time.Hour},
+		{name: "capped lockout", lockoutCount: 10, wantLockout: 24 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &models.User{Username: "ann", Password: hash, LockoutCount: tt.lockoutCount}
+			users := &fakeUserRepository{user: user}
+			history := &fakeLoginHistoryRepository{}
+			service := &UserService{userRepo: users, loginHistoryRepo: history, passwordService: newTestPasswordService(users), lockoutDuration: 15 * time.Minute, maxLockoutDuration: 24 * time.Hour}
+			login := dto.UserLoginDTO{Username: "ann", Password: "guess"}
+
+			for i := 0; i < SuspiciousActivityThreshold; i++ {
+				if _, err := service.AuthenticateUser(login); !errors.Is(err, ErrInvalidCredentials) {
+					t.Fatalf("AuthenticateUser() attempt %d error = %v, want %v", i+1, err, ErrInvalidCredentials)
+				}
+			}
+			if users.lockedUntil == nil {
+				t.Fatalf("account not locked after %d failed logins", SuspiciousActivityThreshold)
+			}
+			if lockout := users.lockedUntil.Sub(history.recorded[len(history.recorded)-1].LoginTime); lockout != tt.wantLockout {
+				t.Errorf("locked for %s, want %s", lockout, tt.wantLockout)
+			}
+
+			// Even the right password is refused while the account is locked
+			user.LockedUntil = users.lockedUntil
+			login.Password = "password"
+			if _, err := service.AuthenticateUser(login); !errors.Is(err, ErrAccountLocked) {
+				t.Errorf("AuthenticateUser() while locked error = %v, want %v", err, ErrAccountLocked)
+			}
+			last := history.recorded[len(history.recorded)-1]
+			if len(history.recorded) != SuspiciousActivityThreshold+1 || last.FailureReason != models.LoginFailureAccountLocked {
+				t.Errorf("recorded %d attempts ending with %q, want %d ending with %q", len(history.recorded), last.FailureReason,
+					SuspiciousActivityThreshold+1, models.LoginFailureAccountLocked)
+			}
+		})
+	}
+}
+
+func TestAuthenticateUserHidesUnknownUsernames(t *testing.T) {
+	history := &fakeLoginHistoryRepository{}
+	users := &fakeUserRepository{user: &models.User{Username: "ann"}}
+	service := &UserService{userRepo: users, loginHistoryRepo: history, passwordService: newTestPasswordService(users)}
+
+	if _, err := service.AuthenticateUser(dto.UserLoginDTO{Username: "bo", Password: "guess"}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("AuthenticateUser() error = %v, want %v", err, ErrInvalidCredentials)
+	}
+	if len(history.recorded) != 0 {
+		t.Errorf("recorded %d attempts for an unknown username, want none", len(history.recorded))
+	}
+}
+
+func TestAuthenticateUserRefusesDisabledAccounts(t *testing.T) {
+	hash, err := password.Hash("password")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	disabledAt := time.Now()
+	tests := []struct {
+		name       string
+		user       models.User
+		password   string
+		want       error
+		wantReason string
+	}{
+		{name: "disabled account", user: models.User{DisabledAt: &disabledAt}, password: "password", want: ErrAccountDisabled, wantReason: models.LoginFailureAccountDisabled},
+		{name: "password reset required", user: models.User{PasswordResetRequired: true}, password: "password", want: ErrPasswordResetRequired, wantReason: models.LoginFailurePasswordReset},
+		// Only callers who know the password learn that the account cannot be used
+		{name: "disabled account with a wrong password", user: models.User{DisabledAt: &disabledAt}, password: "guess", want: ErrInvalidCredentials, wantReason: models.LoginFailureInvalidCredentials},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := tt.user
+			user.Username, user.Password = "ann", hash
+			history := &fakeLoginHistoryRepository{}
+			users := &fakeUserRepository{user: &user}
+			service := &UserService{userRepo: users, loginHistoryRepo: history, passwordService: newTestPasswordService(users), lockoutDuration: time.Minute, maxLockoutDuration: time.Hour}
+
+			if _, err := service.AuthenticateUser(dto.UserLoginDTO{Username: "ann", Password: tt.password}); !errors.Is(err, tt.want) {
+				t.Fatalf("AuthenticateUser() error = %v, want %v", err, tt.want)
+			}
+			if len(history.recorded) != 1 || history.recorded[0].FailureReason != tt.wantReason {
+				t.Errorf("recorded %v, want a single attempt failed with %q", history.recorded, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestDeleteUser(t *testing.T) {
+	tests := []struct {
+		name      string
+		deleteErr error
+	}{
+		{name: "deleted"},
+		{name: "deleting failed", deleteErr: errors.New("connection reset")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			erasures := &fakeErasureRepository{}
+			sessions := &fakeSessionRepository{}
+			service := &UserService{
+				userRepo:       &fakeUserRepository{deleteErr: tt.deleteErr},
+				sessionRepo:    sessions,
+				erasureService: &AccountErasureService{erasureRepo: erasures},
+				auditService:   &fakeAuditService{},
+			}
+
+			if err := service.DeleteUser(7, dto.Actor{UserID: 1}); !errors.Is(err, tt.deleteErr) {
+				t.Fatalf("DeleteUser() error = %v, want %v", err, tt.deleteErr)
+			}
+			// The erasure is only kept for accounts that were deleted
+			if erasures.erasure == nil || erasures.cancelled != (tt.deleteErr != nil) {
+				t.Errorf("erasure = %+v, cancelled = %v", erasures.erasure, erasures.cancelled)
+			}
+			if signedOut := len(sessions.kept) == 1; signedOut != (tt.deleteErr == nil) {
+				t.Errorf("signed out = %v", signedOut)
+			}
+		})
+	}
+}
@@ -2,8 +2,46 @@ package services
 
 import (
 	"auth-service/internal/api/dto"
+	"auth-service/internal/config"
+	"auth-service/internal/models"
 	"auth-service/internal/repository"
+	"auth-service/internal/sms"
+	"auth-service/pkg"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
 	"time"
+
+	"gorm.io/gorm"
+)
+
+// Errors returned by the verification service so handlers can map them to status codes.
+var (
+	ErrInvalidVerificationToken  = errors.New("invalid verification token")
+	ErrVerificationTokenUsed     = errors.New("verification token has already been used")
+	ErrVerificationTokenExpired  = errors.New("verification token has expired")
+	ErrAlreadyVerified           = errors.New("email address is already verified")
+	ErrVerificationResendTooSoon = errors.New("a verification email was sent recently, please wait before requesting another")
+	ErrInvalidResetToken         = errors.New("invalid or expired password reset token")
+	ErrInvalidPhoneCode          = errors.New("invalid or expired phone verification code, please request a new one")
+	ErrPhoneNumberTaken          = errors.New("phone number is already verified by another account")
+	ErrPhoneAlreadyVerified      = errors.New("phone number is already verified")
+	ErrPhoneNumberMissing        = errors.New("user has no phone number to verify")
+)
+
+const (
+	// phoneCodeDigits is the length of the codes sent to verify phone numbers.
+	phoneCodeDigits = 6
+	// maxPhoneCodeAttempts is how many codes can be entered for a phone verification before it is discarded.
+	maxPhoneCodeAttempts = 5
+)
+
+// Notification-service types of the emails sent for verifications.
+const (
+	emailVerificationNotification = "email_verification"
+	passwordResetNotification     = "password_reset"
 )
 
 type IUserVerificationService interface {
@@ -11,35 +49,94 @@ type IUserVerificationService interface {
 	UpdateVerificationStatus(id uint, status string, verifiedAt *time.Time) (dto.UserVerificationResponse, error)
 	GetVerificationDetails(id uint) (dto.UserVerificationResponse, error)
 	GetVerificationsByUserID(userID uint) ([]dto.UserVerificationResponse, error)
+	SendEmailVerification(user models.User) error
+	VerifyEmail(token string) error
+	ResendEmailVerification(userID uint) error
+	RequestPasswordReset(req dto.ForgotPasswordRequest) error
+	SendPasswordReset(user models.User) error
+	ResetPassword(req dto.ResetPasswordRequest, actor dto.Actor) error
+	StartPhoneVerification(userID uint, req dto.PhoneNumberRequest) (*dto.PhoneVerificationResponse, error)
+	VerifyPhone(userID uint, req dto.PhoneCodeRequest) error
+	RemovePhoneNumber(userID uint) error
 }
 
 type UserVerificationService struct {
-	repo repository.IUserVerificationRepository
+	repo                repository.IUserVerificationRepository
+	userRepo            repository.IUserRepository
+	sessionRepo         repository.ISessionRepository
+	notificationService INotificationService
+	passwordService     IPasswordService
+	smsProvider         sms.Provider
+	auditService        IAuditService
+	tokenTTL            time.Duration
+	resetTokenTTL       time.Duration
+	phoneCodeTTL        time.Duration
+	resendCooldown      time.Duration
+	verifyURL           string
+	resetURL            string
 }
 
 // NewUserVerificationService Constructor function to initialize a new UserVerificationService with its dependencies.
-func NewUserVerificationService(repo repository.IUserVerificationRepository) IUserVerificationService {
+func NewUserVerificationService(repo repository.IUserVerificationRepository, userRepo repository.IUserRepository, sessionRepo repository.ISessionRepository, notificationService INotificationService, passwordService IPasswordService, smsProvider sms.Provider, auditService IAuditService) IUserVerificationService {
 	return &UserVerificationService{
-		repo: repo,
+		repo:                repo,
+		userRepo:            userRepo,
+		sessionRepo:         sessionRepo,
+		notificationService: notificationService,
+		passwordService:     passwordService,
+		smsProvider:         smsProvider,
+		auditService:        auditService,
+		tokenTTL:            config.GetDuration("EMAIL_VERIFICATION_TTL", 24*time.Hour),
+		resetTokenTTL:       config.GetDuration("PASSWORD_RESET_TTL", time.Hour),
+		phoneCodeTTL:        config.GetDuration("PHONE_VERIFICATION_TTL", 10*time.Minute),
+		resendCooldown:      config.GetDuration("EMAIL_VERIFICATION_RESEND_COOLDOWN", time.Minute),
+		verifyURL:           os.Getenv("EMAIL_VERIFICATION_URL"),
+		resetURL:            os.Getenv("PASSWORD_RESET_URL"),
 	}
 }
 
-// CreateVerification handles the logic to create a new user verification entry.
+// CreateVerification issues a new verification of the requested type for a user, replacing any pending one.
+// Email verifications are sent to the user, phone verification codes to their current phone number.
 func (s *UserVerificationService) CreateVerification(req dto.CreateUserVerificationRequest) (dto.UserVerificationResponse, error) {
-	verification := req.ToVerificationModel()
-	if err := s.repo.CreateVerification(&verification); err != nil {
+	user, err := s.userRepo.FindByID(req.UserID)
+	if err != nil {
 		return dto.UserVerificationResponse{}, err
 	}
-	return dto.FromVerificationModel(verification), nil
+	var verification *models.UserVerification
+	if req.VerificationType == models.VerificationTypePhone {
+		if user.PhoneNumber == "" {
+			return dto.UserVerificationResponse{}, ErrPhoneNumberMissing
+		}
+		verification, err = s.issuePhoneVerification(*user, user.PhoneNumber)
+	} else {
+		verification, err = s.issueVerification(*user, req.VerificationType)
+	}
+	if err != nil {
+		return dto.UserVerificationResponse{}, err
+	}
+	return dto.FromVerificationModel(*verification), nil
 }
 
 // UpdateVerificationStatus updates the status and verified time of an existing verification record.
+// Marking an email verification as verified also marks the user as verified, and a phone verification sets the
+// verified phone number of the user.
 func (s *UserVerificationService) UpdateVerificationStatus(id uint, status string, verifiedAt *time.Time) (dto.UserVerificationResponse, error) {
 	verification, err := s.repo.FindVerificationByID(id)
 	if err != nil {
 		return dto.UserVerificationResponse{}, err
 	}
 
+	if status == models.VerificationVerified && verification.VerificationStatus == models.VerificationPending {
+		at := time.Now()
+		if verifiedAt != nil && !verifiedAt.IsZero() {
+			at = *verifiedAt
+		}
+		if err := s.repo.CompleteVerification(verification, at); err != nil {
+			return dto.UserVerificationResponse{}, err
+		}
+		return dto.FromVerificationModel(*verification), nil
+	}
+
 	verification.VerificationStatus = status
 	if verifiedAt != nil && !verification.VerifiedAt.Equal(*verifiedAt) {
 		verification.VerifiedAt = *verifiedAt
@@ -73,3 +170,306 @@ func (s *UserVerificationService) GetVerificationsByUserID(userID uint) ([]dto.U
 	}
 	return responses, nil
 }
+
+// SendEmailVerification emails a new verification link to a user, invalidating the links sent before.
+func (s *UserVerificationService) SendEmailVerification(user models.User) error {
+	_, err := s.issueVerification(user, models.VerificationTypeEmail)
+	return err
+}
+
+// VerifyEmail consumes an email verification token and marks its user as verified. Tokens can be used once,
+// before they expire, and only the most recently sent one is valid.
+func (s *UserVerificationService) VerifyEmail(token string) error {
+	verification, err := s.repo.FindVerificationByToken(hashToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidVerificationToken
+		}
+		return err
+	}
+	if verification.VerificationType != models.VerificationTypeEmail {
+		return ErrInvalidVerificationToken // Tokens of other types are consumed by their own flow
+	}
+
+	switch verification.VerificationStatus {
+	case models.VerificationPending:
+	case models.VerificationVerified:
+		return ErrVerificationTokenUsed
+	default:
+		return ErrInvalidVerificationToken
+	}
+
+	now := time.Now()
+	if now.After(verification.ExpirationDate) {
+		verification.VerificationStatus = models.VerificationFailed
+		if err := s.repo.UpdateVerification(verification); err != nil {
+			return err
+		}
+		return ErrVerificationTokenExpired
+	}
+
+	if err := s.repo.CompleteVerification(verification, now); err != nil {
+		if errors.Is(err, repository.ErrVerificationNotPending) {
+			return ErrVerificationTokenUsed
+		}
+		return err
+	}
+	return nil
+}
+
+// ResendEmailVerification sends a new verification link to a user who is not verified yet, at most once per
+// cooldown period.
+func (s *UserVerificationService) ResendEmailVerification(userID uint) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.Verified {
+		return ErrAlreadyVerified
+	}
+
+	latest, err := s.repo.FindLatestVerification(userID, models.VerificationTypeEmail)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if latest != nil && time.Since(latest.CreatedAt) < s.resendCooldown {
+		return ErrVerificationResendTooSoon
+	}
+
+	return s.SendEmailVerification(*user)
+}
+
+// RequestPasswordReset emails a single-use password reset link to the user with the given email address.
+// Unknown addresses and repeated requests within the cooldown are ignored without error, so the response does
+// not reveal which addresses have an account.
+func (s *UserVerificationService) RequestPasswordReset(req dto.ForgotPasswordRequest) error {
+	user, err := s.userRepo.FindByEmail(req.Email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	latest, err := s.repo.FindLatestVerification(user.ID, models.VerificationTypePasswordReset)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if latest != nil && time.Since(latest.CreatedAt) < s.resendCooldown {
+		return nil
+	}
+
+	return s.SendPasswordReset(*user)
+}
+
+// SendPasswordReset emails a single-use password reset link to a user, replacing any link sent before.
+func (s *UserVerificationService) SendPasswordReset(user models.User) error {
+	_, err := s.issueVerification(user, models.VerificationTypePasswordReset)
+	return err
+}
+
+// ResetPassword sets a new password with a password reset token and signs the user out everywhere. The token
+// can be used once, before it expires. The actor is not signed in.
+func (s *UserVerificationService) ResetPassword(req dto.ResetPasswordRequest, actor dto.Actor) error {
+	verification, err := s.repo.FindVerificationByToken(hashToken(req.Token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	now := time.Now()
+	if verification.VerificationType != models.VerificationTypePasswordReset ||
+		verification.VerificationStatus != models.VerificationPending ||
+		now.After(verification.ExpirationDate) {
+		return ErrInvalidResetToken
+	}
+	user, err := s.userRepo.FindByID(verification.UserID)
+	if err != nil {
+		return err
+	}
+	if err := s.passwordService.Validate(*user, req.Password); err != nil {
+		return err
+	}
+
+	passwordHash, err := s.passwordService.Hash(req.Password)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.CompletePasswordReset(verification, passwordHash, now); err != nil {
+		if errors.Is(err, repository.ErrVerificationNotPending) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	if err := s.passwordService.Record(user.ID, passwordHash); err != nil {
+		return err
+	}
+	s.auditService.Record(actor, AuditUserPasswordReset, AuditTargetUser, user.ID, nil)
+	return s.sessionRepo.RevokeUserSessions(verification.UserID, 0, RevokedByPasswordReset, now)
+}
+
+// StartPhoneVerification sends a code by SMS to a phone number the user wants on their account, at most once
+// per cooldown period. The number replaces the current one once verified with VerifyPhone.
+func (s *UserVerificationService) StartPhoneVerification(userID uint, req dto.PhoneNumberRequest) (*dto.PhoneVerificationResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.PhoneVerified && user.PhoneNumber == req.PhoneNumber {
+		return nil, ErrPhoneAlreadyVerified
+	}
+	inUse, err := s.userRepo.PhoneNumberInUse(req.PhoneNumber, userID)
+	if err != nil {
+		return nil, err
+	}
+	if inUse {
+		return nil, ErrPhoneNumberTaken
+	}
+
+	latest, err := s.repo.FindLatestVerification(userID, models.VerificationTypePhone)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if latest != nil && time.Since(latest.CreatedAt) < s.resendCooldown {
+		return nil, ErrVerificationResendTooSoon
+	}
+
+	verification, err := s.issuePhoneVerification(*user, req.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.PhoneVerificationResponse{PhoneNumber: verification.Target, ExpiresAt: verification.ExpirationDate}, nil
+}
+
+// VerifyPhone checks the code of the latest phone verification of a user and sets the verified phone number.
+// Codes can be used once, before they expire. Every code entered is counted before it is compared, and the
+// verification is discarded after maxPhoneCodeAttempts attempts.
+func (s *UserVerificationService) VerifyPhone(userID uint, req dto.PhoneCodeRequest) error {
+	verification, err := s.repo.FindLatestVerification(userID, models.VerificationTypePhone)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidPhoneCode
+		}
+		return err
+	}
+	now := time.Now()
+	if verification.VerificationStatus != models.VerificationPending || now.After(verification.ExpirationDate) ||
+		verification.Attempts >= maxPhoneCodeAttempts {
+		return ErrInvalidPhoneCode
+	}
+	if err := s.repo.IncrementAttempts(verification.ID, maxPhoneCodeAttempts); err != nil {
+		if errors.Is(err, repository.ErrVerificationAttemptsExceeded) {
+			return ErrInvalidPhoneCode
+		}
+		return err
+	}
+	if subtle.ConstantTimeCompare([]byte(hashToken(req.Code)), []byte(verification.VerificationToken)) != 1 {
+		return ErrInvalidPhoneCode
+	}
+
+	// Another account may have verified the number since the code was sent
+	inUse, err := s.userRepo.PhoneNumberInUse(verification.Target, userID)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return ErrPhoneNumberTaken
+	}
+	if err := s.repo.CompleteVerification(verification, now); err != nil {
+		if errors.Is(err, repository.ErrVerificationNotPending) {
+			return ErrInvalidPhoneCode
+		}
+		// The number was verified by another account in the meantime
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrPhoneNumberTaken
+		}
+		return err
+	}
+	return nil
+}
+
+// RemovePhoneNumber removes the phone number of a user, who then has no verified phone.
+func (s *UserVerificationService) RemovePhoneNumber(userID uint) error {
+	if _, err := s.userRepo.FindByID(userID); err != nil {
+		return err
+	}
+	return s.userRepo.RemovePhoneNumber(userID)
+}
+
+// issuePhoneVerification stores a new pending phone verification with a generated code, of which only the hash
+// is kept, and sends the code by SMS to phoneNumber.
+func (s *UserVerificationService) issuePhoneVerification(user models.User, phoneNumber string) (*models.UserVerification, error) {
+	code, err := generateNumericCode(phoneCodeDigits)
+	if err != nil {
+		return nil, err
+	}
+	verification := models.UserVerification{
+		UserID:             user.ID,
+		VerificationType:   models.VerificationTypePhone,
+		VerificationStatus: models.VerificationPending,
+		VerificationToken:  hashToken(code),
+		Target:             phoneNumber,
+		ExpirationDate:     time.Now().Add(s.phoneCodeTTL),
+	}
+	if err := s.repo.ReplaceVerification(&verification); err != nil {
+		return nil, fmt.Errorf("failed to create verification: %w", err)
+	}
+
+	body := fmt.Sprintf("Your verification code is %s. It expires in %d minutes.", code, int(s.phoneCodeTTL.Minutes()))
+	if err := s.smsProvider.Send(phoneNumber, body); err != nil {
+		return nil, fmt.Errorf("failed to send phone verification code: %w", err)
+	}
+	return &verification, nil
+}
+
+// issueVerification stores a new pending verification with a generated token, of which only the hash is kept,
+// and emails the token for email verifications and password resets.
+func (s *UserVerificationService) issueVerification(user models.User, verificationType string) (*models.UserVerification, error) {
+	token, err := pkg.GenerateToken()
+	if err != nil {
+		return nil, err
+	}
+	ttl := s.tokenTTL
+	if verificationType == models.VerificationTypePasswordReset {
+		ttl = s.resetTokenTTL
+	}
+	verification := models.UserVerification{
+		UserID:             user.ID,
+		VerificationType:   verificationType,
+		VerificationStatus: models.VerificationPending,
+		VerificationToken:  hashToken(token),
+		ExpirationDate:     time.Now().Add(ttl),
+	}
+	if err := s.repo.ReplaceVerification(&verification); err != nil {
+		return nil, fmt.Errorf("failed to create verification: %w", err)
+	}
+
+	if err := s.sendVerificationEmail(user, verification, token); err != nil {
+		return nil, err
+	}
+	return &verification, nil
+}
+
+// sendVerificationEmail emails the link carrying token to the user. Verifications of other types are not sent.
+func (s *UserVerificationService) sendVerificationEmail(user models.User, verification models.UserVerification, token string) error {
+	expires := verification.ExpirationDate.Format(time.RFC1123)
+	var notificationType, content string
+	switch verification.VerificationType {
+	case models.VerificationTypeEmail:
+		notificationType = emailVerificationNotification
+		content = fmt.Sprintf("Hi %s, please confirm your email address %s by opening %s?token=%s. The link expires on %s.",
+			user.Username, user.Email, s.verifyURL, url.QueryEscape(token), expires)
+	case models.VerificationTypePasswordReset:
+		notificationType = passwordResetNotification
+		content = fmt.Sprintf("Hi %s, a password reset was requested for your account. Choose a new password at %s?token=%s before %s. If you did not ask for it, you can ignore this email.",
+			user.Username, s.resetURL, url.QueryEscape(token), expires)
+	default:
+		return nil
+	}
+
+	if err := s.notificationService.SendNotification(user.ID, notificationType, NotificationChannelEmail, content); err != nil {
+		return fmt.Errorf("failed to send %s email: %w", notificationType, err)
+	}
+	return nil
+}
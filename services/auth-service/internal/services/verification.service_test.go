@@ -0,0 +1,308 @@
+package services
+
+import (
+	"auth-service/internal/api/dto"
+	"auth-service/internal/models"
+	"auth-service/internal/password"
+	"auth-service/internal/repository"
+	"errors"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type fakeVerificationRepository struct {
+	repository.IUserVerificationRepository
+	verification *models.UserVerification
+	latest       *models.UserVerification
+	replaced     *models.UserVerification
+	completeErr  error
+	completed    bool
+	password     string // Hash set by CompletePasswordReset
+}
+
+func (r *fakeVerificationRepository) FindVerificationByToken(tokenHash string) (*models.UserVerification, error) {
+	if r.verification == nil || r.verification.VerificationToken != tokenHash {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.verification, nil
+}
+
+func (r *fakeVerificationRepository) FindLatestVerification(userID uint, verificationType string) (*models.UserVerification, error) {
+	if r.latest == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.latest, nil
+}
+
+func (r *fakeVerificationRepository) UpdateVerification(verification *models.UserVerification) error {
+	return nil
+}
+
+func (r *fakeVerificationRepository) ReplaceVerification(verification *models.UserVerification) error {
+	r.replaced = verification
+	return nil
+}
+
+func (r *fakeVerificationRepository) IncrementAttempts(id uint, maxAttempts int) error {
+	if r.latest.Attempts >= maxAttempts {
+		return repository.ErrVerificationAttemptsExceeded
+	}
+	r.latest.Attempts++
+	return nil
+}
+
+func (r *fakeVerificationRepository) CompleteVerification(verification *models.UserVerification, verifiedAt time.Time) error {
+	if r.completeErr != nil {
+		return r.completeErr
+	}
+	r.completed = true
+	return nil
+}
+
+func (r *fakeVerificationRepository) CompletePasswordReset(verification *models.UserVerification, passwordHash string, verifiedAt time.Time) error {
+	if err := r.CompleteVerification(verification, verifiedAt); err != nil {
+		return err
+	}
+	r.password = passwordHash
+	return nil
+}
+
+// fakeNotificationService records the content of the notifications sent, or fails to send them with err.
+type fakeNotificationService struct {
+	sent []string
+	err  error
+}
+
+func (s *fakeNotificationService) SendNotification(userID uint, notificationType, channel, content string) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.sent = append(s.sent, content)
+	return nil
+}
+
+func TestVerifyEmail(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        string
+		expires       time.Time
+		completeErr   error
+		want          error
+		wantCompleted bool
+	}{
+		{name: "pending token", status: models.VerificationPending, expires: time.Now().Add(time.Hour), wantCompleted: true},
+		{name: "used token", status: models.VerificationVerified, expires: time.Now().Add(time.Hour), want: ErrVerificationTokenUsed},
+		{name: "superseded token", status: models.VerificationSuperseded, expires: time.Now().Add(time.Hour), want: ErrInvalidVerificationToken},
+		{name: "expired token", status: models.VerificationPending, expires: time.Now().Add(-time.Minute), want: ErrVerificationTokenExpired},
+		{name: "used concurrently", status: models.VerificationPending, expires: time.Now().Add(time.Hour), completeErr: repository.ErrVerificationNotPending, want: ErrVerificationTokenUsed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeVerificationRepository{completeErr: tt.completeErr, verification: &models.UserVerification{
+				VerificationType:   models.VerificationTypeEmail,
+				VerificationStatus: tt.status,
+				VerificationToken:  hashToken("token"),
+				ExpirationDate:     tt.expires,
+			}}
+			service := &UserVerificationService{repo: repo}
+
+			if err := service.VerifyEmail("token"); !errors.Is(err, tt.want) {
+				t.Fatalf("VerifyEmail() error = %v, want %v", err, tt.want)
+			}
+			if repo.completed != tt.wantCompleted {
+				t.Errorf("verification completed = %v, want %v", repo.completed, tt.wantCompleted)
+			}
+		})
+	}
+}
+
+func TestVerifyEmailRejectsUnknownTokens(t *testing.T) {
+	service := &UserVerificationService{repo: &fakeVerificationRepository{}}
+
+	if err := service.VerifyEmail("token"); !errors.Is(err, ErrInvalidVerificationToken) {
+		t.Errorf("VerifyEmail() error = %v, want %v", err, ErrInvalidVerificationToken)
+	}
+}
+
+// emailedToken matches the token of the verification link in an email.
+var emailedToken = regexp.MustCompile(`verify\?token=([^.\s]+)`)
+
+func TestResendEmailVerification(t *testing.T) {
+	tests := []struct {
+		name     string
+		verified bool
+		latest   *models.UserVerification
+		want     error
+	}{
+		{name: "first email"},
+		{name: "after the cooldown", latest: &models.UserVerification{Model: gorm.Model{CreatedAt: time.Now().Add(-2 * time.Minute)}}},
+		{name: "during the cooldown", latest: &models.UserVerification{Model: gorm.Model{CreatedAt: time.Now().Add(-10 * time.Second)}}, want: ErrVerificationResendTooSoon},
+		{name: "already verified", verified: true, want: ErrAlreadyVerified},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeVerificationRepository{latest: tt.latest}
+			notifications := &fakeNotificationService{}
+			service := &UserVerificationService{
+				repo:                repo,
+				userRepo:            &fakeUserRepository{user: &models.User{Username: "ann", Verified: tt.verified}},
+				notificationService: notifications,
+				tokenTTL:            time.Hour,
+				resendCooldown:      time.Minute,
+				verifyURL:           "https://example.com/verify",
+			}
+
+			if err := service.ResendEmailVerification(5); !errors.Is(err, tt.want) {
+				t.Fatalf("ResendEmailVerification() error = %v, want %v", err, tt.want)
+			}
+			if tt.want != nil {
+				if len(notifications.sent) != 0 {
+					t.Errorf("sent %d emails, want none", len(notifications.sent))
+				}
+				return
+			}
+			if len(notifications.sent) != 1 {
+				t.Fatalf("sent %d emails, want 1", len(notifications.sent))
+			}
+			// Only the hash of the emailed token is stored
+			match := emailedToken.FindStringSubmatch(notifications.sent[0])
+			if match == nil {
+				t.Fatalf("email %q has no verification link", notifications.sent[0])
+			}
+			token, _ := url.QueryUnescape(match[1])
+			if repo.replaced.VerificationToken != hashToken(token) || repo.replaced.VerificationToken == token {
+				t.Errorf("stored token %q, want the hash of the emailed token %q", repo.replaced.VerificationToken, token)
+			}
+		})
+	}
+}
+
+func TestRequestPasswordReset(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		latest   *models.UserVerification
+		wantSent bool
+	}{
+		{name: "known address", email: "ann@example.com", wantSent: true},
+		{name: "unknown address", email: "bo@example.com"},
+		{name: "during the cooldown", email: "ann@example.com", latest: &models.UserVerification{Model: gorm.Model{CreatedAt: time.Now()}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeVerificationRepository{latest: tt.latest}
+			notifications := &fakeNotificationService{}
+			service := &UserVerificationService{
+				repo:                repo,
+				userRepo:            &fakeUserRepository{user: &models.User{Username: "ann", Email: "ann@example.com"}},
+				notificationService: notifications,
+				resetTokenTTL:       time.Hour,
+				resendCooldown:      time.Minute,
+			}
+
+			// Every request succeeds, so that the response does not tell which addresses have an account
+			if err := service.RequestPasswordReset(dto.ForgotPasswordRequest{Email: tt.email}); err != nil {
+				t.Fatalf("RequestPasswordReset() error = %v", err)
+			}
+			if sent := len(notifications.sent) == 1; sent != tt.wantSent {
+				t.Fatalf("reset email sent = %v, want %v", sent, tt.wantSent)
+			}
+			if tt.wantSent && repo.replaced.VerificationType != models.VerificationTypePasswordReset {
+				t.Errorf("issued a %s verification, want %s", repo.replaced.VerificationType, models.VerificationTypePasswordReset)
+			}
+		})
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	tests := []struct {
+		name             string
+		verificationType string
+		status           string
+		expires          time.Time
+		completeErr      error
+		want             error
+	}{
+		{name: "pending reset", verificationType: models.VerificationTypePasswordReset, status: models.VerificationPending, expires: time.Now().Add(time.Hour)},
+		{name: "email verification token", verificationType: models.VerificationTypeEmail, status: models.VerificationPending, expires: time.Now().Add(time.Hour), want: ErrInvalidResetToken},
+		{name: "used token", verificationType: models.VerificationTypePasswordReset, status: models.VerificationVerified, expires: time.Now().Add(time.Hour), want: ErrInvalidResetToken},
+		{name: "expired token", verificationType: models.VerificationTypePasswordReset, status: models.VerificationPending, expires: time.Now().Add(-time.Minute), want: ErrInvalidResetToken},
+		{name: "used concurrently", verificationType: models.VerificationTypePasswordReset, status: models.VerificationPending, expires: time.Now().Add(time.Hour), completeErr: repository.ErrVerificationNotPending, want: ErrInvalidResetToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeVerificationRepository{completeErr: tt.completeErr, verification: &models.UserVerification{
+				UserID:             5,
+				VerificationType:   tt.verificationType,
+				VerificationStatus: tt.status,
+				VerificationToken:  hashToken("token"),
+				ExpirationDate:     tt.expires,
+			}}
+			users := &fakeUserRepository{user: &models.User{Username: "ann"}}
+			sessions := &fakeSessionRepository{}
+			service := &UserVerificationService{repo: repo, userRepo: users, sessionRepo: sessions, passwordService: newTestPasswordService(users), auditService: &fakeAuditService{}}
+
+			err := service.ResetPassword(dto.ResetPasswordRequest{Token: "token", Password: "new password"}, dto.Actor{})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("ResetPassword() error = %v, want %v", err, tt.want)
+			}
+			if tt.want != nil {
+				if repo.password != "" || len(sessions.kept) != 0 {
+					t.Error("ResetPassword() changed the password or signed the user out with an invalid token")
+				}
+				return
+			}
+			if match, _, _ := password.Verify("new password", repo.password); !match {
+				t.Error("ResetPassword() did not store the hash of the new password")
+			}
+			if len(sessions.kept) != 1 || sessions.kept[0] != 0 {
+				t.Errorf("sessions kept = %v, want the user signed out everywhere", sessions.kept)
+			}
+		})
+	}
+}
+
+func TestVerifyPhone(t *testing.T) {
+	tests := []struct {
+		name          string
+		attempts      int
+		target        string
+		code          string
+		completeErr   error
+		want          error
+		wantAttempts  int
+		wantCompleted bool
+	}{
+		{name: "right code", target: "+4915100000001", code: "123456", wantAttempts: 1, wantCompleted: true},
+		{name: "wrong code", target: "+4915100000001", code: "654321", want: ErrInvalidPhoneCode, wantAttempts: 1},
+		{name: "right code on the last attempt", attempts: maxPhoneCodeAttempts - 1, target: "+4915100000001", code: "123456", wantAttempts: maxPhoneCodeAttempts, wantCompleted: true},
+		{name: "right code after the last attempt", attempts: maxPhoneCodeAttempts, target: "+4915100000001", code: "123456", want: ErrInvalidPhoneCode, wantAttempts: maxPhoneCodeAttempts},
+		{name: "number verified by another user since", target: "+4915100000002", code: "123456", want: ErrPhoneNumberTaken, wantAttempts: 1},
+		{name: "number verified by another user concurrently", target: "+4915100000001", code: "123456", completeErr: gorm.ErrDuplicatedKey, want: ErrPhoneNumberTaken, wantAttempts: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeVerificationRepository{completeErr: tt.completeErr, latest: &models.UserVerification{
+				UserID:             7,
+				VerificationType:   models.VerificationTypePhone,
+				VerificationStatus: models.VerificationPending,
+				VerificationToken:  hashToken("123456"),
+				Target:             tt.target,
+				ExpirationDate:     time.Now().Add(time.Minute),
+				Attempts:           tt.attempts,
+			}}
+			service := &UserVerificationService{repo: repo, userRepo: &fakeUserRepository{takenPhones: []string{"+4915100000002"}}}
+
+			if err := service.VerifyPhone(7, dto.PhoneCodeRequest{Code: tt.code}); !errors.Is(err, tt.want) {
+				t.Fatalf("VerifyPhone() error = %v, want %v", err, tt.want)
+			}
+			if repo.latest.Attempts != tt.wantAttempts || repo.completed != tt.wantCompleted {
+				t.Errorf("attempts = %d, verified = %v, want %d, %v", repo.latest.Attempts, repo.completed, tt.wantAttempts, tt.wantCompleted)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package sms
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Provider sends text messages to phone numbers in E.164 format.
+type Provider interface {
+	Send(to, body string) error
+}
+
+// Message is a text message sent through the Fake provider.
+type Message struct {
+	To     string
+	Body   string
+	SentAt time.Time
+}
+
+// Fake logs text messages instead of sending them, so codes can be read from the logs in local development.
+type Fake struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+// NewFake creates a new instance of Fake.
+func NewFake() *Fake {
+	return &Fake{}
+}
+
+// Send logs the message and keeps it in memory.
+func (f *Fake) Send(to, body string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.messages = append(f.messages, Message{To: to, Body: body, SentAt: time.Now()})
+	log.Printf("SMS to %s: %s", to, body)
+	return nil
+}
+
+// Messages returns the messages sent so far, oldest first.
+func (f *Fake) Messages() []Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Message(nil), f.messages...)
+}
+
+// twilioBaseURL is the base URL of the Twilio REST API.
+const twilioBaseURL = "https://api.twilio.com/2010-04-01"
+
+// Twilio sends text messages with the Twilio Messaging API.
+type Twilio struct {
+	restyClient *resty.Client
+	accountSID  string
+	from        string
+}
+
+// NewTwilio creates a new instance of Twilio sending messages from the number from.
+func NewTwilio(accountSID, authToken, from string) *Twilio {
+	return &Twilio{
+		restyClient: resty.New().SetTimeout(10*time.Second).SetBasicAuth(accountSID, authToken),
+		accountSID:  accountSID,
+		from:        from,
+	}
+}
+
+// Send queues the message with Twilio.
+func (t *Twilio) Send(to, body string) error {
+	resp, err := t.restyClient.R().
+		SetFormData(map[string]string{"To": to, "From": t.from, "Body": body}).
+		Post(fmt.Sprintf("%s/Accounts/%s/Messages.json", twilioBaseURL, t.accountSID))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusCreated {
+		return fmt.Errorf("twilio responded with status code: %d", resp.StatusCode())
+	}
+	return nil
+}
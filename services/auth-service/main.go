@@ -31,7 +31,11 @@ func main() {
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found, reading environment variables from system")
 	}
-	database := config.NewDatabase(&models.User{}, &models.LoginHistory{}, &models.UserVerification{})
+	database := config.NewDatabase(&models.User{}, &models.LoginHistory{}, &models.UserVerification{}, &models.Session{}, &models.RefreshToken{}, &models.SigningKey{},
+		&models.Role{}, &models.RolePermission{}, &models.UserRole{},
+		&models.MFAEnrollment{}, &models.RecoveryCode{}, &models.MFAChallenge{}, &models.MFAPolicy{}, &models.LoginCode{},
+		&models.OAuthClient{}, &models.OAuthConsent{}, &models.AuthorizationCode{}, &models.APIKey{}, &models.PasswordHistory{},
+		&models.AccountErasure{}, &models.AccountErasureStep{}, &models.DataExport{}, &models.AuditEvent{}, &models.LoginHistoryArchive{})
 	defer database.Close()
 
 	// Get the port number from the environment variable.
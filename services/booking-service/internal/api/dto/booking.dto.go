@@ -0,0 +1,82 @@
+package dto
+
+import (
+	"booking-service/internal/models"
+	"time"
+)
+
+// BookingPassengerResponse is a traveller of a booking and the seat held for them.
+type BookingPassengerResponse struct {
+	SeatID     uint       `json:"seatID"`
+	SeatNumber string     `json:"seatNumber"`
+	FullName   string     `json:"fullName"`
+	Fare       float64    `json:"fare"`
+	BoardedAt  *time.Time `json:"boardedAt"`
+	NoShow     bool       `json:"noShow"`
+}
+
+// BookingFeeResponse is a fee charged on a booking.
+type BookingFeeResponse struct {
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount"`
+}
+
+// BookingResponse is a booking with its passengers and fees.
+type BookingResponse struct {
+	ID                 uint                       `json:"id"`
+	UserID             uint                       `json:"userID"`
+	CreatedAt          time.Time                  `json:"createdAt"`
+	BusID              uint                       `json:"busID"`
+	RouteID            uint                       `json:"routeID"`
+	ScheduleID         uint                       `json:"scheduleID"`
+	DepartureTime      time.Time                  `json:"departureTime"`
+	Status             string                     `json:"status"`
+	TotalAmount        float64                    `json:"totalAmount"`
+	DiscountAmount     float64                    `json:"discountAmount"`
+	Currency           string                     `json:"currency"`
+	ExpiresAt          time.Time                  `json:"expiresAt"`
+	ConfirmedAt        *time.Time                 `json:"confirmedAt"`
+	CheckedInAt        *time.Time                 `json:"checkedInAt"`
+	CorporateAccountID *uint                      `json:"corporateAccountID,omitempty"`
+	ApprovalStatus     string                     `json:"approvalStatus,omitempty"`
+	Passengers         []BookingPassengerResponse `json:"passengers"`
+	Fees               []BookingFeeResponse       `json:"fees"`
+}
+
+// ToBookingResponse converts a booking with its passengers and fees to a BookingResponse.
+func ToBookingResponse(booking models.Booking) BookingResponse {
+	response := BookingResponse{
+		ID:                 booking.ID,
+		UserID:             booking.UserID,
+		CreatedAt:          booking.CreatedAt,
+		BusID:              booking.BusID,
+		RouteID:            booking.RouteID,
+		ScheduleID:         booking.ScheduleID,
+		DepartureTime:      booking.DepartureTime,
+		Status:             string(booking.Status),
+		TotalAmount:        booking.TotalAmount,
+		DiscountAmount:     booking.DiscountAmount,
+		Currency:           booking.Currency,
+		ExpiresAt:          booking.ExpiresAt,
+		ConfirmedAt:        booking.ConfirmedAt,
+		CheckedInAt:        booking.CheckedInAt,
+		CorporateAccountID: booking.CorporateAccountID,
+		ApprovalStatus:     string(booking.ApprovalStatus),
+		Passengers:         make([]BookingPassengerResponse, len(booking.Passengers)),
+		Fees:               make([]BookingFeeResponse, len(booking.Fees)),
+	}
+	for i, passenger := range booking.Passengers {
+		response.Passengers[i] = BookingPassengerResponse{
+			SeatID:     passenger.SeatID,
+			SeatNumber: passenger.SeatNumber,
+			FullName:   passenger.FullName,
+			Fare:       passenger.Fare,
+			BoardedAt:  passenger.BoardedAt,
+			NoShow:     passenger.NoShow,
+		}
+	}
+	for i, fee := range booking.Fees {
+		response.Fees[i] = BookingFeeResponse{Description: fee.Description, Amount: fee.Amount}
+	}
+	return response
+}
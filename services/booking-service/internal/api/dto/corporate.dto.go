@@ -0,0 +1,234 @@
+package dto
+
+import (
+	"booking-service/internal/models"
+	"time"
+)
+
+// CreateCorporateAccountRequest defines a new corporate account and its negotiated terms.
+type CreateCorporateAccountRequest struct {
+	Name                 string  `json:"name" binding:"required,max=255"`
+	BillingEmail         string  `json:"billingEmail" binding:"required,email"`
+	BillingAddress       string  `json:"billingAddress" binding:"omitempty"`
+	BillingRegion        string  `json:"billingRegion" binding:"omitempty,max=100"`
+	TaxID                string  `json:"taxID" binding:"omitempty,max=100"`
+	Currency             string  `json:"currency" binding:"omitempty,len=3"`
+	DiscountRate         float64 `json:"discountRate" binding:"gte=0,lte=100"`
+	MonthlySpendingLimit float64 `json:"monthlySpendingLimit" binding:"gte=0"`
+	ApprovalThreshold    float64 `json:"approvalThreshold" binding:"gte=0"`
+	RequireApproval      bool    `json:"requireApproval"`
+}
+
+// ToModel converts CreateCorporateAccountRequest to a CorporateAccount model.
+func (r *CreateCorporateAccountRequest) ToModel() models.CorporateAccount {
+	account := models.CorporateAccount{
+		Name:                 r.Name,
+		BillingEmail:         r.BillingEmail,
+		BillingAddress:       r.BillingAddress,
+		BillingRegion:        r.BillingRegion,
+		TaxID:                r.TaxID,
+		Currency:             r.Currency,
+		DiscountRate:         r.DiscountRate,
+		MonthlySpendingLimit: r.MonthlySpendingLimit,
+		ApprovalThreshold:    r.ApprovalThreshold,
+		RequireApproval:      r.RequireApproval,
+		Active:               true,
+	}
+	if account.Currency == "" {
+		account.Currency = "BDT"
+	}
+	return account
+}
+
+// UpdateCorporateAccountRequest defines a partial update of a corporate account.
+type UpdateCorporateAccountRequest struct {
+	BillingEmail         *string  `json:"billingEmail,omitempty" binding:"omitempty,email"`
+	BillingAddress       *string  `json:"billingAddress,omitempty"`
+	BillingRegion        *string  `json:"billingRegion,omitempty" binding:"omitempty,max=100"`
+	TaxID                *string  `json:"taxID,omitempty" binding:"omitempty,max=100"`
+	DiscountRate         *float64 `json:"discountRate,omitempty" binding:"omitempty,gte=0,lte=100"`
+	MonthlySpendingLimit *float64 `json:"monthlySpendingLimit,omitempty" binding:"omitempty,gte=0"`
+	ApprovalThreshold    *float64 `json:"approvalThreshold,omitempty" binding:"omitempty,gte=0"`
+	RequireApproval      *bool    `json:"requireApproval,omitempty"`
+	Active               *bool    `json:"active,omitempty"`
+}
+
+// ApplyTo copies the provided fields onto an existing CorporateAccount model.
+func (r *UpdateCorporateAccountRequest) ApplyTo(account *models.CorporateAccount) {
+	if r.BillingEmail != nil {
+		account.BillingEmail = *r.BillingEmail
+	}
+	if r.BillingAddress != nil {
+		account.BillingAddress = *r.BillingAddress
+	}
+	if r.BillingRegion != nil {
+		account.BillingRegion = *r.BillingRegion
+	}
+	if r.TaxID != nil {
+		account.TaxID = *r.TaxID
+	}
+	if r.DiscountRate != nil {
+		account.DiscountRate = *r.DiscountRate
+	}
+	if r.MonthlySpendingLimit != nil {
+		account.MonthlySpendingLimit = *r.MonthlySpendingLimit
+	}
+	if r.ApprovalThreshold != nil {
+		account.ApprovalThreshold = *r.ApprovalThreshold
+	}
+	if r.RequireApproval != nil {
+		account.RequireApproval = *r.RequireApproval
+	}
+	if r.Active != nil {
+		account.Active = *r.Active
+	}
+}
+
+// CorporateMemberResponse represents a user linked to a corporate account.
+type CorporateMemberResponse struct {
+	UserID uint   `json:"userID"`
+	Role   string `json:"role"`
+}
+
+// CorporateAccountResponse represents a corporate account and its members.
+type CorporateAccountResponse struct {
+	ID                   uint                      `json:"id"`
+	Name                 string                    `json:"name"`
+	BillingEmail         string                    `json:"billingEmail"`
+	BillingAddress       string                    `json:"billingAddress"`
+	BillingRegion        string                    `json:"billingRegion"`
+	TaxID                string                    `json:"taxID"`
+	Currency             string                    `json:"currency"`
+	DiscountRate         float64                   `json:"discountRate"`
+	MonthlySpendingLimit float64                   `json:"monthlySpendingLimit"`
+	ApprovalThreshold    float64                   `json:"approvalThreshold"`
+	RequireApproval      bool                      `json:"requireApproval"`
+	Active               bool                      `json:"active"`
+	Members              []CorporateMemberResponse `json:"members,omitempty"`
+}
+
+// FromCorporateAccountModel converts a CorporateAccount model to a CorporateAccountResponse.
+func FromCorporateAccountModel(account models.CorporateAccount) CorporateAccountResponse {
+	members := make([]CorporateMemberResponse, len(account.Members))
+	for i, member := range account.Members {
+		members[i] = CorporateMemberResponse{UserID: member.UserID, Role: string(member.Role)}
+	}
+	return CorporateAccountResponse{
+		ID:                   account.ID,
+		Name:                 account.Name,
+		BillingEmail:         account.BillingEmail,
+		BillingAddress:       account.BillingAddress,
+		BillingRegion:        account.BillingRegion,
+		TaxID:                account.TaxID,
+		Currency:             account.Currency,
+		DiscountRate:         account.DiscountRate,
+		MonthlySpendingLimit: account.MonthlySpendingLimit,
+		ApprovalThreshold:    account.ApprovalThreshold,
+		RequireApproval:      account.RequireApproval,
+		Active:               account.Active,
+		Members:              members,
+	}
+}
+
+// AddCorporateMemberRequest links a user from auth-service to a corporate account.
+type AddCorporateMemberRequest struct {
+	UserID uint   `json:"userID" binding:"required"`
+	Role   string `json:"role" binding:"required,oneof=traveller approver"`
+}
+
+// CorporateBookingResponse represents the corporate billing state of a booking.
+type CorporateBookingResponse struct {
+	BookingID          uint    `json:"bookingID"`
+	CorporateAccountID uint    `json:"corporateAccountID"`
+	Status             string  `json:"status"`
+	ApprovalStatus     string  `json:"approvalStatus"`
+	TotalAmount        float64 `json:"totalAmount"`
+	DiscountAmount     float64 `json:"discountAmount"`
+	NetAmount          float64 `json:"netAmount"`
+}
+
+// FromCorporateBookingModel converts a corporate Booking model to a CorporateBookingResponse.
+func FromCorporateBookingModel(booking models.Booking) CorporateBookingResponse {
+	response := CorporateBookingResponse{
+		BookingID:      booking.ID,
+		Status:         string(booking.Status),
+		ApprovalStatus: string(booking.ApprovalStatus),
+		TotalAmount:    booking.TotalAmount,
+		DiscountAmount: booking.DiscountAmount,
+		NetAmount:      booking.TotalAmount - booking.DiscountAmount,
+	}
+	if booking.CorporateAccountID != nil {
+		response.CorporateAccountID = *booking.CorporateAccountID
+	}
+	return response
+}
+
+// CorporateSpendingResponse reports the spend of a corporate account in a month against its limit.
+type CorporateSpendingResponse struct {
+	Period    string   `json:"period"`
+	Spent     float64  `json:"spent"`
+	Limit     float64  `json:"limit"`
+	Remaining *float64 `json:"remaining,omitempty"` // Omitted when the account has no limit
+}
+
+// GenerateStatementRequest selects the month to bill, formatted as YYYY-MM.
+type GenerateStatementRequest struct {
+	Period string `json:"period" binding:"required"`
+}
+
+// CorporateStatementLineResponse represents a booking billed on a statement.
+type CorporateStatementLineResponse struct {
+	BookingID      uint      `json:"bookingID"`
+	UserID         uint      `json:"userID"`
+	DepartureTime  time.Time `json:"departureTime"`
+	GrossAmount    float64   `json:"grossAmount"`
+	DiscountAmount float64   `json:"discountAmount"`
+	NetAmount      float64   `json:"netAmount"`
+}
+
+// CorporateStatementResponse represents a monthly corporate statement.
+type CorporateStatementResponse struct {
+	ID                 uint                             `json:"id"`
+	CorporateAccountID uint                             `json:"corporateAccountID"`
+	Number             string                           `json:"number"`
+	PeriodStart        time.Time                        `json:"periodStart"`
+	PeriodEnd          time.Time                        `json:"periodEnd"`
+	Currency           string                           `json:"currency"`
+	GrossTotal         float64                          `json:"grossTotal"`
+	DiscountTotal      float64                          `json:"discountTotal"`
+	AmountDue          float64                          `json:"amountDue"`
+	Status             string                           `json:"status"`
+	IssuedAt           time.Time                        `json:"issuedAt"`
+	PaidAt             *time.Time                       `json:"paidAt,omitempty"`
+	Lines              []CorporateStatementLineResponse `json:"lines,omitempty"`
+}
+
+// FromCorporateStatementModel converts a CorporateStatement model to a CorporateStatementResponse.
+func FromCorporateStatementModel(statement models.CorporateStatement) CorporateStatementResponse {
+	lines := make([]CorporateStatementLineResponse, len(statement.Lines))
+	for i, line := range statement.Lines {
+		lines[i] = CorporateStatementLineResponse{
+			BookingID:      line.BookingID,
+			UserID:         line.UserID,
+			DepartureTime:  line.DepartureTime,
+			GrossAmount:    line.GrossAmount,
+			DiscountAmount: line.DiscountAmount,
+			NetAmount:      line.NetAmount,
+		}
+	}
+	return CorporateStatementResponse{
+		ID:                 statement.ID,
+		CorporateAccountID: statement.CorporateAccountID,
+		Number:             statement.Number,
+		PeriodStart:        statement.PeriodStart,
+		PeriodEnd:          statement.PeriodEnd,
+		Currency:           statement.Currency,
+		GrossTotal:         statement.GrossTotal,
+		DiscountTotal:      statement.DiscountTotal,
+		AmountDue:          statement.AmountDue,
+		Status:             string(statement.Status),
+		IssuedAt:           statement.IssuedAt,
+		PaidAt:             statement.PaidAt,
+		Lines:              lines,
+	}
+}
@@ -0,0 +1,116 @@
+package dto
+
+import (
+	"booking-service/internal/models"
+	"booking-service/internal/repository"
+	"time"
+)
+
+// BookingPassengerExport is a traveller of an exported booking.
+type BookingPassengerExport struct {
+	SeatNumber string     `json:"seatNumber"`
+	FullName   string     `json:"fullName"`
+	Fare       float64    `json:"fare"`
+	BoardedAt  *time.Time `json:"boardedAt"`
+	NoShow     bool       `json:"noShow"`
+}
+
+// BookingFeeExport is a fee charged on an exported booking.
+type BookingFeeExport struct {
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount"`
+}
+
+// BookingExport is a booking of the user, as included in the export of their personal data.
+type BookingExport struct {
+	ID                 uint                     `json:"id"`
+	CreatedAt          time.Time                `json:"createdAt"`
+	BusID              uint                     `json:"busID"`
+	RouteID            uint                     `json:"routeID"`
+	DepartureTime      time.Time                `json:"departureTime"`
+	Status             string                   `json:"status"`
+	TotalAmount        float64                  `json:"totalAmount"`
+	DiscountAmount     float64                  `json:"discountAmount"`
+	Currency           string                   `json:"currency"`
+	ConfirmedAt        *time.Time               `json:"confirmedAt"`
+	CheckedInAt        *time.Time               `json:"checkedInAt"`
+	CorporateAccountID *uint                    `json:"corporateAccountID,omitempty"`
+	Passengers         []BookingPassengerExport `json:"passengers"`
+	Fees               []BookingFeeExport       `json:"fees"`
+}
+
+// CorporateMembershipExport is the corporate account the user travels for.
+type CorporateMembershipExport struct {
+	CorporateAccountID uint      `json:"corporateAccountID"`
+	Role               string    `json:"role"`
+	Since              time.Time `json:"since"`
+}
+
+// UserDataExportResponse holds everything booking-service keeps about a user, for the export of their
+// personal data.
+type UserDataExportResponse struct {
+	Bookings            []BookingExport            `json:"bookings"`
+	Invoices            []InvoiceResponse          `json:"invoices"`
+	Reviews             []ReviewResponse           `json:"reviews"`
+	CorporateMembership *CorporateMembershipExport `json:"corporateMembership"` // Nil when the user does not travel for a corporate account
+}
+
+// FromUserData converts the data booking-service holds about a user to a UserDataExportResponse.
+func FromUserData(data repository.UserData) UserDataExportResponse {
+	response := UserDataExportResponse{
+		Bookings: make([]BookingExport, len(data.Bookings)),
+		Invoices: make([]InvoiceResponse, len(data.Invoices)),
+		Reviews:  make([]ReviewResponse, len(data.Reviews)),
+	}
+	for i, booking := range data.Bookings {
+		response.Bookings[i] = fromBookingModel(booking)
+	}
+	for i, invoice := range data.Invoices {
+		response.Invoices[i] = FromInvoiceModel(invoice)
+	}
+	for i, review := range data.Reviews {
+		// Users see the moderation of their own reviews
+		response.Reviews[i] = FromReviewModelForModeration(review)
+	}
+	if data.Membership != nil {
+		response.CorporateMembership = &CorporateMembershipExport{
+			CorporateAccountID: data.Membership.CorporateAccountID,
+			Role:               string(data.Membership.Role),
+			Since:              data.Membership.CreatedAt,
+		}
+	}
+	return response
+}
+
+func fromBookingModel(booking models.Booking) BookingExport {
+	passengers := make([]BookingPassengerExport, len(booking.Passengers))
+	for i, passenger := range booking.Passengers {
+		passengers[i] = BookingPassengerExport{
+			SeatNumber: passenger.SeatNumber,
+			FullName:   passenger.FullName,
+			Fare:       passenger.Fare,
+			BoardedAt:  passenger.BoardedAt,
+			NoShow:     passenger.NoShow,
+		}
+	}
+	fees := make([]BookingFeeExport, len(booking.Fees))
+	for i, fee := range booking.Fees {
+		fees[i] = BookingFeeExport{Description: fee.Description, Amount: fee.Amount}
+	}
+	return BookingExport{
+		ID:                 booking.ID,
+		CreatedAt:          booking.CreatedAt,
+		BusID:              booking.BusID,
+		RouteID:            booking.RouteID,
+		DepartureTime:      booking.DepartureTime,
+		Status:             string(booking.Status),
+		TotalAmount:        booking.TotalAmount,
+		DiscountAmount:     booking.DiscountAmount,
+		Currency:           booking.Currency,
+		ConfirmedAt:        booking.ConfirmedAt,
+		CheckedInAt:        booking.CheckedInAt,
+		CorporateAccountID: booking.CorporateAccountID,
+		Passengers:         passengers,
+		Fees:               fees,
+	}
+}
@@ -0,0 +1,144 @@
+package dto
+
+import (
+	"booking-service/internal/models"
+	"time"
+)
+
+// CreateInvoiceRequest holds the billing details printed on a new invoice.
+type CreateInvoiceRequest struct {
+	BillingName    string `json:"billingName" binding:"required,max=255"`
+	BillingAddress string `json:"billingAddress" binding:"omitempty"`
+	BillingRegion  string `json:"billingRegion" binding:"omitempty,max=100"`
+	BillingTaxID   string `json:"billingTaxID" binding:"omitempty,max=100"`
+}
+
+// CreateCreditNoteRequest describes a refund to be documented by a credit note.
+// When LineIDs is empty every line of the invoice that was not credited yet is reversed.
+type CreateCreditNoteRequest struct {
+	Reason  string `json:"reason" binding:"required"`
+	LineIDs []uint `json:"lineIDs" binding:"omitempty"`
+}
+
+// InvoiceLineResponse represents a single invoice line.
+type InvoiceLineResponse struct {
+	ID             uint    `json:"id"`
+	Kind           string  `json:"kind"`
+	Description    string  `json:"description"`
+	PassengerID    *uint   `json:"passengerID,omitempty"`
+	CreditedLineID *uint   `json:"creditedLineID,omitempty"`
+	Quantity       int     `json:"quantity"`
+	UnitAmount     float64 `json:"unitAmount"`
+	NetAmount      float64 `json:"netAmount"`
+	TaxName        string  `json:"taxName"`
+	TaxRate        float64 `json:"taxRate"`
+	TaxAmount      float64 `json:"taxAmount"`
+	GrossAmount    float64 `json:"grossAmount"`
+}
+
+// InvoiceResponse represents an invoice or credit note.
+type InvoiceResponse struct {
+	ID                uint                  `json:"id"`
+	Number            string                `json:"number"`
+	Type              string                `json:"type"`
+	BookingID         uint                  `json:"bookingID"`
+	UserID            uint                  `json:"userID"`
+	OriginalInvoiceID *uint                 `json:"originalInvoiceID,omitempty"`
+	Reason            string                `json:"reason,omitempty"`
+	IssuedAt          time.Time             `json:"issuedAt"`
+	BillingName       string                `json:"billingName"`
+	BillingAddress    string                `json:"billingAddress"`
+	BillingRegion     string                `json:"billingRegion"`
+	BillingTaxID      string                `json:"billingTaxID"`
+	Currency          string                `json:"currency"`
+	Subtotal          float64               `json:"subtotal"`
+	TaxTotal          float64               `json:"taxTotal"`
+	Total             float64               `json:"total"`
+	Lines             []InvoiceLineResponse `json:"lines"`
+}
+
+// FromInvoiceModel converts an Invoice model to an InvoiceResponse.
+func FromInvoiceModel(invoice models.Invoice) InvoiceResponse {
+	lines := make([]InvoiceLineResponse, len(invoice.Lines))
+	for i, line := range invoice.Lines {
+		lines[i] = InvoiceLineResponse{
+			ID:             line.ID,
+			Kind:           string(line.Kind),
+			Description:    line.Description,
+			PassengerID:    line.PassengerID,
+			CreditedLineID: line.CreditedLineID,
+			Quantity:       line.Quantity,
+			UnitAmount:     line.UnitAmount,
+			NetAmount:      line.NetAmount,
+			TaxName:        line.TaxName,
+			TaxRate:        line.TaxRate,
+			TaxAmount:      line.TaxAmount,
+			GrossAmount:    line.GrossAmount,
+		}
+	}
+	return InvoiceResponse{
+		ID:                invoice.ID,
+		Number:            invoice.Number,
+		Type:              string(invoice.Type),
+		BookingID:         invoice.BookingID,
+		UserID:            invoice.UserID,
+		OriginalInvoiceID: invoice.OriginalInvoiceID,
+		Reason:            invoice.Reason,
+		IssuedAt:          invoice.IssuedAt,
+		BillingName:       invoice.BillingName,
+		BillingAddress:    invoice.BillingAddress,
+		BillingRegion:     invoice.BillingRegion,
+		BillingTaxID:      invoice.BillingTaxID,
+		Currency:          invoice.Currency,
+		Subtotal:          invoice.Subtotal,
+		TaxTotal:          invoice.TaxTotal,
+		Total:             invoice.Total,
+		Lines:             lines,
+	}
+}
+
+// CreateTaxRateRequest defines a new tax rate. Leave RouteID and Region empty for the default rate.
+type CreateTaxRateRequest struct {
+	Name      string     `json:"name" binding:"required,max=100"`
+	Rate      float64    `json:"rate" binding:"gte=0,lte=100"`
+	RouteID   *uint      `json:"routeID" binding:"omitempty"`
+	Region    string     `json:"region" binding:"omitempty,max=100"`
+	ValidFrom time.Time  `json:"validFrom" binding:"required"`
+	ValidTo   *time.Time `json:"validTo" binding:"omitempty"`
+}
+
+// ToModel converts CreateTaxRateRequest to a TaxRate model.
+func (r *CreateTaxRateRequest) ToModel() models.TaxRate {
+	return models.TaxRate{
+		Name:      r.Name,
+		Rate:      r.Rate,
+		RouteID:   r.RouteID,
+		Region:    r.Region,
+		ValidFrom: r.ValidFrom,
+		ValidTo:   r.ValidTo,
+	}
+}
+
+// TaxRateResponse represents a configured tax rate.
+type TaxRateResponse struct {
+	ID        uint       `json:"id"`
+	Name      string     `json:"name"`
+	Rate      float64    `json:"rate"`
+	RouteID   *uint      `json:"routeID,omitempty"`
+	Region    string     `json:"region,omitempty"`
+	ValidFrom time.Time  `json:"validFrom"`
+	ValidTo   *time.Time `json:"validTo,omitempty"`
+}
+
+// FromTaxRateModel converts a TaxRate model to a TaxRateResponse.
+func FromTaxRateModel(rate models.TaxRate) TaxRateResponse {
+	return TaxRateResponse{
+		ID:        rate.ID,
+		Name:      rate.Name,
+		Rate:      rate.Rate,
+		RouteID:   rate.RouteID,
+		Region:    rate.Region,
+		ValidFrom: rate.ValidFrom,
+		ValidTo:   rate.ValidTo,
+	}
+}
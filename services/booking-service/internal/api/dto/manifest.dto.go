@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"booking-service/internal/models"
+	"time"
+)
+
+// ManifestPassenger is a traveller expected on a departure.
+type ManifestPassenger struct {
+	BookingID     uint       `json:"bookingID"`
+	BookingStatus string     `json:"bookingStatus"`
+	SeatID        uint       `json:"seatID"`
+	SeatNumber    string     `json:"seatNumber"`
+	FullName      string     `json:"fullName"`
+	BoardedAt     *time.Time `json:"boardedAt"`
+}
+
+// ManifestResponse lists the passengers of a departure of a bus.
+type ManifestResponse struct {
+	BusID         uint                `json:"busID"`
+	DepartureTime time.Time           `json:"departureTime"`
+	Passengers    []ManifestPassenger `json:"passengers"`
+}
+
+// ToManifestResponse converts the bookings of a departure to a ManifestResponse.
+func ToManifestResponse(busID uint, departureTime time.Time, bookings []models.Booking) ManifestResponse {
+	response := ManifestResponse{BusID: busID, DepartureTime: departureTime, Passengers: []ManifestPassenger{}}
+	for _, booking := range bookings {
+		for _, passenger := range booking.Passengers {
+			response.Passengers = append(response.Passengers, ManifestPassenger{
+				BookingID:     booking.ID,
+				BookingStatus: string(booking.Status),
+				SeatID:        passenger.SeatID,
+				SeatNumber:    passenger.SeatNumber,
+				FullName:      passenger.FullName,
+				BoardedAt:     passenger.BoardedAt,
+			})
+		}
+	}
+	return response
+}
@@ -0,0 +1,104 @@
+package dto
+
+import (
+	"booking-service/internal/models"
+	"booking-service/internal/repository"
+	"math"
+	"time"
+)
+
+// CreateReviewRequest rates a trip after travelling on it.
+type CreateReviewRequest struct {
+	UserID             uint   `json:"-"` // Set from the authenticated caller
+	TripRating         int    `json:"tripRating" binding:"required,min=1,max=5"`
+	DriverRating       int    `json:"driverRating" binding:"required,min=1,max=5"`
+	BusConditionRating int    `json:"busConditionRating" binding:"required,min=1,max=5"`
+	Comment            string `json:"comment" binding:"omitempty,max=2000"`
+}
+
+// ModerateReviewRequest publishes or hides a review.
+type ModerateReviewRequest struct {
+	Status      string `json:"status" binding:"required,oneof=published hidden"`
+	ModeratorID uint   `json:"-"` // Set from the authenticated caller
+	Reason      string `json:"reason" binding:"omitempty,max=1000"`
+}
+
+// ReviewResponse represents a review. Moderation details are only filled in for admins.
+type ReviewResponse struct {
+	ID                 uint       `json:"id"`
+	BookingID          uint       `json:"bookingID"`
+	UserID             uint       `json:"userID"`
+	BusID              uint       `json:"busID"`
+	BusCode            string     `json:"busCode"`
+	RouteID            uint       `json:"routeID"`
+	TripRating         int        `json:"tripRating"`
+	DriverRating       int        `json:"driverRating"`
+	BusConditionRating int        `json:"busConditionRating"`
+	Comment            string     `json:"comment"`
+	Status             string     `json:"status,omitempty"`
+	ModeratedBy        *uint      `json:"moderatedBy,omitempty"`
+	ModeratedAt        *time.Time `json:"moderatedAt,omitempty"`
+	ModerationReason   string     `json:"moderationReason,omitempty"`
+	CreatedAt          time.Time  `json:"createdAt"`
+}
+
+// FromReviewModel converts a Review model to a public ReviewResponse.
+func FromReviewModel(review models.Review) ReviewResponse {
+	return ReviewResponse{
+		ID:                 review.ID,
+		BookingID:          review.BookingID,
+		UserID:             review.UserID,
+		BusID:              review.BusID,
+		BusCode:            review.BusCode,
+		RouteID:            review.RouteID,
+		TripRating:         review.TripRating,
+		DriverRating:       review.DriverRating,
+		BusConditionRating: review.BusConditionRating,
+		Comment:            review.Comment,
+		CreatedAt:          review.CreatedAt,
+	}
+}
+
+// FromReviewModelForModeration converts a Review model to a ReviewResponse including its moderation state.
+func FromReviewModelForModeration(review models.Review) ReviewResponse {
+	response := FromReviewModel(review)
+	response.Status = string(review.Status)
+	response.ModeratedBy = review.ModeratedBy
+	response.ModeratedAt = review.ModeratedAt
+	response.ModerationReason = review.ModerationReason
+	return response
+}
+
+// ReviewListResponse is a page of reviews with the total number of matches.
+type ReviewListResponse struct {
+	Reviews []ReviewResponse `json:"reviews"`
+	Total   int64            `json:"total"`
+}
+
+// RatingSummaryResponse is the aggregated rating of a bus or a route.
+type RatingSummaryResponse struct {
+	BusCode             string  `json:"busCode,omitempty"` // Set on the ratings of a bus
+	ID                  uint    `json:"id,omitempty"`      // Route ID, set on the ratings of a route
+	ReviewCount         int64   `json:"reviewCount"`
+	AverageRating       float64 `json:"averageRating"`
+	AverageTrip         float64 `json:"averageTrip"`
+	AverageDriver       float64 `json:"averageDriver"`
+	AverageBusCondition float64 `json:"averageBusCondition"`
+}
+
+// FromRatingAggregate converts a RatingAggregate to a RatingSummaryResponse rounded to two decimals.
+func FromRatingAggregate(aggregate repository.RatingAggregate) RatingSummaryResponse {
+	return RatingSummaryResponse{
+		BusCode:             aggregate.BusCode,
+		ID:                  aggregate.RouteID,
+		ReviewCount:         aggregate.ReviewCount,
+		AverageRating:       roundRating(aggregate.AverageOverall),
+		AverageTrip:         roundRating(aggregate.AverageTrip),
+		AverageDriver:       roundRating(aggregate.AverageDriver),
+		AverageBusCondition: roundRating(aggregate.AverageBusCondition),
+	}
+}
+
+func roundRating(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"booking-service/internal/services"
+	"booking-service/pkg"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BookingHandler struct {
+	bookingService services.IBookingService
+}
+
+func NewBookingHandler(bookingService services.IBookingService) *BookingHandler {
+	return &BookingHandler{bookingService: bookingService}
+}
+
+// GetBooking handles GET /bookings/{bookingID}
+// @Summary Get booking
+// @Description Retrieves a booking with its passengers and fees. Visible to the user who made it and to support agents allowed to read bookings.
+// @Tags bookings
+// @Produce json
+// @Param bookingID path int true "Booking ID"
+// @Success 200 {object} pkg.APIResponse "Booking fetched successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid booking ID"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 403 {object} pkg.APIResponse "Booking belongs to another user"
+// @Failure 404 {object} pkg.APIResponse "Booking not found"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /bookings/{bookingID} [get]
+func (h *BookingHandler) GetBooking(c *gin.Context) {
+	bookingID, err := strconv.ParseUint(c.Param("bookingID"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid booking ID: %v", err))
+		return
+	}
+
+	response, err := h.bookingService.GetBooking(uint(bookingID))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrBookingNotFound) {
+			status = http.StatusNotFound
+		}
+		pkg.RespondWithError(c, status, err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "Booking fetched successfully")
+}
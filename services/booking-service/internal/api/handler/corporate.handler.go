@@ -0,0 +1,468 @@
+package handler
+
+import (
+	"booking-service/internal/api/dto"
+	"booking-service/internal/services"
+	"booking-service/pkg"
+	"errors"
+	"fmt"
+	"net/http"
+	"shared/authz"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CorporateHandler struct {
+	corporateService services.ICorporateService
+}
+
+func NewCorporateHandler(corporateService services.ICorporateService) *CorporateHandler {
+	return &CorporateHandler{corporateService: corporateService}
+}
+
+// CreateAccount handles POST /corporate-accounts
+// @Summary Create corporate account
+// @Description Registers a company with its negotiated discount, monthly spending limit and approval policy.
+// @Tags corporate
+// @Accept json
+// @Produce json
+// @Param account body dto.CreateCorporateAccountRequest true "Create Corporate Account Request"
+// @Success 201 {object} pkg.APIResponse "Corporate account created successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid request data"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /corporate-accounts [post]
+func (h *CorporateHandler) CreateAccount(c *gin.Context) {
+	var req dto.CreateCorporateAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid request data: %v", err))
+		return
+	}
+
+	response, err := h.corporateService.CreateAccount(req)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusCreated, response, "Corporate account created successfully")
+}
+
+// ListAccounts handles GET /corporate-accounts
+// @Summary List corporate accounts
+// @Description Retrieves all corporate accounts.
+// @Tags corporate
+// @Produce json
+// @Success 200 {object} pkg.APIResponse "Corporate accounts fetched successfully"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /corporate-accounts [get]
+func (h *CorporateHandler) ListAccounts(c *gin.Context) {
+	responses, err := h.corporateService.ListAccounts()
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusOK, responses, "Corporate accounts fetched successfully")
+}
+
+// GetAccount handles GET /corporate-accounts/{id}
+// @Summary Get corporate account
+// @Description Retrieves a corporate account with its members.
+// @Tags corporate
+// @Produce json
+// @Param id path int true "Corporate Account ID"
+// @Success 200 {object} pkg.APIResponse "Corporate account fetched successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid corporate account ID"
+// @Failure 404 {object} pkg.APIResponse "Corporate account not found"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /corporate-accounts/{id} [get]
+func (h *CorporateHandler) GetAccount(c *gin.Context) {
+	id, ok := parseCorporateAccountID(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.corporateService.GetAccount(id)
+	if err != nil {
+		pkg.RespondWithError(c, corporateErrorStatus(err), err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "Corporate account fetched successfully")
+}
+
+// UpdateAccount handles PUT /corporate-accounts/{id}
+// @Summary Update corporate account
+// @Description Changes billing details or negotiated terms. New terms apply to bookings charged afterwards.
+// @Tags corporate
+// @Accept json
+// @Produce json
+// @Param id path int true "Corporate Account ID"
+// @Param account body dto.UpdateCorporateAccountRequest true "Update Corporate Account Request"
+// @Success 200 {object} pkg.APIResponse "Corporate account updated successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid request data"
+// @Failure 404 {object} pkg.APIResponse "Corporate account not found"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /corporate-accounts/{id} [put]
+func (h *CorporateHandler) UpdateAccount(c *gin.Context) {
+	id, ok := parseCorporateAccountID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateCorporateAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid request data: %v", err))
+		return
+	}
+
+	response, err := h.corporateService.UpdateAccount(id, req)
+	if err != nil {
+		pkg.RespondWithError(c, corporateErrorStatus(err), err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "Corporate account updated successfully")
+}
+
+// AddMember handles POST /corporate-accounts/{id}/members
+// @Summary Add corporate member
+// @Description Links a user to the corporate account as a traveller or an approver.
+// @Tags corporate
+// @Accept json
+// @Produce json
+// @Param id path int true "Corporate Account ID"
+// @Param member body dto.AddCorporateMemberRequest true "Add Corporate Member Request"
+// @Success 201 {object} pkg.APIResponse "Member added successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid request data"
+// @Failure 404 {object} pkg.APIResponse "Corporate account not found"
+// @Failure 409 {object} pkg.APIResponse "User already belongs to a corporate account"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /corporate-accounts/{id}/members [post]
+func (h *CorporateHandler) AddMember(c *gin.Context) {
+	id, ok := parseCorporateAccountID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.AddCorporateMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid request data: %v", err))
+		return
+	}
+
+	response, err := h.corporateService.AddMember(id, req)
+	if err != nil {
+		pkg.RespondWithError(c, corporateErrorStatus(err), err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusCreated, response, "Member added successfully")
+}
+
+// RemoveMember handles DELETE /corporate-accounts/{id}/members/{userID}
+// @Summary Remove corporate member
+// @Description Unlinks a user from the corporate account. Bookings already charged stay on the account.
+// @Tags corporate
+// @Produce json
+// @Param id path int true "Corporate Account ID"
+// @Param userID path int true "User ID"
+// @Success 200 {object} pkg.APIResponse "Member removed successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid ID"
+// @Failure 404 {object} pkg.APIResponse "Member not found"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /corporate-accounts/{id}/members/{userID} [delete]
+func (h *CorporateHandler) RemoveMember(c *gin.Context) {
+	id, ok := parseCorporateAccountID(c)
+	if !ok {
+		return
+	}
+	userID, err := strconv.ParseUint(c.Param("userID"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid user ID: %v", err))
+		return
+	}
+
+	if err := h.corporateService.RemoveMember(id, uint(userID)); err != nil {
+		pkg.RespondWithError(c, corporateErrorStatus(err), err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusOK, nil, "Member removed successfully")
+}
+
+// ChargeBooking handles POST /corporate-accounts/{id}/bookings/{bookingID}
+// @Summary Charge booking to corporate account
+// @Description Bills a pending booking of a member to the account with the negotiated discount. The booking is
+// @Description confirmed, or held for approval when the account requires it.
+// @Tags corporate
+// @Produce json
+// @Param id path int true "Corporate Account ID"
+// @Param bookingID path int true "Booking ID"
+// @Success 200 {object} pkg.APIResponse "Booking charged successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid ID"
+// @Failure 403 {object} pkg.APIResponse "Booking owner is not a member"
+// @Failure 404 {object} pkg.APIResponse "Corporate account or booking not found"
+// @Failure 409 {object} pkg.APIResponse "Booking cannot be charged or spending limit exceeded"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /corporate-accounts/{id}/bookings/{bookingID} [post]
+func (h *CorporateHandler) ChargeBooking(c *gin.Context) {
+	id, bookingID, ok := parseCorporateBookingIDs(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.corporateService.ChargeBooking(id, bookingID)
+	if err != nil {
+		pkg.RespondWithError(c, corporateErrorStatus(err), err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "Booking charged successfully")
+}
+
+// ApproveBooking handles POST /corporate-accounts/{id}/bookings/{bookingID}/approve
+// @Summary Approve corporate booking
+// @Description Confirms a corporate booking waiting for approval. The caller must be an approver of the account.
+// @Tags corporate
+// @Produce json
+// @Param id path int true "Corporate Account ID"
+// @Param bookingID path int true "Booking ID"
+// @Success 200 {object} pkg.APIResponse "Booking approved successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid ID"
+// @Failure 403 {object} pkg.APIResponse "User may not approve this booking"
+// @Failure 404 {object} pkg.APIResponse "Booking not found"
+// @Failure 409 {object} pkg.APIResponse "Booking is not waiting for approval"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /corporate-accounts/{id}/bookings/{bookingID}/approve [post]
+func (h *CorporateHandler) ApproveBooking(c *gin.Context) {
+	h.decide(c, h.corporateService.ApproveBooking, "Booking approved successfully")
+}
+
+// RejectBooking handles POST /corporate-accounts/{id}/bookings/{bookingID}/reject
+// @Summary Reject corporate booking
+// @Description Cancels a corporate booking waiting for approval and releases its seats. The caller must be an
+// @Description approver of the account.
+// @Tags corporate
+// @Produce json
+// @Param id path int true "Corporate Account ID"
+// @Param bookingID path int true "Booking ID"
+// @Success 200 {object} pkg.APIResponse "Booking rejected successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid ID"
+// @Failure 403 {object} pkg.APIResponse "User may not reject this booking"
+// @Failure 404 {object} pkg.APIResponse "Booking not found"
+// @Failure 409 {object} pkg.APIResponse "Booking is not waiting for approval"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /corporate-accounts/{id}/bookings/{bookingID}/reject [post]
+func (h *CorporateHandler) RejectBooking(c *gin.Context) {
+	h.decide(c, h.corporateService.RejectBooking, "Booking rejected successfully")
+}
+
+func (h *CorporateHandler) decide(c *gin.Context, decision func(accountID, bookingID, approverUserID uint) (*dto.CorporateBookingResponse, error), message string) {
+	id, bookingID, ok := parseCorporateBookingIDs(c)
+	if !ok {
+		return
+	}
+
+	principal, _ := authz.CurrentPrincipal(c)
+	response, err := decision(id, bookingID, principal.UserID)
+	if err != nil {
+		pkg.RespondWithError(c, corporateErrorStatus(err), err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusOK, response, message)
+}
+
+// GetSpending handles GET /corporate-accounts/{id}/spending
+// @Summary Get corporate spending
+// @Description Reports the spend of the account in a month against its monthly limit.
+// @Tags corporate
+// @Produce json
+// @Param id path int true "Corporate Account ID"
+// @Param month query string false "Month as YYYY-MM, defaults to the current month"
+// @Success 200 {object} pkg.APIResponse "Spending fetched successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid request"
+// @Failure 404 {object} pkg.APIResponse "Corporate account not found"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /corporate-accounts/{id}/spending [get]
+func (h *CorporateHandler) GetSpending(c *gin.Context) {
+	id, ok := parseCorporateAccountID(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.corporateService.GetSpending(id, c.Query("month"))
+	if err != nil {
+		pkg.RespondWithError(c, corporateErrorStatus(err), err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "Spending fetched successfully")
+}
+
+// GenerateStatement handles POST /corporate-accounts/{id}/statements
+// @Summary Generate corporate statement
+// @Description Issues the consolidated statement of the account for a month. Statements for the previous month
+// @Description are also generated automatically.
+// @Tags corporate
+// @Accept json
+// @Produce json
+// @Param id path int true "Corporate Account ID"
+// @Param statement body dto.GenerateStatementRequest true "Statement period"
+// @Success 201 {object} pkg.APIResponse "Statement created successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid request data"
+// @Failure 404 {object} pkg.APIResponse "Corporate account not found"
+// @Failure 409 {object} pkg.APIResponse "Statement exists or nothing to bill"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /corporate-accounts/{id}/statements [post]
+func (h *CorporateHandler) GenerateStatement(c *gin.Context) {
+	id, ok := parseCorporateAccountID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.GenerateStatementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid request data: %v", err))
+		return
+	}
+
+	response, err := h.corporateService.GenerateStatement(id, req.Period)
+	if err != nil {
+		pkg.RespondWithError(c, corporateErrorStatus(err), err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusCreated, response, "Statement created successfully")
+}
+
+// ListStatements handles GET /corporate-accounts/{id}/statements
+// @Summary List corporate statements
+// @Description Retrieves the statements of the account, newest first.
+// @Tags corporate
+// @Produce json
+// @Param id path int true "Corporate Account ID"
+// @Success 200 {object} pkg.APIResponse "Statements fetched successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid corporate account ID"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /corporate-accounts/{id}/statements [get]
+func (h *CorporateHandler) ListStatements(c *gin.Context) {
+	id, ok := parseCorporateAccountID(c)
+	if !ok {
+		return
+	}
+
+	responses, err := h.corporateService.ListStatements(id)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusOK, responses, "Statements fetched successfully")
+}
+
+// GetStatement handles GET /corporate-statements/{id}
+// @Summary Get corporate statement
+// @Description Retrieves a statement with the bookings billed on it.
+// @Tags corporate
+// @Produce json
+// @Param id path int true "Statement ID"
+// @Success 200 {object} pkg.APIResponse "Statement fetched successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid statement ID"
+// @Failure 404 {object} pkg.APIResponse "Statement not found"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /corporate-statements/{id} [get]
+func (h *CorporateHandler) GetStatement(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid statement ID: %v", err))
+		return
+	}
+
+	response, err := h.corporateService.GetStatement(uint(id))
+	if err != nil {
+		pkg.RespondWithError(c, corporateErrorStatus(err), err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "Statement fetched successfully")
+}
+
+// MarkStatementPaid handles POST /corporate-statements/{id}/pay
+// @Summary Mark corporate statement paid
+// @Description Records that the company settled the statement.
+// @Tags corporate
+// @Produce json
+// @Param id path int true "Statement ID"
+// @Success 200 {object} pkg.APIResponse "Statement marked as paid"
+// @Failure 400 {object} pkg.APIResponse "Invalid statement ID"
+// @Failure 404 {object} pkg.APIResponse "Statement not found"
+// @Failure 409 {object} pkg.APIResponse "Statement already paid"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /corporate-statements/{id}/pay [post]
+func (h *CorporateHandler) MarkStatementPaid(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid statement ID: %v", err))
+		return
+	}
+
+	response, err := h.corporateService.MarkStatementPaid(uint(id))
+	if err != nil {
+		pkg.RespondWithError(c, corporateErrorStatus(err), err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "Statement marked as paid")
+}
+
+func parseCorporateAccountID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid corporate account ID: %v", err))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func parseCorporateBookingIDs(c *gin.Context) (uint, uint, bool) {
+	id, ok := parseCorporateAccountID(c)
+	if !ok {
+		return 0, 0, false
+	}
+	bookingID, err := strconv.ParseUint(c.Param("bookingID"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid booking ID: %v", err))
+		return 0, 0, false
+	}
+	return id, uint(bookingID), true
+}
+
+// corporateErrorStatus maps corporate service errors to HTTP status codes.
+func corporateErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidPeriod):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrNotCorporateMember),
+		errors.Is(err, services.ErrNotCorporateApprover),
+		errors.Is(err, services.ErrSelfApproval):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrCorporateAccountNotFound),
+		errors.Is(err, services.ErrBookingNotFound),
+		errors.Is(err, services.ErrStatementNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrCorporateAccountInactive),
+		errors.Is(err, services.ErrAlreadyCorporateMember),
+		errors.Is(err, services.ErrBookingNotPending),
+		errors.Is(err, services.ErrBookingNotAwaitingReview),
+		errors.Is(err, services.ErrSpendingLimitExceeded),
+		errors.Is(err, services.ErrStatementAlreadyExists),
+		errors.Is(err, services.ErrStatementAlreadyPaid),
+		errors.Is(err, services.ErrNothingToBill):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
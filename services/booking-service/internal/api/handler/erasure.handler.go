@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"booking-service/internal/services"
+	"booking-service/pkg"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ErasureHandler struct {
+	erasureService services.IErasureService
+}
+
+func NewErasureHandler(erasureService services.IErasureService) *ErasureHandler {
+	return &ErasureHandler{erasureService: erasureService}
+}
+
+// EraseUserData handles DELETE /users/{userID}/data
+// @Summary Erase the data of a deleted account
+// @Description Anonymises the bookings, invoices and reviews of a deleted account and removes its corporate memberships. Amounts and invoice numbers are kept. Called by auth-service once the grace period of the deletion ends; requires a service token.
+// @Tags erasure
+// @Produce json
+// @Param userID path int true "User ID"
+// @Success 200 {object} pkg.APIResponse "User data erased successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid user ID"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 403 {object} pkg.APIResponse "Not a service token"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /users/{userID}/data [delete]
+func (h *ErasureHandler) EraseUserData(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userID"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid user ID: %v", err))
+		return
+	}
+
+	if err := h.erasureService.EraseUserData(uint(userID)); err != nil {
+		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusOK, nil, "User data erased successfully")
+}
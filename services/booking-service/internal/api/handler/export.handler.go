@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"booking-service/internal/services"
+	"booking-service/pkg"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExportHandler struct {
+	exportService services.IExportService
+}
+
+func NewExportHandler(exportService services.IExportService) *ExportHandler {
+	return &ExportHandler{exportService: exportService}
+}
+
+// ExportUserData handles GET /users/{userID}/data
+// @Summary Export the data of an account
+// @Description Retrieves the bookings, invoices, reviews and corporate membership of a user for the export of their personal data. Called by auth-service; requires a service token.
+// @Tags export
+// @Produce json
+// @Param userID path int true "User ID"
+// @Success 200 {object} pkg.APIResponse "User data exported successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid user ID"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 403 {object} pkg.APIResponse "Not a service token"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /users/{userID}/data [get]
+func (h *ExportHandler) ExportUserData(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userID"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid user ID: %v", err))
+		return
+	}
+
+	response, err := h.exportService.ExportUserData(uint(userID))
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "User data exported successfully")
+}
@@ -0,0 +1,302 @@
+package handler
+
+import (
+	"booking-service/internal/api/dto"
+	"booking-service/internal/services"
+	"booking-service/pkg"
+	"errors"
+	"fmt"
+	"net/http"
+	"shared/authz"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type InvoiceHandler struct {
+	invoiceService services.IInvoiceService
+	taxRateService services.ITaxRateService
+}
+
+func NewInvoiceHandler(invoiceService services.IInvoiceService, taxRateService services.ITaxRateService) *InvoiceHandler {
+	return &InvoiceHandler{
+		invoiceService: invoiceService,
+		taxRateService: taxRateService,
+	}
+}
+
+// GenerateInvoice handles POST /bookings/{bookingID}/invoice
+// @Summary Generate invoice
+// @Description Issues a sequentially numbered invoice for a paid booking, with one line per passenger and fee.
+// @Tags invoices
+// @Accept json
+// @Produce json
+// @Param bookingID path int true "Booking ID"
+// @Param invoice body dto.CreateInvoiceRequest true "Billing details"
+// @Success 201 {object} pkg.APIResponse "Invoice created successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid request data"
+// @Failure 404 {object} pkg.APIResponse "Booking not found"
+// @Failure 409 {object} pkg.APIResponse "Booking cannot be invoiced"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /bookings/{bookingID}/invoice [post]
+func (h *InvoiceHandler) GenerateInvoice(c *gin.Context) {
+	bookingID, err := strconv.ParseUint(c.Param("bookingID"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid booking ID: %v", err))
+		return
+	}
+
+	var req dto.CreateInvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid request data: %v", err))
+		return
+	}
+
+	response, err := h.invoiceService.GenerateInvoice(uint(bookingID), req)
+	if err != nil {
+		pkg.RespondWithError(c, invoiceErrorStatus(err), err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusCreated, response, "Invoice created successfully")
+}
+
+// IssueCreditNote handles POST /invoices/{id}/credit-notes
+// @Summary Issue credit note
+// @Description Issues a credit note reversing the selected lines of an invoice, or all lines not yet credited.
+// @Tags invoices
+// @Accept json
+// @Produce json
+// @Param id path int true "Invoice ID"
+// @Param creditNote body dto.CreateCreditNoteRequest true "Credit note details"
+// @Success 201 {object} pkg.APIResponse "Credit note created successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid request data"
+// @Failure 404 {object} pkg.APIResponse "Invoice not found"
+// @Failure 409 {object} pkg.APIResponse "Nothing left to credit"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /invoices/{id}/credit-notes [post]
+func (h *InvoiceHandler) IssueCreditNote(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid invoice ID: %v", err))
+		return
+	}
+
+	var req dto.CreateCreditNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid request data: %v", err))
+		return
+	}
+
+	response, err := h.invoiceService.IssueCreditNote(uint(id), req)
+	if err != nil {
+		pkg.RespondWithError(c, invoiceErrorStatus(err), err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusCreated, response, "Credit note created successfully")
+}
+
+// GetInvoice handles GET /invoices/{id}
+// @Summary Get invoice
+// @Description Retrieves an invoice or credit note with its lines.
+// @Tags invoices
+// @Produce json
+// @Param id path int true "Invoice ID"
+// @Success 200 {object} pkg.APIResponse "Invoice fetched successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid invoice ID"
+// @Failure 403 {object} pkg.APIResponse "Invoice belongs to another user"
+// @Failure 404 {object} pkg.APIResponse "Invoice not found"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /invoices/{id} [get]
+func (h *InvoiceHandler) GetInvoice(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid invoice ID: %v", err))
+		return
+	}
+
+	response, ok := h.authorizedInvoice(c, uint(id))
+	if !ok {
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "Invoice fetched successfully")
+}
+
+// DownloadInvoicePDF handles GET /invoices/{id}/pdf
+// @Summary Download invoice PDF
+// @Description Downloads an invoice or credit note as a PDF document.
+// @Tags invoices
+// @Produce application/pdf
+// @Param id path int true "Invoice ID"
+// @Success 200 {file} file "Invoice PDF"
+// @Failure 400 {object} pkg.APIResponse "Invalid invoice ID"
+// @Failure 403 {object} pkg.APIResponse "Invoice belongs to another user"
+// @Failure 404 {object} pkg.APIResponse "Invoice not found"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /invoices/{id}/pdf [get]
+func (h *InvoiceHandler) DownloadInvoicePDF(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid invoice ID: %v", err))
+		return
+	}
+
+	if _, ok := h.authorizedInvoice(c, uint(id)); !ok {
+		return
+	}
+
+	content, filename, err := h.invoiceService.RenderInvoicePDF(uint(id))
+	if err != nil {
+		pkg.RespondWithError(c, invoiceErrorStatus(err), err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "application/pdf", content)
+}
+
+// authorizedInvoice fetches an invoice and checks that it belongs to the caller, responding with an error if not.
+func (h *InvoiceHandler) authorizedInvoice(c *gin.Context, id uint) (*dto.InvoiceResponse, bool) {
+	response, err := h.invoiceService.GetInvoice(id)
+	if err != nil {
+		pkg.RespondWithError(c, invoiceErrorStatus(err), err)
+		return nil, false
+	}
+	if principal, _ := authz.CurrentPrincipal(c); !principal.CanAccessUser(response.UserID) {
+		pkg.RespondWithError(c, http.StatusForbidden, authz.ErrForbidden)
+		return nil, false
+	}
+	return response, true
+}
+
+// ListInvoicesByUser handles GET /users/{userID}/invoices
+// @Summary List invoices of a user
+// @Description Retrieves the invoices and credit notes of a user, newest first.
+// @Tags invoices
+// @Produce json
+// @Param userID path int true "User ID"
+// @Param offset query int false "Pagination offset"
+// @Param limit query int false "Pagination limit"
+// @Success 200 {object} pkg.APIResponse "Invoices fetched successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid user ID"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /users/{userID}/invoices [get]
+func (h *InvoiceHandler) ListInvoicesByUser(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userID"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid user ID: %v", err))
+		return
+	}
+
+	offset, _ := strconv.Atoi(c.Query("offset")) // pagination offset
+	limit, _ := strconv.Atoi(c.Query("limit"))   // pagination limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	responses, err := h.invoiceService.ListInvoicesByUser(uint(userID), offset, limit)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusOK, responses, "Invoices fetched successfully")
+}
+
+// CreateTaxRate handles POST /tax-rates
+// @Summary Create tax rate
+// @Description Adds a tax rate for a route, a region or, when neither is given, the default rate.
+// @Tags tax-rates
+// @Accept json
+// @Produce json
+// @Param taxRate body dto.CreateTaxRateRequest true "Create Tax Rate Request"
+// @Success 201 {object} pkg.APIResponse "Tax rate created successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid request data"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /tax-rates [post]
+func (h *InvoiceHandler) CreateTaxRate(c *gin.Context) {
+	var req dto.CreateTaxRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid request data: %v", err))
+		return
+	}
+
+	response, err := h.taxRateService.CreateTaxRate(req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrInvalidTaxRatePeriod) {
+			status = http.StatusBadRequest
+		}
+		pkg.RespondWithError(c, status, err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusCreated, response, "Tax rate created successfully")
+}
+
+// ListTaxRates handles GET /tax-rates
+// @Summary List tax rates
+// @Description Retrieves all configured tax rates.
+// @Tags tax-rates
+// @Produce json
+// @Success 200 {object} pkg.APIResponse "Tax rates fetched successfully"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /tax-rates [get]
+func (h *InvoiceHandler) ListTaxRates(c *gin.Context) {
+	responses, err := h.taxRateService.ListTaxRates()
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusOK, responses, "Tax rates fetched successfully")
+}
+
+// DeleteTaxRate handles DELETE /tax-rates/{id}
+// @Summary Delete tax rate
+// @Description Removes a tax rate. Invoices already issued keep the rate they were issued with.
+// @Tags tax-rates
+// @Produce json
+// @Param id path int true "Tax Rate ID"
+// @Success 200 {object} pkg.APIResponse "Tax rate deleted successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid tax rate ID"
+// @Failure 404 {object} pkg.APIResponse "Tax rate not found"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /tax-rates/{id} [delete]
+func (h *InvoiceHandler) DeleteTaxRate(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid tax rate ID: %v", err))
+		return
+	}
+
+	if err := h.taxRateService.DeleteTaxRate(uint(id)); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			status = http.StatusNotFound
+		}
+		pkg.RespondWithError(c, status, err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusOK, nil, "Tax rate deleted successfully")
+}
+
+// invoiceErrorStatus maps invoice service errors to HTTP status codes.
+func invoiceErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrBookingNotFound), errors.Is(err, services.ErrInvoiceNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrBookingNotInvoiceable),
+		errors.Is(err, services.ErrInvoiceAlreadyExists),
+		errors.Is(err, services.ErrNotAnInvoice),
+		errors.Is(err, services.ErrNothingToCredit),
+		errors.Is(err, services.ErrLinesAlreadyCredited):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"booking-service/internal/services"
+	"booking-service/pkg"
+	"fmt"
+	"net/http"
+	"shared/authz"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ManifestHandler struct {
+	manifestService services.IManifestService
+}
+
+func NewManifestHandler(manifestService services.IManifestService) *ManifestHandler {
+	return &ManifestHandler{manifestService: manifestService}
+}
+
+// GetManifest handles GET /manifest
+// @Summary Get the passenger manifest of a departure
+// @Description Lists the passengers of the confirmed and checked-in bookings of a departure of a bus. Requires the manifest:read permission; partner integrations with an API key allowed to read manifests only see the passengers of their own bookings.
+// @Tags manifest
+// @Produce json
+// @Param busID query int true "Bus ID"
+// @Param departureTime query string true "Departure time, in RFC 3339 format"
+// @Success 200 {object} pkg.APIResponse "Manifest fetched successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid bus ID or departure time"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 403 {object} pkg.APIResponse "Not allowed to read manifests"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /manifest [get]
+func (h *ManifestHandler) GetManifest(c *gin.Context) {
+	busID, err := strconv.ParseUint(c.Query("busID"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid bus ID: %v", err))
+		return
+	}
+	departureTime, err := time.Parse(time.RFC3339, c.Query("departureTime"))
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid departure time: %v", err))
+		return
+	}
+
+	// Partner integrations and other scoped clients were checked for the manifest:read scope and read the
+	// passengers they booked, everyone else needs the permission to read whole manifests
+	principal, _ := authz.CurrentPrincipal(c)
+	var userID uint
+	switch {
+	case principal.IsScoped():
+		userID = principal.UserID
+	case !principal.HasPermission(authz.PermissionManifestRead):
+		pkg.RespondWithError(c, http.StatusForbidden, authz.ErrForbidden)
+		return
+	}
+
+	response, err := h.manifestService.GetManifest(uint(busID), departureTime, userID)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "Manifest fetched successfully")
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"booking-service/internal/api/dto"
+	"net/http"
+	"net/http/httptest"
+	"shared/authz"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeManifestService records the user the manifest was limited to.
+type fakeManifestService struct {
+	userID *uint
+}
+
+func (s *fakeManifestService) GetManifest(busID uint, departureTime time.Time, userID uint) (*dto.ManifestResponse, error) {
+	s.userID = &userID
+	return &dto.ManifestResponse{BusID: busID, DepartureTime: departureTime}, nil
+}
+
+func TestGetManifestAccess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	conductor := &authz.Principal{UserID: 5, Role: "conductor", Permissions: []string{authz.PermissionManifestRead}}
+	partner := &authz.Principal{UserID: 9, Role: "customer", APIKeyID: 2, Scopes: []string{authz.ScopeReadManifest}}
+	customer := &authz.Principal{UserID: 7, Role: "customer"}
+
+	tests := []struct {
+		name       string
+		principal  *authz.Principal
+		query      string
+		wantStatus int
+		wantUserID uint
+	}{
+		{name: "conductor reads the whole manifest", principal: conductor, query: "busID=3&departureTime=2026-10-19T08:00:00Z", wantStatus: http.StatusOK},
+		{name: "partner reads their passengers", principal: partner, query: "busID=3&departureTime=2026-10-19T08:00:00Z", wantStatus: http.StatusOK, wantUserID: 9},
+		{name: "customer", principal: customer, query: "busID=3&departureTime=2026-10-19T08:00:00Z", wantStatus: http.StatusForbidden},
+		{name: "invalid bus", principal: conductor, query: "busID=x&departureTime=2026-10-19T08:00:00Z", wantStatus: http.StatusBadRequest},
+		{name: "invalid departure time", principal: conductor, query: "busID=3&departureTime=tomorrow", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &fakeManifestService{}
+			router := gin.New()
+			router.GET("/manifest", func(c *gin.Context) {
+				authz.SetPrincipal(c, tt.principal)
+			}, NewManifestHandler(service).GetManifest)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/manifest?"+tt.query, nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK && (service.userID == nil || *service.userID != tt.wantUserID) {
+				t.Errorf("manifest limited to user %v, want %d", service.userID, tt.wantUserID)
+			}
+		})
+	}
+}
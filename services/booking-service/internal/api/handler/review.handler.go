@@ -0,0 +1,313 @@
+package handler
+
+import (
+	"booking-service/internal/api/dto"
+	"booking-service/internal/services"
+	"booking-service/pkg"
+	"errors"
+	"fmt"
+	"net/http"
+	"shared/authz"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxRatingIDs caps how many buses or routes can be rated in one request.
+const maxRatingIDs = 100
+
+// maxBusCodeLength is the longest bus code bus-service accepts.
+const maxBusCodeLength = 100
+
+type ReviewHandler struct {
+	reviewService services.IReviewService
+}
+
+func NewReviewHandler(reviewService services.IReviewService) *ReviewHandler {
+	return &ReviewHandler{reviewService: reviewService}
+}
+
+// CreateReview handles POST /bookings/{bookingID}/review
+// @Summary Review a trip
+// @Description Rates the trip, driver and bus condition of a checked-in booking. A booking can be reviewed once.
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param bookingID path int true "Booking ID"
+// @Param review body dto.CreateReviewRequest true "Create Review Request"
+// @Success 201 {object} pkg.APIResponse "Review created successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid request data"
+// @Failure 403 {object} pkg.APIResponse "User did not travel on this booking"
+// @Failure 404 {object} pkg.APIResponse "Booking not found"
+// @Failure 409 {object} pkg.APIResponse "Already reviewed or review period ended"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /bookings/{bookingID}/review [post]
+func (h *ReviewHandler) CreateReview(c *gin.Context) {
+	bookingID, err := strconv.ParseUint(c.Param("bookingID"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid booking ID: %v", err))
+		return
+	}
+
+	var req dto.CreateReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid request data: %v", err))
+		return
+	}
+	principal, _ := authz.CurrentPrincipal(c)
+	req.UserID = principal.UserID
+
+	response, err := h.reviewService.CreateReview(uint(bookingID), req)
+	if err != nil {
+		pkg.RespondWithError(c, reviewErrorStatus(err), err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusCreated, response, "Review created successfully")
+}
+
+// ListBusReviews handles GET /buses/{busCode}/reviews
+// @Summary List reviews of a bus
+// @Description Retrieves the published reviews of a bus, by its code, newest first.
+// @Tags reviews
+// @Produce json
+// @Param busCode path string true "Bus code"
+// @Param offset query int false "Pagination offset"
+// @Param limit query int false "Pagination limit"
+// @Success 200 {object} pkg.APIResponse "Reviews fetched successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid bus code"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /buses/{busCode}/reviews [get]
+func (h *ReviewHandler) ListBusReviews(c *gin.Context) {
+	busCode := c.Param("busCode")
+	if len(busCode) > maxBusCodeLength {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid bus code: longer than %d characters", maxBusCodeLength))
+		return
+	}
+
+	offset, limit := paginationParams(c)
+	response, err := h.reviewService.ListBusReviews(busCode, offset, limit)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "Reviews fetched successfully")
+}
+
+// ListRouteReviews handles GET /routes/{routeID}/reviews
+// @Summary List reviews of a route
+// @Description Retrieves the published reviews of a route, newest first.
+// @Tags reviews
+// @Produce json
+// @Param routeID path int true "Route ID"
+// @Param offset query int false "Pagination offset"
+// @Param limit query int false "Pagination limit"
+// @Success 200 {object} pkg.APIResponse "Reviews fetched successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid route ID"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /routes/{routeID}/reviews [get]
+func (h *ReviewHandler) ListRouteReviews(c *gin.Context) {
+	routeID, err := strconv.ParseUint(c.Param("routeID"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid route ID: %v", err))
+		return
+	}
+
+	offset, limit := paginationParams(c)
+	response, err := h.reviewService.ListRouteReviews(uint(routeID), offset, limit)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "Reviews fetched successfully")
+}
+
+// GetBusRatings handles GET /ratings/buses
+// @Summary Get bus ratings
+// @Description Retrieves the aggregated ratings of up to 100 buses, by their codes. Buses without reviews are omitted.
+// @Tags reviews
+// @Produce json
+// @Param codes query string true "Comma separated bus codes"
+// @Success 200 {object} pkg.APIResponse "Ratings fetched successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid codes"
+// @Failure 403 {object} pkg.APIResponse "The client or API key does not have the ratings:read scope"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /ratings/buses [get]
+func (h *ReviewHandler) GetBusRatings(c *gin.Context) {
+	codes, err := parseCodeList(c.Query("codes"))
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	responses, err := h.reviewService.GetBusRatings(codes)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusOK, responses, "Ratings fetched successfully")
+}
+
+// GetRouteRatings handles GET /ratings/routes
+// @Summary Get route ratings
+// @Description Retrieves the aggregated ratings of up to 100 routes. Routes without reviews are omitted.
+// @Tags reviews
+// @Produce json
+// @Param ids query string true "Comma separated route IDs"
+// @Success 200 {object} pkg.APIResponse "Ratings fetched successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid IDs"
+// @Failure 403 {object} pkg.APIResponse "The client or API key does not have the ratings:read scope"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /ratings/routes [get]
+func (h *ReviewHandler) GetRouteRatings(c *gin.Context) {
+	ids, err := parseIDList(c.Query("ids"))
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	responses, err := h.reviewService.GetRouteRatings(ids)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusOK, responses, "Ratings fetched successfully")
+}
+
+// ListReviewsForModeration handles GET /admin/reviews
+// @Summary List reviews for moderation
+// @Description Retrieves reviews in any status with their moderation details, newest first.
+// @Tags reviews
+// @Produce json
+// @Param status query string false "Filter by status (published, hidden)"
+// @Param offset query int false "Pagination offset"
+// @Param limit query int false "Pagination limit"
+// @Success 200 {object} pkg.APIResponse "Reviews fetched successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid status"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /admin/reviews [get]
+func (h *ReviewHandler) ListReviewsForModeration(c *gin.Context) {
+	status := c.Query("status")
+	if status != "" && status != "published" && status != "hidden" {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid status %q", status))
+		return
+	}
+
+	offset, limit := paginationParams(c)
+	response, err := h.reviewService.ListReviewsForModeration(status, offset, limit)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "Reviews fetched successfully")
+}
+
+// ModerateReview handles PUT /admin/reviews/{id}/moderation
+// @Summary Moderate review
+// @Description Publishes or hides a review. Hidden reviews are not shown and do not count towards ratings.
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path int true "Review ID"
+// @Param moderation body dto.ModerateReviewRequest true "Moderation decision"
+// @Success 200 {object} pkg.APIResponse "Review moderated successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid request data"
+// @Failure 404 {object} pkg.APIResponse "Review not found"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /admin/reviews/{id}/moderation [put]
+func (h *ReviewHandler) ModerateReview(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid review ID: %v", err))
+		return
+	}
+
+	var req dto.ModerateReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid request data: %v", err))
+		return
+	}
+	principal, _ := authz.CurrentPrincipal(c)
+	req.ModeratorID = principal.UserID
+
+	response, err := h.reviewService.ModerateReview(uint(id), req)
+	if err != nil {
+		pkg.RespondWithError(c, reviewErrorStatus(err), err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusOK, response, "Review moderated successfully")
+}
+
+// paginationParams reads the offset and limit query parameters, defaulting to the first 20 results.
+func paginationParams(c *gin.Context) (int, int) {
+	offset, _ := strconv.Atoi(c.Query("offset")) // pagination offset
+	limit, _ := strconv.Atoi(c.Query("limit"))   // pagination limit
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	return offset, limit
+}
+
+// parseIDList parses a comma separated list of IDs such as "1,2,3".
+func parseIDList(raw string) ([]uint, error) {
+	if raw == "" {
+		return nil, errors.New("ids query parameter is required")
+	}
+	parts := strings.Split(raw, ",")
+	if len(parts) > maxRatingIDs {
+		return nil, fmt.Errorf("at most %d ids can be requested at once", maxRatingIDs)
+	}
+	ids := make([]uint, 0, len(parts))
+	for _, part := range parts {
+		id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid id %q: %v", part, err)
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids, nil
+}
+
+// parseCodeList parses a comma separated list of bus codes, as accepted by GetBusRatings.
+func parseCodeList(raw string) ([]string, error) {
+	if raw == "" {
+		return nil, errors.New("codes query parameter is required")
+	}
+	parts := strings.Split(raw, ",")
+	if len(parts) > maxRatingIDs {
+		return nil, fmt.Errorf("at most %d codes can be requested at once", maxRatingIDs)
+	}
+	codes := make([]string, 0, len(parts))
+	for _, part := range parts {
+		code := strings.TrimSpace(part)
+		if code == "" || len(code) > maxBusCodeLength {
+			return nil, fmt.Errorf("invalid code %q", part)
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// reviewErrorStatus maps review service errors to HTTP status codes.
+func reviewErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrReviewNotAllowed):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrBookingNotFound), errors.Is(err, services.ErrReviewNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrReviewAlreadyExists), errors.Is(err, services.ErrReviewWindowClosed):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCodeList(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    []string
+		wantErr bool
+	}{
+		{name: "single", raw: "DHK101", want: []string{"DHK101"}},
+		{name: "several with spaces", raw: "DHK101, CTG7 ,SYL2", want: []string{"DHK101", "CTG7", "SYL2"}},
+		{name: "missing", raw: "", wantErr: true},
+		{name: "empty code", raw: "DHK101,,CTG7", wantErr: true},
+		{name: "code too long", raw: strings.Repeat("A", maxBusCodeLength+1), wantErr: true},
+		{name: "too many codes", raw: strings.Repeat("A,", maxRatingIDs) + "A", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCodeList(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCodeList() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseCodeList() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseIDList(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    []uint
+		wantErr bool
+	}{
+		{name: "several", raw: "1, 2,30", want: []uint{1, 2, 30}},
+		{name: "missing", raw: "", wantErr: true},
+		{name: "not a number", raw: "1,two", wantErr: true},
+		{name: "negative", raw: "-1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseIDList(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseIDList() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseIDList() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
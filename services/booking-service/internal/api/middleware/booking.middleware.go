@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"booking-service/internal/repository"
+	"booking-service/pkg"
+	"errors"
+	"fmt"
+	"net/http"
+	"shared/authz"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RequireBookingOwner only lets through the user who made the booking in the bookingID path parameter, and
+// admins. Principals granted all of permissions are let through too, such as support agents reading bookings.
+// It must run after authz.Authenticate.
+func RequireBookingOwner(bookingRepo repository.IBookingRepository, permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := authz.CurrentPrincipal(c)
+		if !ok {
+			pkg.RespondWithError(c, http.StatusUnauthorized, authz.ErrMissingToken)
+			c.Abort()
+			return
+		}
+		bookingID, err := strconv.ParseUint(c.Param("bookingID"), 10, 32)
+		if err != nil {
+			pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid booking ID: %v", err))
+			c.Abort()
+			return
+		}
+		if principal.IsAdmin() || (len(permissions) > 0 && hasPermissions(principal, permissions)) {
+			c.Next()
+			return
+		}
+
+		booking, err := bookingRepo.GetBookingByID(uint(bookingID))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				pkg.RespondWithError(c, http.StatusNotFound, errors.New("booking not found"))
+			} else {
+				pkg.RespondWithError(c, http.StatusInternalServerError, err)
+			}
+			c.Abort()
+			return
+		}
+		if booking.UserID != principal.UserID {
+			pkg.RespondWithError(c, http.StatusForbidden, authz.ErrForbidden)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func hasPermissions(principal *authz.Principal, permissions []string) bool {
+	for _, permission := range permissions {
+		if !principal.HasPermission(permission) {
+			return false
+		}
+	}
+	return true
+}
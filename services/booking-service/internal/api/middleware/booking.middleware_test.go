@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"booking-service/internal/models"
+	"booking-service/internal/repository"
+	"net/http"
+	"net/http/httptest"
+	"shared/authz"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// fakeBookingRepository holds the bookings in the map.
+type fakeBookingRepository struct {
+	repository.IBookingRepository
+	bookings map[uint]models.Booking
+}
+
+func (r *fakeBookingRepository) GetBookingByID(id uint) (*models.Booking, error) {
+	booking, ok := r.bookings[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &booking, nil
+}
+
+func TestRequireBookingOwner(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	booking := models.Booking{UserID: 7}
+	booking.ID = 1
+	repo := &fakeBookingRepository{bookings: map[uint]models.Booking{1: booking}}
+
+	owner := &authz.Principal{UserID: 7, Role: "customer"}
+	other := &authz.Principal{UserID: 8, Role: "customer"}
+	support := &authz.Principal{UserID: 9, Role: "support", Permissions: []string{authz.PermissionBookingsRead}}
+	admin := &authz.Principal{UserID: 1, Role: authz.RoleAdmin}
+
+	tests := []struct {
+		name        string
+		principal   *authz.Principal
+		permissions []string
+		path        string
+		wantStatus  int
+	}{
+		{name: "owner", principal: owner, path: "/bookings/1", wantStatus: http.StatusOK},
+		{name: "other user", principal: other, path: "/bookings/1", wantStatus: http.StatusForbidden},
+		{name: "admin", principal: admin, path: "/bookings/1", wantStatus: http.StatusOK},
+		{name: "support agent on a read route", principal: support, permissions: []string{authz.PermissionBookingsRead}, path: "/bookings/1", wantStatus: http.StatusOK},
+		{name: "support agent on a route without permissions", principal: support, path: "/bookings/1", wantStatus: http.StatusForbidden},
+		{name: "missing booking", principal: owner, path: "/bookings/2", wantStatus: http.StatusNotFound},
+		{name: "invalid booking ID", principal: owner, path: "/bookings/x", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/bookings/:bookingID", func(c *gin.Context) {
+				authz.SetPrincipal(c, tt.principal)
+			}, RequireBookingOwner(repo, tt.permissions...), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
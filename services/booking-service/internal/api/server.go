@@ -1,10 +1,15 @@
 package api
 
 import (
+	"shared/authz"
 	//_ "booking-service/docs" // Required for Swagger docs
 
+	"booking-service/internal/api/handler"
 	"booking-service/internal/api/middleware"
 	"booking-service/internal/config"
+	"booking-service/internal/repository"
+	"booking-service/internal/services"
+	"shared/scheduler"
 
 	"context"
 	"errors"
@@ -19,10 +24,18 @@ import (
 	"time"
 )
 
+// Advisory lock keys guarding the background jobs, unique across the booking database.
+const (
+	expireBookingsLockKey int64 = 85001
+	markNoShowsLockKey    int64 = 85002
+	statementsLockKey     int64 = 85003
+)
+
 // Server holds the dependencies for a HTTP server.
 type Server struct {
-	Router *gin.Engine
-	DB     *config.Database
+	Router    *gin.Engine
+	DB        *config.Database
+	Scheduler *scheduler.Scheduler
 }
 
 // NewServer creates a new HTTP server and sets up routing.
@@ -36,6 +49,7 @@ func NewServer(databaseClient *config.Database) *Server {
 		DB:     databaseClient,
 	}
 	s.routes()
+	s.jobs()
 	return s
 }
 
@@ -43,7 +57,39 @@ func NewServer(databaseClient *config.Database) *Server {
 func (s *Server) routes() {
 
 	// API Versioning
-	//_ := s.Router.Group("/api/v1/auth")
+	v1 := s.Router.Group("/api/v1/booking")
+
+	// Setup invoice handlers
+	bookingRepo := repository.NewBookingRepository(s.DB.Conn)
+	taxRateRepo := repository.NewTaxRateRepository(s.DB.Conn)
+	i := handler.NewInvoiceHandler(
+		services.NewInvoiceService(repository.NewInvoiceRepository(s.DB.Conn), bookingRepo, taxRateRepo),
+		services.NewTaxRateService(taxRateRepo),
+	)
+
+	// Setup invoice routes
+	s.setupInvoiceRoutes(v1, i, bookingRepo)
+
+	// Setup corporate account handlers and routes
+	ch := handler.NewCorporateHandler(services.NewCorporateService(repository.NewCorporateRepository(s.DB.Conn), bookingRepo))
+	s.setupCorporateRoutes(v1, ch, bookingRepo)
+
+	// Setup review handlers and routes
+	rh := handler.NewReviewHandler(services.NewReviewService(repository.NewReviewRepository(s.DB.Conn), bookingRepo, services.NewBusService()))
+	s.setupReviewRoutes(v1, rh)
+
+	// Setup booking handlers and routes
+	s.setupBookingRoutes(v1, handler.NewBookingHandler(services.NewBookingService(bookingRepo)), bookingRepo)
+
+	// Setup manifest handlers and routes
+	s.setupManifestRoutes(v1, handler.NewManifestHandler(services.NewManifestService(bookingRepo)))
+
+	// Setup export and erasure of the data of accounts, called by auth-service
+	xh := handler.NewExportHandler(services.NewExportService(repository.NewExportRepository(s.DB.Conn)))
+	eh := handler.NewErasureHandler(services.NewErasureService(repository.NewErasureRepository(s.DB.Conn)))
+	auth, service := authz.Authenticate(authz.ScopeBookingsUserData), authz.RequireRole(authz.RoleService)
+	v1.GET("/users/:userID/data", auth, service, xh.ExportUserData)
+	v1.DELETE("/users/:userID/data", auth, service, eh.EraseUserData)
 
 	// Health check route
 	s.setupHealthCheckRoute()
@@ -52,12 +98,128 @@ func (s *Server) routes() {
 	s.setupNoRouteHandler()
 }
 
+// jobs registers the background jobs run by the scheduler.
+func (s *Server) jobs() {
+	sqlDB, err := s.DB.Conn.DB()
+	if err != nil {
+		log.Fatalf("Could not get database connection: %v", err)
+	}
+	s.Scheduler = scheduler.NewScheduler(sqlDB)
+
+	lifecycle := services.NewBookingLifecycleService(
+		repository.NewBookingRepository(s.DB.Conn),
+		services.NewSeatService(),
+		config.GetDuration("NO_SHOW_GRACE_PERIOD", 30*time.Minute),
+	)
+
+	// Expire unpaid bookings and release their seats
+	s.Scheduler.Register(scheduler.Job{
+		Name:     "expire-pending-bookings",
+		Interval: config.GetDuration("BOOKING_EXPIRY_INTERVAL", time.Minute),
+		LockKey:  expireBookingsLockKey,
+		Run:      lifecycle.ExpirePendingBookings,
+	})
+
+	// Mark confirmed bookings that never boarded as no-shows
+	s.Scheduler.Register(scheduler.Job{
+		Name:     "mark-no-shows",
+		Interval: config.GetDuration("NO_SHOW_INTERVAL", 5*time.Minute),
+		LockKey:  markNoShowsLockKey,
+		Run:      lifecycle.MarkNoShows,
+	})
+
+	// Issue last month's statements of corporate accounts
+	corporate := services.NewCorporateService(
+		repository.NewCorporateRepository(s.DB.Conn),
+		repository.NewBookingRepository(s.DB.Conn),
+	)
+	s.Scheduler.Register(scheduler.Job{
+		Name:     "generate-corporate-statements",
+		Interval: config.GetDuration("CORPORATE_STATEMENT_INTERVAL", time.Hour),
+		LockKey:  statementsLockKey,
+		Run:      corporate.GenerateMonthlyStatements,
+	})
+}
+
 func (s *Server) setupHealthCheckRoute() {
 	s.Router.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"code": 200, "message": "Booking services is running"})
 	})
 }
 
+func (s *Server) setupInvoiceRoutes(v1 *gin.RouterGroup, i *handler.InvoiceHandler, bookingRepo repository.IBookingRepository) {
+	// Invoices are visible to the booking owner, credit notes and tax rates are managed by admins. Partner
+	// integrations reach the invoices of their bookings with API keys allowed to book.
+	auth, admin := authz.Authenticate(), authz.RequireRole(authz.RoleAdmin)
+	book := authz.Authenticate(authz.ScopeBook)
+	v1.POST("/bookings/:bookingID/invoice", book, middleware.RequireBookingOwner(bookingRepo), i.GenerateInvoice)
+	v1.GET("/invoices/:id", book, i.GetInvoice)
+	v1.GET("/invoices/:id/pdf", book, i.DownloadInvoicePDF)
+	v1.POST("/invoices/:id/credit-notes", auth, authz.RequirePermission(authz.PermissionInvoicesManage), i.IssueCreditNote)
+	v1.GET("/users/:userID/invoices", book, authz.RequireOwner("userID"), i.ListInvoicesByUser)
+
+	// Tax rates applied to new invoices
+	v1.POST("/tax-rates", auth, admin, i.CreateTaxRate)
+	v1.GET("/tax-rates", i.ListTaxRates)
+	v1.DELETE("/tax-rates/:id", auth, admin, i.DeleteTaxRate)
+}
+
+func (s *Server) setupCorporateRoutes(v1 *gin.RouterGroup, ch *handler.CorporateHandler, bookingRepo repository.IBookingRepository) {
+	// Accounts, members and statements are managed by admins
+	auth, admin := authz.Authenticate(), authz.RequireRole(authz.RoleAdmin)
+	v1.POST("/corporate-accounts", auth, admin, ch.CreateAccount)
+	v1.GET("/corporate-accounts", auth, admin, ch.ListAccounts)
+	v1.GET("/corporate-accounts/:id", auth, admin, ch.GetAccount)
+	v1.PUT("/corporate-accounts/:id", auth, admin, ch.UpdateAccount)
+	v1.POST("/corporate-accounts/:id/members", auth, admin, ch.AddMember)
+	v1.DELETE("/corporate-accounts/:id/members/:userID", auth, admin, ch.RemoveMember)
+
+	// Bookings billed to the account and their approval. Members charge their own bookings, the service
+	// checks that the caller is an approver of the account.
+	v1.POST("/corporate-accounts/:id/bookings/:bookingID", authz.Authenticate(authz.ScopeBook), middleware.RequireBookingOwner(bookingRepo), ch.ChargeBooking)
+	v1.POST("/corporate-accounts/:id/bookings/:bookingID/approve", auth, ch.ApproveBooking)
+	v1.POST("/corporate-accounts/:id/bookings/:bookingID/reject", auth, ch.RejectBooking)
+
+	// Spending and monthly statements
+	v1.GET("/corporate-accounts/:id/spending", auth, admin, ch.GetSpending)
+	v1.POST("/corporate-accounts/:id/statements", auth, admin, ch.GenerateStatement)
+	v1.GET("/corporate-accounts/:id/statements", auth, admin, ch.ListStatements)
+	v1.GET("/corporate-statements/:id", auth, admin, ch.GetStatement)
+	v1.POST("/corporate-statements/:id/pay", auth, admin, ch.MarkStatementPaid)
+}
+
+func (s *Server) setupBookingRoutes(v1 *gin.RouterGroup, bh *handler.BookingHandler, bookingRepo repository.IBookingRepository) {
+	// Bookings are visible to the user who made them and to support agents with the bookings:read permission.
+	// Partner integrations read their bookings with API keys allowed to book.
+	v1.GET("/bookings/:bookingID", authz.Authenticate(authz.ScopeBook),
+		middleware.RequireBookingOwner(bookingRepo, authz.PermissionBookingsRead), bh.GetBooking)
+}
+
+func (s *Server) setupManifestRoutes(v1 *gin.RouterGroup, mh *handler.ManifestHandler) {
+	// Conductors and dispatchers read whole manifests with the manifest:read permission, partner integrations
+	// read the passengers they booked with API keys allowed to read manifests
+	v1.GET("/manifest", authz.Authenticate(authz.ScopeReadManifest), mh.GetManifest)
+}
+
+func (s *Server) setupReviewRoutes(v1 *gin.RouterGroup, rh *handler.ReviewHandler) {
+	// Reviews and ratings are public, writing a review needs the passenger's token
+	auth := authz.Authenticate()
+	v1.POST("/bookings/:bookingID/review", auth, rh.CreateReview)
+	v1.GET("/buses/:busCode/reviews", rh.ListBusReviews)
+	v1.GET("/routes/:routeID/reviews", rh.ListRouteReviews)
+
+	// Aggregated ratings, shown by bus-service and route-service, which read them with a service token that must
+	// have the ratings:read scope
+	ratings := authz.AuthenticateOptional(authz.ScopeRatingsRead)
+	v1.GET("/ratings/buses", ratings, rh.GetBusRatings)
+	v1.GET("/ratings/routes", ratings, rh.GetRouteRatings)
+
+	// Moderation, by support agents
+	moderate := authz.RequirePermission(authz.PermissionReviewsModerate)
+	v1.GET("/admin/reviews", auth, moderate, rh.ListReviewsForModeration)
+	v1.PUT("/admin/reviews/:id/moderation", auth, moderate, rh.ModerateReview)
+}
+
 func (s *Server) setupNoRouteHandler() {
 	s.Router.NoRoute(func(c *gin.Context) {
 		// Improved error message
@@ -86,12 +248,20 @@ func (s *Server) Start(addr string) {
 	}()
 	log.Println("Server started successfully on", addr)
 
+	// Start background jobs; they are stopped together with the HTTP server.
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	s.Scheduler.Start(jobsCtx)
+
 	// Wait for interrupt signal to gracefully shut down the server with a timeout.
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("Shutting down server...")
 
+	// Stop scheduling new runs and let in-flight jobs finish.
+	stopJobs()
+	s.Scheduler.Wait()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second) // Shortened timeout
 	defer cancel()
 	if err := srv.Shutdown(ctx); err != nil {
@@ -0,0 +1,22 @@
+package config
+
+import (
+	"log"
+	"os"
+	"time"
+)
+
+// GetDuration reads a duration such as "90s" or "15m" from the environment variable key,
+// falling back to defaultValue when it is unset or invalid.
+func GetDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		log.Printf("Invalid duration %q for %s, defaulting to %s", value, key, defaultValue)
+		return defaultValue
+	}
+	return duration
+}
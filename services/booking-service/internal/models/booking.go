@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BookingStatus defines the allowed lifecycle states of a Booking.
+type BookingStatus string
+
+const (
+	// BookingPending is a booking whose seats are held while the customer completes payment.
+	BookingPending BookingStatus = "pending"
+	// BookingConfirmed is a paid booking whose passengers have not boarded yet.
+	BookingConfirmed BookingStatus = "confirmed"
+	// BookingCheckedIn is a booking whose passengers boarded the bus.
+	BookingCheckedIn BookingStatus = "checked_in"
+	// BookingCancelled is a booking cancelled by the customer or an operator.
+	BookingCancelled BookingStatus = "cancelled"
+	// BookingExpired is a pending booking that was not paid before ExpiresAt.
+	BookingExpired BookingStatus = "expired"
+	// BookingNoShow is a confirmed booking whose passengers never boarded before departure.
+	BookingNoShow BookingStatus = "no_show"
+)
+
+// Booking represents a reservation of one or more seats on a scheduled bus departure.
+type Booking struct {
+	gorm.Model
+	UserID             uint               `gorm:"not null;index" json:"userID"` // Foreign key for User in auth-service
+	BusID              uint               `gorm:"not null;index" json:"busID"`  // Foreign key for Bus in bus-service
+	RouteID            uint               `gorm:"not null;index" json:"routeID"`
+	ScheduleID         uint               `gorm:"index" json:"scheduleID"`
+	DepartureTime      time.Time          `gorm:"not null;index" json:"departureTime"`
+	Status             BookingStatus      `gorm:"type:varchar(50);not null;default:'pending';index" json:"status"`
+	TotalAmount        float64            `gorm:"type:numeric(12,2);not null;default:0" json:"totalAmount"`
+	Currency           string             `gorm:"type:varchar(3);not null;default:'BDT'" json:"currency"`
+	ExpiresAt          time.Time          `gorm:"index" json:"expiresAt"` // Payment deadline for pending bookings
+	ConfirmedAt        *time.Time         `json:"confirmedAt"`
+	CheckedInAt        *time.Time         `json:"checkedInAt"`
+	ExpiredAt          *time.Time         `json:"expiredAt"`
+	NoShowAt           *time.Time         `json:"noShowAt"`
+	SeatsReleasedAt    *time.Time         `gorm:"index" json:"seatsReleasedAt"`              // Set once bus-service has made the seats available again
+	CorporateAccountID *uint              `gorm:"index" json:"corporateAccountID,omitempty"` // Billed through the account's monthly statement instead of paid
+	DiscountAmount     float64            `gorm:"type:numeric(12,2);not null;default:0" json:"discountAmount"`
+	ApprovalStatus     ApprovalStatus     `gorm:"type:varchar(20)" json:"approvalStatus,omitempty"`
+	ApprovedBy         *uint              `json:"approvedBy,omitempty"`
+	ApprovedAt         *time.Time         `json:"approvedAt,omitempty"`
+	Passengers         []BookingPassenger `gorm:"foreignKey:BookingID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"passengers"`
+	Fees               []BookingFee       `gorm:"foreignKey:BookingID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"fees"`
+}
+
+// TableName specifies the table name for Booking.
+func (Booking) TableName() string {
+	return "bookings"
+}
+
+// BookingPassenger represents a single traveller and the seat held for them within a Booking.
+type BookingPassenger struct {
+	gorm.Model
+	BookingID  uint       `gorm:"not null;index" json:"bookingID"`
+	SeatID     uint       `gorm:"not null;index" json:"seatID"` // Foreign key for Seat in bus-service
+	SeatNumber string     `gorm:"type:varchar(50)" json:"seatNumber"`
+	FullName   string     `gorm:"type:varchar(255);not null" json:"fullName"`
+	Fare       float64    `gorm:"type:numeric(12,2);not null;default:0" json:"fare"`
+	BoardedAt  *time.Time `json:"boardedAt"`
+	NoShow     bool       `gorm:"not null;default:false" json:"noShow"`
+}
+
+// TableName specifies the table name for BookingPassenger.
+func (BookingPassenger) TableName() string {
+	return "booking_passengers"
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CorporateMemberRole defines what a member may do on behalf of a corporate account.
+type CorporateMemberRole string
+
+// ApprovalStatus tracks the approval of a corporate booking.
+type ApprovalStatus string
+
+// CorporateStatementStatus tracks the payment of a monthly statement.
+type CorporateStatementStatus string
+
+const (
+	// CorporateRoleTraveller may book travel that is billed to the account.
+	CorporateRoleTraveller CorporateMemberRole = "traveller"
+	// CorporateRoleApprover may book travel and approve bookings of other members.
+	CorporateRoleApprover CorporateMemberRole = "approver"
+
+	// ApprovalNotRequired is set on corporate bookings within the approval threshold.
+	ApprovalNotRequired ApprovalStatus = "not_required"
+	// ApprovalPending is set on corporate bookings waiting for an approver.
+	ApprovalPending ApprovalStatus = "pending"
+	// ApprovalApproved is set on corporate bookings accepted by an approver.
+	ApprovalApproved ApprovalStatus = "approved"
+	// ApprovalRejected is set on corporate bookings refused by an approver.
+	ApprovalRejected ApprovalStatus = "rejected"
+
+	// StatementIssued is a statement sent to the company and awaiting payment.
+	StatementIssued CorporateStatementStatus = "issued"
+	// StatementPaid is a statement the company has settled.
+	StatementPaid CorporateStatementStatus = "paid"
+)
+
+// CorporateAccount is a company whose employees book travel that is billed monthly instead of paid per booking.
+type CorporateAccount struct {
+	gorm.Model
+	Name                 string            `gorm:"type:varchar(255);not null;uniqueIndex" json:"name"`
+	BillingEmail         string            `gorm:"type:varchar(255);not null" json:"billingEmail"`
+	BillingAddress       string            `gorm:"type:text" json:"billingAddress"`
+	BillingRegion        string            `gorm:"type:varchar(100)" json:"billingRegion"`
+	TaxID                string            `gorm:"type:varchar(100)" json:"taxID"`
+	Currency             string            `gorm:"type:varchar(3);not null;default:'BDT'" json:"currency"`
+	DiscountRate         float64           `gorm:"type:numeric(5,2);not null;default:0" json:"discountRate"`          // Negotiated discount in percent
+	MonthlySpendingLimit float64           `gorm:"type:numeric(12,2);not null;default:0" json:"monthlySpendingLimit"` // 0 means no limit
+	ApprovalThreshold    float64           `gorm:"type:numeric(12,2);not null;default:0" json:"approvalThreshold"`    // Bookings above this amount need approval; 0 means every booking does
+	RequireApproval      bool              `gorm:"not null;default:false" json:"requireApproval"`
+	Active               bool              `gorm:"not null;default:true" json:"active"`
+	Members              []CorporateMember `gorm:"foreignKey:CorporateAccountID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"members"`
+}
+
+// TableName specifies the table name for CorporateAccount.
+func (CorporateAccount) TableName() string {
+	return "corporate_accounts"
+}
+
+// CorporateMember links a user from auth-service to the corporate account that pays for their travel.
+// A user belongs to at most one corporate account.
+type CorporateMember struct {
+	gorm.Model
+	CorporateAccountID uint                `gorm:"not null;index" json:"corporateAccountID"`
+	UserID             uint                `gorm:"not null;uniqueIndex" json:"userID"` // Foreign key for User in auth-service
+	Role               CorporateMemberRole `gorm:"type:varchar(50);not null;default:'traveller'" json:"role"`
+}
+
+// TableName specifies the table name for CorporateMember.
+func (CorporateMember) TableName() string {
+	return "corporate_members"
+}
+
+// CorporateStatement is the consolidated bill of a corporate account for one calendar month.
+type CorporateStatement struct {
+	gorm.Model
+	CorporateAccountID uint                     `gorm:"not null;uniqueIndex:idx_statement_account_period" json:"corporateAccountID"`
+	Number             string                   `gorm:"type:varchar(50);not null;uniqueIndex" json:"number"`
+	PeriodStart        time.Time                `gorm:"not null;uniqueIndex:idx_statement_account_period" json:"periodStart"`
+	PeriodEnd          time.Time                `gorm:"not null" json:"periodEnd"`
+	Currency           string                   `gorm:"type:varchar(3);not null" json:"currency"`
+	GrossTotal         float64                  `gorm:"type:numeric(12,2);not null" json:"grossTotal"`
+	DiscountTotal      float64                  `gorm:"type:numeric(12,2);not null" json:"discountTotal"`
+	AmountDue          float64                  `gorm:"type:numeric(12,2);not null" json:"amountDue"`
+	Status             CorporateStatementStatus `gorm:"type:varchar(20);not null;default:'issued'" json:"status"`
+	IssuedAt           time.Time                `gorm:"not null" json:"issuedAt"`
+	PaidAt             *time.Time               `json:"paidAt"`
+	Lines              []CorporateStatementLine `gorm:"foreignKey:StatementID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"lines"`
+}
+
+// TableName specifies the table name for CorporateStatement.
+func (CorporateStatement) TableName() string {
+	return "corporate_statements"
+}
+
+// CorporateStatementLine is a single booking billed on a corporate statement.
+type CorporateStatementLine struct {
+	gorm.Model
+	StatementID    uint      `gorm:"not null;index" json:"statementID"`
+	BookingID      uint      `gorm:"not null;uniqueIndex" json:"bookingID"` // A booking is billed on one statement only
+	UserID         uint      `gorm:"not null;index" json:"userID"`
+	DepartureTime  time.Time `json:"departureTime"`
+	GrossAmount    float64   `gorm:"type:numeric(12,2);not null" json:"grossAmount"`
+	DiscountAmount float64   `gorm:"type:numeric(12,2);not null" json:"discountAmount"`
+	NetAmount      float64   `gorm:"type:numeric(12,2);not null" json:"netAmount"`
+}
+
+// TableName specifies the table name for CorporateStatementLine.
+func (CorporateStatementLine) TableName() string {
+	return "corporate_statement_lines"
+}
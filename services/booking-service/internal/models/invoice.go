@@ -0,0 +1,146 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// InvoiceType distinguishes invoices from the credit notes that reverse them.
+type InvoiceType string
+
+// InvoiceLineKind tells what an invoice line is charging for.
+type InvoiceLineKind string
+
+const (
+	// InvoiceTypeInvoice is a regular invoice issued for a paid booking.
+	InvoiceTypeInvoice InvoiceType = "invoice"
+	// InvoiceTypeCreditNote reverses all or part of an invoice, e.g. after a refund.
+	InvoiceTypeCreditNote InvoiceType = "credit_note"
+
+	// LineKindFare is the fare of a single passenger.
+	LineKindFare InvoiceLineKind = "fare"
+	// LineKindFee is a booking level fee such as a service or payment fee.
+	LineKindFee InvoiceLineKind = "fee"
+	// LineKindDiscount is a negative line such as a negotiated corporate discount.
+	LineKindDiscount InvoiceLineKind = "discount"
+)
+
+// ErrInvoiceImmutable is returned when trying to change or delete an issued invoice.
+var ErrInvoiceImmutable = errors.New("issued invoices and credit notes are immutable")
+
+// Invoice is a legally numbered invoice or credit note. Once created it is never updated or deleted;
+// corrections are made by issuing a credit note that references the original invoice. The only exception is
+// the erasure of a deleted account, which replaces the buyer's personal data and keeps the amounts.
+type Invoice struct {
+	ID                uint          `gorm:"primaryKey" json:"id"`
+	CreatedAt         time.Time     `json:"createdAt"`
+	Number            string        `gorm:"type:varchar(50);not null;uniqueIndex" json:"number"`
+	Type              InvoiceType   `gorm:"type:varchar(20);not null;index" json:"type"`
+	BookingID         uint          `gorm:"not null;index;uniqueIndex:idx_invoice_booking_invoice,where:type = 'invoice'" json:"bookingID"` // A booking has one invoice
+	UserID            uint          `gorm:"not null;index" json:"userID"`
+	OriginalInvoiceID *uint         `gorm:"index" json:"originalInvoiceID,omitempty"` // Set on credit notes
+	Reason            string        `gorm:"type:text" json:"reason,omitempty"`
+	IssuedAt          time.Time     `gorm:"not null" json:"issuedAt"`
+	BillingName       string        `gorm:"type:varchar(255);not null" json:"billingName"`
+	BillingAddress    string        `gorm:"type:text" json:"billingAddress"`
+	BillingRegion     string        `gorm:"type:varchar(100)" json:"billingRegion"`
+	BillingTaxID      string        `gorm:"type:varchar(100)" json:"billingTaxID"`
+	Currency          string        `gorm:"type:varchar(3);not null" json:"currency"`
+	Subtotal          float64       `gorm:"type:numeric(12,2);not null" json:"subtotal"`
+	TaxTotal          float64       `gorm:"type:numeric(12,2);not null" json:"taxTotal"`
+	Total             float64       `gorm:"type:numeric(12,2);not null" json:"total"`
+	Lines             []InvoiceLine `gorm:"foreignKey:InvoiceID" json:"lines"`
+}
+
+// TableName specifies the table name for Invoice.
+func (Invoice) TableName() string {
+	return "invoices"
+}
+
+// BeforeUpdate rejects any update of an issued invoice.
+func (i *Invoice) BeforeUpdate(tx *gorm.DB) error {
+	return ErrInvoiceImmutable
+}
+
+// BeforeDelete rejects deleting an issued invoice.
+func (i *Invoice) BeforeDelete(tx *gorm.DB) error {
+	return ErrInvoiceImmutable
+}
+
+// InvoiceLine is a single charged item of an invoice, with the tax rate that applied when it was issued.
+type InvoiceLine struct {
+	ID             uint            `gorm:"primaryKey" json:"id"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	InvoiceID      uint            `gorm:"not null;index" json:"invoiceID"`
+	Kind           InvoiceLineKind `gorm:"type:varchar(20);not null" json:"kind"`
+	Description    string          `gorm:"type:varchar(255);not null" json:"description"`
+	PassengerID    *uint           `gorm:"index" json:"passengerID,omitempty"`
+	CreditedLineID *uint           `gorm:"uniqueIndex:idx_invoice_line_credited_line" json:"creditedLineID,omitempty"` // Line of the original invoice reversed by this credit note line, at most once
+	Quantity       int             `gorm:"not null" json:"quantity"`
+	UnitAmount     float64         `gorm:"type:numeric(12,2);not null" json:"unitAmount"`
+	NetAmount      float64         `gorm:"type:numeric(12,2);not null" json:"netAmount"`
+	TaxName        string          `gorm:"type:varchar(100)" json:"taxName"`
+	TaxRate        float64         `gorm:"type:numeric(6,3);not null" json:"taxRate"` // Percentage, e.g. 15 for 15%
+	TaxAmount      float64         `gorm:"type:numeric(12,2);not null" json:"taxAmount"`
+	GrossAmount    float64         `gorm:"type:numeric(12,2);not null" json:"grossAmount"`
+}
+
+// TableName specifies the table name for InvoiceLine.
+func (InvoiceLine) TableName() string {
+	return "invoice_lines"
+}
+
+// BeforeUpdate rejects any update of an issued invoice line.
+func (l *InvoiceLine) BeforeUpdate(tx *gorm.DB) error {
+	return ErrInvoiceImmutable
+}
+
+// BeforeDelete rejects deleting an issued invoice line.
+func (l *InvoiceLine) BeforeDelete(tx *gorm.DB) error {
+	return ErrInvoiceImmutable
+}
+
+// InvoiceSequence holds the next number of a numbering series, e.g. "INV-2026".
+// Numbers are allocated in the same transaction as the invoice so that the series has no gaps.
+type InvoiceSequence struct {
+	Series    string `gorm:"type:varchar(50);primaryKey"`
+	NextValue int64  `gorm:"not null"`
+	UpdatedAt time.Time
+}
+
+// TableName specifies the table name for InvoiceSequence.
+func (InvoiceSequence) TableName() string {
+	return "invoice_sequences"
+}
+
+// TaxRate is a configurable tax applied to invoice lines. A rate scoped to a route wins over one scoped
+// to a region, which wins over the default rate that has neither.
+type TaxRate struct {
+	gorm.Model
+	Name      string     `gorm:"type:varchar(100);not null" json:"name"`
+	Rate      float64    `gorm:"type:numeric(6,3);not null" json:"rate"` // Percentage, e.g. 15 for 15%
+	RouteID   *uint      `gorm:"index" json:"routeID,omitempty"`
+	Region    string     `gorm:"type:varchar(100);index" json:"region,omitempty"`
+	ValidFrom time.Time  `gorm:"not null" json:"validFrom"`
+	ValidTo   *time.Time `json:"validTo,omitempty"`
+}
+
+// TableName specifies the table name for TaxRate.
+func (TaxRate) TableName() string {
+	return "tax_rates"
+}
+
+// BookingFee is a booking level charge that is invoiced next to the passenger fares.
+type BookingFee struct {
+	gorm.Model
+	BookingID   uint    `gorm:"not null;index" json:"bookingID"`
+	Description string  `gorm:"type:varchar(255);not null" json:"description"`
+	Amount      float64 `gorm:"type:numeric(12,2);not null" json:"amount"`
+}
+
+// TableName specifies the table name for BookingFee.
+func (BookingFee) TableName() string {
+	return "booking_fees"
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReviewStatus defines whether a review is visible to other passengers.
+type ReviewStatus string
+
+const (
+	// ReviewPublished is a review shown publicly and counted in the ratings.
+	ReviewPublished ReviewStatus = "published"
+	// ReviewHidden is a review removed by a moderator; it is kept but no longer shown or counted.
+	ReviewHidden ReviewStatus = "hidden"
+)
+
+// Review is the rating a passenger gives a trip after travelling on it. A booking can be reviewed once.
+type Review struct {
+	gorm.Model
+	BookingID          uint         `gorm:"not null;uniqueIndex" json:"bookingID"`
+	UserID             uint         `gorm:"not null;index" json:"userID"`                    // Foreign key for User in auth-service
+	BusID              uint         `gorm:"not null;index" json:"busID"`                     // Foreign key for Bus in bus-service
+	BusCode            string       `gorm:"type:varchar(100);not null;index" json:"busCode"` // Code of the bus in bus-service, ratings of a bus are keyed by it
+	RouteID            uint         `gorm:"not null;index" json:"routeID"`
+	TripRating         int          `gorm:"not null" json:"tripRating"` // 1 to 5
+	DriverRating       int          `gorm:"not null" json:"driverRating"`
+	BusConditionRating int          `gorm:"not null" json:"busConditionRating"`
+	Comment            string       `gorm:"type:text" json:"comment"`
+	Status             ReviewStatus `gorm:"type:varchar(20);not null;default:'published';index" json:"status"`
+	ModeratedBy        *uint        `json:"moderatedBy,omitempty"`
+	ModeratedAt        *time.Time   `json:"moderatedAt,omitempty"`
+	ModerationReason   string       `gorm:"type:text" json:"moderationReason,omitempty"`
+}
+
+// TableName specifies the table name for Review.
+func (Review) TableName() string {
+	return "reviews"
+}
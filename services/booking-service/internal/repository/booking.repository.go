@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"booking-service/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IBookingRepository defines the interface for booking repository operations.
+type IBookingRepository interface {
+	GetBookingByID(id uint) (*models.Booking, error)
+	ExpirePendingBookings(now time.Time, limit int) ([]models.Booking, error)
+	MarkNoShows(departedBefore, now time.Time, limit int) ([]models.Booking, error)
+	FindBookingsWithUnreleasedSeats(limit int) ([]models.Booking, error)
+	MarkSeatsReleased(bookingID uint, releasedAt time.Time) error
+	FindManifestBookings(busID uint, departureTime time.Time, userID uint) ([]models.Booking, error)
+}
+
+// BookingRepository is a GORM-based implementation of IBookingRepository.
+type BookingRepository struct {
+	db *gorm.DB
+}
+
+// NewBookingRepository creates a new instance of BookingRepository.
+func NewBookingRepository(db *gorm.DB) IBookingRepository {
+	return &BookingRepository{db: db}
+}
+
+// GetBookingByID retrieves a booking with its passengers and fees.
+func (r *BookingRepository) GetBookingByID(id uint) (*models.Booking, error) {
+	var booking models.Booking
+	if err := r.db.Preload("Passengers").Preload("Fees").First(&booking, id).Error; err != nil {
+		return nil, err
+	}
+	return &booking, nil
+}
+
+// ExpirePendingBookings moves pending bookings whose payment deadline has passed to the expired state
+// and returns the bookings it changed. Rows locked by another transaction are skipped.
+func (r *BookingRepository) ExpirePendingBookings(now time.Time, limit int) ([]models.Booking, error) {
+	var bookings []models.Booking
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND expires_at < ?", models.BookingPending, now).
+			Order("expires_at").
+			Limit(limit).
+			Find(&bookings).Error; err != nil {
+			return err
+		}
+		if len(bookings) == 0 {
+			return nil
+		}
+
+		ids := bookingIDs(bookings)
+		if err := tx.Model(&models.Booking{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"status":     models.BookingExpired,
+			"expired_at": now,
+		}).Error; err != nil {
+			return err
+		}
+		for i := range bookings {
+			bookings[i].Status = models.BookingExpired
+			bookings[i].ExpiredAt = &now
+		}
+		return nil
+	})
+	return bookings, err
+}
+
+// MarkNoShows moves confirmed bookings that departed before departedBefore without boarding to the no-show state,
+// flags their passengers as no-shows and returns the bookings it changed.
+func (r *BookingRepository) MarkNoShows(departedBefore, now time.Time, limit int) ([]models.Booking, error) {
+	var bookings []models.Booking
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND departure_time < ?", models.BookingConfirmed, departedBefore).
+			Order("departure_time").
+			Limit(limit).
+			Find(&bookings).Error; err != nil {
+			return err
+		}
+		if len(bookings) == 0 {
+			return nil
+		}
+
+		ids := bookingIDs(bookings)
+		if err := tx.Model(&models.Booking{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"status":     models.BookingNoShow,
+			"no_show_at": now,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.BookingPassenger{}).
+			Where("booking_id IN ? AND boarded_at IS NULL", ids).
+			Update("no_show", true).Error; err != nil {
+			return err
+		}
+		for i := range bookings {
+			bookings[i].Status = models.BookingNoShow
+			bookings[i].NoShowAt = &now
+		}
+		return nil
+	})
+	return bookings, err
+}
+
+// FindBookingsWithUnreleasedSeats retrieves expired or cancelled bookings whose seats are still held in bus-service.
+func (r *BookingRepository) FindBookingsWithUnreleasedSeats(limit int) ([]models.Booking, error) {
+	var bookings []models.Booking
+	err := r.db.Preload("Passengers").
+		Where("status IN ? AND seats_released_at IS NULL", []models.BookingStatus{models.BookingExpired, models.BookingCancelled}).
+		Order("updated_at").
+		Limit(limit).
+		Find(&bookings).Error
+	return bookings, err
+}
+
+// MarkSeatsReleased records that the seats of a booking were made available again.
+func (r *BookingRepository) MarkSeatsReleased(bookingID uint, releasedAt time.Time) error {
+	return r.db.Model(&models.Booking{}).Where("id = ?", bookingID).Update("seats_released_at", releasedAt).Error
+}
+
+// FindManifestBookings retrieves the confirmed and checked-in bookings of a departure of a bus with their
+// passengers, only those made by userID unless it is 0.
+func (r *BookingRepository) FindManifestBookings(busID uint, departureTime time.Time, userID uint) ([]models.Booking, error) {
+	query := r.db.Preload("Passengers").
+		Where("bus_id = ? AND departure_time = ? AND status IN ?", busID, departureTime,
+			[]models.BookingStatus{models.BookingConfirmed, models.BookingCheckedIn})
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	var bookings []models.Booking
+	err := query.Order("id").Find(&bookings).Error
+	return bookings, err
+}
+
+func bookingIDs(bookings []models.Booking) []uint {
+	ids := make([]uint, len(bookings))
+	for i, booking := range bookings {
+		ids[i] = booking.ID
+	}
+	return ids
+}
@@ -0,0 +1,259 @@
+package repository
+
+import (
+	"booking-service/internal/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrSpendingLimitExceeded is returned when charging a booking would exceed the monthly spending limit of an account.
+	ErrSpendingLimitExceeded = errors.New("booking exceeds the monthly spending limit of the corporate account")
+	// ErrBookingNotPending is returned when a booking changed state before it could be charged to an account.
+	ErrBookingNotPending = errors.New("booking is no longer pending")
+	// ErrBookingNotAwaitingApproval is returned when a booking was decided or expired before a decision could be stored.
+	ErrBookingNotAwaitingApproval = errors.New("booking is no longer waiting for approval")
+	// ErrStatementNotIssued is returned when a statement was paid before its payment could be recorded.
+	ErrStatementNotIssued = errors.New("statement is no longer awaiting payment")
+)
+
+// billableStatuses are the booking statuses that are billed on a corporate statement.
+var billableStatuses = []models.BookingStatus{models.BookingConfirmed, models.BookingCheckedIn, models.BookingNoShow}
+
+// ICorporateRepository defines the interface for corporate account repository operations.
+type ICorporateRepository interface {
+	CreateAccount(account *models.CorporateAccount) error
+	GetAccountByID(id uint) (*models.CorporateAccount, error)
+	ListAccounts(activeOnly bool) ([]models.CorporateAccount, error)
+	UpdateAccount(account *models.CorporateAccount) error
+	AddMember(member *models.CorporateMember) error
+	RemoveMember(accountID, userID uint) error
+	GetMember(accountID, userID uint) (*models.CorporateMember, error)
+	GetSpend(accountID uint, from, to time.Time) (float64, error)
+	ChargeBooking(booking *models.Booking, spendingLimit float64, from, to time.Time) error
+	UpdateBookingApproval(booking *models.Booking) error
+	CreateStatement(statement *models.CorporateStatement, series string, from, to time.Time) error
+	GetStatementByID(id uint) (*models.CorporateStatement, error)
+	GetStatementByPeriod(accountID uint, periodStart time.Time) (*models.CorporateStatement, error)
+	ListStatements(accountID uint) ([]models.CorporateStatement, error)
+	MarkStatementPaid(id uint, paidAt time.Time) error
+}
+
+// CorporateRepository is a GORM-based implementation of ICorporateRepository.
+type CorporateRepository struct {
+	db *gorm.DB
+}
+
+// NewCorporateRepository creates a new instance of CorporateRepository.
+func NewCorporateRepository(db *gorm.DB) ICorporateRepository {
+	return &CorporateRepository{db: db}
+}
+
+// CreateAccount inserts a new corporate account into the database.
+func (r *CorporateRepository) CreateAccount(account *models.CorporateAccount) error {
+	return r.db.Create(account).Error
+}
+
+// GetAccountByID retrieves a corporate account with its members.
+func (r *CorporateRepository) GetAccountByID(id uint) (*models.CorporateAccount, error) {
+	var account models.CorporateAccount
+	if err := r.db.Preload("Members").First(&account, id).Error; err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// ListAccounts retrieves all corporate accounts, optionally only the active ones.
+func (r *CorporateRepository) ListAccounts(activeOnly bool) ([]models.CorporateAccount, error) {
+	var accounts []models.CorporateAccount
+	query := r.db.Order("name")
+	if activeOnly {
+		query = query.Where("active = ?", true)
+	}
+	err := query.Find(&accounts).Error
+	return accounts, err
+}
+
+// UpdateAccount saves the terms of a corporate account. Members are not touched.
+func (r *CorporateRepository) UpdateAccount(account *models.CorporateAccount) error {
+	return r.db.Omit("Members").Save(account).Error
+}
+
+// AddMember links a user to a corporate account.
+func (r *CorporateRepository) AddMember(member *models.CorporateMember) error {
+	return r.db.Create(member).Error
+}
+
+// RemoveMember unlinks a user from a corporate account.
+func (r *CorporateRepository) RemoveMember(accountID, userID uint) error {
+	result := r.db.Unscoped().Where("corporate_account_id = ? AND user_id = ?", accountID, userID).Delete(&models.CorporateMember{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetMember retrieves the membership of a user in a corporate account.
+func (r *CorporateRepository) GetMember(accountID, userID uint) (*models.CorporateMember, error) {
+	var member models.CorporateMember
+	if err := r.db.Where("corporate_account_id = ? AND user_id = ?", accountID, userID).First(&member).Error; err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// GetSpend returns the amount committed by a corporate account in [from, to): billable bookings confirmed
+// in the period plus bookings still waiting for approval.
+func (r *CorporateRepository) GetSpend(accountID uint, from, to time.Time) (float64, error) {
+	return corporateSpend(r.db, accountID, from, to)
+}
+
+// ChargeBooking stores the corporate terms of a booking. The account row is locked while the spend is
+// checked so that concurrent bookings cannot exceed spendingLimit together; a limit of 0 means unlimited.
+func (r *CorporateRepository) ChargeBooking(booking *models.Booking, spendingLimit float64, from, to time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&models.CorporateAccount{}, *booking.CorporateAccountID).Error; err != nil {
+			return err
+		}
+		if spendingLimit > 0 {
+			spent, err := corporateSpend(tx, *booking.CorporateAccountID, from, to)
+			if err != nil {
+				return err
+			}
+			if spent+booking.TotalAmount-booking.DiscountAmount > spendingLimit {
+				return ErrSpendingLimitExceeded
+			}
+		}
+		result := tx.Model(booking).
+			Where("status = ? AND corporate_account_id IS NULL", models.BookingPending).
+			Select("corporate_account_id", "discount_amount", "approval_status", "status", "expires_at", "confirmed_at").
+			Updates(booking)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrBookingNotPending
+		}
+		return nil
+	})
+}
+
+// UpdateBookingApproval stores the outcome of an approval decision on a corporate booking. It only applies
+// while the booking is still pending approval, so a decision never overrides an expiry or another decision.
+func (r *CorporateRepository) UpdateBookingApproval(booking *models.Booking) error {
+	result := r.db.Model(booking).
+		Where("status = ? AND approval_status = ?", models.BookingPending, models.ApprovalPending).
+		Select("approval_status", "approved_by", "approved_at", "status", "confirmed_at").
+		Updates(booking)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrBookingNotAwaitingApproval
+	}
+	return nil
+}
+
+// CreateStatement numbers a statement and bills on it every billable booking of the account confirmed in
+// [from, to) that is not on a statement yet. It returns gorm.ErrRecordNotFound when there is nothing to bill.
+func (r *CorporateRepository) CreateStatement(statement *models.CorporateStatement, series string, from, to time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var bookings []models.Booking
+		if err := tx.Where("corporate_account_id = ? AND status IN ? AND approval_status IN ?",
+			statement.CorporateAccountID, billableStatuses, []models.ApprovalStatus{models.ApprovalNotRequired, models.ApprovalApproved}).
+			Where("confirmed_at >= ? AND confirmed_at < ?", from, to).
+			Where("NOT EXISTS (SELECT 1 FROM corporate_statement_lines l WHERE l.booking_id = bookings.id AND l.deleted_at IS NULL)").
+			Order("confirmed_at").
+			Find(&bookings).Error; err != nil {
+			return err
+		}
+		if len(bookings) == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		var gross, discount float64
+		for _, booking := range bookings {
+			statement.Lines = append(statement.Lines, models.CorporateStatementLine{
+				BookingID:      booking.ID,
+				UserID:         booking.UserID,
+				DepartureTime:  booking.DepartureTime,
+				GrossAmount:    booking.TotalAmount,
+				DiscountAmount: booking.DiscountAmount,
+				NetAmount:      booking.TotalAmount - booking.DiscountAmount,
+			})
+			gross += booking.TotalAmount
+			discount += booking.DiscountAmount
+		}
+		statement.GrossTotal = gross
+		statement.DiscountTotal = discount
+		statement.AmountDue = gross - discount
+
+		number, err := nextSequenceValue(tx, series)
+		if err != nil {
+			return err
+		}
+		statement.Number = formatSequenceNumber(series, number)
+		return tx.Create(statement).Error
+	})
+}
+
+// GetStatementByID retrieves a statement with its lines.
+func (r *CorporateRepository) GetStatementByID(id uint) (*models.CorporateStatement, error) {
+	var statement models.CorporateStatement
+	if err := r.db.Preload("Lines").First(&statement, id).Error; err != nil {
+		return nil, err
+	}
+	return &statement, nil
+}
+
+// GetStatementByPeriod retrieves the statement of an account for the month starting at periodStart, or nil.
+func (r *CorporateRepository) GetStatementByPeriod(accountID uint, periodStart time.Time) (*models.CorporateStatement, error) {
+	var statement models.CorporateStatement
+	err := r.db.Where("corporate_account_id = ? AND period_start = ?", accountID, periodStart).First(&statement).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &statement, nil
+}
+
+// ListStatements retrieves the statements of an account, newest first.
+func (r *CorporateRepository) ListStatements(accountID uint) ([]models.CorporateStatement, error) {
+	var statements []models.CorporateStatement
+	err := r.db.Where("corporate_account_id = ?", accountID).Order("period_start DESC").Find(&statements).Error
+	return statements, err
+}
+
+// MarkStatementPaid records the payment of a statement.
+func (r *CorporateRepository) MarkStatementPaid(id uint, paidAt time.Time) error {
+	result := r.db.Model(&models.CorporateStatement{}).
+		Where("id = ? AND status = ?", id, models.StatementIssued).
+		Updates(map[string]interface{}{"status": models.StatementPaid, "paid_at": paidAt})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrStatementNotIssued
+	}
+	return nil
+}
+
+func corporateSpend(db *gorm.DB, accountID uint, from, to time.Time) (float64, error) {
+	var spent float64
+	err := db.Model(&models.Booking{}).
+		Select("COALESCE(SUM(total_amount - discount_amount), 0)").
+		Where("corporate_account_id = ?", accountID).
+		Where("((status IN ? AND confirmed_at >= ? AND confirmed_at < ?) OR (status = ? AND approval_status = ?))",
+			billableStatuses, from, to, models.BookingPending, models.ApprovalPending).
+		Scan(&spent).Error
+	return spent, err
+}
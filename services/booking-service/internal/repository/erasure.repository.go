@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"booking-service/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// erasedName replaces the names of passengers and buyers of erased accounts.
+	erasedName = "Erased user"
+	// erasedFareDescription replaces the description of fare lines naming a passenger of an erased account.
+	erasedFareDescription = "Fare for an erased passenger"
+)
+
+// IErasureRepository defines the interface for erasing the data of erased accounts.
+type IErasureRepository interface {
+	EraseUserData(userID uint) error
+}
+
+// ErasureRepository is a GORM-based implementation of IErasureRepository.
+type ErasureRepository struct {
+	db *gorm.DB
+}
+
+// NewErasureRepository creates a new instance of ErasureRepository.
+func NewErasureRepository(db *gorm.DB) IErasureRepository {
+	return &ErasureRepository{db: db}
+}
+
+// EraseUserData anonymises the bookings, invoices, reviews and corporate statement lines of a user, and
+// removes their corporate memberships. Amounts, dates and invoice numbers are kept as financial records must
+// be; names, addresses, tax IDs and review comments are removed, and the records no longer point at the user.
+func (r *ErasureRepository) EraseUserData(userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		bookings := tx.Unscoped().Model(&models.Booking{}).Select("id").Where("user_id = ?", userID)
+		invoices := tx.Model(&models.Invoice{}).Select("id").Where("user_id = ?", userID)
+
+		if err := tx.Unscoped().Model(&models.BookingPassenger{}).Where("booking_id IN (?)", bookings).
+			Update("full_name", erasedName).Error; err != nil {
+			return err
+		}
+
+		// Issued invoices are otherwise immutable; only the personal data on them is replaced
+		immutable := tx.Session(&gorm.Session{SkipHooks: true})
+		if err := immutable.Model(&models.InvoiceLine{}).
+			Where("invoice_id IN (?) AND passenger_id IS NOT NULL", invoices).
+			Update("description", gorm.Expr("CASE WHEN credited_line_id IS NULL THEN ? ELSE ? END",
+				erasedFareDescription, "Credit: "+erasedFareDescription)).Error; err != nil {
+			return err
+		}
+		if err := immutable.Model(&models.Invoice{}).Where("user_id = ?", userID).
+			Updates(map[string]interface{}{
+				"billing_name":    erasedName,
+				"billing_address": "",
+				"billing_tax_id":  "",
+				"user_id":         0,
+			}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Model(&models.Review{}).Where("user_id = ?", userID).
+			Updates(map[string]interface{}{"comment": "", "user_id": 0}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&models.CorporateStatementLine{}).Where("user_id = ?", userID).
+			Update("user_id", 0).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.CorporateMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&models.Booking{}).Where("user_id = ?", userID).Update("user_id", 0).Error
+	})
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"booking-service/internal/models"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// UserData is everything booking-service holds about a user.
+type UserData struct {
+	Bookings   []models.Booking
+	Invoices   []models.Invoice
+	Reviews    []models.Review
+	Membership *models.CorporateMember // Nil when the user does not travel for a corporate account
+}
+
+// IExportRepository defines the interface for exporting the data of a user.
+type IExportRepository interface {
+	FindUserData(userID uint) (*UserData, error)
+}
+
+// ExportRepository is a GORM-based implementation of IExportRepository.
+type ExportRepository struct {
+	db *gorm.DB
+}
+
+// NewExportRepository creates a new instance of ExportRepository.
+func NewExportRepository(db *gorm.DB) IExportRepository {
+	return &ExportRepository{db: db}
+}
+
+// FindUserData retrieves the bookings with their passengers and fees, the invoices and credit notes with their
+// lines, the reviews and the corporate membership of a user, oldest first.
+func (r *ExportRepository) FindUserData(userID uint) (*UserData, error) {
+	var data UserData
+	if err := r.db.Preload("Passengers").Preload("Fees").Where("user_id = ?", userID).
+		Order("created_at, id").Find(&data.Bookings).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Where("user_id = ?", userID).Order("issued_at, id").Find(&data.Invoices).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Where("user_id = ?", userID).Order("created_at, id").Find(&data.Reviews).Error; err != nil {
+		return nil, err
+	}
+
+	var member models.CorporateMember
+	if err := r.db.Where("user_id = ?", userID).First(&member).Error; err == nil {
+		data.Membership = &member
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	return &data, nil
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"booking-service/internal/models"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IInvoiceRepository defines the interface for invoice repository operations.
+type IInvoiceRepository interface {
+	CreateInvoice(invoice *models.Invoice, series string) error
+	GetInvoiceByID(id uint) (*models.Invoice, error)
+	GetInvoiceByBookingID(bookingID uint) (*models.Invoice, error)
+	ListInvoicesByUserID(userID uint, offset, limit int) ([]models.Invoice, error)
+	GetCreditedLineIDs(originalInvoiceID uint) ([]uint, error)
+}
+
+// InvoiceRepository is a GORM-based implementation of IInvoiceRepository.
+type InvoiceRepository struct {
+	db *gorm.DB
+}
+
+// NewInvoiceRepository creates a new instance of InvoiceRepository.
+func NewInvoiceRepository(db *gorm.DB) IInvoiceRepository {
+	return &InvoiceRepository{db: db}
+}
+
+// CreateInvoice allocates the next number of the given series and stores the invoice with its lines.
+// Both happen in one transaction, so a failed insert never consumes a number.
+func (r *InvoiceRepository) CreateInvoice(invoice *models.Invoice, series string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		number, err := nextSequenceValue(tx, series)
+		if err != nil {
+			return err
+		}
+		invoice.Number = formatSequenceNumber(series, number)
+		return tx.Create(invoice).Error
+	})
+}
+
+// GetInvoiceByID retrieves an invoice or credit note with its lines.
+func (r *InvoiceRepository) GetInvoiceByID(id uint) (*models.Invoice, error) {
+	var invoice models.Invoice
+	if err := r.db.Preload("Lines").First(&invoice, id).Error; err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// GetInvoiceByBookingID retrieves the invoice issued for a booking, or nil if there is none.
+func (r *InvoiceRepository) GetInvoiceByBookingID(bookingID uint) (*models.Invoice, error) {
+	var invoice models.Invoice
+	err := r.db.Preload("Lines").
+		Where("booking_id = ? AND type = ?", bookingID, models.InvoiceTypeInvoice).
+		First(&invoice).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// ListInvoicesByUserID retrieves the invoices and credit notes of a user, newest first.
+func (r *InvoiceRepository) ListInvoicesByUserID(userID uint, offset, limit int) ([]models.Invoice, error) {
+	var invoices []models.Invoice
+	err := r.db.Preload("Lines").
+		Where("user_id = ?", userID).
+		Order("issued_at DESC, id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&invoices).Error
+	return invoices, err
+}
+
+// GetCreditedLineIDs returns the lines of an invoice that were already reversed by credit notes.
+func (r *InvoiceRepository) GetCreditedLineIDs(originalInvoiceID uint) ([]uint, error) {
+	var lineIDs []uint
+	err := r.db.Model(&models.InvoiceLine{}).
+		Joins("JOIN invoices ON invoices.id = invoice_lines.invoice_id").
+		Where("invoices.original_invoice_id = ? AND invoice_lines.credited_line_id IS NOT NULL", originalInvoiceID).
+		Pluck("invoice_lines.credited_line_id", &lineIDs).Error
+	return lineIDs, err
+}
+
+// nextSequenceValue takes the next number of a series inside tx. The series row stays locked until tx ends,
+// so concurrent callers are serialised and a rolled back transaction gives its number back.
+func nextSequenceValue(tx *gorm.DB, series string) (int64, error) {
+	// Make sure the series exists, then lock its row.
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.InvoiceSequence{Series: series, NextValue: 1}).Error; err != nil {
+		return 0, err
+	}
+	var sequence models.InvoiceSequence
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("series = ?", series).
+		First(&sequence).Error; err != nil {
+		return 0, err
+	}
+	if err := tx.Model(&models.InvoiceSequence{}).
+		Where("series = ?", series).
+		Update("next_value", sequence.NextValue+1).Error; err != nil {
+		return 0, err
+	}
+	return sequence.NextValue, nil
+}
+
+// formatSequenceNumber renders a document number such as "INV-2026-000042".
+func formatSequenceNumber(series string, value int64) string {
+	return fmt.Sprintf("%s-%06d", series, value)
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"booking-service/internal/models"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ReviewFilter narrows a review listing. Zero values are ignored.
+type ReviewFilter struct {
+	BusCode string
+	RouteID uint
+	UserID  uint
+	Status  models.ReviewStatus
+}
+
+// RatingAggregate is the average rating of a bus or route over its published reviews. Either BusCode or
+// RouteID is set, depending on what was rated.
+type RatingAggregate struct {
+	BusCode             string
+	RouteID             uint
+	ReviewCount         int64
+	AverageTrip         float64
+	AverageDriver       float64
+	AverageBusCondition float64
+	AverageOverall      float64
+}
+
+// IReviewRepository defines the interface for review repository operations.
+type IReviewRepository interface {
+	CreateReview(review *models.Review) error
+	GetReviewByID(id uint) (*models.Review, error)
+	GetReviewByBookingID(bookingID uint) (*models.Review, error)
+	ListReviews(filter ReviewFilter, offset, limit int) ([]models.Review, int64, error)
+	UpdateModeration(review *models.Review) error
+	AggregateByBus(busCodes []string) ([]RatingAggregate, error)
+	AggregateByRoute(routeIDs []uint) ([]RatingAggregate, error)
+}
+
+// ReviewRepository is a GORM-based implementation of IReviewRepository.
+type ReviewRepository struct {
+	db *gorm.DB
+}
+
+// NewReviewRepository creates a new instance of ReviewRepository.
+func NewReviewRepository(db *gorm.DB) IReviewRepository {
+	return &ReviewRepository{db: db}
+}
+
+// CreateReview inserts a new review into the database.
+func (r *ReviewRepository) CreateReview(review *models.Review) error {
+	return r.db.Create(review).Error
+}
+
+// GetReviewByID retrieves a review by its ID.
+func (r *ReviewRepository) GetReviewByID(id uint) (*models.Review, error) {
+	var review models.Review
+	if err := r.db.First(&review, id).Error; err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// GetReviewByBookingID retrieves the review of a booking, or nil if it has not been reviewed.
+func (r *ReviewRepository) GetReviewByBookingID(bookingID uint) (*models.Review, error) {
+	var review models.Review
+	err := r.db.Where("booking_id = ?", bookingID).First(&review).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// ListReviews retrieves the reviews matching filter, newest first, with the total number of matches.
+func (r *ReviewRepository) ListReviews(filter ReviewFilter, offset, limit int) ([]models.Review, int64, error) {
+	query := r.db.Model(&models.Review{})
+	if filter.BusCode != "" {
+		query = query.Where("bus_code = ?", filter.BusCode)
+	}
+	if filter.RouteID != 0 {
+		query = query.Where("route_id = ?", filter.RouteID)
+	}
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var reviews []models.Review
+	err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&reviews).Error
+	return reviews, total, err
+}
+
+// UpdateModeration stores the outcome of a moderation decision.
+func (r *ReviewRepository) UpdateModeration(review *models.Review) error {
+	return r.db.Model(review).
+		Select("status", "moderated_by", "moderated_at", "moderation_reason").
+		Updates(review).Error
+}
+
+// AggregateByBus computes the ratings of the buses with the given codes over their published reviews.
+func (r *ReviewRepository) AggregateByBus(busCodes []string) ([]RatingAggregate, error) {
+	var aggregates []RatingAggregate
+	if len(busCodes) == 0 {
+		return aggregates, nil
+	}
+	return aggregates, r.aggregate("bus_code", busCodes, &aggregates)
+}
+
+// AggregateByRoute computes the ratings of the given routes over their published reviews.
+func (r *ReviewRepository) AggregateByRoute(routeIDs []uint) ([]RatingAggregate, error) {
+	var aggregates []RatingAggregate
+	if len(routeIDs) == 0 {
+		return aggregates, nil
+	}
+	return aggregates, r.aggregate("route_id", routeIDs, &aggregates)
+}
+
+// aggregate groups the published reviews whose column is one of values, which must be a trusted column
+// name of RatingAggregate, into aggregates.
+func (r *ReviewRepository) aggregate(column string, values interface{}, aggregates *[]RatingAggregate) error {
+	return r.db.Model(&models.Review{}).
+		Select(column+", COUNT(*) AS review_count, "+
+			"AVG(trip_rating) AS average_trip, AVG(driver_rating) AS average_driver, "+
+			"AVG(bus_condition_rating) AS average_bus_condition, "+
+			"AVG((trip_rating + driver_rating + bus_condition_rating) / 3.0) AS average_overall").
+		Where(column+" IN ? AND status = ?", values, models.ReviewPublished).
+		Group(column).
+		Scan(aggregates).Error
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"booking-service/internal/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ITaxRateRepository defines the interface for tax rate repository operations.
+type ITaxRateRepository interface {
+	Create(rate *models.TaxRate) error
+	List() ([]models.TaxRate, error)
+	Delete(id uint) error
+	FindApplicable(routeID uint, region string, at time.Time) (*models.TaxRate, error)
+}
+
+// TaxRateRepository is a GORM-based implementation of ITaxRateRepository.
+type TaxRateRepository struct {
+	db *gorm.DB
+}
+
+// NewTaxRateRepository creates a new instance of TaxRateRepository.
+func NewTaxRateRepository(db *gorm.DB) ITaxRateRepository {
+	return &TaxRateRepository{db: db}
+}
+
+// Create inserts a new tax rate into the database.
+func (r *TaxRateRepository) Create(rate *models.TaxRate) error {
+	return r.db.Create(rate).Error
+}
+
+// List retrieves all configured tax rates.
+func (r *TaxRateRepository) List() ([]models.TaxRate, error) {
+	var rates []models.TaxRate
+	err := r.db.Order("valid_from DESC").Find(&rates).Error
+	return rates, err
+}
+
+// Delete removes a tax rate. Invoices keep a copy of the rate they were issued with.
+func (r *TaxRateRepository) Delete(id uint) error {
+	result := r.db.Delete(&models.TaxRate{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// FindApplicable returns the most specific tax rate valid at the given time: a rate for the route,
+// then a rate for the region, then the default rate. It returns nil when no rate is configured.
+func (r *TaxRateRepository) FindApplicable(routeID uint, region string, at time.Time) (*models.TaxRate, error) {
+	var rate models.TaxRate
+	err := r.db.
+		Where("valid_from <= ? AND (valid_to IS NULL OR valid_to > ?)", at, at).
+		Where("route_id = ? OR (route_id IS NULL AND (region = ? OR region = ''))", routeID, region).
+		Order("route_id IS NULL, region = '', valid_from DESC").
+		First(&rate).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
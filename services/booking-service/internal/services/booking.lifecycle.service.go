@@ -0,0 +1,102 @@
+package services
+
+import (
+	"booking-service/internal/repository"
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// batchSize caps the number of bookings handled per sweep so a backlog cannot hold row locks for long.
+const batchSize = 100
+
+// IBookingLifecycleService defines the background transitions of a booking that are not triggered by a user.
+type IBookingLifecycleService interface {
+	ExpirePendingBookings(ctx context.Context) error
+	ReleaseSeats(ctx context.Context) error
+	MarkNoShows(ctx context.Context) error
+}
+
+// BookingLifecycleService expires unpaid bookings, releases their seats and marks no-shows.
+type BookingLifecycleService struct {
+	bookingRepo repository.IBookingRepository
+	seatService ISeatService
+	noShowGrace time.Duration
+	now         func() time.Time
+}
+
+// NewBookingLifecycleService creates a new instance of BookingLifecycleService.
+// noShowGrace is how long after departure a confirmed booking is kept before it is marked as a no-show.
+func NewBookingLifecycleService(bookingRepo repository.IBookingRepository, seatService ISeatService, noShowGrace time.Duration) IBookingLifecycleService {
+	return &BookingLifecycleService{
+		bookingRepo: bookingRepo,
+		seatService: seatService,
+		noShowGrace: noShowGrace,
+		now:         time.Now,
+	}
+}
+
+// ExpirePendingBookings expires every pending booking past its payment deadline and then releases its seats.
+func (s *BookingLifecycleService) ExpirePendingBookings(ctx context.Context) error {
+	for ctx.Err() == nil {
+		expired, err := s.bookingRepo.ExpirePendingBookings(s.now(), batchSize)
+		if err != nil {
+			return fmt.Errorf("failed to expire pending bookings: %w", err)
+		}
+		if len(expired) > 0 {
+			log.Printf("Expired %d pending bookings", len(expired))
+		}
+		if len(expired) < batchSize {
+			break
+		}
+	}
+	return s.ReleaseSeats(ctx)
+}
+
+// ReleaseSeats makes the seats of expired and cancelled bookings available in bus-service.
+// Bookings whose seats could not all be released are retried on the next run.
+func (s *BookingLifecycleService) ReleaseSeats(ctx context.Context) error {
+	bookings, err := s.bookingRepo.FindBookingsWithUnreleasedSeats(batchSize)
+	if err != nil {
+		return fmt.Errorf("failed to find bookings with unreleased seats: %w", err)
+	}
+
+	for _, booking := range bookings {
+		if ctx.Err() != nil {
+			return nil
+		}
+		released := true
+		for _, passenger := range booking.Passengers {
+			if err := s.seatService.ReleaseSeat(booking.BusID, passenger.SeatID); err != nil {
+				log.Printf("Error releasing seat %d of booking %d: %v", passenger.SeatID, booking.ID, err)
+				released = false
+			}
+		}
+		if !released {
+			continue
+		}
+		if err := s.bookingRepo.MarkSeatsReleased(booking.ID, s.now()); err != nil {
+			return fmt.Errorf("failed to mark seats of booking %d as released: %w", booking.ID, err)
+		}
+	}
+	return nil
+}
+
+// MarkNoShows marks confirmed bookings whose bus departed more than the grace period ago as no-shows.
+func (s *BookingLifecycleService) MarkNoShows(ctx context.Context) error {
+	for ctx.Err() == nil {
+		now := s.now()
+		noShows, err := s.bookingRepo.MarkNoShows(now.Add(-s.noShowGrace), now, batchSize)
+		if err != nil {
+			return fmt.Errorf("failed to mark no-show bookings: %w", err)
+		}
+		if len(noShows) > 0 {
+			log.Printf("Marked %d bookings as no-show", len(noShows))
+		}
+		if len(noShows) < batchSize {
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"booking-service/internal/models"
+	"booking-service/internal/repository"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeLifecycleRepository hands out pages of bookings in the order the sweeps ask for them.
+type fakeLifecycleRepository struct {
+	repository.IBookingRepository
+	expirePages    [][]models.Booking
+	noShowPages    [][]models.Booking
+	unreleased     []models.Booking
+	noShowCutoffs  []time.Time
+	releasedIDs    []uint
+	expireRequests int
+}
+
+func (r *fakeLifecycleRepository) ExpirePendingBookings(now time.Time, limit int) ([]models.Booking, error) {
+	r.expireRequests++
+	return nextPage(&r.expirePages), nil
+}
+
+func (r *fakeLifecycleRepository) MarkNoShows(departedBefore, now time.Time, limit int) ([]models.Booking, error) {
+	r.noShowCutoffs = append(r.noShowCutoffs, departedBefore)
+	return nextPage(&r.noShowPages), nil
+}
+
+func (r *fakeLifecycleRepository) FindBookingsWithUnreleasedSeats(limit int) ([]models.Booking, error) {
+	return r.unreleased, nil
+}
+
+func (r *fakeLifecycleRepository) MarkSeatsReleased(bookingID uint, releasedAt time.Time) error {
+	r.releasedIDs = append(r.releasedIDs, bookingID)
+	return nil
+}
+
+func nextPage(pages *[][]models.Booking) []models.Booking {
+	if len(*pages) == 0 {
+		return nil
+	}
+	page := (*pages)[0]
+	*pages = (*pages)[1:]
+	return page
+}
+
+// fakeSeatService fails to release the seats listed in failing.
+type fakeSeatService struct {
+	failing  map[uint]bool
+	released []uint
+}
+
+func (s *fakeSeatService) UpdateSeatStatus(busID, seatID uint, status string) error {
+	return nil
+}
+
+func (s *fakeSeatService) ReleaseSeat(busID, seatID uint) error {
+	if s.failing[seatID] {
+		return errors.New("bus-service unavailable")
+	}
+	s.released = append(s.released, seatID)
+	return nil
+}
+
+func bookings(n int) []models.Booking {
+	page := make([]models.Booking, n)
+	for i := range page {
+		page[i].ID = uint(i + 1)
+	}
+	return page
+}
+
+func bookingWithSeats(id uint, seatIDs ...uint) models.Booking {
+	booking := models.Booking{BusID: 7}
+	booking.ID = id
+	for _, seatID := range seatIDs {
+		booking.Passengers = append(booking.Passengers, models.BookingPassenger{SeatID: seatID})
+	}
+	return booking
+}
+
+func TestExpirePendingBookingsSweepsFullBatches(t *testing.T) {
+	tests := []struct {
+		name         string
+		pages        [][]models.Booking
+		wantRequests int
+	}{
+		{name: "nothing to expire", pages: nil, wantRequests: 1},
+		{name: "partial batch", pages: [][]models.Booking{bookings(3)}, wantRequests: 1},
+		{name: "full batches are followed by another sweep", pages: [][]models.Booking{bookings(batchSize), bookings(batchSize), bookings(1)}, wantRequests: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeLifecycleRepository{expirePages: tt.pages}
+			service := NewBookingLifecycleService(repo, &fakeSeatService{}, time.Hour)
+
+			if err := service.ExpirePendingBookings(context.Background()); err != nil {
+				t.Fatalf("ExpirePendingBookings() error = %v", err)
+			}
+			if repo.expireRequests != tt.wantRequests {
+				t.Errorf("ExpirePendingBookings() swept %d times, want %d", repo.expireRequests, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestExpirePendingBookingsReleasesSeats(t *testing.T) {
+	repo := &fakeLifecycleRepository{
+		expirePages: [][]models.Booking{{bookingWithSeats(1, 11, 12)}},
+		unreleased:  []models.Booking{bookingWithSeats(1, 11, 12)},
+	}
+	seats := &fakeSeatService{}
+	service := NewBookingLifecycleService(repo, seats, time.Hour)
+
+	if err := service.ExpirePendingBookings(context.Background()); err != nil {
+		t.Fatalf("ExpirePendingBookings() error = %v", err)
+	}
+	if len(seats.released) != 2 {
+		t.Errorf("released seats %v, want both seats of the expired booking", seats.released)
+	}
+	if len(repo.releasedIDs) != 1 || repo.releasedIDs[0] != 1 {
+		t.Errorf("bookings marked released = %v, want [1]", repo.releasedIDs)
+	}
+}
+
+func TestReleaseSeatsRetriesBookingsWithUnreleasedSeats(t *testing.T) {
+	repo := &fakeLifecycleRepository{
+		unreleased: []models.Booking{bookingWithSeats(1, 11), bookingWithSeats(2, 21, 22)},
+	}
+	seats := &fakeSeatService{failing: map[uint]bool{22: true}}
+	service := NewBookingLifecycleService(repo, seats, time.Hour)
+
+	if err := service.ReleaseSeats(context.Background()); err != nil {
+		t.Fatalf("ReleaseSeats() error = %v", err)
+	}
+	// Booking 2 keeps its seat 22 held, so it is left for the next run
+	if len(repo.releasedIDs) != 1 || repo.releasedIDs[0] != 1 {
+		t.Errorf("bookings marked released = %v, want [1]", repo.releasedIDs)
+	}
+}
+
+func TestMarkNoShowsWaitsForTheGracePeriod(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	repo := &fakeLifecycleRepository{noShowPages: [][]models.Booking{bookings(batchSize), bookings(2)}}
+	service := &BookingLifecycleService{
+		bookingRepo: repo,
+		seatService: &fakeSeatService{},
+		noShowGrace: 30 * time.Minute,
+		now:         func() time.Time { return now },
+	}
+
+	if err := service.MarkNoShows(context.Background()); err != nil {
+		t.Fatalf("MarkNoShows() error = %v", err)
+	}
+	if len(repo.noShowCutoffs) != 2 {
+		t.Fatalf("MarkNoShows() swept %d times, want 2", len(repo.noShowCutoffs))
+	}
+	if want := now.Add(-30 * time.Minute); !repo.noShowCutoffs[0].Equal(want) {
+		t.Errorf("bookings departed before %v marked as no-show, want before %v", repo.noShowCutoffs[0], want)
+	}
+}
+
+func TestMarkNoShowsStopsWhenCancelled(t *testing.T) {
+	repo := &fakeLifecycleRepository{noShowPages: [][]models.Booking{bookings(batchSize), bookings(batchSize)}}
+	service := NewBookingLifecycleService(repo, &fakeSeatService{}, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := service.MarkNoShows(ctx); err != nil {
+		t.Fatalf("MarkNoShows() error = %v", err)
+	}
+	if len(repo.noShowCutoffs) != 0 {
+		t.Errorf("MarkNoShows() swept %d times after shutdown, want 0", len(repo.noShowCutoffs))
+	}
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"booking-service/internal/api/dto"
+	"booking-service/internal/repository"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// IBookingService defines the interface for reading bookings.
+type IBookingService interface {
+	GetBooking(id uint) (*dto.BookingResponse, error)
+}
+
+// BookingService reads bookings for their owners and support agents.
+type BookingService struct {
+	bookingRepo repository.IBookingRepository
+}
+
+// NewBookingService creates a new instance of BookingService.
+func NewBookingService(bookingRepo repository.IBookingRepository) IBookingService {
+	return &BookingService{bookingRepo: bookingRepo}
+}
+
+// GetBooking retrieves a booking with its passengers and fees.
+func (s *BookingService) GetBooking(id uint) (*dto.BookingResponse, error) {
+	booking, err := s.bookingRepo.GetBookingByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBookingNotFound
+		}
+		return nil, err
+	}
+	response := dto.ToBookingResponse(*booking)
+	return &response, nil
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// busesReadScope is the scope of the service token booking-service reads buses from bus-service with.
+const busesReadScope = "buses:read"
+
+// IBusService defines the reads booking-service makes of buses owned by bus-service.
+type IBusService interface {
+	GetBusCode(busID uint) (string, error)
+}
+
+// BusService talks to bus-service over HTTP to read buses.
+type BusService struct {
+	restyClient *resty.Client
+}
+
+// NewBusService creates a new instance of BusService.
+func NewBusService() IBusService {
+	return &BusService{
+		restyClient: newServiceClient(busesReadScope),
+	}
+}
+
+// GetBusCode retrieves the code of a bus, which identifies it to passengers and keys its ratings.
+func (s *BusService) GetBusCode(busID uint) (string, error) {
+	var result struct {
+		Success bool `json:"success"`
+		Data    struct {
+			BusCode string `json:"busCode"`
+		} `json:"data"`
+	}
+	resp, err := s.restyClient.R().
+		SetResult(&result).
+		Get(fmt.Sprintf("%s/%d", busServiceBaseURL, busID))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode() != http.StatusOK || !result.Success || result.Data.BusCode == "" {
+		return "", fmt.Errorf("bus Service responded with status code: %d", resp.StatusCode())
+	}
+	return result.Data.BusCode, nil
+}
@@ -0,0 +1,439 @@
+package services
+
+import (
+	"booking-service/internal/api/dto"
+	"booking-service/internal/config"
+	"booking-service/internal/models"
+	"booking-service/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Errors returned by the corporate service so handlers can map them to status codes.
+var (
+	ErrCorporateAccountNotFound = errors.New("corporate account not found")
+	ErrCorporateAccountInactive = errors.New("corporate account is inactive")
+	ErrNotCorporateMember       = errors.New("user is not a member of the corporate account")
+	ErrAlreadyCorporateMember   = errors.New("user already belongs to a corporate account")
+	ErrNotCorporateApprover     = errors.New("user is not an approver of the corporate account")
+	ErrSelfApproval             = errors.New("approvers cannot approve their own bookings")
+	ErrBookingNotPending        = errors.New("only pending bookings can be charged to a corporate account")
+	ErrBookingNotAwaitingReview = errors.New("booking is not waiting for approval")
+	ErrStatementNotFound        = errors.New("statement not found")
+	ErrStatementAlreadyExists   = errors.New("a statement was already issued for this period")
+	ErrNothingToBill            = errors.New("no billable bookings in this period")
+	ErrStatementAlreadyPaid     = errors.New("statement is already paid")
+	ErrInvalidPeriod            = errors.New("period must be formatted as YYYY-MM")
+	ErrSpendingLimitExceeded    = repository.ErrSpendingLimitExceeded
+)
+
+// ICorporateService defines the interface for corporate account operations.
+type ICorporateService interface {
+	CreateAccount(req dto.CreateCorporateAccountRequest) (*dto.CorporateAccountResponse, error)
+	GetAccount(id uint) (*dto.CorporateAccountResponse, error)
+	ListAccounts() ([]dto.CorporateAccountResponse, error)
+	UpdateAccount(id uint, req dto.UpdateCorporateAccountRequest) (*dto.CorporateAccountResponse, error)
+	AddMember(accountID uint, req dto.AddCorporateMemberRequest) (*dto.CorporateAccountResponse, error)
+	RemoveMember(accountID, userID uint) error
+	ChargeBooking(accountID, bookingID uint) (*dto.CorporateBookingResponse, error)
+	ApproveBooking(accountID, bookingID, approverUserID uint) (*dto.CorporateBookingResponse, error)
+	RejectBooking(accountID, bookingID, approverUserID uint) (*dto.CorporateBookingResponse, error)
+	GetSpending(accountID uint, period string) (*dto.CorporateSpendingResponse, error)
+	GenerateStatement(accountID uint, period string) (*dto.CorporateStatementResponse, error)
+	GenerateMonthlyStatements(ctx context.Context) error
+	GetStatement(id uint) (*dto.CorporateStatementResponse, error)
+	ListStatements(accountID uint) ([]dto.CorporateStatementResponse, error)
+	MarkStatementPaid(id uint) (*dto.CorporateStatementResponse, error)
+}
+
+// CorporateService manages corporate accounts, their bookings and monthly statements.
+type CorporateService struct {
+	corporateRepo   repository.ICorporateRepository
+	bookingRepo     repository.IBookingRepository
+	approvalTimeout time.Duration
+	now             func() time.Time
+}
+
+// NewCorporateService creates a new instance of CorporateService.
+func NewCorporateService(corporateRepo repository.ICorporateRepository, bookingRepo repository.IBookingRepository) ICorporateService {
+	return &CorporateService{
+		corporateRepo:   corporateRepo,
+		bookingRepo:     bookingRepo,
+		approvalTimeout: config.GetDuration("CORPORATE_APPROVAL_TIMEOUT", 24*time.Hour),
+		now:             time.Now,
+	}
+}
+
+// CreateAccount registers a new corporate account.
+func (s *CorporateService) CreateAccount(req dto.CreateCorporateAccountRequest) (*dto.CorporateAccountResponse, error) {
+	account := req.ToModel()
+	if err := s.corporateRepo.CreateAccount(&account); err != nil {
+		return nil, fmt.Errorf("failed to create corporate account: %w", err)
+	}
+	response := dto.FromCorporateAccountModel(account)
+	return &response, nil
+}
+
+// GetAccount retrieves a corporate account with its members.
+func (s *CorporateService) GetAccount(id uint) (*dto.CorporateAccountResponse, error) {
+	account, err := s.getAccount(id)
+	if err != nil {
+		return nil, err
+	}
+	response := dto.FromCorporateAccountModel(*account)
+	return &response, nil
+}
+
+// ListAccounts retrieves all corporate accounts.
+func (s *CorporateService) ListAccounts() ([]dto.CorporateAccountResponse, error) {
+	accounts, err := s.corporateRepo.ListAccounts(false)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]dto.CorporateAccountResponse, len(accounts))
+	for i, account := range accounts {
+		responses[i] = dto.FromCorporateAccountModel(account)
+	}
+	return responses, nil
+}
+
+// UpdateAccount changes the billing details or negotiated terms of a corporate account.
+// New terms only apply to bookings charged afterwards.
+func (s *CorporateService) UpdateAccount(id uint, req dto.UpdateCorporateAccountRequest) (*dto.CorporateAccountResponse, error) {
+	account, err := s.getAccount(id)
+	if err != nil {
+		return nil, err
+	}
+	req.ApplyTo(account)
+	if err := s.corporateRepo.UpdateAccount(account); err != nil {
+		return nil, fmt.Errorf("failed to update corporate account: %w", err)
+	}
+	response := dto.FromCorporateAccountModel(*account)
+	return &response, nil
+}
+
+// AddMember links a user to a corporate account so their bookings can be billed to it.
+func (s *CorporateService) AddMember(accountID uint, req dto.AddCorporateMemberRequest) (*dto.CorporateAccountResponse, error) {
+	if _, err := s.getAccount(accountID); err != nil {
+		return nil, err
+	}
+	member := models.CorporateMember{
+		CorporateAccountID: accountID,
+		UserID:             req.UserID,
+		Role:               models.CorporateMemberRole(req.Role),
+	}
+	if err := s.corporateRepo.AddMember(&member); err != nil {
+		// Users belong to a single corporate account
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrAlreadyCorporateMember
+		}
+		return nil, fmt.Errorf("failed to add member: %w", err)
+	}
+	return s.GetAccount(accountID)
+}
+
+// RemoveMember unlinks a user from a corporate account. Bookings already charged stay on the account.
+func (s *CorporateService) RemoveMember(accountID, userID uint) error {
+	if err := s.corporateRepo.RemoveMember(accountID, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotCorporateMember
+		}
+		return err
+	}
+	return nil
+}
+
+// ChargeBooking bills a pending booking of a member to the corporate account instead of asking for payment.
+// The negotiated discount is applied and the booking is confirmed, or left pending until an approver decides
+// when the account requires approval for its amount.
+func (s *CorporateService) ChargeBooking(accountID, bookingID uint) (*dto.CorporateBookingResponse, error) {
+	account, err := s.getAccount(accountID)
+	if err != nil {
+		return nil, err
+	}
+	if !account.Active {
+		return nil, ErrCorporateAccountInactive
+	}
+	booking, err := s.getBooking(bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if booking.Status != models.BookingPending || booking.CorporateAccountID != nil {
+		return nil, ErrBookingNotPending
+	}
+	if _, err := s.getMember(accountID, booking.UserID); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	booking.CorporateAccountID = &account.ID
+	booking.DiscountAmount = roundMoney(booking.TotalAmount * account.DiscountRate / 100)
+	net := booking.TotalAmount - booking.DiscountAmount
+	if account.RequireApproval && (account.ApprovalThreshold == 0 || net > account.ApprovalThreshold) {
+		// Keep the seats held while an approver decides; the expiry job releases them if nobody does.
+		booking.ApprovalStatus = models.ApprovalPending
+		booking.ExpiresAt = now.Add(s.approvalTimeout)
+	} else {
+		booking.ApprovalStatus = models.ApprovalNotRequired
+		booking.Status = models.BookingConfirmed
+		booking.ConfirmedAt = &now
+	}
+
+	from, to := monthBounds(now)
+	if err := s.corporateRepo.ChargeBooking(booking, account.MonthlySpendingLimit, from, to); err != nil {
+		if errors.Is(err, repository.ErrBookingNotPending) {
+			return nil, ErrBookingNotPending
+		}
+		return nil, err
+	}
+	response := dto.FromCorporateBookingModel(*booking)
+	return &response, nil
+}
+
+// ApproveBooking confirms a corporate booking waiting for approval.
+func (s *CorporateService) ApproveBooking(accountID, bookingID, approverUserID uint) (*dto.CorporateBookingResponse, error) {
+	return s.decide(accountID, bookingID, approverUserID, true)
+}
+
+// RejectBooking cancels a corporate booking waiting for approval; its seats are released by the scheduler.
+func (s *CorporateService) RejectBooking(accountID, bookingID, approverUserID uint) (*dto.CorporateBookingResponse, error) {
+	return s.decide(accountID, bookingID, approverUserID, false)
+}
+
+func (s *CorporateService) decide(accountID, bookingID, approverUserID uint, approve bool) (*dto.CorporateBookingResponse, error) {
+	approver, err := s.getMember(accountID, approverUserID)
+	if err != nil {
+		return nil, err
+	}
+	if approver.Role != models.CorporateRoleApprover {
+		return nil, ErrNotCorporateApprover
+	}
+	booking, err := s.getBooking(bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if booking.CorporateAccountID == nil || *booking.CorporateAccountID != accountID ||
+		booking.Status != models.BookingPending || booking.ApprovalStatus != models.ApprovalPending {
+		return nil, ErrBookingNotAwaitingReview
+	}
+	if booking.UserID == approverUserID {
+		return nil, ErrSelfApproval
+	}
+
+	now := s.now()
+	booking.ApprovedBy = &approverUserID
+	booking.ApprovedAt = &now
+	if approve {
+		booking.ApprovalStatus = models.ApprovalApproved
+		booking.Status = models.BookingConfirmed
+		booking.ConfirmedAt = &now
+	} else {
+		booking.ApprovalStatus = models.ApprovalRejected
+		booking.Status = models.BookingCancelled
+	}
+	if err := s.corporateRepo.UpdateBookingApproval(booking); err != nil {
+		if errors.Is(err, repository.ErrBookingNotAwaitingApproval) {
+			return nil, ErrBookingNotAwaitingReview
+		}
+		return nil, err
+	}
+	response := dto.FromCorporateBookingModel(*booking)
+	return &response, nil
+}
+
+// GetSpending reports how much of its monthly limit a corporate account has used in a month (YYYY-MM).
+func (s *CorporateService) GetSpending(accountID uint, period string) (*dto.CorporateSpendingResponse, error) {
+	account, err := s.getAccount(accountID)
+	if err != nil {
+		return nil, err
+	}
+	start := s.now()
+	if period != "" {
+		if start, err = time.Parse("2006-01", period); err != nil {
+			return nil, ErrInvalidPeriod
+		}
+	}
+	from, to := monthBounds(start)
+	spent, err := s.corporateRepo.GetSpend(accountID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.CorporateSpendingResponse{
+		Period: from.Format("2006-01"),
+		Spent:  roundMoney(spent),
+		Limit:  account.MonthlySpendingLimit,
+	}
+	if account.MonthlySpendingLimit > 0 {
+		remaining := roundMoney(account.MonthlySpendingLimit - spent)
+		response.Remaining = &remaining
+	}
+	return response, nil
+}
+
+// GenerateStatement issues the consolidated statement of a corporate account for a month (YYYY-MM).
+func (s *CorporateService) GenerateStatement(accountID uint, period string) (*dto.CorporateStatementResponse, error) {
+	start, err := time.Parse("2006-01", period)
+	if err != nil {
+		return nil, ErrInvalidPeriod
+	}
+	account, err := s.getAccount(accountID)
+	if err != nil {
+		return nil, err
+	}
+	statement, err := s.generateStatement(account, start)
+	if err != nil {
+		return nil, err
+	}
+	response := dto.FromCorporateStatementModel(*statement)
+	return &response, nil
+}
+
+// GenerateMonthlyStatements issues the statements of the previous month for every active account
+// that has billable bookings and no statement yet. It is run by the scheduler.
+func (s *CorporateService) GenerateMonthlyStatements(ctx context.Context) error {
+	previousMonth, _ := monthBounds(s.now())
+	previousMonth = previousMonth.AddDate(0, -1, 0)
+
+	accounts, err := s.corporateRepo.ListAccounts(true)
+	if err != nil {
+		return fmt.Errorf("failed to list corporate accounts: %w", err)
+	}
+	for i := range accounts {
+		if ctx.Err() != nil {
+			return nil
+		}
+		statement, err := s.generateStatement(&accounts[i], previousMonth)
+		switch {
+		case errors.Is(err, ErrStatementAlreadyExists), errors.Is(err, ErrNothingToBill):
+		case err != nil:
+			log.Printf("Error generating statement for corporate account %d: %v", accounts[i].ID, err)
+		default:
+			log.Printf("Issued statement %s for corporate account %d", statement.Number, accounts[i].ID)
+		}
+	}
+	return nil
+}
+
+// GetStatement retrieves a statement with its lines.
+func (s *CorporateService) GetStatement(id uint) (*dto.CorporateStatementResponse, error) {
+	statement, err := s.corporateRepo.GetStatementByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrStatementNotFound
+		}
+		return nil, err
+	}
+	response := dto.FromCorporateStatementModel(*statement)
+	return &response, nil
+}
+
+// ListStatements retrieves the statements of a corporate account.
+func (s *CorporateService) ListStatements(accountID uint) ([]dto.CorporateStatementResponse, error) {
+	statements, err := s.corporateRepo.ListStatements(accountID)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]dto.CorporateStatementResponse, len(statements))
+	for i, statement := range statements {
+		responses[i] = dto.FromCorporateStatementModel(statement)
+	}
+	return responses, nil
+}
+
+// MarkStatementPaid records that the company settled a statement.
+func (s *CorporateService) MarkStatementPaid(id uint) (*dto.CorporateStatementResponse, error) {
+	statement, err := s.GetStatement(id)
+	if err != nil {
+		return nil, err
+	}
+	if statement.Status != string(models.StatementIssued) {
+		return nil, ErrStatementAlreadyPaid
+	}
+	if err := s.corporateRepo.MarkStatementPaid(id, s.now()); err != nil {
+		if errors.Is(err, repository.ErrStatementNotIssued) {
+			return nil, ErrStatementAlreadyPaid
+		}
+		return nil, err
+	}
+	return s.GetStatement(id)
+}
+
+func (s *CorporateService) generateStatement(account *models.CorporateAccount, month time.Time) (*models.CorporateStatement, error) {
+	from, to := monthBounds(month)
+	existing, err := s.corporateRepo.GetStatementByPeriod(account.ID, from)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrStatementAlreadyExists
+	}
+
+	issuedAt := s.now()
+	statement := models.CorporateStatement{
+		CorporateAccountID: account.ID,
+		PeriodStart:        from,
+		PeriodEnd:          to,
+		Currency:           account.Currency,
+		Status:             models.StatementIssued,
+		IssuedAt:           issuedAt,
+	}
+	series := invoiceSeries(os.Getenv("CORPORATE_STATEMENT_NUMBER_PREFIX"), "ST", issuedAt)
+	if err := s.corporateRepo.CreateStatement(&statement, series, from, to); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNothingToBill
+		}
+		// Another request issued the statement of the period since it was checked above
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrStatementAlreadyExists
+		}
+		return nil, fmt.Errorf("failed to create statement: %w", err)
+	}
+	return &statement, nil
+}
+
+func (s *CorporateService) getAccount(id uint) (*models.CorporateAccount, error) {
+	account, err := s.corporateRepo.GetAccountByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCorporateAccountNotFound
+		}
+		return nil, err
+	}
+	return account, nil
+}
+
+func (s *CorporateService) getMember(accountID, userID uint) (*models.CorporateMember, error) {
+	member, err := s.corporateRepo.GetMember(accountID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotCorporateMember
+		}
+		return nil, err
+	}
+	return member, nil
+}
+
+func (s *CorporateService) getBooking(id uint) (*models.Booking, error) {
+	booking, err := s.bookingRepo.GetBookingByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBookingNotFound
+		}
+		return nil, err
+	}
+	return booking, nil
+}
+
+// monthBounds returns the first instant of the month containing t and of the following month, in UTC.
+func monthBounds(t time.Time) (time.Time, time.Time) {
+	t = t.UTC()
+	start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 1, 0)
+}
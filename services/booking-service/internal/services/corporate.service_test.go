@@ -0,0 +1,292 @@
+package services
+
+import (
+	"booking-service/internal/models"
+	"booking-service/internal/repository"
+	"context"
+	"booking-service/internal/api/dto"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type fakeCorporateRepository struct {
+	repository.ICorporateRepository
+	account   *models.CorporateAccount
+	member    *models.CorporateMember
+	statement *models.CorporateStatement
+	charged   *models.Booking
+	periods   []time.Time
+	addErr    error
+	createErr error
+	updateErr error
+}
+
+func (r *fakeCorporateRepository) GetAccountByID(id uint) (*models.CorporateAccount, error) {
+	return r.account, nil
+}
+
+func (r *fakeCorporateRepository) ListAccounts(activeOnly bool) ([]models.CorporateAccount, error) {
+	return []models.CorporateAccount{*r.account}, nil
+}
+
+func (r *fakeCorporateRepository) AddMember(member *models.CorporateMember) error {
+	return r.addErr
+}
+
+func (r *fakeCorporateRepository) GetMember(accountID, userID uint) (*models.CorporateMember, error) {
+	return r.member, nil
+}
+
+func (r *fakeCorporateRepository) ChargeBooking(booking *models.Booking, spendingLimit float64, from, to time.Time) error {
+	r.charged = booking
+	return nil
+}
+
+func (r *fakeCorporateRepository) UpdateBookingApproval(booking *models.Booking) error {
+	return r.updateErr
+}
+
+func (r *fakeCorporateRepository) GetStatementByPeriod(accountID uint, periodStart time.Time) (*models.CorporateStatement, error) {
+	return nil, nil
+}
+
+func (r *fakeCorporateRepository) CreateStatement(statement *models.CorporateStatement, series string, from, to time.Time) error {
+	if r.createErr != nil {
+		return r.createErr
+	}
+	r.periods = append(r.periods, from)
+	return nil
+}
+
+func (r *fakeCorporateRepository) GetStatementByID(id uint) (*models.CorporateStatement, error) {
+	return r.statement, nil
+}
+
+func (r *fakeCorporateRepository) MarkStatementPaid(id uint, paidAt time.Time) error {
+	return r.updateErr
+}
+
+func pendingBooking(userID uint, amount float64) *models.Booking {
+	booking := &models.Booking{UserID: userID, Status: models.BookingPending, TotalAmount: amount}
+	booking.ID = 9
+	return booking
+}
+
+func TestAddMemberOfAnotherAccount(t *testing.T) {
+	service := &CorporateService{corporateRepo: &fakeCorporateRepository{account: &models.CorporateAccount{Active: true}, addErr: gorm.ErrDuplicatedKey}}
+
+	if _, err := service.AddMember(1, dto.AddCorporateMemberRequest{UserID: 7, Role: string(models.CorporateRoleTraveller)}); !errors.Is(err, ErrAlreadyCorporateMember) {
+		t.Errorf("AddMember() error = %v, want %v", err, ErrAlreadyCorporateMember)
+	}
+}
+
+func TestChargeBookingApproval(t *testing.T) {
+	tests := []struct {
+		name         string
+		account      models.CorporateAccount
+		amount       float64
+		wantStatus   models.BookingStatus
+		wantApproval models.ApprovalStatus
+		wantDiscount float64
+	}{
+		{name: "no approval rules", account: models.CorporateAccount{DiscountRate: 10}, amount: 200,
+			wantStatus: models.BookingConfirmed, wantApproval: models.ApprovalNotRequired, wantDiscount: 20},
+		{name: "every booking needs approval", account: models.CorporateAccount{RequireApproval: true}, amount: 20,
+			wantStatus: models.BookingPending, wantApproval: models.ApprovalPending},
+		{name: "discounted amount under the threshold", account: models.CorporateAccount{RequireApproval: true, ApprovalThreshold: 100, DiscountRate: 50}, amount: 150,
+			wantStatus: models.BookingConfirmed, wantApproval: models.ApprovalNotRequired, wantDiscount: 75},
+		{name: "amount over the threshold", account: models.CorporateAccount{RequireApproval: true, ApprovalThreshold: 100}, amount: 150,
+			wantStatus: models.BookingPending, wantApproval: models.ApprovalPending},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			account := tt.account
+			account.ID, account.Active = 4, true
+			repo := &fakeCorporateRepository{account: &account, member: &models.CorporateMember{UserID: 2}}
+			service := NewCorporateService(repo, &fakeBookingRepository{booking: pendingBooking(2, tt.amount)})
+
+			response, err := service.ChargeBooking(account.ID, 9)
+			if err != nil {
+				t.Fatalf("ChargeBooking() error = %v", err)
+			}
+			if response.Status != string(tt.wantStatus) || repo.charged.ApprovalStatus != tt.wantApproval {
+				t.Errorf("ChargeBooking() = %s awaiting %s, want %s awaiting %s",
+					response.Status, repo.charged.ApprovalStatus, tt.wantStatus, tt.wantApproval)
+			}
+			if repo.charged.DiscountAmount != tt.wantDiscount {
+				t.Errorf("ChargeBooking() discount = %v, want %v", repo.charged.DiscountAmount, tt.wantDiscount)
+			}
+		})
+	}
+}
+
+func TestChargeBookingRejectsInactiveAccounts(t *testing.T) {
+	account := &models.CorporateAccount{}
+	service := NewCorporateService(&fakeCorporateRepository{account: account}, &fakeBookingRepository{booking: pendingBooking(2, 10)})
+
+	if _, err := service.ChargeBooking(4, 9); !errors.Is(err, ErrCorporateAccountInactive) {
+		t.Errorf("ChargeBooking() error = %v, want %v", err, ErrCorporateAccountInactive)
+	}
+}
+
+func TestDecideChecksTheApprover(t *testing.T) {
+	accountID := uint(4)
+	tests := []struct {
+		name     string
+		approver models.CorporateMember
+		want     error
+	}{
+		{name: "approver", approver: models.CorporateMember{UserID: 3, Role: models.CorporateRoleApprover}},
+		{name: "traveller", approver: models.CorporateMember{UserID: 3, Role: models.CorporateRoleTraveller}, want: ErrNotCorporateApprover},
+		{name: "own booking", approver: models.CorporateMember{UserID: 2, Role: models.CorporateRoleApprover}, want: ErrSelfApproval},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			booking := pendingBooking(2, 10)
+			booking.CorporateAccountID, booking.ApprovalStatus = &accountID, models.ApprovalPending
+			service := NewCorporateService(&fakeCorporateRepository{member: &tt.approver}, &fakeBookingRepository{booking: booking})
+
+			response, err := service.RejectBooking(accountID, booking.ID, tt.approver.UserID)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("RejectBooking() error = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && response.Status != string(models.BookingCancelled) {
+				t.Errorf("RejectBooking() status = %s, want %s", response.Status, models.BookingCancelled)
+			}
+		})
+	}
+}
+
+func TestApproveBookingUpdateErrors(t *testing.T) {
+	updateErr := errors.New("connection reset")
+	tests := []struct {
+		name      string
+		updateErr error
+		want      error
+	}{
+		{name: "approved", updateErr: nil, want: nil},
+		{name: "decided or expired concurrently", updateErr: repository.ErrBookingNotAwaitingApproval, want: ErrBookingNotAwaitingReview},
+		{name: "database error", updateErr: updateErr, want: updateErr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accountID := uint(4)
+			booking := &models.Booking{UserID: 2, Status: models.BookingPending, ApprovalStatus: models.ApprovalPending,
+				CorporateAccountID: &accountID}
+			booking.ID = 9
+			service := NewCorporateService(&fakeCorporateRepository{
+				member:    &models.CorporateMember{CorporateAccountID: accountID, UserID: 3, Role: models.CorporateRoleApprover},
+				updateErr: tt.updateErr,
+			}, &fakeBookingRepository{booking: booking})
+
+			response, err := service.ApproveBooking(accountID, booking.ID, 3)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("ApproveBooking() error = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && response.Status != string(models.BookingConfirmed) {
+				t.Errorf("ApproveBooking() status = %s, want %s", response.Status, models.BookingConfirmed)
+			}
+		})
+	}
+}
+
+func TestGenerateMonthlyStatementsBillsThePreviousMonth(t *testing.T) {
+	account := &models.CorporateAccount{Active: true}
+	repo := &fakeCorporateRepository{account: account}
+	service := &CorporateService{corporateRepo: repo, now: func() time.Time {
+		return time.Date(2026, 1, 1, 3, 0, 0, 0, time.UTC)
+	}}
+
+	if err := service.GenerateMonthlyStatements(context.Background()); err != nil {
+		t.Fatalf("GenerateMonthlyStatements() error = %v", err)
+	}
+	want := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	if len(repo.periods) != 1 || !repo.periods[0].Equal(want) {
+		t.Errorf("statements issued for %v, want the month starting %v", repo.periods, want)
+	}
+}
+
+func TestGenerateStatementErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		createErr error
+		want      error
+	}{
+		{name: "issued", want: nil},
+		{name: "nothing to bill", createErr: gorm.ErrRecordNotFound, want: ErrNothingToBill},
+		{name: "issued concurrently", createErr: gorm.ErrDuplicatedKey, want: ErrStatementAlreadyExists},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeCorporateRepository{account: &models.CorporateAccount{Active: true}, createErr: tt.createErr}
+			service := &CorporateService{corporateRepo: repo, now: time.Now}
+
+			if _, err := service.GenerateStatement(1, "2026-09"); !errors.Is(err, tt.want) {
+				t.Errorf("GenerateStatement() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkStatementPaidUpdateErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    models.CorporateStatementStatus
+		updateErr error
+		want      error
+	}{
+		{name: "paid", status: models.StatementIssued, want: nil},
+		{name: "already paid", status: models.StatementPaid, want: ErrStatementAlreadyPaid},
+		{name: "paid concurrently", status: models.StatementIssued, updateErr: repository.ErrStatementNotIssued, want: ErrStatementAlreadyPaid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statement := &models.CorporateStatement{Status: tt.status}
+			statement.ID = 5
+			service := NewCorporateService(&fakeCorporateRepository{statement: statement, updateErr: tt.updateErr}, &fakeBookingRepository{})
+
+			if _, err := service.MarkStatementPaid(statement.ID); !errors.Is(err, tt.want) {
+				t.Errorf("MarkStatementPaid() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMonthBounds(t *testing.T) {
+	tests := []struct {
+		name      string
+		at        time.Time
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{
+			name:      "middle of the month",
+			at:        time.Date(2026, time.March, 14, 9, 30, 0, 0, time.UTC),
+			wantStart: time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "end of the year",
+			at:        time.Date(2026, time.December, 31, 23, 59, 59, 0, time.UTC),
+			wantStart: time.Date(2026, time.December, 1, 0, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "other time zone",
+			at:        time.Date(2026, time.May, 1, 2, 0, 0, 0, time.FixedZone("UTC+6", 6*60*60)),
+			wantStart: time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2026, time.May, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := monthBounds(tt.at)
+			if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+				t.Errorf("monthBounds() = %v, %v, want %v, %v", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
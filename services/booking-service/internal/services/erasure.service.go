@@ -0,0 +1,29 @@
+package services
+
+import (
+	"booking-service/internal/repository"
+	"fmt"
+)
+
+// IErasureService defines the interface for erasing the data of deleted accounts.
+type IErasureService interface {
+	EraseUserData(userID uint) error
+}
+
+// ErasureService anonymises what booking-service holds about a deleted account once auth-service erases it.
+type ErasureService struct {
+	erasureRepo repository.IErasureRepository
+}
+
+// NewErasureService creates a new instance of ErasureService.
+func NewErasureService(erasureRepo repository.IErasureRepository) IErasureService {
+	return &ErasureService{erasureRepo: erasureRepo}
+}
+
+// EraseUserData anonymises the bookings, invoices and reviews of a user. It can be called again safely.
+func (s *ErasureService) EraseUserData(userID uint) error {
+	if err := s.erasureRepo.EraseUserData(userID); err != nil {
+		return fmt.Errorf("failed to erase user data: %w", err)
+	}
+	return nil
+}
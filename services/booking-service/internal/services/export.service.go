@@ -0,0 +1,32 @@
+package services
+
+import (
+	"booking-service/internal/api/dto"
+	"booking-service/internal/repository"
+	"fmt"
+)
+
+// IExportService defines the interface for exporting the personal data of users.
+type IExportService interface {
+	ExportUserData(userID uint) (*dto.UserDataExportResponse, error)
+}
+
+// ExportService gathers what booking-service holds about a user for the export of their personal data.
+type ExportService struct {
+	exportRepo repository.IExportRepository
+}
+
+// NewExportService creates a new instance of ExportService.
+func NewExportService(exportRepo repository.IExportRepository) IExportService {
+	return &ExportService{exportRepo: exportRepo}
+}
+
+// ExportUserData gathers the bookings, invoices, reviews and corporate membership of a user.
+func (s *ExportService) ExportUserData(userID uint) (*dto.UserDataExportResponse, error) {
+	data, err := s.exportRepo.FindUserData(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export user data: %w", err)
+	}
+	response := dto.FromUserData(*data)
+	return &response, nil
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"booking-service/internal/models"
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// renderInvoicePDF lays out an invoice or credit note on a single A4 page (more if it has many lines).
+// The seller block is read from INVOICE_SELLER_NAME, INVOICE_SELLER_ADDRESS and INVOICE_SELLER_TAX_ID.
+func renderInvoicePDF(invoice models.Invoice) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetTitle(invoice.Number, true)
+	pdf.AddPage()
+
+	title := "INVOICE"
+	if invoice.Type == models.InvoiceTypeCreditNote {
+		title = "CREDIT NOTE"
+	}
+	pdf.SetFont("Arial", "B", 18)
+	pdf.CellFormat(0, 10, title, "", 1, "L", false, 0, "")
+
+	// Seller and document details
+	pdf.SetFont("Arial", "", 10)
+	pdf.MultiCell(0, 5, tr(sellerBlock()), "", "L", false)
+	pdf.Ln(4)
+	pdf.CellFormat(0, 5, tr("Number: "+invoice.Number), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 5, "Issue date: "+invoice.IssuedAt.Format("2006-01-02"), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 5, fmt.Sprintf("Booking: #%d", invoice.BookingID), "", 1, "L", false, 0, "")
+	if invoice.Type == models.InvoiceTypeCreditNote && invoice.OriginalInvoiceID != nil {
+		pdf.CellFormat(0, 5, fmt.Sprintf("Credits invoice: #%d", *invoice.OriginalInvoiceID), "", 1, "L", false, 0, "")
+		pdf.MultiCell(0, 5, tr("Reason: "+invoice.Reason), "", "L", false)
+	}
+	pdf.Ln(4)
+
+	// Customer details
+	pdf.SetFont("Arial", "B", 10)
+	pdf.CellFormat(0, 5, "Bill to", "", 1, "L", false, 0, "")
+	pdf.SetFont("Arial", "", 10)
+	pdf.MultiCell(0, 5, tr(billingBlock(invoice)), "", "L", false)
+	pdf.Ln(6)
+
+	// Lines
+	widths := []float64{80, 25, 25, 25, 25}
+	headers := []string{"Description", "Net", "Tax %", "Tax", "Gross"}
+	pdf.SetFont("Arial", "B", 10)
+	for i, header := range headers {
+		align := "R"
+		if i == 0 {
+			align = "L"
+		}
+		pdf.CellFormat(widths[i], 7, header, "B", 0, align, false, 0, "")
+	}
+	pdf.Ln(-1)
+	pdf.SetFont("Arial", "", 10)
+	for _, line := range invoice.Lines {
+		pdf.CellFormat(widths[0], 6, tr(line.Description), "", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[1], 6, formatAmount(line.NetAmount), "", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[2], 6, fmt.Sprintf("%.2f", line.TaxRate), "", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[3], 6, formatAmount(line.TaxAmount), "", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[4], 6, formatAmount(line.GrossAmount), "", 1, "R", false, 0, "")
+	}
+
+	// Totals
+	pdf.Ln(2)
+	labelWidth := widths[0] + widths[1] + widths[2] + widths[3]
+	pdf.CellFormat(labelWidth, 6, "Subtotal", "T", 0, "R", false, 0, "")
+	pdf.CellFormat(widths[4], 6, formatAmount(invoice.Subtotal), "T", 1, "R", false, 0, "")
+	pdf.CellFormat(labelWidth, 6, "Tax", "", 0, "R", false, 0, "")
+	pdf.CellFormat(widths[4], 6, formatAmount(invoice.TaxTotal), "", 1, "R", false, 0, "")
+	pdf.SetFont("Arial", "B", 10)
+	pdf.CellFormat(labelWidth, 7, "Total ("+invoice.Currency+")", "", 0, "R", false, 0, "")
+	pdf.CellFormat(widths[4], 7, formatAmount(invoice.Total), "", 1, "R", false, 0, "")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func sellerBlock() string {
+	block := os.Getenv("INVOICE_SELLER_NAME")
+	if address := os.Getenv("INVOICE_SELLER_ADDRESS"); address != "" {
+		block += "\n" + address
+	}
+	if taxID := os.Getenv("INVOICE_SELLER_TAX_ID"); taxID != "" {
+		block += "\nTax ID: " + taxID
+	}
+	return block
+}
+
+func billingBlock(invoice models.Invoice) string {
+	block := invoice.BillingName
+	if invoice.BillingAddress != "" {
+		block += "\n" + invoice.BillingAddress
+	}
+	if invoice.BillingRegion != "" {
+		block += "\n" + invoice.BillingRegion
+	}
+	if invoice.BillingTaxID != "" {
+		block += "\nTax ID: " + invoice.BillingTaxID
+	}
+	return block
+}
+
+func formatAmount(amount float64) string {
+	return fmt.Sprintf("%.2f", amount)
+}
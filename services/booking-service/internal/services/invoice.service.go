@@ -0,0 +1,288 @@
+package services
+
+import (
+	"booking-service/internal/api/dto"
+	"booking-service/internal/models"
+	"booking-service/internal/repository"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Errors returned by the invoice service so handlers can map them to status codes.
+var (
+	ErrBookingNotFound       = errors.New("booking not found")
+	ErrInvoiceNotFound       = errors.New("invoice not found")
+	ErrBookingNotInvoiceable = errors.New("only paid bookings can be invoiced")
+	ErrInvoiceAlreadyExists  = errors.New("an invoice was already issued for this booking")
+	ErrNotAnInvoice          = errors.New("credit notes can only be issued for invoices")
+	ErrNothingToCredit       = errors.New("all selected lines were already credited")
+	ErrLinesAlreadyCredited  = errors.New("some of the selected lines were credited at the same time")
+)
+
+// IInvoiceService defines the interface for invoice operations.
+type IInvoiceService interface {
+	GenerateInvoice(bookingID uint, req dto.CreateInvoiceRequest) (*dto.InvoiceResponse, error)
+	IssueCreditNote(invoiceID uint, req dto.CreateCreditNoteRequest) (*dto.InvoiceResponse, error)
+	GetInvoice(id uint) (*dto.InvoiceResponse, error)
+	ListInvoicesByUser(userID uint, offset, limit int) ([]dto.InvoiceResponse, error)
+	RenderInvoicePDF(id uint) ([]byte, string, error)
+}
+
+// InvoiceService issues invoices and credit notes for bookings.
+type InvoiceService struct {
+	invoiceRepo repository.IInvoiceRepository
+	bookingRepo repository.IBookingRepository
+	taxRateRepo repository.ITaxRateRepository
+	now         func() time.Time
+}
+
+// NewInvoiceService creates a new instance of InvoiceService.
+func NewInvoiceService(invoiceRepo repository.IInvoiceRepository, bookingRepo repository.IBookingRepository, taxRateRepo repository.ITaxRateRepository) IInvoiceService {
+	return &InvoiceService{
+		invoiceRepo: invoiceRepo,
+		bookingRepo: bookingRepo,
+		taxRateRepo: taxRateRepo,
+		now:         time.Now,
+	}
+}
+
+// GenerateInvoice issues the invoice of a paid booking with one line per passenger and one per fee.
+func (s *InvoiceService) GenerateInvoice(bookingID uint, req dto.CreateInvoiceRequest) (*dto.InvoiceResponse, error) {
+	booking, err := s.bookingRepo.GetBookingByID(bookingID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBookingNotFound
+		}
+		return nil, err
+	}
+	switch booking.Status {
+	case models.BookingConfirmed, models.BookingCheckedIn, models.BookingNoShow:
+	default:
+		return nil, ErrBookingNotInvoiceable
+	}
+
+	existing, err := s.invoiceRepo.GetInvoiceByBookingID(booking.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrInvoiceAlreadyExists
+	}
+
+	issuedAt := s.now()
+	taxRate, err := s.taxRateRepo.FindApplicable(booking.RouteID, req.BillingRegion, issuedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve tax rate: %w", err)
+	}
+
+	var lines []models.InvoiceLine
+	for _, passenger := range booking.Passengers {
+		passengerID := passenger.ID
+		description := fmt.Sprintf("Fare for %s", passenger.FullName)
+		if passenger.SeatNumber != "" {
+			description = fmt.Sprintf("Fare for %s, seat %s", passenger.FullName, passenger.SeatNumber)
+		}
+		lines = append(lines, newInvoiceLine(models.LineKindFare, description, &passengerID, passenger.Fare, taxRate))
+	}
+	for _, fee := range booking.Fees {
+		lines = append(lines, newInvoiceLine(models.LineKindFee, fee.Description, nil, fee.Amount, taxRate))
+	}
+	if booking.DiscountAmount > 0 {
+		lines = append(lines, newInvoiceLine(models.LineKindDiscount, "Corporate discount", nil, -booking.DiscountAmount, taxRate))
+	}
+
+	invoice := models.Invoice{
+		Type:           models.InvoiceTypeInvoice,
+		BookingID:      booking.ID,
+		UserID:         booking.UserID,
+		IssuedAt:       issuedAt,
+		BillingName:    req.BillingName,
+		BillingAddress: req.BillingAddress,
+		BillingRegion:  req.BillingRegion,
+		BillingTaxID:   req.BillingTaxID,
+		Currency:       booking.Currency,
+		Lines:          lines,
+	}
+	applyInvoiceTotals(&invoice)
+
+	if err := s.invoiceRepo.CreateInvoice(&invoice, invoiceSeries(os.Getenv("INVOICE_NUMBER_PREFIX"), "INV", issuedAt)); err != nil {
+		// Another request invoiced the booking since it was checked above
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrInvoiceAlreadyExists
+		}
+		return nil, fmt.Errorf("failed to create invoice: %w", err)
+	}
+	response := dto.FromInvoiceModel(invoice)
+	return &response, nil
+}
+
+// IssueCreditNote reverses the selected lines of an invoice, or every line not credited yet.
+// The credit note copies the tax rate of each original line so the reversal matches the invoice exactly.
+func (s *InvoiceService) IssueCreditNote(invoiceID uint, req dto.CreateCreditNoteRequest) (*dto.InvoiceResponse, error) {
+	original, err := s.invoiceRepo.GetInvoiceByID(invoiceID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvoiceNotFound
+		}
+		return nil, err
+	}
+	if original.Type != models.InvoiceTypeInvoice {
+		return nil, ErrNotAnInvoice
+	}
+
+	creditedIDs, err := s.invoiceRepo.GetCreditedLineIDs(original.ID)
+	if err != nil {
+		return nil, err
+	}
+	credited := make(map[uint]bool, len(creditedIDs))
+	for _, id := range creditedIDs {
+		credited[id] = true
+	}
+	selected := make(map[uint]bool, len(req.LineIDs))
+	for _, id := range req.LineIDs {
+		selected[id] = true
+	}
+
+	var lines []models.InvoiceLine
+	for _, line := range original.Lines {
+		if credited[line.ID] || (len(selected) > 0 && !selected[line.ID]) {
+			continue
+		}
+		lineID := line.ID
+		lines = append(lines, models.InvoiceLine{
+			Kind:           line.Kind,
+			Description:    "Credit: " + line.Description,
+			PassengerID:    line.PassengerID,
+			CreditedLineID: &lineID,
+			Quantity:       line.Quantity,
+			UnitAmount:     -line.UnitAmount,
+			NetAmount:      -line.NetAmount,
+			TaxName:        line.TaxName,
+			TaxRate:        line.TaxRate,
+			TaxAmount:      -line.TaxAmount,
+			GrossAmount:    -line.GrossAmount,
+		})
+	}
+	if len(lines) == 0 {
+		return nil, ErrNothingToCredit
+	}
+
+	issuedAt := s.now()
+	originalID := original.ID
+	creditNote := models.Invoice{
+		Type:              models.InvoiceTypeCreditNote,
+		BookingID:         original.BookingID,
+		UserID:            original.UserID,
+		OriginalInvoiceID: &originalID,
+		Reason:            req.Reason,
+		IssuedAt:          issuedAt,
+		BillingName:       original.BillingName,
+		BillingAddress:    original.BillingAddress,
+		BillingRegion:     original.BillingRegion,
+		BillingTaxID:      original.BillingTaxID,
+		Currency:          original.Currency,
+		Lines:             lines,
+	}
+	applyInvoiceTotals(&creditNote)
+
+	if err := s.invoiceRepo.CreateInvoice(&creditNote, invoiceSeries(os.Getenv("CREDIT_NOTE_NUMBER_PREFIX"), "CN", issuedAt)); err != nil {
+		// Another credit note reversed one of the lines since they were checked above
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrLinesAlreadyCredited
+		}
+		return nil, fmt.Errorf("failed to create credit note: %w", err)
+	}
+	response := dto.FromInvoiceModel(creditNote)
+	return &response, nil
+}
+
+// GetInvoice retrieves an invoice or credit note by its ID.
+func (s *InvoiceService) GetInvoice(id uint) (*dto.InvoiceResponse, error) {
+	invoice, err := s.invoiceRepo.GetInvoiceByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvoiceNotFound
+		}
+		return nil, err
+	}
+	response := dto.FromInvoiceModel(*invoice)
+	return &response, nil
+}
+
+// ListInvoicesByUser retrieves the invoices and credit notes of a user.
+func (s *InvoiceService) ListInvoicesByUser(userID uint, offset, limit int) ([]dto.InvoiceResponse, error) {
+	invoices, err := s.invoiceRepo.ListInvoicesByUserID(userID, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]dto.InvoiceResponse, len(invoices))
+	for i, invoice := range invoices {
+		responses[i] = dto.FromInvoiceModel(invoice)
+	}
+	return responses, nil
+}
+
+// RenderInvoicePDF renders a stored invoice or credit note as a PDF and returns it with a file name.
+func (s *InvoiceService) RenderInvoicePDF(id uint) ([]byte, string, error) {
+	invoice, err := s.invoiceRepo.GetInvoiceByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", ErrInvoiceNotFound
+		}
+		return nil, "", err
+	}
+	content, err := renderInvoicePDF(*invoice)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to render invoice PDF: %w", err)
+	}
+	return content, invoice.Number + ".pdf", nil
+}
+
+// newInvoiceLine builds a single-quantity line, applying taxRate on top of the net amount.
+func newInvoiceLine(kind models.InvoiceLineKind, description string, passengerID *uint, netAmount float64, taxRate *models.TaxRate) models.InvoiceLine {
+	line := models.InvoiceLine{
+		Kind:        kind,
+		Description: description,
+		PassengerID: passengerID,
+		Quantity:    1,
+		UnitAmount:  roundMoney(netAmount),
+		NetAmount:   roundMoney(netAmount),
+	}
+	if taxRate != nil {
+		line.TaxName = taxRate.Name
+		line.TaxRate = taxRate.Rate
+		line.TaxAmount = roundMoney(line.NetAmount * taxRate.Rate / 100)
+	}
+	line.GrossAmount = roundMoney(line.NetAmount + line.TaxAmount)
+	return line
+}
+
+// applyInvoiceTotals sums the lines of an invoice into its subtotal, tax and total.
+func applyInvoiceTotals(invoice *models.Invoice) {
+	var subtotal, taxTotal float64
+	for _, line := range invoice.Lines {
+		subtotal += line.NetAmount
+		taxTotal += line.TaxAmount
+	}
+	invoice.Subtotal = roundMoney(subtotal)
+	invoice.TaxTotal = roundMoney(taxTotal)
+	invoice.Total = roundMoney(subtotal + taxTotal)
+}
+
+// invoiceSeries returns the yearly numbering series, e.g. "INV-2026", so numbering restarts every fiscal year.
+func invoiceSeries(prefix, defaultPrefix string, issuedAt time.Time) string {
+	if prefix == "" {
+		prefix = defaultPrefix
+	}
+	return fmt.Sprintf("%s-%d", prefix, issuedAt.Year())
+}
+
+// roundMoney rounds an amount to two decimal places, half away from zero.
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"booking-service/internal/api/dto"
+	"booking-service/internal/models"
+	"booking-service/internal/repository"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type fakeInvoiceRepository struct {
+	repository.IInvoiceRepository
+	invoice   *models.Invoice
+	credited  []uint
+	created   *models.Invoice
+	series    string
+	createErr error
+}
+
+func (r *fakeInvoiceRepository) CreateInvoice(invoice *models.Invoice, series string) error {
+	r.created, r.series = invoice, series
+	return r.createErr
+}
+
+func (r *fakeInvoiceRepository) GetInvoiceByID(id uint) (*models.Invoice, error) {
+	return r.invoice, nil
+}
+
+func (r *fakeInvoiceRepository) GetInvoiceByBookingID(bookingID uint) (*models.Invoice, error) {
+	return nil, nil
+}
+
+func (r *fakeInvoiceRepository) GetCreditedLineIDs(originalInvoiceID uint) ([]uint, error) {
+	return r.credited, nil
+}
+
+type fakeBookingRepository struct {
+	repository.IBookingRepository
+	booking *models.Booking
+}
+
+func (r *fakeBookingRepository) GetBookingByID(id uint) (*models.Booking, error) {
+	return r.booking, nil
+}
+
+type fakeTaxRateRepository struct {
+	repository.ITaxRateRepository
+}
+
+func (r *fakeTaxRateRepository) FindApplicable(routeID uint, region string, at time.Time) (*models.TaxRate, error) {
+	return &models.TaxRate{Name: "VAT", Rate: 15}, nil
+}
+
+func TestGenerateInvoiceLines(t *testing.T) {
+	booking := &models.Booking{Status: models.BookingCheckedIn, Currency: "BDT",
+		Passengers: []models.BookingPassenger{{FullName: "Ann Lee", SeatNumber: "A1", Fare: 99.99}, {FullName: "Bo Lee", Fare: 50}},
+		Fees:       []models.BookingFee{{Description: "Booking fee", Amount: 10}}}
+	booking.ID = 4
+	repo := &fakeInvoiceRepository{}
+	service := &InvoiceService{invoiceRepo: repo, bookingRepo: &fakeBookingRepository{booking: booking},
+		taxRateRepo: &fakeTaxRateRepository{}, now: func() time.Time { return time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC) }}
+
+	if _, err := service.GenerateInvoice(booking.ID, dto.CreateInvoiceRequest{BillingName: "Ann Lee"}); err != nil {
+		t.Fatalf("GenerateInvoice() error = %v", err)
+	}
+
+	invoice := repo.created
+	if repo.series != "INV-2026" {
+		t.Errorf("numbered in series %q, want INV-2026", repo.series)
+	}
+	wantLines := []struct {
+		description string
+		tax, gross  float64
+	}{
+		{"Fare for Ann Lee, seat A1", 15, 114.99},
+		{"Fare for Bo Lee", 7.5, 57.5},
+		{"Booking fee", 1.5, 11.5},
+	}
+	if len(invoice.Lines) != len(wantLines) {
+		t.Fatalf("invoice has %d lines, want %d", len(invoice.Lines), len(wantLines))
+	}
+	for i, want := range wantLines {
+		line := invoice.Lines[i]
+		if line.Description != want.description || line.TaxAmount != want.tax || line.GrossAmount != want.gross {
+			t.Errorf("line %d = %q tax %v gross %v, want %q tax %v gross %v",
+				i, line.Description, line.TaxAmount, line.GrossAmount, want.description, want.tax, want.gross)
+		}
+	}
+	if invoice.Subtotal != 159.99 || invoice.TaxTotal != 24 || invoice.Total != 183.99 {
+		t.Errorf("totals = %v + %v = %v, want 159.99 + 24 = 183.99", invoice.Subtotal, invoice.TaxTotal, invoice.Total)
+	}
+}
+
+func TestGenerateInvoiceRequiresAPaidBooking(t *testing.T) {
+	for _, status := range []models.BookingStatus{models.BookingPending, models.BookingCancelled, models.BookingExpired} {
+		t.Run(string(status), func(t *testing.T) {
+			booking := &models.Booking{Status: status}
+			service := NewInvoiceService(&fakeInvoiceRepository{}, &fakeBookingRepository{booking: booking}, &fakeTaxRateRepository{})
+
+			if _, err := service.GenerateInvoice(1, dto.CreateInvoiceRequest{}); !errors.Is(err, ErrBookingNotInvoiceable) {
+				t.Errorf("GenerateInvoice() error = %v, want %v", err, ErrBookingNotInvoiceable)
+			}
+		})
+	}
+}
+
+func TestIssueCreditNoteReversesLines(t *testing.T) {
+	original := &models.Invoice{ID: 7, Type: models.InvoiceTypeInvoice, Lines: []models.InvoiceLine{
+		{ID: 1, Description: "Fare", NetAmount: 100, TaxRate: 15, TaxAmount: 15, GrossAmount: 115},
+		{ID: 2, Description: "Fee", NetAmount: 10, TaxRate: 15, TaxAmount: 1.5, GrossAmount: 11.5},
+		{ID: 3, Description: "Fare", NetAmount: 50, TaxRate: 15, TaxAmount: 7.5, GrossAmount: 57.5},
+	}}
+	tests := []struct {
+		name     string
+		credited []uint
+		selected []uint
+		want     []uint
+		wantErr  error
+	}{
+		{name: "every line", want: []uint{1, 2, 3}},
+		{name: "selected lines", selected: []uint{2, 3}, want: []uint{2, 3}},
+		{name: "lines not credited yet", credited: []uint{1}, want: []uint{2, 3}},
+		{name: "selected lines already credited", credited: []uint{2}, selected: []uint{2}, wantErr: ErrNothingToCredit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeInvoiceRepository{invoice: original, credited: tt.credited}
+			service := NewInvoiceService(repo, &fakeBookingRepository{}, &fakeTaxRateRepository{})
+
+			_, err := service.IssueCreditNote(original.ID, dto.CreateCreditNoteRequest{Reason: "Refund", LineIDs: tt.selected})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("IssueCreditNote() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if len(repo.created.Lines) != len(tt.want) {
+				t.Fatalf("credit note has %d lines, want %d", len(repo.created.Lines), len(tt.want))
+			}
+			for i, line := range repo.created.Lines {
+				if *line.CreditedLineID != tt.want[i] || line.GrossAmount >= 0 {
+					t.Errorf("line %d credits line %d for %v, want a negative amount crediting line %d",
+						i, *line.CreditedLineID, line.GrossAmount, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateInvoiceCreateErrors(t *testing.T) {
+	createErr := errors.New("connection reset")
+	tests := []struct {
+		name      string
+		createErr error
+		want      error
+	}{
+		{name: "created", createErr: nil, want: nil},
+		{name: "invoiced concurrently", createErr: gorm.ErrDuplicatedKey, want: ErrInvoiceAlreadyExists},
+		{name: "database error", createErr: createErr, want: createErr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			booking := &models.Booking{Status: models.BookingConfirmed, Currency: "USD",
+				Passengers: []models.BookingPassenger{{FullName: "Ann Lee", Fare: 100}}}
+			booking.ID = 1
+			service := NewInvoiceService(&fakeInvoiceRepository{createErr: tt.createErr},
+				&fakeBookingRepository{booking: booking}, &fakeTaxRateRepository{})
+
+			response, err := service.GenerateInvoice(booking.ID, dto.CreateInvoiceRequest{BillingName: "Ann Lee"})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("GenerateInvoice() error = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && (response == nil || response.Total != 115) {
+				t.Errorf("GenerateInvoice() = %+v, want a total of 115", response)
+			}
+		})
+	}
+}
+
+func TestIssueCreditNoteCreateErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		createErr error
+		want      error
+	}{
+		{name: "created", createErr: nil, want: nil},
+		{name: "credited concurrently", createErr: gorm.ErrDuplicatedKey, want: ErrLinesAlreadyCredited},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invoice := &models.Invoice{ID: 7, Type: models.InvoiceTypeInvoice, BookingID: 1,
+				Lines: []models.InvoiceLine{{ID: 3, Kind: models.LineKindFare, NetAmount: 100, TaxAmount: 15, GrossAmount: 115}}}
+			service := NewInvoiceService(&fakeInvoiceRepository{invoice: invoice, createErr: tt.createErr},
+				&fakeBookingRepository{}, &fakeTaxRateRepository{})
+
+			response, err := service.IssueCreditNote(invoice.ID, dto.CreateCreditNoteRequest{Reason: "Refund"})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("IssueCreditNote() error = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && (response == nil || response.Total != -115) {
+				t.Errorf("IssueCreditNote() = %+v, want a total of -115", response)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package services
+
+import (
+	"booking-service/internal/api/dto"
+	"booking-service/internal/repository"
+	"fmt"
+	"time"
+)
+
+// IManifestService defines the interface for reading the passenger manifests of departures.
+type IManifestService interface {
+	GetManifest(busID uint, departureTime time.Time, userID uint) (*dto.ManifestResponse, error)
+}
+
+// ManifestService lists the passengers expected on departures, for conductors and partner integrations.
+type ManifestService struct {
+	bookingRepo repository.IBookingRepository
+}
+
+// NewManifestService creates a new instance of ManifestService.
+func NewManifestService(bookingRepo repository.IBookingRepository) IManifestService {
+	return &ManifestService{bookingRepo: bookingRepo}
+}
+
+// GetManifest lists the passengers of the confirmed and checked-in bookings of a departure of a bus. When userID
+// is not 0, only the passengers of the bookings made by that user are listed.
+func (s *ManifestService) GetManifest(busID uint, departureTime time.Time, userID uint) (*dto.ManifestResponse, error) {
+	bookings, err := s.bookingRepo.FindManifestBookings(busID, departureTime, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get manifest: %w", err)
+	}
+	response := dto.ToManifestResponse(busID, departureTime, bookings)
+	return &response, nil
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"booking-service/internal/api/dto"
+	"booking-service/internal/config"
+	"booking-service/internal/models"
+	"booking-service/internal/repository"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Errors returned by the review service so handlers can map them to status codes.
+var (
+	ErrReviewNotFound      = errors.New("review not found")
+	ErrReviewNotAllowed    = errors.New("only passengers who checked in on this booking can review it")
+	ErrReviewAlreadyExists = errors.New("this booking has already been reviewed")
+	ErrReviewWindowClosed  = errors.New("the review period for this trip has ended")
+)
+
+// IReviewService defines the interface for trip review operations.
+type IReviewService interface {
+	CreateReview(bookingID uint, req dto.CreateReviewRequest) (*dto.ReviewResponse, error)
+	ListBusReviews(busCode string, offset, limit int) (*dto.ReviewListResponse, error)
+	ListRouteReviews(routeID uint, offset, limit int) (*dto.ReviewListResponse, error)
+	GetBusRatings(busCodes []string) ([]dto.RatingSummaryResponse, error)
+	GetRouteRatings(routeIDs []uint) ([]dto.RatingSummaryResponse, error)
+	ListReviewsForModeration(status string, offset, limit int) (*dto.ReviewListResponse, error)
+	ModerateReview(id uint, req dto.ModerateReviewRequest) (*dto.ReviewResponse, error)
+}
+
+// ReviewService manages passenger reviews of their trips and the ratings derived from them.
+type ReviewService struct {
+	reviewRepo   repository.IReviewRepository
+	bookingRepo  repository.IBookingRepository
+	busService   IBusService
+	reviewWindow time.Duration
+	now          func() time.Time
+}
+
+// NewReviewService creates a new instance of ReviewService.
+func NewReviewService(reviewRepo repository.IReviewRepository, bookingRepo repository.IBookingRepository, busService IBusService) IReviewService {
+	return &ReviewService{
+		reviewRepo:   reviewRepo,
+		bookingRepo:  bookingRepo,
+		busService:   busService,
+		reviewWindow: config.GetDuration("REVIEW_WINDOW", 30*24*time.Hour),
+		now:          time.Now,
+	}
+}
+
+// CreateReview records the rating of a trip by the user who booked it. Only checked-in bookings can be
+// reviewed, once, within the review window after departure.
+func (s *ReviewService) CreateReview(bookingID uint, req dto.CreateReviewRequest) (*dto.ReviewResponse, error) {
+	booking, err := s.bookingRepo.GetBookingByID(bookingID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBookingNotFound
+		}
+		return nil, err
+	}
+	if booking.UserID != req.UserID || booking.Status != models.BookingCheckedIn {
+		return nil, ErrReviewNotAllowed
+	}
+	if s.reviewWindow > 0 && s.now().After(booking.DepartureTime.Add(s.reviewWindow)) {
+		return nil, ErrReviewWindowClosed
+	}
+
+	existing, err := s.reviewRepo.GetReviewByBookingID(booking.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrReviewAlreadyExists
+	}
+	busCode, err := s.busService.GetBusCode(booking.BusID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bus code: %w", err)
+	}
+
+	review := models.Review{
+		BookingID:          booking.ID,
+		UserID:             booking.UserID,
+		BusID:              booking.BusID,
+		BusCode:            busCode,
+		RouteID:            booking.RouteID,
+		TripRating:         req.TripRating,
+		DriverRating:       req.DriverRating,
+		BusConditionRating: req.BusConditionRating,
+		Comment:            req.Comment,
+		Status:             models.ReviewPublished,
+	}
+	if err := s.reviewRepo.CreateReview(&review); err != nil {
+		// Another request reviewed the booking since it was checked above
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrReviewAlreadyExists
+		}
+		return nil, fmt.Errorf("failed to create review: %w", err)
+	}
+	response := dto.FromReviewModel(review)
+	return &response, nil
+}
+
+// ListBusReviews retrieves the published reviews of a bus, by its code, newest first.
+func (s *ReviewService) ListBusReviews(busCode string, offset, limit int) (*dto.ReviewListResponse, error) {
+	return s.listReviews(repository.ReviewFilter{BusCode: busCode, Status: models.ReviewPublished}, offset, limit, dto.FromReviewModel)
+}
+
+// ListRouteReviews retrieves the published reviews of a route, newest first.
+func (s *ReviewService) ListRouteReviews(routeID uint, offset, limit int) (*dto.ReviewListResponse, error) {
+	return s.listReviews(repository.ReviewFilter{RouteID: routeID, Status: models.ReviewPublished}, offset, limit, dto.FromReviewModel)
+}
+
+// GetBusRatings retrieves the aggregated ratings of the buses with the given codes. Buses without reviews
+// are omitted.
+func (s *ReviewService) GetBusRatings(busCodes []string) ([]dto.RatingSummaryResponse, error) {
+	aggregates, err := s.reviewRepo.AggregateByBus(busCodes)
+	if err != nil {
+		return nil, err
+	}
+	return ratingSummaries(aggregates), nil
+}
+
+// GetRouteRatings retrieves the aggregated ratings of the given routes. Routes without reviews are omitted.
+func (s *ReviewService) GetRouteRatings(routeIDs []uint) ([]dto.RatingSummaryResponse, error) {
+	aggregates, err := s.reviewRepo.AggregateByRoute(routeIDs)
+	if err != nil {
+		return nil, err
+	}
+	return ratingSummaries(aggregates), nil
+}
+
+// ListReviewsForModeration retrieves reviews in any status, optionally filtered by status, for admins.
+func (s *ReviewService) ListReviewsForModeration(status string, offset, limit int) (*dto.ReviewListResponse, error) {
+	filter := repository.ReviewFilter{Status: models.ReviewStatus(status)}
+	return s.listReviews(filter, offset, limit, dto.FromReviewModelForModeration)
+}
+
+// ModerateReview publishes or hides a review. Hidden reviews no longer count towards ratings.
+func (s *ReviewService) ModerateReview(id uint, req dto.ModerateReviewRequest) (*dto.ReviewResponse, error) {
+	review, err := s.reviewRepo.GetReviewByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReviewNotFound
+		}
+		return nil, err
+	}
+
+	now := s.now()
+	review.Status = models.ReviewStatus(req.Status)
+	review.ModeratedBy = &req.ModeratorID
+	review.ModeratedAt = &now
+	review.ModerationReason = req.Reason
+	if err := s.reviewRepo.UpdateModeration(review); err != nil {
+		return nil, fmt.Errorf("failed to moderate review: %w", err)
+	}
+	response := dto.FromReviewModelForModeration(*review)
+	return &response, nil
+}
+
+func (s *ReviewService) listReviews(filter repository.ReviewFilter, offset, limit int, convert func(models.Review) dto.ReviewResponse) (*dto.ReviewListResponse, error) {
+	reviews, total, err := s.reviewRepo.ListReviews(filter, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]dto.ReviewResponse, len(reviews))
+	for i, review := range reviews {
+		responses[i] = convert(review)
+	}
+	return &dto.ReviewListResponse{Reviews: responses, Total: total}, nil
+}
+
+func ratingSummaries(aggregates []repository.RatingAggregate) []dto.RatingSummaryResponse {
+	summaries := make([]dto.RatingSummaryResponse, len(aggregates))
+	for i, aggregate := range aggregates {
+		summaries[i] = dto.FromRatingAggregate(aggregate)
+	}
+	return summaries
+}
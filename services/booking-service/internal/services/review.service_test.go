@@ -0,0 +1,140 @@
+package services
+
+import (
+	"booking-service/internal/api/dto"
+	"booking-service/internal/models"
+	"booking-service/internal/repository"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type fakeReviewRepository struct {
+	repository.IReviewRepository
+	existing  *models.Review
+	created   *models.Review
+	createErr error
+}
+
+func (r *fakeReviewRepository) GetReviewByBookingID(bookingID uint) (*models.Review, error) {
+	return r.existing, nil
+}
+
+func (r *fakeReviewRepository) CreateReview(review *models.Review) error {
+	r.created = review
+	return r.createErr
+}
+
+func (r *fakeReviewRepository) GetReviewByID(id uint) (*models.Review, error) {
+	return r.existing, nil
+}
+
+func (r *fakeReviewRepository) UpdateModeration(review *models.Review) error {
+	return nil
+}
+
+func TestCreateReviewEligibility(t *testing.T) {
+	now := time.Date(2026, time.June, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		userID   uint
+		status   models.BookingStatus
+		departed time.Time
+		existing *models.Review
+		want     error
+	}{
+		{name: "checked-in passenger", userID: 2, status: models.BookingCheckedIn, departed: now.Add(-48 * time.Hour)},
+		{name: "someone else's booking", userID: 5, status: models.BookingCheckedIn, departed: now.Add(-time.Hour), want: ErrReviewNotAllowed},
+		{name: "no-show", userID: 2, status: models.BookingNoShow, departed: now.Add(-time.Hour), want: ErrReviewNotAllowed},
+		{name: "after the review window", userID: 2, status: models.BookingCheckedIn, departed: now.Add(-8 * 24 * time.Hour), want: ErrReviewWindowClosed},
+		{name: "already reviewed", userID: 2, status: models.BookingCheckedIn, departed: now.Add(-time.Hour), existing: &models.Review{}, want: ErrReviewAlreadyExists},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			booking := &models.Booking{UserID: 2, BusID: 8, RouteID: 3, Status: tt.status, DepartureTime: tt.departed}
+			booking.ID = 6
+			reviewRepo := &fakeReviewRepository{existing: tt.existing}
+			service := &ReviewService{
+				reviewRepo:   reviewRepo,
+				bookingRepo:  &fakeBookingRepository{booking: booking},
+				busService:   &fakeBusService{busCode: "DHK101"},
+				reviewWindow: 7 * 24 * time.Hour,
+				now:          func() time.Time { return now },
+			}
+
+			_, err := service.CreateReview(booking.ID, dto.CreateReviewRequest{UserID: tt.userID, TripRating: 4, DriverRating: 5, BusConditionRating: 3})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("CreateReview() error = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && (reviewRepo.created.RouteID != 3 || reviewRepo.created.Status != models.ReviewPublished) {
+				t.Errorf("CreateReview() stored %+v, want a published review of route 3", reviewRepo.created)
+			}
+		})
+	}
+}
+
+type fakeBusService struct {
+	busCode string
+	err     error
+}
+
+func (s *fakeBusService) GetBusCode(busID uint) (string, error) {
+	return s.busCode, s.err
+}
+
+func TestCreateReviewStoresTheBusCode(t *testing.T) {
+	busErr := errors.New("bus Service responded with status code: 503")
+	tests := []struct {
+		name      string
+		status    models.BookingStatus
+		departure time.Duration // Before now
+		busErr    error
+		createErr error
+		want      error
+	}{
+		{name: "created", status: models.BookingCheckedIn, departure: time.Hour},
+		{name: "bus lookup failed", status: models.BookingCheckedIn, departure: time.Hour, busErr: busErr, want: busErr},
+		{name: "reviewed concurrently", status: models.BookingCheckedIn, departure: time.Hour, createErr: gorm.ErrDuplicatedKey, want: ErrReviewAlreadyExists},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2026, time.June, 1, 12, 0, 0, 0, time.UTC)
+			booking := &models.Booking{UserID: 2, BusID: 8, RouteID: 3, Status: tt.status, DepartureTime: now.Add(-tt.departure)}
+			booking.ID = 6
+			reviewRepo := &fakeReviewRepository{createErr: tt.createErr}
+			service := &ReviewService{
+				reviewRepo:   reviewRepo,
+				bookingRepo:  &fakeBookingRepository{booking: booking},
+				busService:   &fakeBusService{busCode: "DHK101", err: tt.busErr},
+				reviewWindow: 30 * 24 * time.Hour,
+				now:          func() time.Time { return now },
+			}
+
+			response, err := service.CreateReview(booking.ID, dto.CreateReviewRequest{
+				UserID: 2, TripRating: 5, DriverRating: 4, BusConditionRating: 3,
+			})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("CreateReview() error = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && (response.BusCode != "DHK101" || reviewRepo.created.BusCode != "DHK101") {
+				t.Errorf("CreateReview() bus code = %q, want DHK101", response.BusCode)
+			}
+		})
+	}
+}
+
+func TestModerateReviewRecordsTheModerator(t *testing.T) {
+	now := time.Date(2026, time.June, 1, 12, 0, 0, 0, time.UTC)
+	review := &models.Review{Status: models.ReviewPublished}
+	service := &ReviewService{reviewRepo: &fakeReviewRepository{existing: review}, now: func() time.Time { return now }}
+
+	response, err := service.ModerateReview(1, dto.ModerateReviewRequest{Status: "hidden", ModeratorID: 9, Reason: "Abusive"})
+	if err != nil {
+		t.Fatalf("ModerateReview() error = %v", err)
+	}
+	if response.Status != string(models.ReviewHidden) || *response.ModeratedBy != 9 || !response.ModeratedAt.Equal(now) {
+		t.Errorf("ModerateReview() = %+v, want hidden by moderator 9 at %v", response, now)
+	}
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/go-resty/resty/v2"
+)
+
+var (
+	busServiceBaseURL = os.Getenv("BUS_SERVICE_BASE_URL")
+)
+
+// seatsWriteScope is the scope of the service token booking-service changes seat statuses in bus-service with.
+const seatsWriteScope = "seats:write"
+
+// Seat statuses understood by bus-service.
+const (
+	SeatStatusAvailable = "Available"
+	SeatStatusReserved  = "Reserved"
+	SeatStatusBooked    = "Booked"
+)
+
+// ISeatService defines the operations booking-service performs on seats owned by bus-service.
+type ISeatService interface {
+	UpdateSeatStatus(busID, seatID uint, status string) error
+	ReleaseSeat(busID, seatID uint) error
+}
+
+// SeatService talks to bus-service over HTTP to change seat statuses.
+type SeatService struct {
+	restyClient *resty.Client
+}
+
+// NewSeatService creates a new instance of SeatService.
+func NewSeatService() ISeatService {
+	return &SeatService{
+		restyClient: newServiceClient(seatsWriteScope),
+	}
+}
+
+// UpdateSeatStatus sets the status of a seat on a bus.
+func (s *SeatService) UpdateSeatStatus(busID, seatID uint, status string) error {
+	url := fmt.Sprintf("%s/%d/seats/%d/status", busServiceBaseURL, busID, seatID)
+	resp, err := s.restyClient.R().
+		SetBody(map[string]string{"seat_status": status}).
+		Put(url)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("bus Service responded with status code: %d", resp.StatusCode())
+	}
+	return nil
+}
+
+// ReleaseSeat makes a previously held seat available for booking again.
+func (s *SeatService) ReleaseSeat(busID, seatID uint) error {
+	return s.UpdateSeatStatus(busID, seatID, SeatStatusAvailable)
+}
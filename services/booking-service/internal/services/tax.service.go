@@ -0,0 +1,58 @@
+package services
+
+import (
+	"booking-service/internal/api/dto"
+	"booking-service/internal/repository"
+	"errors"
+)
+
+// ErrInvalidTaxRatePeriod is returned when a tax rate ends before it starts.
+var ErrInvalidTaxRatePeriod = errors.New("validTo must be after validFrom")
+
+// ITaxRateService defines the interface for tax rate configuration.
+type ITaxRateService interface {
+	CreateTaxRate(req dto.CreateTaxRateRequest) (*dto.TaxRateResponse, error)
+	ListTaxRates() ([]dto.TaxRateResponse, error)
+	DeleteTaxRate(id uint) error
+}
+
+// TaxRateService manages the tax rates applied to new invoices.
+type TaxRateService struct {
+	repo repository.ITaxRateRepository
+}
+
+// NewTaxRateService creates a new instance of TaxRateService.
+func NewTaxRateService(repo repository.ITaxRateRepository) ITaxRateService {
+	return &TaxRateService{repo: repo}
+}
+
+// CreateTaxRate adds a tax rate for a route, a region or, when neither is given, the default rate.
+func (s *TaxRateService) CreateTaxRate(req dto.CreateTaxRateRequest) (*dto.TaxRateResponse, error) {
+	if req.ValidTo != nil && !req.ValidTo.After(req.ValidFrom) {
+		return nil, ErrInvalidTaxRatePeriod
+	}
+	rate := req.ToModel()
+	if err := s.repo.Create(&rate); err != nil {
+		return nil, err
+	}
+	response := dto.FromTaxRateModel(rate)
+	return &response, nil
+}
+
+// ListTaxRates retrieves all configured tax rates.
+func (s *TaxRateService) ListTaxRates() ([]dto.TaxRateResponse, error) {
+	rates, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]dto.TaxRateResponse, len(rates))
+	for i, rate := range rates {
+		responses[i] = dto.FromTaxRateModel(rate)
+	}
+	return responses, nil
+}
+
+// DeleteTaxRate removes a tax rate; invoices already issued with it are not affected.
+func (s *TaxRateService) DeleteTaxRate(id uint) error {
+	return s.repo.Delete(id)
+}
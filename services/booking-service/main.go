@@ -17,6 +17,7 @@ package main
 import (
 	"booking-service/internal/api"
 	"booking-service/internal/config"
+	"booking-service/internal/models"
 	"github.com/joho/godotenv"
 	"log"
 	"os"
@@ -28,7 +29,12 @@ func main() {
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found, reading environment variables from system")
 	}
-	database := config.NewDatabase()
+	database := config.NewDatabase(
+		&models.Booking{}, &models.BookingPassenger{}, &models.BookingFee{},
+		&models.Invoice{}, &models.InvoiceLine{}, &models.InvoiceSequence{}, &models.TaxRate{},
+		&models.CorporateAccount{}, &models.CorporateMember{}, &models.CorporateStatement{}, &models.CorporateStatementLine{},
+		&models.Review{},
+	)
 	defer database.Close()
 
 	// Get the port number from the environment variable.
@@ -90,18 +90,29 @@ func (dto *UpdateBusDTO) ToModel(bus *models.Bus) *models.Bus {
 }
 
 type BusResponse struct {
-	ID              uint      `json:"id"`
-	RouteID         uint      `json:"routeId"`
-	BusCode         string    `json:"busCode"`
-	Capacity        int       `json:"capacity"`
-	MakeModel       string    `json:"makeModel"`
-	Year            int       `json:"year"`
-	LicensePlate    string    `json:"licensePlate"`
-	Status          string    `json:"status"`
-	LastServiceDate time.Time `json:"lastServiceDate"`
-	NextServiceDate time.Time `json:"nextServiceDate"`
-	CreatedAt       time.Time `json:"createdAt"`
-	UpdatedAt       time.Time `json:"updatedAt"`
+	ID              uint            `json:"id"`
+	RouteID         uint            `json:"routeId"`
+	BusCode         string          `json:"busCode"`
+	Capacity        int             `json:"capacity"`
+	MakeModel       string          `json:"makeModel"`
+	Year            int             `json:"year"`
+	LicensePlate    string          `json:"licensePlate"`
+	Status          string          `json:"status"`
+	LastServiceDate time.Time       `json:"lastServiceDate"`
+	NextServiceDate time.Time       `json:"nextServiceDate"`
+	CreatedAt       time.Time       `json:"createdAt"`
+	UpdatedAt       time.Time       `json:"updatedAt"`
+	Rating          *RatingResponse `json:"rating,omitempty"` // Omitted until the bus has been reviewed
+}
+
+// RatingResponse is the aggregated passenger rating of a bus, as computed by booking-service.
+type RatingResponse struct {
+	BusCode             string  `json:"busCode"`
+	ReviewCount         int64   `json:"reviewCount"`
+	AverageRating       float64 `json:"averageRating"`
+	AverageTrip         float64 `json:"averageTrip"`
+	AverageDriver       float64 `json:"averageDriver"`
+	AverageBusCondition float64 `json:"averageBusCondition"`
 }
 
 // FromModel converts Bus model to BusResponseDTO.
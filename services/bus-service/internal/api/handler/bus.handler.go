@@ -28,6 +28,8 @@ func NewBusHandler(busService *services.BusService) *BusHandler {
 // @Accept  json
 // @Produce  json
 // @Success 200 {array} dto.BusResponse "List of all buses"
+// @Failure 401 {object} pkg.APIResponse "Invalid API key or token"
+// @Failure 403 {object} pkg.APIResponse "API key not allowed to search"
 // @Failure 500 {object} pkg.APIResponse "Internal Server Error - Could not retrieve buses"
 //
 //	@Router / [get]
@@ -42,6 +44,7 @@ func (h *BusHandler) GetAllBuses(c *gin.Context) {
 		busesResponse = append(busesResponse, dto.FromModel(bus))
 
 	}
+	h.busService.AttachRatings(busesResponse)
 	pkg.RespondWithSuccess(c, http.StatusOK, busesResponse, "")
 }
 
@@ -65,7 +68,9 @@ func (h *BusHandler) GetBusByID(c *gin.Context) {
 		return
 	}
 
-	pkg.RespondWithSuccess(c, http.StatusOK, dto.FromModel(*bus), "")
+	busResponse := []dto.BusResponse{dto.FromModel(*bus)}
+	h.busService.AttachRatings(busResponse)
+	pkg.RespondWithSuccess(c, http.StatusOK, busResponse[0], "")
 }
 
 // CreateBus
@@ -235,6 +240,7 @@ func (h *BusHandler) GetBusesByStatus(c *gin.Context) {
 	for _, bus := range buses {
 		busesResponse = append(busesResponse, dto.FromModel(bus))
 	}
+	h.busService.AttachRatings(busesResponse)
 
 	pkg.RespondWithSuccess(c, http.StatusOK, busesResponse, "")
 }
@@ -283,7 +289,7 @@ func (h *BusHandler) UpdateBusServiceDates(c *gin.Context) {
 
 // GetBusesByRouteID godoc
 // @Summary Get all buses by route ID
-// @Description Retrieve a list of all buses that operate on a specific route.
+// @Description Retrieve a list of all buses that operate on a specific route. Called by signed-in users and by route-service with a service token that has the buses:read scope.
 // @Tags buses
 // @Accept  json
 // @Produce  json
@@ -292,6 +298,8 @@ func (h *BusHandler) UpdateBusServiceDates(c *gin.Context) {
 //
 // @Success 200 {array} dto.BusResponse "List of all buses"
 // @Failure 400 {object} pkg.APIResponse "Bad Request - Invalid route ID"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 403 {object} pkg.APIResponse "The client or API key does not have the buses:read scope"
 //
 //	@Router /routes/{routeId} [get]
 func (h *BusHandler) GetBusesByRouteID(c *gin.Context) {
@@ -311,6 +319,7 @@ func (h *BusHandler) GetBusesByRouteID(c *gin.Context) {
 	for _, bus := range buses {
 		busesResponse = append(busesResponse, dto.FromModel(bus))
 	}
+	h.busService.AttachRatings(busesResponse)
 
 	pkg.RespondWithSuccess(c, http.StatusOK, busesResponse, "")
 }
@@ -178,6 +178,8 @@ func (h *SeatHandler) DeleteSeat(c *gin.Context) {
 // @Accept  json
 // @Produce  json
 // @Success 200 {array} dto.SeatResponse "Array of available seats"
+// @Failure 401 {object} pkg.APIResponse "Invalid API key or token"
+// @Failure 403 {object} pkg.APIResponse "API key not allowed to search"
 // @Failure 500 {object} pkg.APIResponse "Internal Server Error"
 // @Router /{busID}/seats/availability [get]
 func (h *SeatHandler) GetAvailableSeats(c *gin.Context) {
@@ -191,7 +193,7 @@ func (h *SeatHandler) GetAvailableSeats(c *gin.Context) {
 }
 
 // UpdateSeatStatus @Summary Update the status of a seat
-// @Description Updates the status of a seat (booked, available, reserved)
+// @Description Updates the status of a seat (booked, available, reserved). Called by booking-service with a service token that has the seats:write scope, and by staff with the seats:update permission.
 // @Tags seats
 // @Accept  json
 // @Produce  json
@@ -199,6 +201,8 @@ func (h *SeatHandler) GetAvailableSeats(c *gin.Context) {
 // @Param status body dto.UpdateSeatRequest true "Seat status data"
 // @Success 200 {object} pkg.APIResponse "Successfully updated seat status"
 // @Failure 400 {object} pkg.APIResponse "Bad Request"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 403 {object} pkg.APIResponse "Neither a service with the seats:write scope nor allowed to update seats"
 // @Failure 500 {object} pkg.APIResponse "Internal Server Error"
 // @Router /{busID}/seats/{id}/status [put]
 func (h *SeatHandler) UpdateSeatStatus(c *gin.Context) {
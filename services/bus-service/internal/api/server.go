@@ -13,6 +13,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"shared/authz"
 	"syscall"
 	"time"
 
@@ -86,16 +87,21 @@ func (s *Server) setupNoRouteHandler() {
 // setup Bus routes
 func (s *Server) setupBusRoutes(v1 *gin.RouterGroup, b *handler.BusHandler) {
 	//bus routes
+	// Reads are public; the fleet is managed by admins, and mechanics take buses out of service. The buses of a
+	// route are read by route-service with a service token, which must have the buses:read scope. Partner
+	// integrations search buses with API keys allowed to search.
+	auth, manage := authz.Authenticate(), authz.RequirePermission(authz.PermissionFleetManage)
+	search := authz.AuthenticateOptional(authz.ScopeSearch)
 	busGroup := v1.Group("/")
 	{
-		busGroup.GET("/", b.GetAllBuses)
-		busGroup.POST("/", b.CreateBus)
+		busGroup.GET("/", search, b.GetAllBuses)
+		busGroup.POST("/", auth, manage, b.CreateBus)
 		busGroup.GET("/:busID", b.GetBusByID)
-		busGroup.PUT("/:busID", b.UpdateBus)
-		busGroup.DELETE("/:busID", b.DeleteBus)
+		busGroup.PUT("/:busID", auth, manage, b.UpdateBus)
+		busGroup.DELETE("/:busID", auth, manage, b.DeleteBus)
 		busGroup.GET("/status", b.GetBusesByStatus)
-		busGroup.PUT("/:busID/service-dates", b.UpdateBusServiceDates)
-		busGroup.GET("/routes/:routeId", b.GetBusesByRouteID)
+		busGroup.PUT("/:busID/service-dates", auth, authz.RequirePermission(authz.PermissionFleetMaintain), b.UpdateBusServiceDates)
+		busGroup.GET("/routes/:routeId", authz.Authenticate(authz.ScopeBusesRead), b.GetBusesByRouteID)
 	}
 
 }
@@ -103,16 +109,20 @@ func (s *Server) setupBusRoutes(v1 *gin.RouterGroup, b *handler.BusHandler) {
 // setup Seat routes
 func (s *Server) setupSeatRoutes(v1 *gin.RouterGroup, se *handler.SeatHandler) {
 	//seat routes
+	auth, manage := authz.Authenticate(), authz.RequirePermission(authz.PermissionFleetManage)
 	seatGroup := v1.Group("/:busID/seats/")
 	{
 		seatGroup.GET("/", se.GetSeatsByBus)
-		seatGroup.POST("/", se.CreateSeat)
+		seatGroup.POST("/", auth, manage, se.CreateSeat)
 		seatGroup.GET("/:id", se.GetSeatByID)
-		seatGroup.PUT("/:id", se.UpdateSeat)
-		seatGroup.DELETE("/:id", se.DeleteSeat)
+		seatGroup.PUT("/:id", auth, manage, se.UpdateSeat)
+		seatGroup.DELETE("/:id", auth, manage, se.DeleteSeat)
 		seatGroup.GET("/status/:status", se.GetSeatsByStatus)
-		seatGroup.PUT("/:id/status", se.UpdateSeatStatus)
-		seatGroup.GET("/availability", se.GetAvailableSeats)
+		// Seat status is updated by booking-service while booking, with a service token that must have the
+		// seats:write scope, and by conductors and dispatchers allowed to update seats at boarding
+		seatGroup.PUT("/:id/status", authz.Authenticate(authz.ScopeSeatsWrite), authz.RequireServiceOrPermission(authz.PermissionSeatsUpdate), se.UpdateSeatStatus)
+		// Partner integrations check seat availability with API keys allowed to search
+		seatGroup.GET("/availability", authz.AuthenticateOptional(authz.ScopeSearch), se.GetAvailableSeats)
 	}
 
 }
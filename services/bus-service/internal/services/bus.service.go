@@ -9,6 +9,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/go-resty/resty/v2"
@@ -16,26 +17,36 @@ import (
 )
 
 var (
-	bookingServiceBaseURL = "http://booking-service"
+	bookingServiceBaseURL = os.Getenv("BOOKING_SERVICE_BASE_URL")
 	routeServiceBaseURL   = os.Getenv("ROUTE_SERVICE_BASE_URL")
 )
 
+// Scopes of the service tokens bus-service calls other services with.
+const (
+	// routesReadScope reads routes from route-service.
+	routesReadScope = "routes:read"
+	// ratingsReadScope reads the ratings of buses from booking-service.
+	ratingsReadScope = "ratings:read"
+)
+
 type BusService struct {
-	busRepo     *repository.BusRepository
-	restyClient *resty.Client
+	busRepo       *repository.BusRepository
+	routeClient   *resty.Client
+	bookingClient *resty.Client
 }
 
 func NewBusService(busRepo *repository.BusRepository) *BusService {
 	return &BusService{
-		busRepo:     busRepo,
-		restyClient: resty.New(),
+		busRepo:       busRepo,
+		routeClient:   newServiceClient(routesReadScope),
+		bookingClient: newServiceClient(ratingsReadScope),
 	}
 }
 
 func (service *BusService) getRoute(routeID uint) (*dto.RouteResponse, error) {
 	var route dto.RouteResponse
 	url := fmt.Sprintf("%s/%d", routeServiceBaseURL, routeID)
-	resp, err := service.restyClient.R().
+	resp, err := service.routeClient.R().
 		SetResult(&route).
 		Get(url)
 
@@ -49,6 +60,61 @@ func (service *BusService) getRoute(routeID uint) (*dto.RouteResponse, error) {
 	return &route, nil // Return the route if everything is okay
 }
 
+// AttachRatings fills in the passenger ratings of the given buses from booking-service. Ratings are
+// optional, so a failure is logged and the buses are returned without them.
+func (service *BusService) AttachRatings(buses []dto.BusResponse) {
+	if len(buses) == 0 || bookingServiceBaseURL == "" {
+		return
+	}
+	ratings, err := service.getRatings(buses)
+	if err != nil {
+		log.Printf("error getting bus ratings: %v", err)
+		return
+	}
+	byBusCode := make(map[string]dto.RatingResponse, len(ratings))
+	for _, rating := range ratings {
+		byBusCode[rating.BusCode] = rating
+	}
+	for i := range buses {
+		if rating, ok := byBusCode[buses[i].BusCode]; ok {
+			buses[i].Rating = &rating
+		}
+	}
+}
+
+// getRatings fetches the ratings of the buses, by their codes, in batches of the size booking-service accepts.
+func (service *BusService) getRatings(buses []dto.BusResponse) ([]dto.RatingResponse, error) {
+	const batchSize = 100
+	var ratings []dto.RatingResponse
+	for start := 0; start < len(buses); start += batchSize {
+		end := start + batchSize
+		if end > len(buses) {
+			end = len(buses)
+		}
+		codes := make([]string, 0, end-start)
+		for _, bus := range buses[start:end] {
+			codes = append(codes, bus.BusCode)
+		}
+
+		var result struct {
+			Success bool                 `json:"success"`
+			Data    []dto.RatingResponse `json:"data"`
+		}
+		resp, err := service.bookingClient.R().
+			SetQueryParam("codes", strings.Join(codes, ",")).
+			SetResult(&result).
+			Get(bookingServiceBaseURL + "/ratings/buses")
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode() != http.StatusOK || !result.Success {
+			return nil, fmt.Errorf("BookingService responded with status code: %d", resp.StatusCode())
+		}
+		ratings = append(ratings, result.Data...)
+	}
+	return ratings, nil
+}
+
 // GetAllBuses retrieves all buses.
 func (service *BusService) GetAllBuses() ([]models.Bus, error) {
 	buses, err := service.busRepo.GetAllBuses()
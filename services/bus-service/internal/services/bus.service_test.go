@@ -0,0 +1,64 @@
+package services
+
+import (
+	"bus-service/internal/api/dto"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// serviceTokens sets up a token endpoint issuing tokens named after their scope, and returns a server that
+// records the token each request was sent with.
+func serviceTokens(t *testing.T) (*httptest.Server, *[]string) {
+	t.Helper()
+	tokens := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "token-" + r.FormValue("scope"), "expires_in": 300})
+	}))
+	t.Cleanup(tokens.Close)
+	t.Setenv("AUTH_SERVICE_TOKEN_URL", tokens.URL)
+	t.Setenv("SERVICE_CLIENT_ID", "bus")
+	t.Setenv("SERVICE_CLIENT_SECRET", "secret")
+
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = append(sent, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"success":true,"data":[]}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &sent
+}
+
+func TestBusServiceCallsWithScopedTokens(t *testing.T) {
+	server, sent := serviceTokens(t)
+	routeServiceBaseURL, bookingServiceBaseURL = server.URL, server.URL
+	t.Cleanup(func() { routeServiceBaseURL, bookingServiceBaseURL = "", "" })
+	service := NewBusService(nil)
+
+	tests := []struct {
+		name string
+		call func() error
+		want string
+	}{
+		{name: "route of a bus", call: func() error {
+			_, err := service.getRoute(1)
+			return err
+		}, want: "Bearer token-routes:read"},
+		{name: "ratings of buses", call: func() error {
+			_, err := service.getRatings([]dto.BusResponse{{BusCode: "B1"}})
+			return err
+		}, want: "Bearer token-ratings:read"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			*sent = nil
+			if err := tt.call(); err != nil {
+				t.Fatalf("call error = %v", err)
+			}
+			if len(*sent) != 1 || (*sent)[0] != tt.want {
+				t.Errorf("Authorization = %v, want %q", *sent, tt.want)
+			}
+		})
+	}
+}
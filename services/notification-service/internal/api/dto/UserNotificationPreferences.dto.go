@@ -69,3 +69,10 @@ func FromPreferencesModel(model models.UserNotificationPreferences) UserNotifica
 		PhoneNumber:  model.PhoneNumber,
 	}
 }
+
+// UserDataExportResponse holds everything notification-service keeps about a user, for the export of their
+// personal data.
+type UserDataExportResponse struct {
+	Preferences   *UserNotificationPreferencesResponse `json:"preferences"` // Nil when the user has none
+	Notifications []*NotificationResponse              `json:"notifications"`
+}
@@ -6,6 +6,7 @@ import (
 	"notification-service/internal/api/dto"
 	"notification-service/internal/services"
 	"notification-service/pkg"
+	"shared/authz"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -58,6 +59,7 @@ func (h *NotificationHandler) CreateNotification(c *gin.Context) {
 // @Param id path int true "Notification ID"
 // @Success 204 "Notification deleted successfully"
 // @Failure 400 {object} pkg.APIResponse "Invalid notification ID"
+// @Failure 403 {object} pkg.APIResponse "Notification belongs to another user"
 // @Failure 404 {object} pkg.APIResponse "Notification not found"
 // @Failure 500 {object} pkg.APIResponse "Internal server error"
 // @Router /{id} [delete]
@@ -68,6 +70,16 @@ func (h *NotificationHandler) DeleteNotification(c *gin.Context) {
 		return
 	}
 
+	notification, err := h.notificationService.GetNotification(uint(id))
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	if principal, _ := authz.CurrentPrincipal(c); !principal.CanAccessUser(notification.UserID) {
+		pkg.RespondWithError(c, http.StatusForbidden, authz.ErrForbidden)
+		return
+	}
+
 	if err := h.notificationService.DeleteNotification(uint(id)); err != nil {
 		pkg.RespondWithError(c, http.StatusInternalServerError, err)
 		return
@@ -84,6 +96,7 @@ func (h *NotificationHandler) DeleteNotification(c *gin.Context) {
 // @Param id path int true "Notification ID"
 // @Success 200 {object} dto.NotificationResponse "Notification fetched successfully"
 // @Failure 400 {object} pkg.APIResponse "Invalid notification ID"
+// @Failure 403 {object} pkg.APIResponse "Notification belongs to another user"
 // @Failure 404 {object} pkg.APIResponse "Notification not found"
 // @Failure 500 {object} pkg.APIResponse "Internal server error"
 // @Router /{id} [get]
@@ -99,13 +112,17 @@ func (h *NotificationHandler) GetNotification(c *gin.Context) {
 		pkg.RespondWithError(c, http.StatusInternalServerError, err)
 		return
 	}
+	if principal, _ := authz.CurrentPrincipal(c); !principal.CanAccessUser(response.UserID) {
+		pkg.RespondWithError(c, http.StatusForbidden, authz.ErrForbidden)
+		return
+	}
 
 	pkg.RespondWithSuccess(c, http.StatusOK, response, "Notification fetched successfully")
 }
 
 // ListNotifications handles GET /notifications
 // @Summary List notifications
-// @Description Retrieves a list of notifications, optionally filtered by user ID.
+// @Description Retrieves a list of notifications, optionally filtered by user ID. Users only see their own notifications.
 // @Tags notifications
 // @Produce json
 // @Param userID query int false "User ID"
@@ -114,6 +131,9 @@ func (h *NotificationHandler) GetNotification(c *gin.Context) {
 // @Router / [get]
 func (h *NotificationHandler) ListNotifications(c *gin.Context) {
 	userID, err := strconv.ParseUint(c.Query("userID"), 10, 32) // optional user ID filter
+	if principal, _ := authz.CurrentPrincipal(c); !principal.IsAdmin() {
+		userID = uint64(principal.UserID)
+	}
 
 	offset, _ := strconv.Atoi(c.Query("offset")) // pagination offset
 	limit, _ := strconv.Atoi(c.Query("limit"))   // pagination limit
@@ -140,6 +140,61 @@ func (h *UserNotificationHandler) DeleteUserPreferences(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// ExportUserData handles GET /users/{userID}/data
+// @Summary Export user data
+// @Description Retrieves the notification preferences and notifications of a user for the export of their personal data. Called by auth-service only.
+// @Tags User Preferences
+// @Produce json
+// @Param userID path int true "User ID"
+// @Success 200 {object} pkg.APIResponse "User data exported successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid user ID"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 403 {object} pkg.APIResponse "Not a service"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /users/{userID}/data [get]
+func (h *UserNotificationHandler) ExportUserData(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userID"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid user ID: %v", err))
+		return
+	}
+
+	export, err := h.userNotificationService.ExportUserData(uint(userID))
+	if err != nil {
+		pkg.RespondWithError(c, determineStatusCode(err), err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusOK, export, "User data exported successfully")
+}
+
+// EraseUserData handles DELETE /users/{userID}/data
+// @Summary Erase user data
+// @Description Permanently removes the notification preferences and notifications of a user whose account is erased. Called by auth-service only.
+// @Tags User Preferences
+// @Produce json
+// @Param userID path int true "User ID"
+// @Success 200 {object} pkg.APIResponse "User data erased successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid user ID"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 403 {object} pkg.APIResponse "Not a service"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /users/{userID}/data [delete]
+func (h *UserNotificationHandler) EraseUserData(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userID"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid user ID: %v", err))
+		return
+	}
+
+	if err := h.userNotificationService.EraseUserData(uint(userID)); err != nil {
+		pkg.RespondWithError(c, determineStatusCode(err), err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusOK, nil, "User data erased successfully")
+}
+
 // Helper function to determine the HTTP status code based on the error type.
 func determineStatusCode(err error) int {
 	//if err == services.ErrNotFound {
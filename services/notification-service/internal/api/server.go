@@ -7,6 +7,7 @@ import (
 	"notification-service/internal/config"
 	"notification-service/internal/repository"
 	"notification-service/internal/services"
+	"shared/authz"
 
 	"context"
 	"errors"
@@ -72,17 +73,26 @@ func (s *Server) setupHealthCheckRoute() {
 }
 
 func (s *Server) setupNotificationRoutes(v1 *gin.RouterGroup, n *handler.NotificationHandler) {
-	v1.POST("/", n.CreateNotification)
-	v1.GET("/:id", n.GetNotification)
-	v1.GET("/", n.ListNotifications)
-	v1.DELETE("/:id", n.DeleteNotification)
+	// Users read and delete their own notifications, only admins and other services send them
+	auth := authz.Authenticate()
+	v1.POST("/", authz.Authenticate(authz.ScopeNotificationsSend), authz.RequireRole(authz.RoleAdmin, authz.RoleService), n.CreateNotification)
+	v1.GET("/:id", auth, n.GetNotification)
+	v1.GET("/", auth, n.ListNotifications)
+	v1.DELETE("/:id", auth, n.DeleteNotification)
 }
 
 func (s *Server) setUpUserNotificationRoutes(v1 *gin.RouterGroup, h *handler.UserNotificationHandler) {
-	v1.GET("/users/:userID/preferences", h.GetUserPreferences)
-	v1.POST("/users/:userID/preferences", h.CreateUserPreferences)
-	v1.PUT("/users/:userID/preferences", h.UpdateUserPreferences)
-	v1.DELETE("/users/:userID/preferences", h.DeleteUserPreferences)
+	// Preferences are only managed by their owner and admins
+	auth, owner := authz.Authenticate(), authz.RequireOwner("userID")
+	v1.GET("/users/:userID/preferences", auth, owner, h.GetUserPreferences)
+	v1.POST("/users/:userID/preferences", auth, owner, h.CreateUserPreferences)
+	v1.PUT("/users/:userID/preferences", auth, owner, h.UpdateUserPreferences)
+	v1.DELETE("/users/:userID/preferences", auth, owner, h.DeleteUserPreferences)
+
+	// Export and erase the data of an account, called by auth-service
+	dataAuth, service := authz.Authenticate(authz.ScopeNotificationsUserData), authz.RequireRole(authz.RoleService)
+	v1.GET("/users/:userID/data", dataAuth, service, h.ExportUserData)
+	v1.DELETE("/users/:userID/data", dataAuth, service, h.EraseUserData)
 
 }
 
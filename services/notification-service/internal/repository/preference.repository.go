@@ -14,6 +14,8 @@ type UserNotification interface {
 	GetPreferences(userID uint) (models.UserNotificationPreferences, error)
 	UpdatePreferences(prefs models.UserNotificationPreferences) error
 	DeletePreferences(userID uint) error
+	ExportUserData(userID uint) (*models.UserNotificationPreferences, []models.Notification, error)
+	EraseUserData(userID uint) error
 }
 
 // UserNotificationRepositoryImpl  implements UserRepository with a GORM backend.
@@ -70,3 +72,35 @@ func (repo *UserNotificationRepositoryImpl) DeletePreferences(userID uint) error
 	}
 	return nil
 }
+
+// ExportUserData retrieves the notification preferences of a user, nil when they have none, and their
+// notifications, oldest first.
+func (repo *UserNotificationRepositoryImpl) ExportUserData(userID uint) (*models.UserNotificationPreferences, []models.Notification, error) {
+	var prefs models.UserNotificationPreferences
+	result := repo.db.Where("user_id = ?", userID).Limit(1).Find(&prefs)
+	if result.Error != nil {
+		return nil, nil, fmt.Errorf("failed to export user notification preferences: %w", result.Error)
+	}
+	var notifications []models.Notification
+	if err := repo.db.Where("user_id = ?", userID).Order("send_date, id").Find(&notifications).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to export user notifications: %w", err)
+	}
+	if result.RowsAffected == 0 {
+		return nil, notifications, nil
+	}
+	return &prefs, notifications, nil
+}
+
+// EraseUserData permanently removes the notification preferences and the notifications of a user, including
+// deleted ones.
+func (repo *UserNotificationRepositoryImpl) EraseUserData(userID uint) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.UserNotificationPreferences{}).Error; err != nil {
+			return fmt.Errorf("failed to erase user notification preferences: %w", err)
+		}
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.Notification{}).Error; err != nil {
+			return fmt.Errorf("failed to erase user notifications: %w", err)
+		}
+		return nil
+	})
+}
@@ -12,6 +12,8 @@ type UserNotificationService interface {
 	UpdateUserPreferences(userID uint, update dto.UserNotificationPreferencesUpdate) error
 	GetUserPreferences(userID uint) (dto.UserNotificationPreferencesResponse, error)
 	DeleteUserPreferences(userID uint) error
+	ExportUserData(userID uint) (dto.UserDataExportResponse, error)
+	EraseUserData(userID uint) error
 }
 
 type userNotificationServiceImpl struct {
@@ -71,3 +73,30 @@ func (s *userNotificationServiceImpl) DeleteUserPreferences(userID uint) error {
 	}
 	return nil
 }
+
+// ExportUserData gathers the notification preferences and the notifications of a user for the export of their
+// personal data.
+func (s *userNotificationServiceImpl) ExportUserData(userID uint) (dto.UserDataExportResponse, error) {
+	prefs, notifications, err := s.userRepo.ExportUserData(userID)
+	if err != nil {
+		return dto.UserDataExportResponse{}, fmt.Errorf("error exporting user data: %w", err)
+	}
+	export := dto.UserDataExportResponse{Notifications: make([]*dto.NotificationResponse, 0, len(notifications))}
+	if prefs != nil {
+		response := dto.FromPreferencesModel(*prefs)
+		export.Preferences = &response
+	}
+	for _, notification := range notifications {
+		export.Notifications = append(export.Notifications, dto.FromModel(notification))
+	}
+	return export, nil
+}
+
+// EraseUserData permanently removes everything notification-service holds about a user whose account is
+// erased. Calling it again succeeds, as there is nothing left to erase.
+func (s *userNotificationServiceImpl) EraseUserData(userID uint) error {
+	if err := s.userRepo.EraseUserData(userID); err != nil {
+		return fmt.Errorf("error erasing user data: %w", err)
+	}
+	return nil
+}
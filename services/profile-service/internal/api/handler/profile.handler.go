@@ -6,6 +6,7 @@ import (
 	"profile-service/internal/api/dto"
 	"profile-service/internal/services"
 	"profile-service/pkg"
+	"shared/authz"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -30,6 +31,8 @@ func NewProfileHandler(profileService services.IUserProfileService) *ProfileHand
 // @Param profile body dto.UserProfileRequest true "Create Profile Request"
 // @Success 201 {object} dto.UserProfileResponse "Profile created successfully"
 // @Failure 400 {object} pkg.APIResponse "Invalid profile data"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 403 {object} pkg.APIResponse "Profile belongs to another user"
 // @Failure 500 {object} pkg.APIResponse "Internal server error"
 // @Router /create [post]
 func (h *ProfileHandler) CreateProfile(c *gin.Context) {
@@ -38,6 +41,10 @@ func (h *ProfileHandler) CreateProfile(c *gin.Context) {
 		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid profile data: %v", err))
 		return
 	}
+	if principal, _ := authz.CurrentPrincipal(c); !principal.CanAccessUser(req.UserID) {
+		pkg.RespondWithError(c, http.StatusForbidden, authz.ErrForbidden)
+		return
+	}
 
 	profile, err := h.profileService.CreateUserProfile(req)
 	if err != nil {
@@ -134,3 +141,58 @@ func (h *ProfileHandler) DeleteProfile(c *gin.Context) {
 
 	pkg.RespondWithSuccess(c, http.StatusNoContent, nil, "Profile deleted successfully")
 }
+
+// ExportUserData handles GET /{userID}/data
+// @Summary Export user data
+// @Description Retrieves the profile of a user for the export of their personal data; data is null when they have none. Called by auth-service only.
+// @Tags profile
+// @Produce json
+// @Param userID path int true "User ID"
+// @Success 200 {object} pkg.APIResponse "User data exported successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid user ID"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 403 {object} pkg.APIResponse "Not a service"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /{userID}/data [get]
+func (h *ProfileHandler) ExportUserData(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userID"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid user ID: %v", err))
+		return
+	}
+
+	profile, err := h.profileService.ExportUserData(uint(userID))
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusOK, profile, "User data exported successfully")
+}
+
+// EraseUserData handles DELETE /{userID}/data
+// @Summary Erase user data
+// @Description Permanently removes the profile of a user whose account is erased. Called by auth-service only.
+// @Tags profile
+// @Produce json
+// @Param userID path int true "User ID"
+// @Success 200 {object} pkg.APIResponse "User data erased successfully"
+// @Failure 400 {object} pkg.APIResponse "Invalid user ID"
+// @Failure 401 {object} pkg.APIResponse "Missing or invalid token"
+// @Failure 403 {object} pkg.APIResponse "Not a service"
+// @Failure 500 {object} pkg.APIResponse "Internal server error"
+// @Router /{userID}/data [delete]
+func (h *ProfileHandler) EraseUserData(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userID"), 10, 32)
+	if err != nil {
+		pkg.RespondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid user ID: %v", err))
+		return
+	}
+
+	if err := h.profileService.EraseUserData(uint(userID)); err != nil {
+		pkg.RespondWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	pkg.RespondWithSuccess(c, http.StatusOK, nil, "User data erased successfully")
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	_ "profile-service/docs" // Required for Swagger docs
+	"shared/authz"
 
 	"profile-service/internal/api/handler"
 	"profile-service/internal/api/middleware"
@@ -68,17 +69,25 @@ func (s *Server) setupHealthCheckRoute() {
 }
 
 func (s *Server) setupProfileRoutes(v1 *gin.RouterGroup, p *handler.ProfileHandler) {
+	// Profiles are only visible to their owner and admins
+	auth, owner := authz.Authenticate(), authz.RequireOwner("userID")
+
 	// Create user profile
-	v1.POST("/create", p.CreateProfile)
+	v1.POST("/create", auth, p.CreateProfile)
 
 	// Get user profile
-	v1.GET("/:userID", p.GetProfile)
+	v1.GET("/:userID", auth, owner, p.GetProfile)
 
 	// Update user profile
-	v1.PUT("/:userID", p.UpdateProfile)
+	v1.PUT("/:userID", auth, owner, p.UpdateProfile)
 
 	// Delete user profile
-	v1.DELETE("/:userID", p.DeleteProfile)
+	v1.DELETE("/:userID", auth, owner, p.DeleteProfile)
+
+	// Export and erase the data of an account, called by auth-service
+	dataAuth, service := authz.Authenticate(authz.ScopeProfilesUserData), authz.RequireRole(authz.RoleService)
+	v1.GET("/:userID/data", dataAuth, service, p.ExportUserData)
+	v1.DELETE("/:userID/data", dataAuth, service, p.EraseUserData)
 
 }
 
@@ -12,6 +12,7 @@ type IUserProfileRepository interface {
 	GetByUserID(userID uint) (*models.UserProfile, error)
 	Update(profileID uint, profileData models.UserProfile) error
 	Delete(userID uint) error
+	Purge(userID uint) error
 }
 
 // UserProfileRepository handles database operations for user profiles using GORM.
@@ -71,3 +72,8 @@ func (repo *UserProfileRepository) Delete(userID uint) error {
 	}
 	return nil
 }
+
+// Purge permanently removes every UserProfile of a user, including deleted ones.
+func (repo *UserProfileRepository) Purge(userID uint) error {
+	return repo.db.Unscoped().Where("user_id = ?", userID).Delete(&models.UserProfile{}).Error
+}
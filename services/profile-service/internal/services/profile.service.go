@@ -37,6 +37,8 @@ type IUserProfileService interface {
 	GetUserProfile(userID uint) (*dto.UserProfileResponse, error)
 	UpdateUserProfile(userID uint, request dto.UserProfileUpdate) (*dto.UserProfileResponse, error)
 	DeleteUserProfile(userID uint) error
+	ExportUserData(userID uint) (*dto.UserProfileResponse, error)
+	EraseUserData(userID uint) error
 }
 
 // NewUserProfileService creates a new instance of user profile service.
@@ -138,3 +140,24 @@ func (s *UserProfileService) DeleteUserProfile(userID uint) error {
 	}
 	return nil
 }
+
+// ExportUserData retrieves the profile of a user for the export of their personal data. Users without a
+// profile have nothing to export, so nil is returned.
+func (s *UserProfileService) ExportUserData(userID uint) (*dto.UserProfileResponse, error) {
+	profile, err := s.repo.GetByUserID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	response := dto.FromUserProfileModel(*profile)
+	return &response, nil
+}
+
+// EraseUserData permanently removes the profile of a user whose account is erased. Users without a profile
+// have nothing to erase, so calling it again succeeds.
+func (s *UserProfileService) EraseUserData(userID uint) error {
+	return s.repo.Purge(userID)
+}
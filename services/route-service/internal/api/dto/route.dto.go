@@ -21,12 +21,13 @@ type RouteInfo struct {
 }
 
 type RouteResponse struct {
-	ID            uint           `json:"id"`
-	Name          string         `json:"name"`
-	StartLocation string         `json:"startLocation"`
-	EndLocation   string         `json:"endLocation"`
-	Stops         []StopResponse `json:"stops,omitempty"` // Optional Stops
-	Buses         []BusResponse  `json:"buses,omitempty"` // Optional Buses
+	ID            uint            `json:"id"`
+	Name          string          `json:"name"`
+	StartLocation string          `json:"startLocation"`
+	EndLocation   string          `json:"endLocation"`
+	Stops         []StopResponse  `json:"stops,omitempty"`  // Optional Stops
+	Buses         []BusResponse   `json:"buses,omitempty"`  // Optional Buses
+	Rating        *RatingResponse `json:"rating,omitempty"` // Omitted until the route has been reviewed
 	//CreatedAt string        `json:"createdAt"`
 	//UpdatedAt string        `json:"updatedAt"`
 }
@@ -82,11 +83,23 @@ func RouteModelToRouteInfo(route *models.Route) RouteInfo {
 }
 
 type BusResponse struct {
-	ID              uint      `json:"id"`
-	BusCode         string    `json:"busCode"`
-	Capacity        int       `json:"capacity"`
-	LicensePlate    string    `json:"licensePlate"`
-	Status          string    `json:"status"`
-	LastServiceDate time.Time `json:"lastServiceDate"`
-	NextServiceDate time.Time `json:"nextServiceDate"`
+	ID              uint            `json:"id"`
+	BusCode         string          `json:"busCode"`
+	Capacity        int             `json:"capacity"`
+	LicensePlate    string          `json:"licensePlate"`
+	Status          string          `json:"status"`
+	LastServiceDate time.Time       `json:"lastServiceDate"`
+	NextServiceDate time.Time       `json:"nextServiceDate"`
+	Rating          *RatingResponse `json:"rating,omitempty"`
+}
+
+// RatingResponse is the aggregated passenger rating of a route or bus, as computed by booking-service.
+type RatingResponse struct {
+	ID                  uint    `json:"id,omitempty"`      // Set on the ratings of a route
+	BusCode             string  `json:"busCode,omitempty"` // Set on the ratings of a bus
+	ReviewCount         int64   `json:"reviewCount"`
+	AverageRating       float64 `json:"averageRating"`
+	AverageTrip         float64 `json:"averageTrip"`
+	AverageDriver       float64 `json:"averageDriver"`
+	AverageBusCondition float64 `json:"averageBusCondition"`
 }
@@ -105,7 +105,7 @@ func (h *RouteHandler) GetAllRoutes(c *gin.Context) {
 // GetRouteByID godoc
 //
 //	@Summary		Get route by ID
-//	@Description	Retrieve a route by its unique ID
+//	@Description	Retrieve a route by its unique ID. Called by signed-in users and by bus-service with a service token that has the routes:read scope.
 //	@Tags			routes
 //	@Accept			json
 //	@Produce		json
@@ -114,6 +114,8 @@ func (h *RouteHandler) GetAllRoutes(c *gin.Context) {
 //
 //	@Success		200		{object}	dto.RouteResponse	"Successfully retrieved route"
 //	@Failure		400		{object}	pkg.ErrorMessage	"Invalid route ID"
+//	@Failure		401		{object}	pkg.ErrorMessage	"Missing or invalid token"
+//	@Failure		403		{object}	pkg.ErrorMessage	"The client or API key does not have the routes:read scope"
 //	@Failure		404		{object}	pkg.ErrorMessage	"Route not found"
 //	@Failure		500		{object}	pkg.ErrorMessage	"Unable to retrieve route"
 //	@Router			/routes/{routeId} [get]
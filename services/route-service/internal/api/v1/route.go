@@ -22,6 +22,8 @@ import (
 	"route-service/internal/config"
 	"route-service/internal/repository"
 	"route-service/internal/services"
+	"route-service/pkg"
+	"shared/authz"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -47,6 +49,11 @@ func SetupRoutes(router *gin.Engine, db *config.Database) {
 	router.GET("/swagger/v1/*any", func(c *gin.Context) {
 		ginSwagger.WrapHandler(swaggerFiles.Handler, swagUrl)(c)
 	})
+	// Authentication failures are reported in the same shape as the other errors of route-service
+	authz.SetErrorResponder(func(c *gin.Context, statusCode int, err error) {
+		c.JSON(statusCode, pkg.NewErrorResponse(err.Error()))
+	})
+
 	// Define handlers
 	rh := v1.NewRouteHandler(services.NewRouteService(repository.NewRouteRepository(db.Conn)))
 	sh := v1.NewStopHandler(services.NewStopService(repository.NewStopRepository(db.Conn)))
@@ -92,36 +99,44 @@ func setupNoRouteHandler(router *gin.Engine) {
 }
 
 func setupRouteHandlers(rg *gin.RouterGroup, rh *v1.RouteHandler) {
+	// Listing routes is public; changes are reserved to those allowed to manage routes. A single route is read by
+	// signed-in users and by bus-service with a service token, which must have the routes:read scope. Partner
+	// integrations search routes with API keys allowed to search.
+	auth, manage := authz.Authenticate(), authz.RequirePermission(authz.PermissionRoutesManage)
 	routesGroup := rg.Group("/")
 	// Route handlers
 	{
-		routesGroup.POST("/", rh.CreateRoute)
-		routesGroup.GET("/", rh.GetAllRoutes)
-		routesGroup.GET("/:routeId", rh.GetRouteByID)
-		routesGroup.PUT("/:routeId", rh.UpdateRoute)
-		routesGroup.DELETE("/:routeId", rh.DeleteRoute)
+		routesGroup.POST("/", auth, manage, rh.CreateRoute)
+		routesGroup.GET("/", authz.AuthenticateOptional(authz.ScopeSearch), rh.GetAllRoutes)
+		routesGroup.GET("/:routeId", authz.Authenticate(authz.ScopeRoutesRead), rh.GetRouteByID)
+		routesGroup.PUT("/:routeId", auth, manage, rh.UpdateRoute)
+		routesGroup.DELETE("/:routeId", auth, manage, rh.DeleteRoute)
 	}
 }
 
 func setupStopHandlers(rg *gin.RouterGroup, sh v1.StopHandlerInterface) {
+	// Reads are public; changes are reserved to those allowed to manage routes
+	auth, manage := authz.Authenticate(), authz.RequirePermission(authz.PermissionRoutesManage)
 	stopsGroup := rg.Group("/:routeId/stops")
 	// Stop handlers
 	{
 		stopsGroup.GET("/", sh.ListStopsForRoute)
-		stopsGroup.POST("/", sh.AddStopToRoute)
+		stopsGroup.POST("/", auth, manage, sh.AddStopToRoute)
 		stopsGroup.GET("/:id", sh.GetStopByID)
-		stopsGroup.PUT("/:id", sh.UpdateStop)
-		stopsGroup.DELETE("/:id", sh.DeleteStop)
+		stopsGroup.PUT("/:id", auth, manage, sh.UpdateStop)
+		stopsGroup.DELETE("/:id", auth, manage, sh.DeleteStop)
 	}
 }
 
 func setupScheduleHandlers(rg *gin.RouterGroup, sch *v1.ScheduleHandler) {
+	// Reads are public; changes are reserved to dispatchers
+	auth, manage := authz.Authenticate(), authz.RequirePermission(authz.PermissionSchedulesManage)
 	schedulesGroup := rg.Group("/stops/:stopId/schedules")
 	{
-		schedulesGroup.POST("/", sch.CreateSchedule)
+		schedulesGroup.POST("/", auth, manage, sch.CreateSchedule)
 		schedulesGroup.GET("/", sch.GetSchedules)
 		schedulesGroup.GET("/:id", sch.GetScheduleByID)
-		schedulesGroup.PUT("/:id", sch.UpdateSchedule)
-		schedulesGroup.DELETE("/:id", sch.DeleteSchedule)
+		schedulesGroup.PUT("/:id", auth, manage, sch.UpdateSchedule)
+		schedulesGroup.DELETE("/:id", auth, manage, sch.DeleteSchedule)
 	}
 }
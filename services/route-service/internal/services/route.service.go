@@ -8,18 +8,30 @@ import (
 	"route-service/internal/api/dto"
 	"route-service/internal/models"
 	"route-service/internal/repository"
+	"strconv"
+	"strings"
 
 	"github.com/go-resty/resty/v2"
 )
 
 // RouteService provides methods to work with the routes' repository.
 type RouteService struct {
-	repo        *repository.RouteRepository
-	restyClient *resty.Client
+	repo          *repository.RouteRepository
+	busClient     *resty.Client
+	bookingClient *resty.Client
 }
 
 var (
-	busServiceBaseURL = os.Getenv("BUS_SERVICE_BASE_URL")
+	busServiceBaseURL     = os.Getenv("BUS_SERVICE_BASE_URL")
+	bookingServiceBaseURL = os.Getenv("BOOKING_SERVICE_BASE_URL")
+)
+
+// Scopes of the service tokens route-service calls other services with.
+const (
+	// busesReadScope reads buses from bus-service.
+	busesReadScope = "buses:read"
+	// ratingsReadScope reads the ratings of routes from booking-service.
+	ratingsReadScope = "ratings:read"
 )
 
 type BusServiceResponse struct {
@@ -27,11 +39,17 @@ type BusServiceResponse struct {
 	Data    []dto.BusResponse `json:"data"`
 }
 
+type RatingServiceResponse struct {
+	Success bool                 `json:"success"`
+	Data    []dto.RatingResponse `json:"data"`
+}
+
 // NewRouteService creates a new instance of RouteService.
 func NewRouteService(repo *repository.RouteRepository) *RouteService {
 	return &RouteService{
-		repo:        repo,
-		restyClient: resty.New(),
+		repo:          repo,
+		busClient:     newServiceClient(busesReadScope),
+		bookingClient: newServiceClient(ratingsReadScope),
 	}
 }
 
@@ -64,6 +82,7 @@ func (s *RouteService) GetRoutes(ctx context.Context) ([]dto.RouteResponse, erro
 		// If MapRouteModelToRouteResponse returns a pointer, use *mappedRoute; otherwise, use mappedRoute directly.
 		routesResponse = append(routesResponse, *mappedRoute)
 	}
+	attachRatings(s, routesResponse)
 
 	// Return the populated slice of route responses
 	return routesResponse, nil
@@ -75,7 +94,9 @@ func (s *RouteService) GetRouteByID(ctx context.Context, id uint) (*dto.RouteRes
 	if err != nil {
 		return nil, err
 	}
-	return MapRouteModelToRouteResponse(route, s, true), nil
+	routesResponse := []dto.RouteResponse{*MapRouteModelToRouteResponse(route, s, true)}
+	attachRatings(s, routesResponse)
+	return &routesResponse[0], nil
 }
 
 // UpdateRoute updates an existing route's details based on the provided request.
@@ -137,7 +158,7 @@ func MapRouteModelToRouteResponse(route *models.Route, s *RouteService, isExPend
 func getBuses(s *RouteService, routeID uint) ([]dto.BusResponse, error) {
 	var busServiceResponse BusServiceResponse
 	url := fmt.Sprintf("%s/routes/%d", busServiceBaseURL, routeID)
-	resp, err := s.restyClient.R().
+	resp, err := s.busClient.R().
 		SetResult(&busServiceResponse).
 		Get(url)
 	if err != nil {
@@ -154,3 +175,58 @@ func getBuses(s *RouteService, routeID uint) ([]dto.BusResponse, error) {
 
 	return busServiceResponse.Data, nil
 }
+
+// attachRatings fills in the passenger ratings of the given routes from booking-service. Ratings are
+// optional, so a failure is logged and the routes are returned without them.
+func attachRatings(s *RouteService, routes []dto.RouteResponse) {
+	if len(routes) == 0 || bookingServiceBaseURL == "" {
+		return
+	}
+	ratings, err := getRatings(s, routes)
+	if err != nil {
+		fmt.Printf("error getting route ratings: %v\n", err)
+		return
+	}
+	byRouteID := make(map[uint]dto.RatingResponse, len(ratings))
+	for _, rating := range ratings {
+		byRouteID[rating.ID] = rating
+	}
+	for i := range routes {
+		if rating, ok := byRouteID[routes[i].ID]; ok {
+			routes[i].Rating = &rating
+		}
+	}
+}
+
+// getRatings fetches the ratings of the routes in batches of the size booking-service accepts.
+func getRatings(s *RouteService, routes []dto.RouteResponse) ([]dto.RatingResponse, error) {
+	const batchSize = 100
+	var ratings []dto.RatingResponse
+	for start := 0; start < len(routes); start += batchSize {
+		end := start + batchSize
+		if end > len(routes) {
+			end = len(routes)
+		}
+		ids := make([]string, 0, end-start)
+		for _, route := range routes[start:end] {
+			ids = append(ids, strconv.FormatUint(uint64(route.ID), 10))
+		}
+
+		var ratingServiceResponse RatingServiceResponse
+		resp, err := s.bookingClient.R().
+			SetQueryParam("ids", strings.Join(ids, ",")).
+			SetResult(&ratingServiceResponse).
+			Get(bookingServiceBaseURL + "/ratings/routes")
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode() != http.StatusOK {
+			return nil, fmt.Errorf("booking Service responded with status code: %d", resp.StatusCode())
+		}
+		if !ratingServiceResponse.Success {
+			return nil, fmt.Errorf("booking Service responded with success: false")
+		}
+		ratings = append(ratings, ratingServiceResponse.Data...)
+	}
+	return ratings, nil
+}
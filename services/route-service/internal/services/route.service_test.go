@@ -0,0 +1,64 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"route-service/internal/api/dto"
+	"testing"
+)
+
+// serviceTokens sets up a token endpoint issuing tokens named after their scope, and returns a server that
+// records the token each request was sent with.
+func serviceTokens(t *testing.T) (*httptest.Server, *[]string) {
+	t.Helper()
+	tokens := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "token-" + r.FormValue("scope"), "expires_in": 300})
+	}))
+	t.Cleanup(tokens.Close)
+	t.Setenv("AUTH_SERVICE_TOKEN_URL", tokens.URL)
+	t.Setenv("SERVICE_CLIENT_ID", "route")
+	t.Setenv("SERVICE_CLIENT_SECRET", "secret")
+
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = append(sent, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"success":true,"data":[]}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &sent
+}
+
+func TestRouteServiceCallsWithScopedTokens(t *testing.T) {
+	server, sent := serviceTokens(t)
+	busServiceBaseURL, bookingServiceBaseURL = server.URL, server.URL
+	t.Cleanup(func() { busServiceBaseURL, bookingServiceBaseURL = "", "" })
+	service := NewRouteService(nil)
+
+	tests := []struct {
+		name string
+		call func() error
+		want string
+	}{
+		{name: "buses of a route", call: func() error {
+			_, err := getBuses(service, 1)
+			return err
+		}, want: "Bearer token-buses:read"},
+		{name: "ratings of routes", call: func() error {
+			_, err := getRatings(service, []dto.RouteResponse{{ID: 1}})
+			return err
+		}, want: "Bearer token-ratings:read"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			*sent = nil
+			if err := tt.call(); err != nil {
+				t.Fatalf("call error = %v", err)
+			}
+			if len(*sent) != 1 || (*sent)[0] != tt.want {
+				t.Errorf("Authorization = %v, want %q", *sent, tt.want)
+			}
+		})
+	}
+}
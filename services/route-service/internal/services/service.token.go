@@ -0,0 +1,31 @@
+package services
+
+import (
+	"shared/authz"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// newServiceClient returns a resty client calling other services with an access token of this service limited
+// to scope, got with the client credentials in SERVICE_CLIENT_ID and SERVICE_CLIENT_SECRET from the token
+// endpoint at AUTH_SERVICE_TOKEN_URL. Without client credentials, requests are sent without a token.
+func newServiceClient(scope string) *resty.Client {
+	source := authz.NewServiceTokenSourceFromEnv(scope)
+	return resty.New().
+		OnBeforeRequest(func(_ *resty.Client, r *resty.Request) error {
+			if !source.Enabled() {
+				return nil
+			}
+			token, err := source.Token()
+			if err != nil {
+				return err
+			}
+			r.SetAuthToken(token)
+			return nil
+		}).
+		OnAfterResponse(func(_ *resty.Client, resp *resty.Response) error {
+			// Drop a token the other service rejected, so the next request gets a new one
+			source.Expire(resp.StatusCode())
+			return nil
+		})
+}
@@ -0,0 +1,167 @@
+package authz
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// apiKeyCacheTTL is how long the introspection of an API key is reused, so revoked keys are accepted for at
+	// most this long.
+	apiKeyCacheTTL = time.Minute
+	// apiKeyCacheSize bounds the cached introspections, which include keys that were made up.
+	apiKeyCacheSize = 10000
+)
+
+var (
+	apiKeySource APIKeySource
+	apiKeyOnce   sync.Once
+	apiKeyCache  *APIKeyCache
+)
+
+// SetAPIKeySource sets where API keys are checked instead of the introspection endpoint of auth-service.
+// auth-service looks keys up itself. It must be called before the server starts.
+func SetAPIKeySource(source APIKeySource) {
+	apiKeySource = source
+}
+
+// apiKeys returns the API keys set by SetAPIKeySource, or those checked with auth-service at
+// AUTH_SERVICE_API_KEY_INTROSPECTION_URL with a service token allowed to introspect them.
+func apiKeys() APIKeySource {
+	if apiKeySource != nil {
+		return apiKeySource
+	}
+	apiKeyOnce.Do(func() {
+		apiKeyCache = NewAPIKeyCache(os.Getenv("AUTH_SERVICE_API_KEY_INTROSPECTION_URL"), NewServiceTokenSourceFromEnv(ScopeAPIKeysIntrospect))
+	})
+	return apiKeyCache
+}
+
+// apiKeyIntrospection mirrors the API key introspection response of auth-service.
+type apiKeyIntrospection struct {
+	Active   bool   `json:"active"`
+	KeyID    uint   `json:"keyId"`
+	UserID   uint   `json:"userId"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	Scope    string `json:"scope"`
+}
+
+// apiKeyEntry is a cached introspection; principal is nil for inactive keys.
+type apiKeyEntry struct {
+	principal *Principal
+	expiresAt time.Time
+}
+
+// APIKeyCache checks API keys with auth-service and caches the results for apiKeyCacheTTL, keyed by the hash
+// of the key so the keys themselves are not kept in memory.
+type APIKeyCache struct {
+	url    string
+	tokens *ServiceTokenSource
+	client *http.Client
+
+	mu      sync.Mutex
+	entries map[string]apiKeyEntry
+}
+
+// NewAPIKeyCache creates a new APIKeyCache for the introspection endpoint at url, called with the service
+// tokens of tokens.
+func NewAPIKeyCache(url string, tokens *ServiceTokenSource) *APIKeyCache {
+	return &APIKeyCache{
+		url:     url,
+		tokens:  tokens,
+		client:  &http.Client{Timeout: 5 * time.Second},
+		entries: make(map[string]apiKeyEntry),
+	}
+}
+
+// APIKeyPrincipal returns the principal the API key authenticates.
+func (c *APIKeyCache) APIKeyPrincipal(key string) (*Principal, error) {
+	sum := sha256.Sum256([]byte(key))
+	hash := hex.EncodeToString(sum[:])
+
+	c.mu.Lock()
+	entry, ok := c.entries[hash]
+	c.mu.Unlock()
+	if !ok || time.Now().After(entry.expiresAt) {
+		principal, err := c.introspect(key)
+		if err != nil {
+			return nil, err
+		}
+		entry = apiKeyEntry{principal: principal, expiresAt: time.Now().Add(apiKeyCacheTTL)}
+		c.store(hash, entry)
+	}
+	if entry.principal == nil {
+		return nil, errInvalidKey
+	}
+	principal := *entry.principal
+	return &principal, nil
+}
+
+func (c *APIKeyCache) store(hash string, entry apiKeyEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= apiKeyCacheSize {
+		now := time.Now()
+		for h, e := range c.entries {
+			if now.After(e.expiresAt) {
+				delete(c.entries, h)
+			}
+		}
+		if len(c.entries) >= apiKeyCacheSize {
+			c.entries = make(map[string]apiKeyEntry)
+		}
+	}
+	c.entries[hash] = entry
+}
+
+func (c *APIKeyCache) introspect(key string) (*Principal, error) {
+	if c.url == "" {
+		return nil, errors.New("AUTH_SERVICE_API_KEY_INTROSPECTION_URL is not set")
+	}
+	body, err := json.Marshal(map[string]string{"key": key})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := c.tokens.Authorize(req); err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to introspect api key: %w", err)
+	}
+	defer resp.Body.Close()
+	c.tokens.Expire(resp.StatusCode)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to introspect api key: status %d", resp.StatusCode)
+	}
+
+	var result apiKeyIntrospection
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode api key introspection: %w", err)
+	}
+	if !result.Active {
+		return nil, nil
+	}
+	return &Principal{
+		UserID:   result.UserID,
+		Username: result.Username,
+		Role:     result.Role,
+		APIKeyID: result.KeyID,
+		Scopes:   strings.Fields(result.Scope),
+	}, nil
+}
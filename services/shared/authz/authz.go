@@ -0,0 +1,371 @@
+// Package authz authenticates the callers of the services with the access tokens and API keys issued by
+// auth-service, and checks what they may access. It is shared by every service.
+package authz
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Roles issued by auth-service.
+const (
+	RoleAdmin   = "admin"   // Allowed to manage every resource
+	RoleService = "service" // Another service calling on its own behalf, not for a user
+)
+
+// Permissions granted to roles by auth-service. Admins have every permission.
+const (
+	PermissionUsersRead       = "users:read"
+	PermissionUsersUnlock     = "users:unlock"
+	PermissionFleetManage     = "fleet:manage"
+	PermissionFleetMaintain   = "fleet:maintain"
+	PermissionRoutesManage    = "routes:manage"
+	PermissionSchedulesManage = "schedules:manage"
+	PermissionManifestRead    = "manifest:read"
+	PermissionSeatsUpdate     = "seats:update"
+	PermissionBookingsRead    = "bookings:read"
+	PermissionInvoicesManage  = "invoices:manage"
+	PermissionReviewsModerate = "reviews:moderate"
+	PermissionAuditRead       = "audit:read"
+)
+
+// Scopes an API key can be limited to.
+const (
+	ScopeSearch       = "search"        // Search routes, buses and seat availability
+	ScopeBook         = "book"          // Create and manage bookings
+	ScopeReadManifest = "manifest:read" // Read passenger manifests of trips
+)
+
+// Scopes auth-service grants to service clients, other services calling with a token of their own.
+const (
+	ScopeRoutesRead            = "routes:read"             // Read routes from route-service
+	ScopeBusesRead             = "buses:read"              // Read buses from bus-service
+	ScopeSeatsWrite            = "seats:write"             // Change seat statuses in bus-service
+	ScopeRatingsRead           = "ratings:read"            // Read the ratings of buses and routes from booking-service
+	ScopeAPIKeysIntrospect     = "api-keys:introspect"     // Check the API keys sent to the service with auth-service
+	ScopeNotificationsSend     = "notifications:send"      // Send notifications to users through notification-service
+	ScopeProfilesUserData      = "profiles:user-data"      // Export and erase the data of accounts in profile-service
+	ScopeNotificationsUserData = "notifications:user-data" // Export and erase the data of accounts in notification-service
+	ScopeBookingsUserData      = "bookings:user-data"      // Export and erase the data of accounts in booking-service
+)
+
+const (
+	// principalKey is the gin context key holding the authenticated Principal.
+	principalKey = "principal"
+	// apiKeyHeader carries the API key of partner integrations, sent instead of a bearer token.
+	apiKeyHeader = "X-API-Key"
+)
+
+var (
+	// ErrMissingToken is returned when a request needs a token or API key and has none.
+	ErrMissingToken = errors.New("missing bearer token")
+	errInvalidToken = errors.New("invalid, expired or revoked token")
+	errInvalidKey   = errors.New("invalid, revoked or expired api key")
+	errScope        = errors.New("the api key or client is not allowed to access this resource")
+	// ErrForbidden is returned when the principal may not access a resource.
+	ErrForbidden = errors.New("you are not allowed to access this resource")
+)
+
+// Principal is the caller authenticated by an access token or API key issued by auth-service.
+type Principal struct {
+	UserID        uint
+	Username      string
+	Role          string   // Role of the account
+	Roles         []string // Every role of the user, including Role
+	Permissions   []string // Permissions granted by Roles
+	PhoneVerified bool     // The user has a verified phone number
+	SessionID     uint
+	APIKeyID      uint     // Set when authenticated by an API key
+	ClientID      string   // OAuth client the token was issued to
+	Scopes        []string // Scopes of the API key or OAuth client
+}
+
+// HasRole reports whether the principal has role, as the role of their account or given in addition.
+func (p *Principal) HasRole(role string) bool {
+	return p.Role == role || contains(p.Roles, role)
+}
+
+// IsAdmin reports whether the principal has the admin role.
+func (p *Principal) IsAdmin() bool {
+	return p.HasRole(RoleAdmin)
+}
+
+// HasPermission reports whether the principal was granted permission. Admins have every permission.
+func (p *Principal) HasPermission(permission string) bool {
+	return p.IsAdmin() || contains(p.Permissions, permission)
+}
+
+// CanAccessUser reports whether the principal may act on the data of userID: their own, or anyone's for admins.
+func (p *Principal) CanAccessUser(userID uint) bool {
+	return p.IsAdmin() || p.UserID == userID
+}
+
+// IsServiceClient reports whether the principal is a service authenticated with its own client credentials.
+func (p *Principal) IsServiceClient() bool {
+	return p.Role == RoleService && p.ClientID != "" && p.UserID == 0
+}
+
+// IsScoped reports whether the principal is limited to its scopes: an API key, a service client, or an OAuth
+// client acting for a user.
+func (p *Principal) IsScoped() bool {
+	return p.APIKeyID != 0 || p.ClientID != ""
+}
+
+// HasScopes reports whether the principal may use all of scopes. Principals that are not scoped may use any.
+func (p *Principal) HasScopes(scopes ...string) bool {
+	if !p.IsScoped() {
+		return true
+	}
+	for _, scope := range scopes {
+		if !contains(p.Scopes, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+// signingAlgorithms are the algorithms auth-service signs access tokens with.
+var signingAlgorithms = []string{"RS256", "EdDSA"}
+
+// KeySource resolves the public key verifying tokens signed with the key kid.
+type KeySource interface {
+	PublicKey(kid string) (crypto.PublicKey, error)
+}
+
+// APIKeySource resolves the principal an API key authenticates, failing for inactive keys.
+type APIKeySource interface {
+	APIKeyPrincipal(key string) (*Principal, error)
+}
+
+// SessionSource reports whether the session of an access token is still active, failing when that cannot be
+// checked.
+type SessionSource interface {
+	SessionActive(sessionID uint, token string) (bool, error)
+}
+
+// claims mirrors the access token claims of auth-service.
+type claims struct {
+	UserID        uint     `json:"userID"`
+	Username      string   `json:"username"`
+	Role          string   `json:"role"`
+	Roles         []string `json:"roles"`
+	Permissions   []string `json:"perms"`
+	PhoneVerified bool     `json:"phone_verified"`
+	SessionID     uint     `json:"sid"`
+	ClientID      string   `json:"client_id"`
+	Scope         string   `json:"scope"`
+	jwt.RegisteredClaims
+}
+
+// Authenticate rejects requests without a valid access token or API key and stores the caller in the context.
+// API keys and OAuth clients are only accepted on routes naming the scopes they need, and must have all of them.
+func Authenticate(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, err := principalFromRequest(c.Request)
+		if err != nil {
+			abortWithError(c, http.StatusUnauthorized, err)
+			return
+		}
+		if principal.IsScoped() && (len(scopes) == 0 || !principal.HasScopes(scopes...)) {
+			abortWithError(c, http.StatusForbidden, errScope)
+			return
+		}
+		SetPrincipal(c, principal)
+		c.Next()
+	}
+}
+
+// AuthenticateOptional lets anonymous requests through, for public routes other services call with a token of
+// their own. Callers sending a token or API key are authenticated as by Authenticate.
+func AuthenticateOptional(scopes ...string) gin.HandlerFunc {
+	authenticate := Authenticate(scopes...)
+	return func(c *gin.Context) {
+		if c.GetHeader(apiKeyHeader) == "" && c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+		authenticate(c)
+	}
+}
+
+// RequireRole only lets principals with one of roles through, where RoleService only admits service clients.
+// It must run after Authenticate.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := CurrentPrincipal(c)
+		if !ok {
+			abortWithError(c, http.StatusUnauthorized, ErrMissingToken)
+			return
+		}
+		for _, role := range roles {
+			// Only client credentials act as another service, whatever roles a user was given
+			if role == RoleService && principal.IsServiceClient() || role != RoleService && principal.HasRole(role) {
+				c.Next()
+				return
+			}
+		}
+		abortWithError(c, http.StatusForbidden, ErrForbidden)
+	}
+}
+
+// RequirePermission only lets through principals granted all of permissions, and admins. It must run after
+// Authenticate.
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := CurrentPrincipal(c)
+		if !ok {
+			abortWithError(c, http.StatusUnauthorized, ErrMissingToken)
+			return
+		}
+		for _, permission := range permissions {
+			if !principal.HasPermission(permission) {
+				abortWithError(c, http.StatusForbidden, ErrForbidden)
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// RequireServiceOrPermission only lets through other services and principals granted all of permissions, for
+// routes both called by services and used by staff. It must run after Authenticate.
+func RequireServiceOrPermission(permissions ...string) gin.HandlerFunc {
+	requirePermission := RequirePermission(permissions...)
+	return func(c *gin.Context) {
+		principal, ok := CurrentPrincipal(c)
+		if !ok {
+			abortWithError(c, http.StatusUnauthorized, ErrMissingToken)
+			return
+		}
+		if principal.IsServiceClient() {
+			c.Next()
+			return
+		}
+		requirePermission(c)
+	}
+}
+
+// RequireOwner only lets through the user whose ID is in the path parameter param, and admins.
+// It must run after Authenticate.
+func RequireOwner(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := CurrentPrincipal(c)
+		if !ok {
+			abortWithError(c, http.StatusUnauthorized, ErrMissingToken)
+			return
+		}
+		userID, err := strconv.ParseUint(c.Param(param), 10, 32)
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, fmt.Errorf("invalid user ID: %v", err))
+			return
+		}
+		if !principal.CanAccessUser(uint(userID)) {
+			abortWithError(c, http.StatusForbidden, ErrForbidden)
+			return
+		}
+		c.Next()
+	}
+}
+
+// SetPrincipal stores principal as the caller of the request, as Authenticate does.
+func SetPrincipal(c *gin.Context, principal *Principal) {
+	c.Set(principalKey, principal)
+}
+
+// CurrentPrincipal returns the caller stored by Authenticate.
+func CurrentPrincipal(c *gin.Context) (*Principal, bool) {
+	value, ok := c.Get(principalKey)
+	if !ok {
+		return nil, false
+	}
+	principal, ok := value.(*Principal)
+	return principal, ok
+}
+
+func principalFromRequest(r *http.Request) (*Principal, error) {
+	if key := r.Header.Get(apiKeyHeader); key != "" {
+		principal, err := apiKeys().APIKeyPrincipal(key)
+		if err != nil {
+			return nil, errInvalidKey
+		}
+		return principal, nil
+	}
+
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return nil, ErrMissingToken
+	}
+
+	raw := strings.TrimPrefix(header, "Bearer ")
+	var tokenClaims claims
+	token, err := jwt.ParseWithClaims(raw, &tokenClaims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return verificationKeys().PublicKey(kid)
+	}, jwt.WithValidMethods(signingAlgorithms), jwt.WithExpirationRequired())
+	if err != nil || !token.Valid || (tokenClaims.UserID == 0 && (tokenClaims.Role != RoleService || tokenClaims.ClientID == "")) {
+		return nil, errInvalidToken
+	}
+	// Tokens of sessions the user signed out of are rejected before they expire
+	if tokenClaims.SessionID != 0 {
+		active, err := sessions().SessionActive(tokenClaims.SessionID, raw)
+		if err != nil || !active {
+			return nil, errInvalidToken
+		}
+	}
+
+	principal := &Principal{
+		UserID:        tokenClaims.UserID,
+		Username:      tokenClaims.Username,
+		Role:          tokenClaims.Role,
+		Roles:         tokenClaims.Roles,
+		Permissions:   tokenClaims.Permissions,
+		PhoneVerified: tokenClaims.PhoneVerified,
+		SessionID:     tokenClaims.SessionID,
+		ClientID:      tokenClaims.ClientID,
+	}
+	if principal.ClientID != "" {
+		principal.Scopes = strings.Fields(tokenClaims.Scope)
+	}
+	return principal, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrorResponder writes the response to a request that was not let through.
+type ErrorResponder func(c *gin.Context, statusCode int, err error)
+
+// errorResponse is the default error response, in the format most services respond with.
+type errorResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error"`
+}
+
+// errorResponder writes the responses to rejected requests, set by SetErrorResponder.
+var errorResponder ErrorResponder = func(c *gin.Context, statusCode int, err error) {
+	c.JSON(statusCode, errorResponse{Success: false, Error: err.Error()})
+}
+
+// SetErrorResponder sets how rejected requests are answered, for services whose error responses have another
+// format. It must be called before the server starts.
+func SetErrorResponder(responder ErrorResponder) {
+	errorResponder = responder
+}
+
+func abortWithError(c *gin.Context, statusCode int, err error) {
+	errorResponder(c, statusCode, err)
+	c.Abort()
+}
@@ -0,0 +1,363 @@
+package authz
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testKeys verifies tokens signed with a single Ed25519 key.
+type testKeys struct {
+	public crypto.PublicKey
+}
+
+func (k testKeys) PublicKey(kid string) (crypto.PublicKey, error) {
+	if kid != "test" {
+		return nil, errors.New("unknown signing key")
+	}
+	return k.public, nil
+}
+
+// testAPIKeys authenticates the API keys in the map.
+type testAPIKeys map[string]*Principal
+
+func (k testAPIKeys) APIKeyPrincipal(key string) (*Principal, error) {
+	principal, ok := k[key]
+	if !ok {
+		return nil, errInvalidKey
+	}
+	copied := *principal
+	return &copied, nil
+}
+
+func TestPrincipalHasScopes(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal Principal
+		scopes    []string
+		want      bool
+	}{
+		{name: "user", principal: Principal{UserID: 1, Role: "customer"}, scopes: []string{ScopeBook}, want: true},
+		{name: "api key with scope", principal: Principal{APIKeyID: 1, Scopes: []string{ScopeBook, ScopeSearch}}, scopes: []string{ScopeBook}, want: true},
+		{name: "api key without scope", principal: Principal{APIKeyID: 1, Scopes: []string{ScopeSearch}}, scopes: []string{ScopeBook}, want: false},
+		{name: "api key missing one scope", principal: Principal{APIKeyID: 1, Scopes: []string{ScopeSearch}}, scopes: []string{ScopeSearch, ScopeBook}, want: false},
+		{name: "service client with scope", principal: Principal{Role: RoleService, ClientID: "booking", Scopes: []string{ScopeSeatsWrite}}, scopes: []string{ScopeSeatsWrite}, want: true},
+		{name: "service client without scope", principal: Principal{Role: RoleService, ClientID: "booking", Scopes: []string{ScopeBusesRead}}, scopes: []string{ScopeSeatsWrite}, want: false},
+		{name: "oauth client of a user without scope", principal: Principal{UserID: 1, ClientID: "partner", Scopes: []string{"openid"}}, scopes: []string{ScopeBook}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.principal.HasScopes(tt.scopes...); got != tt.want {
+				t.Errorf("HasScopes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name       string
+		principal  *Principal
+		wantStatus int
+	}{
+		{name: "granted", principal: &Principal{UserID: 5, Role: "conductor", Permissions: []string{PermissionManifestRead}}, wantStatus: http.StatusOK},
+		{name: "admin", principal: &Principal{UserID: 1, Role: RoleAdmin}, wantStatus: http.StatusOK},
+		{name: "extra admin role", principal: &Principal{UserID: 2, Role: "customer", Roles: []string{"customer", RoleAdmin}}, wantStatus: http.StatusOK},
+		{name: "other permission", principal: &Principal{UserID: 5, Role: "conductor", Permissions: []string{PermissionSeatsUpdate}}, wantStatus: http.StatusForbidden},
+		{name: "no principal", wantStatus: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/", func(c *gin.Context) {
+				if tt.principal != nil {
+					SetPrincipal(c, tt.principal)
+				}
+			}, RequirePermission(PermissionManifestRead), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestAuthenticateAPIKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	SetAPIKeySource(testAPIKeys{
+		"book-key": {UserID: 7, Role: "customer", APIKeyID: 1, Scopes: []string{ScopeBook}},
+	})
+	t.Cleanup(func() { SetAPIKeySource(nil) })
+
+	tests := []struct {
+		name       string
+		scopes     []string
+		key        string
+		wantStatus int
+	}{
+		{name: "api key with scope", scopes: []string{ScopeBook}, key: "book-key", wantStatus: http.StatusOK},
+		{name: "api key without scope", scopes: []string{ScopeSearch}, key: "book-key", wantStatus: http.StatusForbidden},
+		{name: "api key on route without scopes", key: "book-key", wantStatus: http.StatusForbidden},
+		{name: "unknown api key", scopes: []string{ScopeBook}, key: "other", wantStatus: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/", Authenticate(tt.scopes...), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(apiKeyHeader, tt.key)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	SetKeySource(testKeys{public: public})
+	t.Cleanup(func() { SetKeySource(nil) })
+
+	sign := func(method jwt.SigningMethod, key interface{}, kid string, claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(method, claims)
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatalf("SignedString() error = %v", err)
+		}
+		return signed
+	}
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	exp := time.Now().Add(time.Minute).Unix()
+
+	tests := []struct {
+		name       string
+		header     string
+		wantStatus int
+	}{
+		{name: "valid token", header: "Bearer " + sign(jwt.SigningMethodEdDSA, private, "test", jwt.MapClaims{"userID": 7, "exp": exp}), wantStatus: http.StatusOK},
+		{name: "no token", wantStatus: http.StatusUnauthorized},
+		{name: "not a bearer token", header: "Basic YW5uOnNlY3JldA==", wantStatus: http.StatusUnauthorized},
+		{name: "expired token", header: "Bearer " + sign(jwt.SigningMethodEdDSA, private, "test", jwt.MapClaims{"userID": 7, "exp": time.Now().Add(-time.Minute).Unix()}), wantStatus: http.StatusUnauthorized},
+		{name: "token without expiry", header: "Bearer " + sign(jwt.SigningMethodEdDSA, private, "test", jwt.MapClaims{"userID": 7}), wantStatus: http.StatusUnauthorized},
+		{name: "signed with another key", header: "Bearer " + sign(jwt.SigningMethodEdDSA, otherKey, "test", jwt.MapClaims{"userID": 7, "exp": exp}), wantStatus: http.StatusUnauthorized},
+		{name: "unknown key", header: "Bearer " + sign(jwt.SigningMethodEdDSA, private, "old", jwt.MapClaims{"userID": 7, "exp": exp}), wantStatus: http.StatusUnauthorized},
+		{name: "shared secret", header: "Bearer " + sign(jwt.SigningMethodHS256, []byte("secret"), "test", jwt.MapClaims{"userID": 7, "exp": exp}), wantStatus: http.StatusUnauthorized},
+		{name: "service token without a client", header: "Bearer " + sign(jwt.SigningMethodEdDSA, private, "test", jwt.MapClaims{"role": RoleService, "exp": exp}), wantStatus: http.StatusUnauthorized},
+		{name: "no user", header: "Bearer " + sign(jwt.SigningMethodEdDSA, private, "test", jwt.MapClaims{"exp": exp}), wantStatus: http.StatusUnauthorized},
+		// Service and OAuth clients are limited to routes naming the scopes they were granted
+		{name: "service client", header: "Bearer " + sign(jwt.SigningMethodEdDSA, private, "test", jwt.MapClaims{"role": RoleService, "client_id": "booking", "scope": ScopeSeatsWrite, "exp": exp}), wantStatus: http.StatusForbidden},
+		{name: "oauth client", header: "Bearer " + sign(jwt.SigningMethodEdDSA, private, "test", jwt.MapClaims{"userID": 7, "client_id": "partner", "scope": "openid profile", "exp": exp}), wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/", Authenticate(), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+}
+
+func TestAuthenticateOptional(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	SetKeySource(testKeys{public: public})
+	t.Cleanup(func() { SetKeySource(nil) })
+
+	serviceToken := func(scope string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{"role": RoleService, "client_id": "route", "scope": scope, "exp": time.Now().Add(time.Minute).Unix()})
+		token.Header["kid"] = "test"
+		signed, err := token.SignedString(private)
+		if err != nil {
+			t.Fatalf("SignedString() error = %v", err)
+		}
+		return "Bearer " + signed
+	}
+	tests := []struct {
+		name          string
+		header        string
+		wantStatus    int
+		wantPrincipal bool
+	}{
+		{name: "anonymous", wantStatus: http.StatusOK},
+		{name: "service client with scope", header: serviceToken(ScopeBusesRead), wantStatus: http.StatusOK, wantPrincipal: true},
+		{name: "service client without scope", header: serviceToken(ScopeRoutesRead), wantStatus: http.StatusForbidden},
+		{name: "invalid token", header: "Bearer invalid", wantStatus: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var authenticated bool
+			router := gin.New()
+			router.GET("/", AuthenticateOptional(ScopeBusesRead), func(c *gin.Context) {
+				_, authenticated = CurrentPrincipal(c)
+				c.Status(http.StatusOK)
+			})
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus || authenticated != tt.wantPrincipal {
+				t.Errorf("status = %d, authenticated = %v, want %d, %v: %s", rec.Code, authenticated, tt.wantStatus, tt.wantPrincipal, rec.Body)
+			}
+		})
+	}
+}
+
+func TestRequireOwner(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name       string
+		principal  *Principal
+		path       string
+		wantStatus int
+	}{
+		{name: "owner", principal: &Principal{UserID: 7, Role: "customer"}, path: "/users/7", wantStatus: http.StatusOK},
+		{name: "another user", principal: &Principal{UserID: 8, Role: "customer"}, path: "/users/7", wantStatus: http.StatusForbidden},
+		{name: "admin", principal: &Principal{UserID: 1, Role: RoleAdmin}, path: "/users/7", wantStatus: http.StatusOK},
+		{name: "invalid user ID", principal: &Principal{UserID: 7}, path: "/users/ann", wantStatus: http.StatusBadRequest},
+		{name: "not authenticated", path: "/users/7", wantStatus: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/users/:id", func(c *gin.Context) {
+				if tt.principal != nil {
+					SetPrincipal(c, tt.principal)
+				}
+			}, RequireOwner("id"), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestSetErrorResponder(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	previous := errorResponder
+	t.Cleanup(func() { SetErrorResponder(previous) })
+	SetErrorResponder(func(c *gin.Context, statusCode int, err error) {
+		c.String(statusCode, "denied: "+err.Error())
+	})
+	router := gin.New()
+	router.GET("/", Authenticate(), func(c *gin.Context) {
+		t.Error("handler ran for an unauthenticated request")
+	})
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if want := "denied: " + ErrMissingToken.Error(); rec.Code != http.StatusUnauthorized || rec.Body.String() != want {
+		t.Errorf("response = %d %q, want %d %q", rec.Code, rec.Body, http.StatusUnauthorized, want)
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name       string
+		principal  *Principal
+		wantStatus int
+	}{
+		{name: "service client", principal: &Principal{Role: RoleService, ClientID: "booking"}, wantStatus: http.StatusOK},
+		{name: "admin", principal: &Principal{UserID: 1, Role: RoleAdmin}, wantStatus: http.StatusOK},
+		{name: "user with the service role", principal: &Principal{UserID: 9, Role: "customer", Roles: []string{"customer", RoleService}}, wantStatus: http.StatusForbidden},
+		{name: "oauth client of a user with the service role", principal: &Principal{UserID: 9, Role: RoleService, ClientID: "partner"}, wantStatus: http.StatusForbidden},
+		{name: "customer", principal: &Principal{UserID: 7, Role: "customer"}, wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/", func(c *gin.Context) {
+				SetPrincipal(c, tt.principal)
+			}, RequireRole(RoleAdmin, RoleService), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRequireServiceOrPermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name       string
+		principal  *Principal
+		wantStatus int
+	}{
+		{name: "service client", principal: &Principal{Role: RoleService, ClientID: "booking", Scopes: []string{ScopeSeatsWrite}}, wantStatus: http.StatusOK},
+		{name: "conductor", principal: &Principal{UserID: 5, Role: "conductor", Permissions: []string{PermissionSeatsUpdate}}, wantStatus: http.StatusOK},
+		{name: "admin", principal: &Principal{UserID: 1, Role: RoleAdmin}, wantStatus: http.StatusOK},
+		{name: "customer", principal: &Principal{UserID: 7, Role: "customer"}, wantStatus: http.StatusForbidden},
+		{name: "user with the service role", principal: &Principal{UserID: 9, Role: RoleService}, wantStatus: http.StatusForbidden},
+		{name: "no principal", wantStatus: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.PUT("/", func(c *gin.Context) {
+				if tt.principal != nil {
+					SetPrincipal(c, tt.principal)
+				}
+			}, RequireServiceOrPermission(PermissionSeatsUpdate), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/", nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package authz
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// jwksRefreshInterval is how long the fetched key set is used before it is fetched again.
+	jwksRefreshInterval = 10 * time.Minute
+	// jwksMinRefreshInterval limits refreshes caused by tokens naming an unknown key.
+	jwksMinRefreshInterval = 30 * time.Second
+)
+
+var (
+	keySource KeySource
+	jwksOnce  sync.Once
+	jwksCache *JWKSCache
+)
+
+// SetKeySource sets the keys access tokens are verified with instead of those auth-service publishes. auth-service
+// verifies its own tokens with the keys it signs them with. It must be called before the server starts.
+func SetKeySource(source KeySource) {
+	keySource = source
+}
+
+// verificationKeys returns the keys set by SetKeySource, or those published by auth-service at
+// AUTH_SERVICE_JWKS_URL.
+func verificationKeys() KeySource {
+	if keySource != nil {
+		return keySource
+	}
+	jwksOnce.Do(func() {
+		jwksCache = NewJWKSCache(os.Getenv("AUTH_SERVICE_JWKS_URL"))
+	})
+	return jwksCache
+}
+
+// jwk is a public key of a JSON Web Key Set.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+}
+
+// JWKSCache fetches the token signing keys published by auth-service and caches them. The key set is fetched
+// again every jwksRefreshInterval, and early when a token names a key that is not cached, which happens right
+// after auth-service rotated its key. One request fetches the key set at a time, the others wait for it without
+// holding the lock, so requests whose keys are cached are never slowed down by a fetch.
+type JWKSCache struct {
+	url    string
+	client *http.Client
+
+	mu          sync.Mutex
+	keys        map[string]crypto.PublicKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	refreshing  chan struct{} // Closed when the fetch in flight ends, nil when there is none
+}
+
+// NewJWKSCache creates a new JWKSCache for the key set at url.
+func NewJWKSCache(url string) *JWKSCache {
+	return &JWKSCache{
+		url:    url,
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// PublicKey returns the public key with the given key ID.
+func (c *JWKSCache) PublicKey(kid string) (crypto.PublicKey, error) {
+	c.mu.Lock()
+	key, ok := c.keys[kid]
+	if ok && time.Since(c.fetchedAt) < jwksRefreshInterval {
+		c.mu.Unlock()
+		return key, nil
+	}
+	if refreshing := c.refreshing; refreshing != nil {
+		c.mu.Unlock()
+		if ok {
+			// Keep verifying with the cached key until the key set being fetched replaces it.
+			return key, nil
+		}
+		<-refreshing
+		c.mu.Lock()
+		key, ok = c.keys[kid]
+		c.mu.Unlock()
+		return signingKey(kid, key, ok)
+	}
+	if time.Since(c.attemptedAt) < jwksMinRefreshInterval {
+		c.mu.Unlock()
+		return signingKey(kid, key, ok)
+	}
+	refreshing := make(chan struct{})
+	c.refreshing, c.attemptedAt = refreshing, time.Now()
+	c.mu.Unlock()
+
+	keys, err := c.fetch()
+
+	c.mu.Lock()
+	if err == nil {
+		c.keys, c.fetchedAt = keys, time.Now()
+	}
+	c.refreshing = nil
+	c.mu.Unlock()
+	close(refreshing)
+
+	if err != nil {
+		if ok {
+			// Keep verifying with the cached key while auth-service cannot be reached.
+			return key, nil
+		}
+		return nil, err
+	}
+	key, ok = keys[kid]
+	return signingKey(kid, key, ok)
+}
+
+// signingKey returns key if it was found, and an error naming the unknown key otherwise.
+func signingKey(kid string, key crypto.PublicKey, ok bool) (crypto.PublicKey, error) {
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+func (c *JWKSCache) fetch() (map[string]crypto.PublicKey, error) {
+	if c.url == "" {
+		return nil, errors.New("AUTH_SERVICE_JWKS_URL is not set")
+	}
+	resp, err := c.client.Get(c.url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch signing keys: status %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode signing keys: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		key, err := k.publicKey()
+		if err != nil {
+			continue // Skip keys of types this service cannot verify
+		}
+		keys[k.Kid] = key
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch {
+	case k.Kty == "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case k.Kty == "OKP" && k.Crv == "Ed25519":
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 public key")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+	}
+}
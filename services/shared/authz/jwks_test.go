@@ -0,0 +1,137 @@
+package authz
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// keySetServer serves a key set holding an Ed25519 key for each of kids. Fetches wait for release when it is
+// not nil.
+func keySetServer(t *testing.T, release <-chan struct{}, fetches *int32, kids ...string) *httptest.Server {
+	t.Helper()
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	for _, kid := range kids {
+		public, _, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatalf("GenerateKey() error = %v", err)
+		}
+		set.Keys = append(set.Keys, jwk{Kty: "OKP", Crv: "Ed25519", Kid: kid, X: base64.RawURLEncoding.EncodeToString(public)})
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(fetches, 1)
+		if release != nil {
+			<-release
+		}
+		json.NewEncoder(w).Encode(set)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestJWKSCacheFetchesOnceForConcurrentRequests(t *testing.T) {
+	release := make(chan struct{})
+	var fetches int32
+	cache := NewJWKSCache(keySetServer(t, release, &fetches, "k1").URL)
+
+	const requests = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, requests)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := cache.PublicKey("k1")
+			errs <- err
+		}()
+	}
+	// Let every request reach the cache before the key set is served
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("PublicKey() error = %v", err)
+		}
+	}
+	if n := atomic.LoadInt32(&fetches); n != 1 {
+		t.Errorf("key set fetched %d times, want 1", n)
+	}
+}
+
+func TestJWKSCacheServesCachedKeysDuringFetch(t *testing.T) {
+	var fetches int32
+	cache := NewJWKSCache(keySetServer(t, nil, &fetches, "k1").URL)
+	if _, err := cache.PublicKey("k1"); err != nil {
+		t.Fatalf("PublicKey() error = %v", err)
+	}
+
+	// Fetch the key set again from a server that does not answer until released
+	release := make(chan struct{})
+	defer close(release)
+	cache.url = keySetServer(t, release, &fetches, "k2").URL
+	cache.mu.Lock()
+	cache.fetchedAt = time.Now().Add(-jwksRefreshInterval)
+	cache.attemptedAt = time.Time{}
+	cache.mu.Unlock()
+	go cache.PublicKey("k2")
+	time.Sleep(50 * time.Millisecond)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := cache.PublicKey("k1")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("PublicKey() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("PublicKey() of a cached key waited for the fetch in flight")
+	}
+}
+
+func TestJWKSCacheFetchesUnknownKeysAtMostOnceAnInterval(t *testing.T) {
+	var fetches int32
+	cache := NewJWKSCache(keySetServer(t, nil, &fetches, "k1").URL)
+
+	if _, err := cache.PublicKey("k1"); err != nil {
+		t.Fatalf("PublicKey() error = %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := cache.PublicKey("k2"); err == nil {
+			t.Error("PublicKey() of a key missing from the key set returned no error")
+		}
+	}
+	if n := atomic.LoadInt32(&fetches); n != 1 {
+		t.Errorf("key set fetched %d times, want 1", n)
+	}
+}
+
+func TestJWKSCacheKeepsCachedKeysWhenTheFetchFails(t *testing.T) {
+	var fetches int32
+	server := keySetServer(t, nil, &fetches, "k1")
+	cache := NewJWKSCache(server.URL)
+	if _, err := cache.PublicKey("k1"); err != nil {
+		t.Fatalf("PublicKey() error = %v", err)
+	}
+
+	server.Close()
+	cache.fetchedAt = time.Now().Add(-jwksRefreshInterval)
+	cache.attemptedAt = time.Time{}
+	if _, err := cache.PublicKey("k1"); err != nil {
+		t.Errorf("PublicKey() error = %v, want the cached key", err)
+	}
+}
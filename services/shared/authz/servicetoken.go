@@ -0,0 +1,138 @@
+package authz
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// serviceTokenRefreshMargin is how long before it expires a cached service token is replaced, so requests do not
+// reach the other service with a token that expires in flight.
+const serviceTokenRefreshMargin = 30 * time.Second
+
+// serviceTokenResponse is the response of the auth-service token endpoint.
+type serviceTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// serviceTokenError is the error response of the auth-service token endpoint.
+type serviceTokenError struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// ServiceTokenSource gets access tokens for this service from auth-service with the client credentials grant
+// and caches them until shortly before they expire. Without client credentials, requests are sent without a
+// token, which only the public routes of other services accept.
+type ServiceTokenSource struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scope        string
+	client       *http.Client
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// NewServiceTokenSource creates a new ServiceTokenSource for the client clientID, asking for scope.
+func NewServiceTokenSource(tokenURL, clientID, clientSecret, scope string) *ServiceTokenSource {
+	if clientID == "" {
+		log.Printf("SERVICE_CLIENT_ID is not set, calling other services without a token")
+	}
+	return &ServiceTokenSource{
+		tokenURL:     tokenURL,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		scope:        scope,
+		client:       &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// NewServiceTokenSourceFromEnv creates a new ServiceTokenSource asking for scope, with the client credentials in
+// SERVICE_CLIENT_ID and SERVICE_CLIENT_SECRET and the token endpoint at AUTH_SERVICE_TOKEN_URL.
+func NewServiceTokenSourceFromEnv(scope string) *ServiceTokenSource {
+	return NewServiceTokenSource(os.Getenv("AUTH_SERVICE_TOKEN_URL"), os.Getenv("SERVICE_CLIENT_ID"), os.Getenv("SERVICE_CLIENT_SECRET"), scope)
+}
+
+// Enabled reports whether the source has client credentials to get tokens with.
+func (s *ServiceTokenSource) Enabled() bool {
+	return s.clientID != ""
+}
+
+// Token returns the cached access token, or gets a new one when it is about to expire.
+func (s *ServiceTokenSource) Token() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && time.Now().Before(s.expiresAt.Add(-serviceTokenRefreshMargin)) {
+		return s.token, nil
+	}
+	token, expiresAt, err := s.fetch()
+	if err != nil {
+		return "", err
+	}
+	s.token, s.expiresAt = token, expiresAt
+	return token, nil
+}
+
+// Authorize sets the access token of this service on req, unless the source has no client credentials.
+func (s *ServiceTokenSource) Authorize(req *http.Request) error {
+	if !s.Enabled() {
+		return nil
+	}
+	token, err := s.Token()
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// Expire drops the cached token when another service rejected it with statusCode, so the next request gets a
+// new one.
+func (s *ServiceTokenSource) Expire(statusCode int) {
+	if statusCode == http.StatusUnauthorized {
+		s.mu.Lock()
+		s.token = ""
+		s.mu.Unlock()
+	}
+}
+
+func (s *ServiceTokenSource) fetch() (string, time.Time, error) {
+	if s.tokenURL == "" {
+		return "", time.Time{}, errors.New("AUTH_SERVICE_TOKEN_URL is not set")
+	}
+	form := url.Values{"grant_type": {"client_credentials"}, "scope": {s.scope}}
+	req, err := http.NewRequest(http.MethodPost, s.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(s.clientID, s.clientSecret)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to get service token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var failure serviceTokenError
+		json.NewDecoder(resp.Body).Decode(&failure)
+		return "", time.Time{}, fmt.Errorf("failed to get service token: status %d: %s", resp.StatusCode, failure.Error)
+	}
+
+	var result serviceTokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to decode service token: %w", err)
+	}
+	return result.AccessToken, time.Now().Add(time.Duration(result.ExpiresIn) * time.Second), nil
+}
@@ -0,0 +1,105 @@
+package authz
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// tokenServer issues numbered client credentials tokens to the client "bus" with secret "secret".
+func tokenServer(t *testing.T, issued *int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientID, secret, _ := r.BasicAuth()
+		if clientID != "bus" || secret != "secret" || r.FormValue("grant_type") != "client_credentials" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(serviceTokenError{Error: "invalid_client"})
+			return
+		}
+		*issued++
+		json.NewEncoder(w).Encode(serviceTokenResponse{AccessToken: fmt.Sprintf("token-%d-%s", *issued, r.FormValue("scope")), ExpiresIn: 300})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestServiceTokenSourceToken(t *testing.T) {
+	var issued int
+	source := NewServiceTokenSource(tokenServer(t, &issued).URL, "bus", "secret", ScopeRoutesRead)
+
+	tests := []struct {
+		name   string
+		before func()
+		want   string
+	}{
+		{name: "first token", want: "token-1-routes:read"},
+		{name: "cached token", want: "token-1-routes:read"},
+		{name: "after a rejection", before: func() { source.Expire(http.StatusUnauthorized) }, want: "token-2-routes:read"},
+		{name: "after another error", before: func() { source.Expire(http.StatusForbidden) }, want: "token-2-routes:read"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.before != nil {
+				tt.before()
+			}
+			got, err := source.Token()
+			if err != nil {
+				t.Fatalf("Token() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Token() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServiceTokenSourceAuthorize(t *testing.T) {
+	var issued int
+	server := tokenServer(t, &issued)
+
+	tests := []struct {
+		name       string
+		source     *ServiceTokenSource
+		wantHeader string
+		wantErr    bool
+	}{
+		{name: "client credentials", source: NewServiceTokenSource(server.URL, "bus", "secret", ScopeSeatsWrite), wantHeader: "Bearer token-1-seats:write"},
+		{name: "no client credentials", source: NewServiceTokenSource(server.URL, "", "", ScopeSeatsWrite)},
+		{name: "wrong secret", source: NewServiceTokenSource(server.URL, "bus", "wrong", ScopeSeatsWrite), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			err := tt.source.Authorize(req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Authorize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := req.Header.Get("Authorization"); got != tt.wantHeader {
+				t.Errorf("Authorization = %q, want %q", got, tt.wantHeader)
+			}
+		})
+	}
+}
+
+func TestAPIKeyCacheSendsServiceToken(t *testing.T) {
+	var issued int
+	tokens := NewServiceTokenSource(tokenServer(t, &issued).URL, "bus", "secret", ScopeAPIKeysIntrospect)
+	introspection := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token-1-api-keys:introspect" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(apiKeyIntrospection{Active: true, KeyID: 3, UserID: 7, Scope: "search book"})
+	}))
+	t.Cleanup(introspection.Close)
+
+	principal, err := NewAPIKeyCache(introspection.URL, tokens).APIKeyPrincipal("key")
+	if err != nil {
+		t.Fatalf("APIKeyPrincipal() error = %v", err)
+	}
+	if principal.APIKeyID != 3 || principal.UserID != 7 || !principal.HasScopes(ScopeSearch, ScopeBook) {
+		t.Errorf("APIKeyPrincipal() = %+v", principal)
+	}
+}
@@ -0,0 +1,137 @@
+package authz
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// sessionCacheTTL is how long the state of a session is reused, so access tokens of revoked sessions are
+	// accepted for at most this long.
+	sessionCacheTTL = time.Minute
+	// sessionCacheSize bounds the cached session states.
+	sessionCacheSize = 10000
+)
+
+var (
+	sessionSource SessionSource
+	sessionOnce   sync.Once
+	sessionCache  *SessionCache
+)
+
+// SetSessionSource sets where the sessions of access tokens are checked instead of the introspection endpoint
+// of auth-service. auth-service looks sessions up itself. It must be called before the server starts.
+func SetSessionSource(source SessionSource) {
+	sessionSource = source
+}
+
+// sessions returns the sessions set by SetSessionSource, or those checked with auth-service at
+// AUTH_SERVICE_SESSION_INTROSPECTION_URL.
+func sessions() SessionSource {
+	if sessionSource != nil {
+		return sessionSource
+	}
+	sessionOnce.Do(func() {
+		sessionCache = NewSessionCache(os.Getenv("AUTH_SERVICE_SESSION_INTROSPECTION_URL"))
+	})
+	return sessionCache
+}
+
+// sessionIntrospection mirrors the session introspection response of auth-service.
+type sessionIntrospection struct {
+	Active    bool `json:"active"`
+	SessionID uint `json:"sessionId"`
+}
+
+// sessionEntry is the cached state of a session.
+type sessionEntry struct {
+	active    bool
+	expiresAt time.Time
+}
+
+// SessionCache checks with auth-service that the sessions of access tokens are active and caches the results
+// for sessionCacheTTL, keyed by session ID.
+type SessionCache struct {
+	url    string
+	client *http.Client
+
+	mu      sync.Mutex
+	entries map[uint]sessionEntry
+}
+
+// NewSessionCache creates a new SessionCache for the introspection endpoint at url.
+func NewSessionCache(url string) *SessionCache {
+	return &SessionCache{
+		url:     url,
+		client:  &http.Client{Timeout: 5 * time.Second},
+		entries: make(map[uint]sessionEntry),
+	}
+}
+
+// SessionActive reports whether the session of token, which was already verified, is still active.
+func (c *SessionCache) SessionActive(sessionID uint, token string) (bool, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[sessionID]
+	c.mu.Unlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.active, nil
+	}
+
+	active, err := c.introspect(sessionID, token)
+	if err != nil {
+		return false, err
+	}
+	c.store(sessionID, sessionEntry{active: active, expiresAt: time.Now().Add(sessionCacheTTL)})
+	return active, nil
+}
+
+func (c *SessionCache) store(sessionID uint, entry sessionEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= sessionCacheSize {
+		now := time.Now()
+		for id, e := range c.entries {
+			if now.After(e.expiresAt) {
+				delete(c.entries, id)
+			}
+		}
+		if len(c.entries) >= sessionCacheSize {
+			c.entries = make(map[uint]sessionEntry)
+		}
+	}
+	c.entries[sessionID] = entry
+}
+
+func (c *SessionCache) introspect(sessionID uint, token string) (bool, error) {
+	if c.url == "" {
+		return false, errors.New("AUTH_SERVICE_SESSION_INTROSPECTION_URL is not set")
+	}
+	req, err := http.NewRequest(http.MethodGet, c.url, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to introspect session: %w", err)
+	}
+	defer resp.Body.Close()
+	// auth-service rejects tokens of revoked sessions like any invalid token
+	if resp.StatusCode == http.StatusUnauthorized {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("failed to introspect session: status %d", resp.StatusCode)
+	}
+
+	var result sessionIntrospection
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, fmt.Errorf("failed to decode session introspection: %w", err)
+	}
+	return result.Active && result.SessionID == sessionID, nil
+}
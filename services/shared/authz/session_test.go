@@ -0,0 +1,108 @@
+package authz
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testSessions reports the sessions in the map as active.
+type testSessions map[uint]bool
+
+func (s testSessions) SessionActive(sessionID uint, _ string) (bool, error) {
+	return s[sessionID], nil
+}
+
+func TestAuthenticateRejectsRevokedSessions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	SetKeySource(testKeys{public: public})
+	SetSessionSource(testSessions{3: true})
+	t.Cleanup(func() {
+		SetKeySource(nil)
+		SetSessionSource(nil)
+	})
+
+	tests := []struct {
+		name       string
+		session    uint
+		wantStatus int
+	}{
+		{name: "active session", session: 3, wantStatus: http.StatusOK},
+		{name: "revoked session", session: 4, wantStatus: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{"userID": 7, "sid": tt.session, "exp": time.Now().Add(time.Minute).Unix()})
+			token.Header["kid"] = "test"
+			signed, err := token.SignedString(private)
+			if err != nil {
+				t.Fatalf("SignedString() error = %v", err)
+			}
+			router := gin.New()
+			router.GET("/", Authenticate(), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer "+signed)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestSessionCache(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		session    uint
+		want       bool
+		wantErr    bool
+		wantCached bool
+	}{
+		{name: "active session", status: http.StatusOK, session: 3, want: true, wantCached: true},
+		{name: "revoked session", status: http.StatusUnauthorized, wantCached: true},
+		{name: "token of another session", status: http.StatusOK, session: 4, wantCached: true},
+		{name: "auth-service failing", status: http.StatusInternalServerError, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				if r.Header.Get("Authorization") != "Bearer token" {
+					t.Errorf("Authorization = %q, want the checked token", r.Header.Get("Authorization"))
+				}
+				w.WriteHeader(tt.status)
+				json.NewEncoder(w).Encode(sessionIntrospection{Active: true, SessionID: tt.session})
+			}))
+			defer server.Close()
+			cache := NewSessionCache(server.URL)
+
+			for i := 0; i < 2; i++ {
+				active, err := cache.SessionActive(3, "token")
+				if (err != nil) != tt.wantErr || active != tt.want {
+					t.Fatalf("SessionActive() = %v, %v, want %v, error %v", active, err, tt.want, tt.wantErr)
+				}
+			}
+			// The state of a session is reused until it expires, failures are not
+			if cached := atomic.LoadInt32(&calls) == 1; cached != tt.wantCached {
+				t.Errorf("introspected %d times, want cached = %v", calls, tt.wantCached)
+			}
+		})
+	}
+}
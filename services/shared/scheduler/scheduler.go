@@ -0,0 +1,109 @@
+// Package scheduler runs the periodic background jobs of the services, once across all their replicas.
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"sync"
+	"time"
+)
+
+// Job is a unit of background work run periodically by the Scheduler.
+type Job struct {
+	// Name identifies the job in logs.
+	Name string
+	// Interval is the time between two runs of the job.
+	Interval time.Duration
+	// LockKey is the PostgreSQL advisory lock key guarding the job; only one replica runs the job at a time.
+	LockKey int64
+	// Run performs the work. It should return promptly once ctx is cancelled.
+	Run func(ctx context.Context) error
+}
+
+// Scheduler runs Jobs on their interval until its context is cancelled.
+// Each run is guarded by a PostgreSQL advisory lock so that it is safe to run several replicas of the service.
+type Scheduler struct {
+	db   *sql.DB
+	jobs []Job
+	wg   sync.WaitGroup
+}
+
+// NewScheduler creates a new Scheduler using db for advisory locking.
+func NewScheduler(db *sql.DB) *Scheduler {
+	return &Scheduler{db: db}
+}
+
+// Register adds a job to the scheduler. Jobs must be registered before Start is called.
+func (s *Scheduler) Register(job Job) {
+	s.jobs = append(s.jobs, job)
+}
+
+// Start launches every registered job in its own goroutine and returns immediately.
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go func(job Job) {
+			defer s.wg.Done()
+			s.loop(ctx, job)
+		}(job)
+	}
+	log.Printf("Scheduler started with %d jobs", len(s.jobs))
+}
+
+// Wait blocks until every job has returned after the context passed to Start was cancelled.
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.runOnce(ctx, job)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runOnce runs the job if this replica can take its advisory lock, and skips the run otherwise.
+func (s *Scheduler) runOnce(ctx context.Context, job Job) {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Scheduler job %s: could not get database connection: %v", job.Name, err)
+		}
+		return
+	}
+	defer func(conn *sql.Conn) {
+		if err := conn.Close(); err != nil {
+			log.Printf("Scheduler job %s: could not close database connection: %v", job.Name, err)
+		}
+	}(conn)
+
+	// Session level advisory locks belong to the connection, so the lock is taken and released on the same conn.
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", job.LockKey).Scan(&acquired); err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Scheduler job %s: could not acquire advisory lock: %v", job.Name, err)
+		}
+		return
+	}
+	if !acquired {
+		return
+	}
+	defer func() {
+		// Use a fresh context so the lock is released even when shutting down.
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", job.LockKey); err != nil {
+			log.Printf("Scheduler job %s: could not release advisory lock: %v", job.Name, err)
+		}
+	}()
+
+	if err := job.Run(ctx); err != nil {
+		log.Printf("Scheduler job %s failed: %v", job.Name, err)
+	}
+}
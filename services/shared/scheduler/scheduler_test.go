@@ -0,0 +1,151 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// advisoryLocks stands in for the advisory locks of PostgreSQL, which are all the scheduler asks the database for.
+type advisoryLocks struct {
+	mu       sync.Mutex
+	held     map[int64]bool
+	unlocked []int64
+}
+
+func (l *advisoryLocks) Connect(context.Context) (driver.Conn, error) {
+	return &lockConn{locks: l}, nil
+}
+func (l *advisoryLocks) Driver() driver.Driver { return nil }
+
+type lockConn struct {
+	locks *advisoryLocks
+}
+
+func (c *lockConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *lockConn) Close() error                        { return nil }
+func (c *lockConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c *lockConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if !strings.Contains(query, "pg_try_advisory_lock") {
+		return nil, errors.New("unexpected query " + query)
+	}
+	c.locks.mu.Lock()
+	defer c.locks.mu.Unlock()
+	key := args[0].Value.(int64)
+	acquired := !c.locks.held[key]
+	c.locks.held[key] = true
+	return &boolRows{value: acquired}, nil
+}
+
+func (c *lockConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if !strings.Contains(query, "pg_advisory_unlock") {
+		return nil, errors.New("unexpected query " + query)
+	}
+	c.locks.mu.Lock()
+	defer c.locks.mu.Unlock()
+	key := args[0].Value.(int64)
+	delete(c.locks.held, key)
+	c.locks.unlocked = append(c.locks.unlocked, key)
+	return driver.RowsAffected(0), nil
+}
+
+type boolRows struct {
+	value bool
+	done  bool
+}
+
+func (r *boolRows) Columns() []string { return []string{"acquired"} }
+func (r *boolRows) Close() error      { return nil }
+func (r *boolRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = r.value
+	return nil
+}
+
+func newTestScheduler(locks *advisoryLocks) *Scheduler {
+	return NewScheduler(sql.OpenDB(locks))
+}
+
+func TestRunOnce(t *testing.T) {
+	tests := []struct {
+		name        string
+		heldByPeer  bool
+		wantRun     bool
+		wantUnlocks int
+	}{
+		{name: "runs the job and releases its lock", wantRun: true, wantUnlocks: 1},
+		{name: "skips the job while another replica holds its lock", heldByPeer: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locks := &advisoryLocks{held: map[int64]bool{42: tt.heldByPeer}}
+			ran := false
+			job := Job{Name: "test", LockKey: 42, Run: func(context.Context) error {
+				ran = true
+				return nil
+			}}
+
+			newTestScheduler(locks).runOnce(context.Background(), job)
+
+			if ran != tt.wantRun {
+				t.Errorf("job ran = %v, want %v", ran, tt.wantRun)
+			}
+			if len(locks.unlocked) != tt.wantUnlocks {
+				t.Errorf("lock released %d times, want %d", len(locks.unlocked), tt.wantUnlocks)
+			}
+		})
+	}
+}
+
+func TestRunOnceReleasesTheLockWhenTheJobFails(t *testing.T) {
+	locks := &advisoryLocks{held: map[int64]bool{}}
+	job := Job{Name: "failing", LockKey: 7, Run: func(context.Context) error {
+		return errors.New("boom")
+	}}
+
+	newTestScheduler(locks).runOnce(context.Background(), job)
+
+	if locks.held[7] {
+		t.Error("lock still held after the job failed")
+	}
+}
+
+func TestStartRunsJobsUntilCancelled(t *testing.T) {
+	locks := &advisoryLocks{held: map[int64]bool{}}
+	s := newTestScheduler(locks)
+	runs := make(chan struct{}, 10)
+	s.Register(Job{Name: "ticker", Interval: time.Millisecond, LockKey: 1, Run: func(context.Context) error {
+		select {
+		case runs <- struct{}{}:
+		default:
+		}
+		return nil
+	}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.Start(ctx)
+	<-runs
+	<-runs
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Wait() did not return after the context was cancelled")
+	}
+}